@@ -1,19 +1,31 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"codex-mirror/internal"
 )
 
+// 发送给前端的事件名称.
+const (
+	EventSyncProgress = "sync:progress" // 同步进度，数据为 internal.SyncProgress
+	EventSyncConflict = "sync:conflict" // 同步检测到冲突，数据为 []ConflictItemDTO
+	EventTestResult   = "test:result"   // 单个镜像源测试完成，数据为 TestResultDTO
+)
+
 // App GUI 应用结构体，封装 internal 包调用.
 type App struct {
+	ctx           context.Context // Wails 运行时上下文，Startup 前为空
 	mirrorManager *internal.MirrorManager
 	configPath    string
+	tester        internal.ConnectivityTester // 连通性测试器，零值使用默认配置
 }
 
 // MirrorDTO 镜像源数据传输对象.
@@ -31,22 +43,11 @@ type MirrorDTO struct {
 	LastModified string            `json:"last_modified"`
 }
 
-// StatusDTO 状态数据传输对象.
-type StatusDTO struct {
-	CurrentCodex  string       `json:"current_codex"`
-	CurrentClaude string       `json:"current_claude"`
-	CodexStatus   ConfigStatus `json:"codex_status"`
-	ClaudeStatus  ConfigStatus `json:"claude_status"`
-	VSCodeStatus  ConfigStatus `json:"vscode_status"`
-	ConfigPath    string       `json:"config_path"`
-}
+// StatusDTO 状态数据传输对象，与 CLI 的 status --json 输出一致.
+type StatusDTO = internal.StatusDTO
 
 // ConfigStatus 配置状态.
-type ConfigStatus struct {
-	Exists bool   `json:"exists"`
-	Path   string `json:"path"`
-	Error  string `json:"error,omitempty"`
-}
+type ConfigStatus = internal.ConfigStatus
 
 // NewApp 创建 App 实例.
 func NewApp() (*App, error) {
@@ -105,13 +106,11 @@ func (a *App) UpdateMirror(mirror MirrorDTO) error {
 		return err
 	}
 
+	// 前端回传的掩码值（或纯星号占位符）表示未修改密钥
 	apiKey := mirror.APIKey
-	if isMaskedAPIKey(apiKey) {
+	if apiKey != "" && (apiKey == internal.MaskAPIKey(originalMirror.APIKey) || strings.Trim(apiKey, "*") == "") {
 		apiKey = originalMirror.APIKey
 	}
-	if apiKey == "" && mirror.APIKey == "" && !isMaskedAPIKey(mirror.APIKey) {
-		apiKey = ""
-	}
 
 	err = a.mirrorManager.UpdateMirrorFull(
 		mirror.Name,
@@ -126,13 +125,7 @@ func (a *App) UpdateMirror(mirror MirrorDTO) error {
 
 	// 如果有额外环境变量，需要特殊处理
 	if len(mirror.ExtraEnv) > 0 {
-		// 获取原始镜像源配置
-		mirrorConfig, err := a.mirrorManager.GetMirrorByName(mirror.Name)
-		if err != nil {
-			return err
-		}
-		mirrorConfig.ExtraEnv = mirror.ExtraEnv
-		return a.mirrorManager.SaveConfig()
+		return a.mirrorManager.UpdateMirrorExtraEnv(mirror.Name, mirror.ExtraEnv)
 	}
 
 	return nil
@@ -151,6 +144,12 @@ func (a *App) SwitchMirror(name string) error {
 		return err
 	}
 
+	// 记录切换前的镜像源，用于判断是否从 Codex 切换到 Claude
+	var previousType internal.ToolType
+	if previous, err := a.mirrorManager.GetCurrentMirror(); err == nil {
+		previousType = previous.ToolType
+	}
+
 	// 切换镜像源
 	if err := a.mirrorManager.SwitchMirror(name); err != nil {
 		return err
@@ -168,6 +167,17 @@ func (a *App) SwitchMirror(name string) error {
 		if err := a.applyClaudeConfig(name); err != nil {
 			return fmt.Errorf("应用 Claude 配置失败: %w", err)
 		}
+
+		// 清理 VS Code 中指向旧 Codex 端点的配置
+		if previousType == internal.ToolTypeCodex {
+			if vcm, err := internal.NewVSCodeConfigManager(); err == nil {
+				if _, err := os.Stat(vcm.GetSettingsPath()); err == nil {
+					if err := vcm.RemoveChatGPTConfig(); err != nil {
+						return fmt.Errorf("清理 VS Code 配置失败: %w", err)
+					}
+				}
+			}
+		}
 	}
 
 	return nil
@@ -175,43 +185,76 @@ func (a *App) SwitchMirror(name string) error {
 
 // GetCurrentStatus 获取当前状态.
 func (a *App) GetCurrentStatus() StatusDTO {
-	config := a.mirrorManager.GetConfig()
+	return a.mirrorManager.Status()
+}
 
-	status := StatusDTO{
-		CurrentCodex:  config.CurrentCodex,
-		CurrentClaude: config.CurrentClaude,
-		ConfigPath:    a.configPath,
-	}
+// ValidateURL 验证 URL 格式.
+func (a *App) ValidateURL(url string) error {
+	return internal.ValidateBaseURL(url)
+}
 
-	// 检查 Codex 配置状态
-	if codexPath, err := internal.GetCodexConfigPath(); err == nil {
-		status.CodexStatus = ConfigStatus{
-			Path: codexPath,
-		}
-		if _, err := internal.GetPathConfig(); err == nil {
-			status.CodexStatus.Exists = true
-		}
+// TestResultDTO 连通性测试结果数据传输对象，字段与 CLI 的 TestResult 一致.
+type TestResultDTO struct {
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	ToolType     string `json:"tool_type"`
+	Success      bool   `json:"success"`
+	Latency      int64  `json:"latency_ms"` // 毫秒
+	StatusCode   int    `json:"status_code,omitempty"`
+	Error        string `json:"error,omitempty"`
+	HasAPIKey    bool   `json:"has_api_key"`
+	NetworkError bool   `json:"network_error,omitempty"`
+	Attempts     int    `json:"attempts"`
+	// 结果分类 (ok、unauthorized、forbidden、rate_limited、server_error 等) 和可用模型数量
+	Category   string `json:"category"`
+	ModelCount int    `json:"model_count,omitempty"`
+}
+
+// TestMirror 测试指定镜像源的连通性.
+func (a *App) TestMirror(name string) (TestResultDTO, error) {
+	mirror, err := a.mirrorManager.GetMirrorByName(name)
+	if err != nil {
+		return TestResultDTO{}, err
 	}
 
-	// 检查 Claude 配置状态
-	status.ClaudeStatus = ConfigStatus{
-		Exists: true, // Claude 使用环境变量，总是返回 true
-		Path:   "环境变量",
-	}
+	dto := toTestResultDTO(a.tester.Test(mirror, internal.DefaultTestTimeout, internal.DefaultTestRetries))
+	a.emit(EventTestResult, dto)
+	return dto, nil
+}
 
-	// 检查 VS Code 配置状态
-	if vscodePath, err := internal.GetVSCodeSettingsPath(); err == nil {
-		status.VSCodeStatus = ConfigStatus{
-			Path: vscodePath,
-		}
+// TestAllMirrors 并行测试所有镜像源的连通性，结果顺序与 ListMirrors 一致.
+// 每个镜像源测试完成时发送 test:result 事件.
+func (a *App) TestAllMirrors() []TestResultDTO {
+	mirrors := a.mirrorManager.ListActiveMirrors()
+	tester := a.tester
+	tester.OnResult = func(result *internal.TestResult) {
+		a.emit(EventTestResult, toTestResultDTO(result))
 	}
+	results := tester.TestAll(mirrors, internal.DefaultTestTimeout, internal.DefaultTestRetries, internal.DefaultMaxConcurrency)
 
-	return status
+	dtos := make([]TestResultDTO, 0, len(results))
+	for _, result := range results {
+		dtos = append(dtos, toTestResultDTO(result))
+	}
+	return dtos
 }
 
-// ValidateURL 验证 URL 格式.
-func (a *App) ValidateURL(url string) error {
-	return internal.ValidateBaseURL(url)
+// toTestResultDTO 将 TestResult 转换为 TestResultDTO.
+func toTestResultDTO(r *internal.TestResult) TestResultDTO {
+	return TestResultDTO{
+		Name:         r.Name,
+		URL:          r.URL,
+		ToolType:     string(r.ToolType),
+		Success:      r.Success,
+		Latency:      r.Latency,
+		StatusCode:   r.StatusCode,
+		Error:        r.Error,
+		HasAPIKey:    r.HasAPIKey,
+		NetworkError: r.NetworkError,
+		Attempts:     r.Attempts,
+		Category:     string(r.Category),
+		ModelCount:   r.ModelCount,
+	}
 }
 
 // toMirrorDTO 将 MirrorConfig 转换为 MirrorDTO.
@@ -219,7 +262,7 @@ func (a *App) toMirrorDTO(m internal.MirrorConfig, config *internal.SystemConfig
 	dto := MirrorDTO{
 		Name:         m.Name,
 		BaseURL:      m.BaseURL,
-		HasAPIKey:    m.APIKey != "",
+		HasAPIKey:    m.HasAPIKey(),
 		EnvKey:       m.EnvKey,
 		ToolType:     string(m.ToolType),
 		ModelName:    m.ModelName,
@@ -230,7 +273,7 @@ func (a *App) toMirrorDTO(m internal.MirrorConfig, config *internal.SystemConfig
 
 	// API Key 掩码处理
 	if m.APIKey != "" {
-		dto.APIKey = a.maskAPIKey(m.APIKey)
+		dto.APIKey = internal.MaskAPIKey(m.APIKey)
 	}
 
 	// 判断是否为当前激活的镜像源
@@ -243,39 +286,22 @@ func (a *App) toMirrorDTO(m internal.MirrorConfig, config *internal.SystemConfig
 	return dto
 }
 
-// maskAPIKey 掩码 API Key.
-func (a *App) maskAPIKey(apiKey string) string {
-	if len(apiKey) <= 8 {
-		return strings.Repeat("*", len(apiKey))
-	}
-	// 显示前4个字符和后4个字符，中间用*代替
-	return apiKey[:4] + strings.Repeat("*", len(apiKey)-8) + apiKey[len(apiKey)-4:]
-}
-
-func isMaskedAPIKey(key string) bool {
-	if key == "" {
-		return false
-	}
-	if key == strings.Repeat("*", len(key)) {
-		return true
-	}
-	hasStar := strings.Contains(key, "*")
-	hasVisible := strings.ReplaceAll(key, "*", "") != ""
-	return hasStar && hasVisible && (strings.HasPrefix(key, "*") || strings.HasSuffix(key, "*"))
-}
-
 // applyCodexConfig 应用配置到 Codex.
 func (a *App) applyCodexConfig(mirrorName string) error {
 	mirror, err := a.mirrorManager.GetMirrorByName(mirrorName)
 	if err != nil {
 		return err
 	}
+	if mirror, err = a.mirrorManager.ResolveModelAlias(mirror); err != nil {
+		return err
+	}
 
 	// 使用 CodexConfigManager 应用配置
 	ccm, err := internal.NewCodexConfigManager()
 	if err != nil {
 		return err
 	}
+	ccm.SetPersistEnv(!a.mirrorManager.NoEnv())
 
 	return ccm.ApplyMirror(mirror)
 }
@@ -286,6 +312,9 @@ func (a *App) applyClaudeConfig(mirrorName string) error {
 	if err != nil {
 		return err
 	}
+	if mirror, err = a.mirrorManager.ResolveModelAlias(mirror); err != nil {
+		return err
+	}
 
 	// 使用 ClaudeConfigManager 应用配置
 	ccm, err := internal.NewClaudeConfigManager()
@@ -301,6 +330,34 @@ func (a *App) GetConfigPath() string {
 	return a.configPath
 }
 
+// GetBuildInfo 获取程序版本和构建信息.
+func (a *App) GetBuildInfo() internal.BuildInfo {
+	return internal.GetBuildInfo()
+}
+
+// CheckForUpdate 检查 GitHub 上是否有新版本.
+func (a *App) CheckForUpdate() (internal.UpdateInfo, error) {
+	ctx := a.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	checker := &internal.UpdateChecker{}
+	info, err := checker.Check(ctx)
+	if err != nil {
+		return internal.UpdateInfo{}, fmt.Errorf("检查更新失败: %w", err)
+	}
+	return *info, nil
+}
+
+// OpenConfigDir 在系统文件管理器中打开配置目录.
+func (a *App) OpenConfigDir() error {
+	configDir := filepath.Dir(a.configPath)
+	if err := internal.OpenInFileManager(configDir); err != nil {
+		return fmt.Errorf("%v，请手动打开: %s", err, configDir)
+	}
+	return nil
+}
+
 // ExportConfig 导出配置（用于备份）.
 func (a *App) ExportConfig() (string, error) {
 	config := a.mirrorManager.GetConfig()
@@ -311,9 +368,34 @@ func (a *App) ExportConfig() (string, error) {
 	return string(data), nil
 }
 
+// ImportResultDTO 导入镜像源结果数据传输对象.
+type ImportResultDTO struct {
+	Added   int `json:"added"`
+	Skipped int `json:"skipped"`
+}
+
+// ExportMirrors 将镜像源导出为 JSON，默认掩码 API Key 等敏感值，includeKeys 为 true 时导出完整密钥.
+func (a *App) ExportMirrors(includeKeys bool) (string, error) {
+	var buf bytes.Buffer
+	if err := a.mirrorManager.ExportMirrors(&buf, internal.ExportFormatJSON, includeKeys); err != nil {
+		return "", fmt.Errorf("导出镜像源失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// ImportMirrors 从 JSON 导入镜像源，已存在的同名镜像源默认跳过，overwrite 为 true 时覆盖.
+func (a *App) ImportMirrors(data string, overwrite bool) (ImportResultDTO, error) {
+	added, skipped, err := a.mirrorManager.ImportMirrors(strings.NewReader(data), overwrite)
+	if err != nil {
+		return ImportResultDTO{}, fmt.Errorf("导入镜像源失败: %w", err)
+	}
+	return ImportResultDTO{Added: added, Skipped: skipped}, nil
+}
+
 // Startup 应用启动时的回调.
 func (a *App) Startup(ctx context.Context) {
-	// 初始化操作
+	// 保存运行时上下文，用于向前端发送事件
+	a.ctx = ctx
 }
 
 // DomReady DOM 加载完成时的回调.
@@ -344,6 +426,11 @@ type SyncStatusDTO struct {
 	SyncInterval int    `json:"sync_interval"`
 	LastSync     string `json:"last_sync"`
 	Message      string `json:"message"`
+	// LastSyncError 最近一次同步失败的原因，成功时为空.
+	LastSyncError string `json:"last_sync_error,omitempty"`
+	// LastRemoteDevice 云端配置最后写入的设备ID，LastRemoteTime 为其写入时间.
+	LastRemoteDevice string `json:"last_remote_device,omitempty"`
+	LastRemoteTime   string `json:"last_remote_time,omitempty"`
 }
 
 // SyncInitRequest 初始化同步请求.
@@ -365,37 +452,92 @@ type SyncInitResult struct {
 	Message string `json:"message"`
 }
 
-// GetSyncStatus 获取同步状态.
-func (a *App) GetSyncStatus() SyncStatusDTO {
-	config := a.mirrorManager.GetConfig()
+// ConflictItemDTO 同步冲突项数据传输对象，API Key 已掩码.
+type ConflictItemDTO struct {
+	Type        string     `json:"type"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Local       *MirrorDTO `json:"local,omitempty"`
+	Remote      *MirrorDTO `json:"remote,omitempty"`
+}
 
-	if config.Sync == nil {
-		return SyncStatusDTO{
-			Enabled: false,
-			Message: "未配置云同步",
-		}
+// SyncStatus 获取同步状态.
+func (a *App) SyncStatus() (SyncStatusDTO, error) {
+	status, err := a.newSyncManager().GetStatus()
+	if err != nil {
+		return SyncStatusDTO{}, err
 	}
 
 	result := SyncStatusDTO{
-		Enabled:      config.Sync.Enabled,
-		Provider:     config.Sync.Provider,
-		Endpoint:     config.Sync.Endpoint,
-		DeviceID:     config.Sync.DeviceID,
-		GistID:       config.Sync.GistID,
-		AutoSync:     config.Sync.AutoSync,
-		SyncInterval: config.Sync.SyncInterval,
+		Enabled:       status.Enabled,
+		Provider:      status.Provider,
+		Endpoint:      status.Endpoint,
+		DeviceID:      status.DeviceID,
+		AutoSync:      status.AutoSync,
+		SyncInterval:  status.SyncInterval,
+		Message:       status.Message,
+		LastSyncError: status.LastSyncError,
+	}
+	if config := a.mirrorManager.GetSyncProfile(internal.DefaultSyncProfile); config != nil {
+		result.GistID = config.GistID
+	}
+	if status.LastRemoteDevice != "" {
+		result.LastRemoteDevice = status.LastRemoteDevice
+		result.LastRemoteTime = status.LastRemoteTimestamp.Format("2006-01-02 15:04:05")
 	}
+	if !status.LastSync.IsZero() {
+		result.LastSync = status.LastSync.Format("2006-01-02 15:04:05")
+		result.Message = "上次同步: " + formatDuration(time.Since(status.LastSync))
+	}
+
+	return result, nil
+}
 
-	if config.Sync.LastSync.IsZero() {
-		result.Message = "尚未进行过同步"
-	} else {
-		result.LastSync = config.Sync.LastSync.Format("2006-01-02 15:04:05")
-		result.Message = "上次同步: " + formatDuration(time.Since(config.Sync.LastSync))
+// GetSyncConflicts 检测本地与云端配置的冲突，供前端展示并选择解决策略.
+func (a *App) GetSyncConflicts() ([]ConflictItemDTO, error) {
+	resolution, err := a.newSyncManager().DetectRemoteConflicts()
+	if err != nil {
+		return nil, err
 	}
+	return a.toConflictDTOs(resolution), nil
+}
 
+// toConflictDTOs 将冲突检测结果转换为 ConflictItemDTO 列表.
+func (a *App) toConflictDTOs(resolution *internal.ConflictResolution) []ConflictItemDTO {
+	config := a.mirrorManager.GetConfig()
+	result := make([]ConflictItemDTO, 0, len(resolution.Conflicts))
+	for _, item := range resolution.Conflicts {
+		dto := ConflictItemDTO{
+			Type:        string(item.Type),
+			Name:        item.Name,
+			Description: item.Description,
+		}
+		if item.LocalMirror != nil {
+			local := a.toMirrorDTO(*item.LocalMirror, config)
+			dto.Local = &local
+		}
+		if item.RemoteMirror != nil {
+			remote := a.toMirrorDTO(*item.RemoteMirror, config)
+			dto.Remote = &remote
+		}
+		result = append(result, dto)
+	}
 	return result
 }
 
+// newSyncManager 创建非交互模式的同步管理器，避免 GUI 中读取标准输入；进度和冲突通过事件通知前端.
+func (a *App) newSyncManager() *internal.SyncManager {
+	syncManager := internal.NewSyncManager(a.mirrorManager)
+	syncManager.SetInteractive(false)
+	syncManager.SetProgressHandler(func(progress internal.SyncProgress) {
+		a.emit(EventSyncProgress, progress)
+	})
+	syncManager.SetConflictHandler(func(resolution *internal.ConflictResolution) {
+		a.emit(EventSyncConflict, a.toConflictDTOs(resolution))
+	})
+	return syncManager
+}
+
 // InitSync 初始化云同步.
 func (a *App) InitSync(req SyncInitRequest) SyncInitResult {
 	// 验证参数
@@ -435,54 +577,48 @@ func (a *App) InitSync(req SyncInitRequest) SyncInitResult {
 	}
 }
 
-// SyncPush 推送配置到云端.
-func (a *App) SyncPush() (string, error) {
-	syncManager := internal.NewSyncManager(a.mirrorManager)
-
-	if err := syncManager.Push(); err != nil {
-		return "", err
+// SyncPush 使用指定策略推送配置到云端，策略为空时使用 auto.
+func (a *App) SyncPush(strategy string) error {
+	if strategy == "" {
+		strategy = internal.StrategyAuto
 	}
-
-	return "配置已推送到云端", nil
+	return a.newSyncManager().PushWithStrategy(strategy)
 }
 
-// SyncPull 从云端拉取配置.
-func (a *App) SyncPull() (string, error) {
-	syncManager := internal.NewSyncManager(a.mirrorManager)
-
-	if err := syncManager.Pull(); err != nil {
-		return "", err
+// SyncPull 使用指定策略从云端拉取配置，策略为空时使用 auto.
+func (a *App) SyncPull(strategy string) error {
+	if strategy == "" {
+		strategy = internal.StrategyAuto
 	}
-
-	return "配置已从云端拉取", nil
+	return a.newSyncManager().PullWithStrategy(strategy)
 }
 
 // DisableSync 禁用云同步.
 func (a *App) DisableSync() error {
-	config := a.mirrorManager.GetConfig()
-	if config.Sync == nil {
+	config := a.mirrorManager.GetSyncProfile(internal.DefaultSyncProfile)
+	if config == nil {
 		return fmt.Errorf("云同步未配置")
 	}
 
-	config.Sync.Enabled = false
+	config.Enabled = false
 	return a.mirrorManager.SaveConfig()
 }
 
 // EnableSync 启用云同步.
 func (a *App) EnableSync() error {
-	config := a.mirrorManager.GetConfig()
-	if config.Sync == nil {
+	config := a.mirrorManager.GetSyncProfile(internal.DefaultSyncProfile)
+	if config == nil {
 		return fmt.Errorf("云同步未初始化")
 	}
 
-	config.Sync.Enabled = true
+	config.Enabled = true
 	return a.mirrorManager.SaveConfig()
 }
 
 // UpdateSyncSettings 更新同步设置（密码或 Gist ID）.
 func (a *App) UpdateSyncSettings(req SyncUpdateRequest) SyncInitResult {
-	config := a.mirrorManager.GetConfig()
-	if config.Sync == nil {
+	config := a.mirrorManager.GetSyncProfile(internal.DefaultSyncProfile)
+	if config == nil {
 		return SyncInitResult{
 			Success: false,
 			Message: "云同步未初始化",
@@ -497,12 +633,12 @@ func (a *App) UpdateSyncSettings(req SyncUpdateRequest) SyncInitResult {
 				Message: "密码长度至少8位",
 			}
 		}
-		config.Sync.EncryptionPwd = req.NewPassword
+		config.EncryptionPwd = req.NewPassword
 	}
 
 	// 更新 Gist ID
 	if req.NewGistID != "" {
-		config.Sync.GistID = req.NewGistID
+		config.GistID = req.NewGistID
 	}
 
 	// 保存配置
@@ -0,0 +1,16 @@
+//go:build !cli
+// +build !cli
+
+package main
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// emit 通过 Wails 运行时向前端发送事件，未启动 GUI（ctx 为空）时不做任何操作.
+func (a *App) emit(event string, data interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, event, data)
+}
@@ -0,0 +1,7 @@
+//go:build cli
+// +build cli
+
+package main
+
+// emit CLI 模式下没有前端，事件直接丢弃.
+func (a *App) emit(_ string, _ interface{}) {}
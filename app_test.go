@@ -1,16 +1,24 @@
 package main
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"codex-mirror/internal"
 )
 
-// 创建测试用的 App 实例.
+// 创建测试用的 App 实例，配置文件位于临时目录，不影响用户的 ~/.codex-mirror/mirrors.toml.
 func createTestApp(t *testing.T) *App {
 	t.Helper()
 
-	// 创建一个临时配置路径的 MirrorManager
+	// 创建一个临时配置路径的 MirrorManager，删除镜像源时只会改动临时主目录下的 shell 配置
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("USERPROFILE", homeDir)
+	t.Setenv("CODEX_MIRROR_CONFIG_PATH", filepath.Join(t.TempDir(), "mirrors.toml"))
 	mm, err := internal.NewMirrorManager()
 	if err != nil {
 		t.Fatalf("创建测试 App 失败: %v", err)
@@ -24,6 +32,7 @@ func createTestApp(t *testing.T) *App {
 
 // TestNewApp 测试 App 构造函数.
 func TestNewApp(t *testing.T) {
+	t.Setenv("CODEX_MIRROR_CONFIG_PATH", filepath.Join(t.TempDir(), "mirrors.toml"))
 	app, err := NewApp()
 	if err != nil {
 		t.Fatalf("NewApp() 失败: %v", err)
@@ -137,6 +146,100 @@ func TestAddMirror(t *testing.T) {
 	app.RemoveMirror("test-mirror")
 }
 
+// TestTestMirror 测试镜像源连通性测试.
+func TestTestMirror(t *testing.T) {
+	app := createTestApp(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := app.AddMirror(MirrorDTO{
+		Name:     "test-gui-connectivity",
+		BaseURL:  server.URL,
+		APIKey:   "test-key-1234567890",
+		ToolType: "codex",
+	})
+	if err != nil {
+		t.Fatalf("AddMirror() 失败: %v", err)
+	}
+	defer app.RemoveMirror("test-gui-connectivity")
+
+	result, err := app.TestMirror("test-gui-connectivity")
+	if err != nil {
+		t.Fatalf("TestMirror() 失败: %v", err)
+	}
+	if !result.Success || result.StatusCode != http.StatusOK {
+		t.Errorf("TestMirror() = %+v, expected success with status 200", result)
+	}
+	if result.ToolType != "codex" || result.URL != server.URL {
+		t.Errorf("TestMirror() 返回的镜像源信息不正确: %+v", result)
+	}
+
+	if _, err := app.TestMirror("nonexistent"); err == nil {
+		t.Error("TestMirror('nonexistent') 应该返回错误")
+	}
+}
+
+// TestExportImportMirrors 测试导出和导入镜像源.
+func TestExportImportMirrors(t *testing.T) {
+	app := createTestApp(t)
+
+	data := `[{"name": "test-gui-import", "base_url": "https://import.test.com", "api_key": "sk-import-1234567890", "tool_type": "claude"}]`
+	result, err := app.ImportMirrors(data, false)
+	if err != nil {
+		t.Fatalf("ImportMirrors() 失败: %v", err)
+	}
+	defer app.RemoveMirror("test-gui-import")
+	if result.Added != 1 || result.Skipped != 0 {
+		t.Errorf("ImportMirrors() = %+v, expected 1 added", result)
+	}
+
+	// 再次导入同名镜像源应跳过
+	result, err = app.ImportMirrors(data, false)
+	if err != nil {
+		t.Fatalf("ImportMirrors() 失败: %v", err)
+	}
+	if result.Added != 0 || result.Skipped != 1 {
+		t.Errorf("重复导入结果 = %+v, expected 1 skipped", result)
+	}
+
+	masked, err := app.ExportMirrors(false)
+	if err != nil {
+		t.Fatalf("ExportMirrors(false) 失败: %v", err)
+	}
+	if !strings.Contains(masked, "test-gui-import") || strings.Contains(masked, "sk-import-1234567890") || !strings.Contains(masked, "sk-i****7890") {
+		t.Error("ExportMirrors(false) 应包含镜像源且只导出掩码后的 API Key")
+	}
+
+	// 掩码导出内容覆盖导入后，原有密钥不应被改写
+	if _, err := app.ImportMirrors(masked, true); err != nil {
+		t.Fatalf("ImportMirrors(overwrite) 失败: %v", err)
+	}
+	if mirror, err := app.mirrorManager.GetMirrorByName("test-gui-import"); err != nil || mirror.APIKey != "sk-import-1234567890" {
+		t.Errorf("导出再导入后 API Key 被改写: %+v, err: %v", mirror, err)
+	}
+	if _, err := app.ImportMirrors(`[{"name": "test-gui-import", "base_url": "https://import.test.com", "api_key": "sk-i****7890", "tool_type": "claude"}]`, true); err != nil {
+		t.Fatalf("ImportMirrors(masked) 失败: %v", err)
+	}
+	if mirror, _ := app.mirrorManager.GetMirrorByName("test-gui-import"); mirror == nil || mirror.APIKey != "sk-import-1234567890" {
+		t.Error("导入掩码 API Key 不应覆盖真实密钥")
+	}
+
+	full, err := app.ExportMirrors(true)
+	if err != nil {
+		t.Fatalf("ExportMirrors(true) 失败: %v", err)
+	}
+	if !strings.Contains(full, "sk-import-1234567890") {
+		t.Error("ExportMirrors(true) 应包含完整 API Key")
+	}
+
+	if _, err := app.ImportMirrors(`[{"name": "test-gui-invalid", "base_url": "not-a-url"}]`, false); err == nil {
+		t.Error("导入无效条目应返回错误")
+	}
+}
+
 // TestAddMirrorDuplicate 测试添加重复镜像源.
 func TestAddMirrorDuplicate(t *testing.T) {
 	app := createTestApp(t)
@@ -281,8 +384,8 @@ func TestGetCurrentStatus(t *testing.T) {
 		t.Error("CodexStatus.Path 为空")
 	}
 
-	if status.ClaudeStatus.Path != "环境变量" {
-		t.Error("ClaudeStatus.Path 应该是 '环境变量'")
+	if filepath.Base(status.ClaudeStatus.Path) != "settings.json" {
+		t.Errorf("ClaudeStatus.Path 应为 Claude settings.json，实际为 '%s'", status.ClaudeStatus.Path)
 	}
 }
 
@@ -312,54 +415,34 @@ func TestValidateURL(t *testing.T) {
 	}
 }
 
-// TestMaskAPIKey 测试 API Key 掩码.
-func TestMaskAPIKey(t *testing.T) {
+// TestMirrorDTOMasksAPIKey 测试 DTO 中的 API Key 使用统一的掩码规则.
+func TestMirrorDTOMasksAPIKey(t *testing.T) {
 	app := createTestApp(t)
+	config := &internal.SystemConfig{}
 
 	tests := []struct {
 		name     string
 		apiKey   string
-		contains string // 检查结果是否包含这些字符
+		expected string
 	}{
-		{"短 Key", "abc123", "******"},
+		{"短 Key", "abc123", "****"},
 		{"8字符 Key", "abcd1234", "****"},
-		{"长 Key", "sk-1234567890abcdefghijklmnop", "sk-1"},
-		{"标准 Key", "sk-proj-abc123def456ghi789", "sk-p"},
+		{"长 Key", "sk-1234567890abcdefghijklmnop", "sk-1****mnop"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := app.maskAPIKey(tt.apiKey)
-			// 确保掩码后长度与原 Key 相同
-			if len(result) != len(tt.apiKey) {
-				t.Errorf("掩码后长度不匹配: got %d, want %d", len(result), len(tt.apiKey))
-			}
-			// 确保包含星号
-			if !contains(result, "*") {
-				t.Error("掩码结果应该包含星号")
+			dto := app.toMirrorDTO(internal.MirrorConfig{Name: "mask", APIKey: tt.apiKey}, config)
+			if dto.APIKey != tt.expected {
+				t.Errorf("APIKey = %q, expected %q", dto.APIKey, tt.expected)
 			}
-			// 确保包含前缀（如果有）
-			if tt.contains != "" && !contains(result, tt.contains) {
-				t.Errorf("掩码结果应该包含 %q, got %q", tt.contains, result)
+			if dto.APIKey != internal.MaskAPIKey(tt.apiKey) {
+				t.Errorf("DTO 掩码应与 internal.MaskAPIKey 一致")
 			}
 		})
 	}
 }
 
-// 辅助函数：检查字符串是否包含子串.
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && indexOf(s, substr) >= 0)
-}
-
-func indexOf(s, substr string) int {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return i
-		}
-	}
-	return -1
-}
-
 // TestToMirrorDTO 测试 MirrorConfig 到 MirrorDTO 的转换.
 func TestToMirrorDTO(t *testing.T) {
 	app := createTestApp(t)
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -24,17 +27,44 @@ var addCmd = &cobra.Command{
 标志：
   --type   工具类型 (codex|claude, 默认: codex)
   --model  模型名称 (可选，主Claude使用，如 claude-3-5-sonnet-20241022)
+  --haiku-model/--sonnet-model/--opus-model  各级别使用的模型 (可选，仅 Claude，
+           写入 ANTHROPIC_DEFAULT_HAIKU_MODEL 等环境变量)
+  --api-key-command  获取 API 密钥的命令 (可选，如 "op read op://vault/item/key"，
+           与 api-key 参数互斥，密钥在切换和测试时读取，不保存到配置文件)
+  --api-key-stdin  从标准输入读取一行作为 API 密钥 (与 api-key 参数互斥)
+  --api-key-file   从文件读取 API 密钥 (与 api-key 参数互斥)
+           直接在命令行传入密钥会留在 shell 历史和 ps 输出中，推荐使用这两种方式
   --extra-env  额外环境变量 (可选，格式: KEY=VALUE，可多次使用)
+  --proxy  HTTP 代理地址 (可选，如 http://127.0.0.1:7890)
+  --tag    分组标签 (可选，可多次使用，如 work、cheap)
+  --health-path  连通性测试路径 (可选，如 /healthz，默认探测 /v1/models 或 /v1/messages)
+  --timeout  连通性测试超时时间 (可选，秒，用于响应较慢的镜像源，默认使用 test/doctor 的超时)
+  --test-header  连通性测试附加的请求头 (可选，格式: KEY=VALUE，可多次使用)
+  --no-validate-url  跳过 URL 格式校验 (用于特殊的内网地址)
+  --provider-kind  Codex 提供商形式 (openai|azure, 默认: openai)
+  --api-version    Azure API 版本 (--provider-kind azure 时必需，如 2025-04-01-preview)
+  --force, -f  镜像源已存在时原地更新 (URL、密钥、模型、额外环境变量)，而不是报错
 
 示例：
   codex-mirror add myapi https://api.example.com sk-1234567890
   codex-mirror add myclaude https://api.anthropic.com sk-ant-123 --type claude
   codex-mirror add custom https://api.custom.com sk-key --type claude --model claude-3-5-sonnet-20241022
   codex-mirror add proxy https://proxy.example.com sk-key --type claude \
-    --extra-env ANTHROPIC_DEFAULT_HAIKU_MODEL=gemini-2.5-flash-lite \
-    --extra-env ANTHROPIC_DEFAULT_SONNET_MODEL=gemini-claude-sonnet-4-5-thinking \
-    --extra-env ANTHROPIC_DEFAULT_OPUS_MODEL=gemini-claude-opus-4-5-thinking
-  codex-mirror add local http://localhost:8080`,
+    --haiku-model gemini-2.5-flash-lite \
+    --sonnet-model gemini-claude-sonnet-4-5-thinking \
+    --opus-model gemini-claude-opus-4-5-thinking
+  codex-mirror add timeout https://api.example.com sk-key --type claude --extra-env API_TIMEOUT_MS=600000
+  codex-mirror add local http://localhost:8080
+  codex-mirror add vault https://api.example.com --api-key-command "pass show ai/example"
+  pass show ai/example | codex-mirror add piped https://api.example.com --api-key-stdin
+  codex-mirror add fromfile https://api.example.com --api-key-file ~/.secrets/example-key
+  codex-mirror add remote https://api.example.com sk-key --proxy http://127.0.0.1:7890
+  codex-mirror add gateway https://gw.example.com sk-key --test-header X-Org-Id=org-123
+  codex-mirror add slow https://slow.example.com sk-key --timeout 30
+  codex-mirror add cheap-api https://cheap.example.com sk-key --tag cheap --tag personal
+  codex-mirror add azure https://my-resource.openai.azure.com/openai sk-key \
+    --provider-kind azure --api-version 2025-04-01-preview
+  codex-mirror add myapi https://api.example.com sk-new-key --force`,
 	Args: cobra.RangeArgs(2, 3),
 	RunE: runAddCommand,
 }
@@ -48,9 +78,23 @@ func runAddCommand(cmd *cobra.Command, args []string) error {
 		apiKey = args[2]
 	}
 
-	// 验证 URL 格式
-	if err := internal.ValidateBaseURL(baseURL); err != nil {
-		return fmt.Errorf("无效的 API 地址: %v", err)
+	// 获取密钥命令，密钥只能二选一
+	apiKeyCommand, _ := cmd.Flags().GetString("api-key-command")
+	apiKeyCommand = strings.TrimSpace(apiKeyCommand)
+	if apiKeyCommand != "" && apiKey != "" {
+		return fmt.Errorf("--api-key-command 不能与 api-key 参数同时使用")
+	}
+
+	// 从标准输入或文件读取密钥，避免密钥出现在 shell 历史和 ps 输出中
+	inputKey, fromInput, err := readAPIKeyInput(cmd)
+	if err != nil {
+		return err
+	}
+	if fromInput {
+		if apiKey != "" {
+			return fmt.Errorf("--api-key-stdin/--api-key-file 不能与 api-key 参数同时使用")
+		}
+		apiKey = inputKey
 	}
 
 	// 获取工具类型
@@ -62,10 +106,51 @@ func runAddCommand(cmd *cobra.Command, args []string) error {
 	// 获取模型名称
 	modelName, _ := cmd.Flags().GetString("model")
 
+	// 获取各级别模型
+	tierModels := make(map[internal.ModelTier]string)
+	for _, tier := range internal.ModelTiers {
+		if model, _ := cmd.Flags().GetString(tierModelFlag(tier)); strings.TrimSpace(model) != "" {
+			tierModels[tier] = model
+		}
+	}
+
 	// 获取额外环境变量
 	extraEnvSlice, _ := cmd.Flags().GetStringArray("extra-env")
 	extraEnv := parseExtraEnv(extraEnvSlice)
 
+	// 获取标签
+	tagSlice, _ := cmd.Flags().GetStringArray("tag")
+	tags := internal.NormalizeTags(tagSlice)
+
+	// 获取并校验测试路径
+	healthPath, _ := cmd.Flags().GetString("health-path")
+	healthPath, err = internal.NormalizeHealthPath(healthPath)
+	if err != nil {
+		return err
+	}
+
+	// 获取测试超时时间
+	timeoutSeconds, _ := cmd.Flags().GetInt("timeout")
+	if timeoutSeconds < 0 {
+		return fmt.Errorf("--timeout 不能为负数")
+	}
+
+	// 获取测试请求头
+	testHeaderSlice, _ := cmd.Flags().GetStringArray("test-header")
+	testHeaders, err := parseTestHeaders(testHeaderSlice)
+	if err != nil {
+		return err
+	}
+
+	// 获取并校验代理地址
+	proxy, _ := cmd.Flags().GetString("proxy")
+	proxy = strings.TrimSpace(proxy)
+	if proxy != "" {
+		if err := internal.ValidateProxyURL(proxy); err != nil {
+			return fmt.Errorf("无效的代理地址: %w", err)
+		}
+	}
+
 	// 验证工具类型
 	var internalToolType internal.ToolType
 	switch toolType {
@@ -77,6 +162,11 @@ func runAddCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("无效的工具类型 '%s'，支持: %s, %s", toolType, internal.ToolTypeCodex, internal.ToolTypeClaude)
 	}
 
+	providerKind, apiVersion, err := parseProviderFlags(cmd, internalToolType)
+	if err != nil {
+		return err
+	}
+
 	// 创建镜像源管理器
 	mm, err := internal.NewMirrorManager()
 	if err != nil {
@@ -84,32 +174,284 @@ func runAddCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%v", err)
 	}
 
-	// 添加镜像源
-	if err := mm.AddMirrorWithExtra(name, baseURL, apiKey, internalToolType, modelName, extraEnv); err != nil {
-		fmt.Fprintf(os.Stderr, "添加镜像源失败: %v\n", err)
-		return fmt.Errorf("添加镜像源失败: %v", err)
+	// --force 覆盖已存在的镜像源且未指定 --type 时，按已有镜像源的工具类型校验级别模型
+	force, _ := cmd.Flags().GetBool("force")
+	checkType := internalToolType
+	if force && !cmd.Flags().Changed("type") {
+		if existing, err := mm.GetMirrorByName(name); err == nil && !existing.Deleted {
+			checkType = existing.ToolType
+		}
+	}
+	if len(tierModels) > 0 && checkType != internal.ToolTypeClaude {
+		return fmt.Errorf("--haiku-model、--sonnet-model、--opus-model 仅适用于 Claude 镜像源")
 	}
 
-	fmt.Printf("成功添加镜像源 '%s'\n", name)
+	// 先组装完整的镜像源配置，校验通过后只保存一次，避免中途失败留下不完整的镜像源
+	entry := internal.MirrorConfig{
+		Name:           name,
+		BaseURL:        baseURL,
+		APIKey:         apiKey,
+		APIKeyCommand:  apiKeyCommand,
+		ToolType:       internalToolType,
+		ModelName:      modelName,
+		HaikuModel:     tierModels[internal.ModelTierHaiku],
+		SonnetModel:    tierModels[internal.ModelTierSonnet],
+		OpusModel:      tierModels[internal.ModelTierOpus],
+		Proxy:          proxy,
+		HealthPath:     healthPath,
+		Tags:           tags,
+		TimeoutSeconds: timeoutSeconds,
+		ExtraEnv:       extraEnv,
+	}
+	if len(testHeaders) > 0 {
+		entry.TestHeaders = testHeaders
+	}
+	if providerKind == internal.ProviderKindAzure {
+		entry.ProviderKind = providerKind
+		entry.APIVersion = apiVersion
+	}
+
+	// 添加镜像源（默认校验并规范化 URL）
+	noValidateURL, _ := cmd.Flags().GetBool("no-validate-url")
+	mm.SetURLValidation(!noValidateURL)
+	updated := false
+	if err := mm.AddMirrorConfig(entry); err != nil {
+		if !force || !errors.Is(err, internal.ErrMirrorExists) {
+			fmt.Fprintf(os.Stderr, "添加镜像源失败: %v\n", err)
+			if errors.Is(err, internal.ErrMirrorExists) {
+				fmt.Fprintf(os.Stderr, "💡 使用 'codex-mirror update %s' 修改已有的镜像源，或加上 --force 覆盖\n", name)
+			}
+			return fmt.Errorf("添加镜像源失败: %w", err)
+		}
+
+		// --force：镜像源已存在时原地更新，同样只保存一次
+		typeChanged := cmd.Flags().Changed("type")
+		if err := mm.WithMirror(name, func(m *internal.MirrorConfig) error {
+			applyForcedFields(m, &entry, typeChanged)
+			return mm.ValidateMirrorConfig(m)
+		}); err != nil {
+			return fmt.Errorf("更新镜像源失败: %w", err)
+		}
+		updated = true
+	}
+
+	mirror, err := mm.GetMirrorByName(name)
+	if err == nil {
+		baseURL = mirror.BaseURL
+	}
+
+	if updated {
+		fmt.Printf("镜像源 '%s' 已存在，已原地更新\n", name)
+	} else {
+		fmt.Printf("成功添加镜像源 '%s'\n", name)
+	}
 	fmt.Printf("  名称: %s\n", name)
-	fmt.Printf("  类型: %s\n", toolType)
+	if mirror != nil {
+		fmt.Printf("  类型: %s\n", mirror.ToolType)
+	} else {
+		fmt.Printf("  类型: %s\n", toolType)
+	}
 	fmt.Printf("  URL: %s\n", baseURL)
 	if apiKey != "" {
-		fmt.Printf("  API密钥: %s\n", maskAPIKey(apiKey))
+		fmt.Printf("  API密钥: %s\n", internal.MaskAPIKey(apiKey))
+	}
+	if apiKeyCommand != "" {
+		fmt.Printf("  密钥命令: %s\n", apiKeyCommand)
 	}
 	if modelName != "" {
 		fmt.Printf("  模型: %s\n", modelName)
 	}
+	if mirror != nil {
+		printTierModels(mirror)
+		printProviderKind(mirror)
+	}
+	if proxy != "" {
+		fmt.Printf("  代理: %s\n", internal.RedactURL(proxy))
+	}
 	if len(extraEnv) > 0 {
 		fmt.Println("  额外环境变量:")
 		for key, value := range extraEnv {
-			fmt.Printf("    %s=%s\n", key, value)
+			fmt.Printf("    %s=%s\n", key, displayEnvValue(key, value))
+		}
+	}
+	if len(tags) > 0 {
+		fmt.Printf("  标签: %s\n", strings.Join(tags, ", "))
+	}
+	if healthPath != "" {
+		fmt.Printf("  测试路径: %s\n", healthPath)
+	}
+	if timeoutSeconds > 0 {
+		fmt.Printf("  测试超时: %d 秒\n", timeoutSeconds)
+	}
+	if len(testHeaders) > 0 {
+		fmt.Println("  测试请求头:")
+		for key, value := range testHeaders {
+			fmt.Printf("    %s: %s\n", key, displayEnvValue(key, value))
 		}
 	}
 
+	// 同一地址配置多个镜像源通常是误操作，但也可能是有意为之，只给出警告
+	if others := mm.MirrorsSharingBaseURL(name); len(others) > 0 {
+		fmt.Printf("\n⚠️  警告: 镜像源 %s 与 '%s' 使用相同的 API 地址，切换时可能难以区分\n", strings.Join(others, ", "), name)
+	}
+
 	return nil
 }
 
+// applyForcedFields 将 add --force 中指定的字段覆盖到已存在的镜像源，未指定的字段保持不变.
+// 未通过 --type 指定时保留原有的工具类型.
+func applyForcedFields(mirror, entry *internal.MirrorConfig, typeChanged bool) {
+	mirror.BaseURL = entry.BaseURL
+	if entry.APIKey != "" {
+		mirror.APIKey = entry.APIKey
+		mirror.APIKeyCommand = ""
+	}
+	if entry.APIKeyCommand != "" {
+		mirror.APIKeyCommand = entry.APIKeyCommand
+		mirror.APIKey = ""
+	}
+	if typeChanged {
+		mirror.ToolType = entry.ToolType
+	}
+	if entry.ModelName != "" {
+		mirror.ModelName = entry.ModelName
+	}
+	if entry.HaikuModel != "" {
+		mirror.HaikuModel = entry.HaikuModel
+	}
+	if entry.SonnetModel != "" {
+		mirror.SonnetModel = entry.SonnetModel
+	}
+	if entry.OpusModel != "" {
+		mirror.OpusModel = entry.OpusModel
+	}
+	if entry.ProviderKind != "" {
+		mirror.ProviderKind = entry.ProviderKind
+		mirror.APIVersion = entry.APIVersion
+	}
+	if entry.Proxy != "" {
+		mirror.Proxy = entry.Proxy
+	}
+	if len(entry.Tags) > 0 {
+		mirror.Tags = entry.Tags
+	}
+	if entry.HealthPath != "" {
+		mirror.HealthPath = entry.HealthPath
+	}
+	if len(entry.TestHeaders) > 0 {
+		mirror.TestHeaders = entry.TestHeaders
+	}
+	if entry.TimeoutSeconds > 0 {
+		mirror.TimeoutSeconds = entry.TimeoutSeconds
+	}
+	if len(entry.ExtraEnv) > 0 {
+		mirror.ExtraEnv = entry.ExtraEnv
+	}
+}
+
+// displayEnvValue 返回额外环境变量或请求头用于展示的值，密钥类的值只显示掩码.
+func displayEnvValue(key, value string) string {
+	if internal.IsSecretEnvKey(key) {
+		return internal.MaskAPIKey(value)
+	}
+	return value
+}
+
+// registerAPIKeyInputFlags 注册 --api-key-stdin 和 --api-key-file 标志，两者与 --api-key-command 互斥.
+func registerAPIKeyInputFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("api-key-stdin", false, "从标准输入读取一行作为 API 密钥")
+	cmd.Flags().String("api-key-file", "", "从文件读取 API 密钥")
+	cmd.MarkFlagsMutuallyExclusive("api-key-stdin", "api-key-file", "api-key-command")
+}
+
+// readAPIKeyInput 读取 --api-key-stdin 或 --api-key-file 指定的 API 密钥，两者均未指定时返回 false.
+// 读取的内容会去除首尾空白和换行，内容为空或包含多行时报错.
+func readAPIKeyInput(cmd *cobra.Command) (string, bool, error) {
+	fromStdin, _ := cmd.Flags().GetBool("api-key-stdin")
+	keyFile, _ := cmd.Flags().GetString("api-key-file")
+
+	var key string
+	switch {
+	case fromStdin:
+		line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", false, fmt.Errorf("从标准输入读取 API 密钥失败: %w", err)
+		}
+		key = strings.TrimSpace(line)
+		if key == "" {
+			return "", false, fmt.Errorf("从标准输入读取的 API 密钥为空")
+		}
+	case cmd.Flags().Changed("api-key-file"):
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return "", false, fmt.Errorf("读取 API 密钥文件失败: %w", err)
+		}
+		key = strings.TrimSpace(string(data))
+		if key == "" {
+			return "", false, fmt.Errorf("API 密钥文件 %s 为空", keyFile)
+		}
+		if strings.ContainsAny(key, "\r\n") {
+			return "", false, fmt.Errorf("API 密钥文件 %s 应只包含一行", keyFile)
+		}
+	default:
+		return "", false, nil
+	}
+	return key, true, nil
+}
+
+// tierModelFlag 返回设置指定级别模型的标志名，如 haiku-model.
+func tierModelFlag(tier internal.ModelTier) string {
+	return string(tier) + "-model"
+}
+
+// registerTierModelFlags 注册 --haiku-model 等按级别设置模型的标志.
+func registerTierModelFlags(cmd *cobra.Command) {
+	for _, tier := range internal.ModelTiers {
+		cmd.Flags().String(tierModelFlag(tier), "", fmt.Sprintf("Claude %s 级别使用的模型", tier))
+	}
+}
+
+// printTierModels 打印镜像源按级别设置的模型.
+func printTierModels(mirror *internal.MirrorConfig) {
+	for _, tier := range internal.ModelTiers {
+		if model := internal.TierModel(mirror, tier); model != "" {
+			fmt.Printf("  %s 模型: %s\n", tier, model)
+		}
+	}
+}
+
+// parseProviderFlags 解析并校验 --provider-kind 和 --api-version 标志.
+func parseProviderFlags(cmd *cobra.Command, toolType internal.ToolType) (internal.ProviderKind, string, error) {
+	kindFlag, _ := cmd.Flags().GetString("provider-kind")
+	apiVersion, _ := cmd.Flags().GetString("api-version")
+	apiVersion = strings.TrimSpace(apiVersion)
+
+	kind, err := internal.ParseProviderKind(kindFlag)
+	if err != nil {
+		return "", "", err
+	}
+	if kind != internal.ProviderKindAzure {
+		if apiVersion != "" {
+			return "", "", fmt.Errorf("--api-version 仅适用于 --provider-kind azure")
+		}
+		return kind, "", nil
+	}
+	if toolType != internal.ToolTypeCodex {
+		return "", "", fmt.Errorf("--provider-kind azure 仅适用于 Codex 镜像源")
+	}
+	if apiVersion == "" {
+		return "", "", fmt.Errorf("--provider-kind azure 需要同时指定 --api-version")
+	}
+	return kind, apiVersion, nil
+}
+
+// printProviderKind 打印 Azure 等非默认的提供商形式.
+func printProviderKind(mirror *internal.MirrorConfig) {
+	if internal.IsAzureMirror(mirror) {
+		fmt.Printf("  提供商: %s (api-version: %s)\n", mirror.ProviderKind, mirror.APIVersion)
+	}
+}
+
 // parseExtraEnv 解析额外环境变量参数.
 func parseExtraEnv(envSlice []string) map[string]string {
 	result := make(map[string]string)
@@ -122,9 +464,39 @@ func parseExtraEnv(envSlice []string) map[string]string {
 	return result
 }
 
+// parseTestHeaders 解析测试请求头参数，格式为 KEY=VALUE.
+func parseTestHeaders(headerSlice []string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, header := range headerSlice {
+		key, value, ok := strings.Cut(header, "=")
+		key = strings.TrimSpace(key)
+		if !ok {
+			return nil, fmt.Errorf("无效的请求头 '%s'，格式应为 KEY=VALUE", header)
+		}
+		if err := internal.ValidateHeaderName(key); err != nil {
+			return nil, err
+		}
+		result[key] = strings.TrimSpace(value)
+	}
+	return result, nil
+}
+
 func init() {
 	addCmd.Flags().StringP("type", "t", "codex", "工具类型 (codex|claude)")
 	addCmd.Flags().StringP("model", "m", "", "模型名称 (可选，主Claude使用)")
+	registerTierModelFlags(addCmd)
 	addCmd.Flags().StringArrayP("extra-env", "e", []string{}, "额外环境变量 (格式: KEY=VALUE，可多次使用)")
+	addCmd.Flags().String("api-key-command", "", "获取 API 密钥的命令 (与 api-key 参数互斥)")
+	registerAPIKeyInputFlags(addCmd)
+	addCmd.Flags().String("proxy", "", "HTTP 代理地址 (如 http://127.0.0.1:7890)")
+	addCmd.Flags().StringArray("tag", []string{}, "分组标签 (可多次使用)")
+	_ = addCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	addCmd.Flags().String("health-path", "", "连通性测试路径 (如 /healthz)")
+	addCmd.Flags().Int("timeout", 0, "连通性测试超时时间（秒），0 表示使用默认超时")
+	addCmd.Flags().StringArray("test-header", []string{}, "连通性测试附加的请求头 (格式: KEY=VALUE，可多次使用)")
+	addCmd.Flags().Bool("no-validate-url", false, "跳过 URL 格式校验")
+	addCmd.Flags().String("provider-kind", "", "Codex 提供商形式 (openai|azure)")
+	addCmd.Flags().String("api-version", "", "Azure API 版本 (--provider-kind azure 时必需)")
+	addCmd.Flags().BoolP("force", "f", false, "镜像源已存在时原地更新，而不是报错")
 	rootCmd.AddCommand(addCmd)
 }
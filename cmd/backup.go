@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// backupCmd 代表backup命令.
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "查看和清理备份",
+	Long: `查看和清理本工具在各处创建的备份：
+
+  mirrors  ~/.codex-mirror/backup/<前缀>-<时间戳>.toml    (同步、恢复等操作前的镜像源配置备份)
+  switch   ~/.codex-mirror/backup/switch-<时间戳>/        (切换前快照)
+  codex    ~/.codex/backup/*.bak                          (Codex CLI 配置备份)
+  claude   ~/.claude/backup/*.bak                         (Claude Code 配置备份)
+  vscode   <VS Code 用户目录>/backup/*.bak                (VS Code 设置备份)`,
+}
+
+// backupListCmd 代表backup list命令.
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出所有备份",
+	Long: `列出所有已知备份目录中的备份，显示来源、时间和大小，按时间从新到旧排序。
+
+示例：
+  codex-mirror backup list
+  codex-mirror backup list --json`,
+	Args: cobra.NoArgs,
+	RunE: runBackupListCommand,
+}
+
+// backupCleanCmd 代表backup clean命令.
+var backupCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "清理旧备份",
+	Long: `清理所有已知备份目录中的旧备份。
+
+--keep N 在每个分组中保留最新的 N 个备份：镜像源配置备份按前缀分组（如 pre-pull、pre-restore），
+切换前快照为一组，Codex/Claude/VS Code 的 .bak 文件各自一组。
+--older-than D 只删除早于 D 的备份，D 支持天数（如 30d）或 Go 时长格式（如 12h、90m）。
+同时指定时只删除既超出保留数量又早于 D 的备份。
+
+示例：
+  codex-mirror backup clean --keep 3
+  codex-mirror backup clean --older-than 30d
+  codex-mirror backup clean --keep 5 --older-than 7d --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runBackupCleanCommand,
+}
+
+// runBackupListCommand 执行backup list命令.
+func runBackupListCommand(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	backups, err := mm.ListAllBackups()
+	if err != nil {
+		return fmt.Errorf("列出备份失败: %w", err)
+	}
+
+	if asJSON {
+		if backups == nil {
+			backups = []internal.BackupEntry{}
+		}
+		data, err := json.MarshalIndent(backups, "", "  ")
+		if err != nil {
+			return fmt.Errorf("序列化备份列表失败: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(backups) == 0 {
+		fmt.Println("没有任何备份")
+		return nil
+	}
+
+	printBackupEntries(backups)
+	return nil
+}
+
+// runBackupCleanCommand 执行backup clean命令.
+func runBackupCleanCommand(cmd *cobra.Command, args []string) error {
+	keep, _ := cmd.Flags().GetInt("keep")
+	olderThanStr, _ := cmd.Flags().GetString("older-than")
+	dryRunClean, _ := cmd.Flags().GetBool("dry-run")
+
+	if keep < 0 {
+		return fmt.Errorf("--keep 不能为负数")
+	}
+	var olderThan time.Duration
+	if olderThanStr != "" {
+		var err error
+		olderThan, err = parseAge(olderThanStr)
+		if err != nil {
+			return err
+		}
+	}
+	if keep == 0 && olderThan == 0 {
+		return fmt.Errorf("请指定 --keep 或 --older-than")
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	if dryRunClean {
+		backups, err := mm.PlanCleanBackups(keep, olderThan)
+		if err != nil {
+			return fmt.Errorf("计算待清理的备份失败: %w", err)
+		}
+		if len(backups) == 0 {
+			fmt.Println("[DRY-RUN] 没有需要清理的备份")
+			return nil
+		}
+		fmt.Printf("[DRY-RUN] 将删除 %d 个备份:\n", len(backups))
+		printBackupEntries(backups)
+		return nil
+	}
+
+	removed, err := mm.CleanBackups(keep, olderThan)
+	for _, backup := range removed {
+		fmt.Printf("已删除 %s (%s)\n", backup.Path, backup.Source)
+	}
+	if err != nil {
+		return fmt.Errorf("清理备份失败: %w", err)
+	}
+
+	if len(removed) == 0 {
+		fmt.Println("没有需要清理的备份")
+		return nil
+	}
+	fmt.Printf("✅ 已清理 %d 个备份\n", len(removed))
+	return nil
+}
+
+// printBackupEntries 以表格形式打印备份列表.
+func printBackupEntries(backups []internal.BackupEntry) {
+	fmt.Println(strings.Repeat("-", 90))
+	fmt.Printf("%-8s %-40s %-20s %s\n", "来源", "名称", "时间", "大小")
+	fmt.Println(strings.Repeat("-", 90))
+	for _, backup := range backups {
+		fmt.Printf("%-8s %-40s %-20s %s\n", backup.Source, backup.Name, backup.Timestamp.Format("2006-01-02 15:04:05"), formatSize(backup.Size))
+	}
+}
+
+// parseAge 解析时长，支持天数（如 30d）和 Go 时长格式（如 12h）.
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, found := strings.CutSuffix(s, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("无效的时长 '%s'，请使用如 30d 或 12h 的格式", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("无效的时长 '%s'，请使用如 30d 或 12h 的格式", s)
+	}
+	return d, nil
+}
+
+// formatSize 以易读的单位格式化文件大小.
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+func init() {
+	backupListCmd.Flags().Bool("json", false, "以 JSON 格式输出")
+	backupCleanCmd.Flags().Int("keep", 0, "每个分组保留最新的 N 个备份")
+	backupCleanCmd.Flags().String("older-than", "", "只删除早于该时长的备份（如 30d、12h）")
+	backupCleanCmd.Flags().Bool("dry-run", false, "只列出将要删除的备份，不实际删除")
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupCleanCmd)
+	rootCmd.AddCommand(backupCmd)
+}
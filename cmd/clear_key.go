@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// clearKeyYes 跳过确认直接清除.
+var clearKeyYes bool
+
+// clearKeyCmd 代表 clear-key 命令.
+var clearKeyCmd = &cobra.Command{
+	Use:   "clear-key [name]",
+	Short: "清除镜像源的 API 密钥",
+	Long: `清除指定镜像源保存的 API 密钥，适用于密钥已泄露需要立即作废的情况。
+
+如果该镜像源是当前激活的配置，同时清除持久化的环境变量
+（Codex 为 ` + internal.CodexSwitchAPIKeyEnv + `，Claude 为 ` + internal.AnthropicAuthTokenEnv + `）。
+
+清除前需要确认，使用 --yes 跳过确认。
+
+示例：
+  codex-mirror clear-key myapi
+  codex-mirror clear-key myapi --yes`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return getMirrorNamesForCompletion(toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: runClearKeyCommand,
+}
+
+// runClearKeyCommand 执行 clear-key 命令.
+func runClearKeyCommand(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	mirror, err := mm.GetMirrorByName(name)
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+	if mirror.APIKey == "" {
+		return fmt.Errorf("镜像源 '%s' 没有 API Key 需要清除", name)
+	}
+
+	if !clearKeyYes {
+		fmt.Printf("确定要清除镜像源 '%s' 的 API 密钥 (%s) 吗？(y/N): ", name, internal.MaskAPIKey(mirror.APIKey))
+		var confirm string
+		_, _ = fmt.Scanln(&confirm)
+		if confirm != "y" && confirm != "Y" {
+			fmt.Println("已取消清除")
+			return nil
+		}
+	}
+
+	toolType := mirror.ToolType
+	if err := mm.ClearAPIKey(name); err != nil {
+		return fmt.Errorf("清除 API 密钥失败: %w", err)
+	}
+	fmt.Printf("✅ 已清除镜像源 '%s' 的 API 密钥\n", name)
+
+	config := mm.GetConfig()
+	if config.CurrentCodex != name && config.CurrentClaude != name {
+		return nil
+	}
+
+	envKey := activeKeyEnv(toolType)
+	if err := internal.NewEnvManager().UnsetEnvVar(envKey); err != nil {
+		return fmt.Errorf("清除环境变量 %s 失败: %w", envKey, err)
+	}
+	fmt.Printf("✅ 已清除当前激活配置的环境变量 %s\n", envKey)
+	fmt.Println("💡 已打开的终端仍保留旧值，请重新打开终端或手动 unset")
+	return nil
+}
+
+// activeKeyEnv 返回切换镜像源时持久化 API 密钥使用的环境变量.
+func activeKeyEnv(toolType internal.ToolType) string {
+	if toolType == internal.ToolTypeClaude {
+		return internal.AnthropicAuthTokenEnv
+	}
+	return internal.CodexSwitchAPIKeyEnv
+}
+
+func init() {
+	clearKeyCmd.Flags().BoolVarP(&clearKeyYes, "yes", "y", false, "跳过确认直接清除")
+	rootCmd.AddCommand(clearKeyCmd)
+}
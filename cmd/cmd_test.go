@@ -2,15 +2,23 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"codex-mirror/internal"
 
+	"github.com/BurntSushi/toml"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -91,6 +99,24 @@ func setupTestEnvironment(t *testing.T) (string, func()) {
 	return tempDir, cleanup
 }
 
+// resetCommandFlags 递归重置所有子命令（包括 backup clean 等嵌套子命令）的标志.
+func resetCommandFlags(cmd *cobra.Command) {
+	for _, subCmd := range cmd.Commands() {
+		if subCmd.Flags() != nil {
+			subCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+				flag.Changed = false
+				// 重置标志值到默认值（切片标志的 Set 会追加，需要单独处理）
+				if sv, ok := flag.Value.(pflag.SliceValue); ok {
+					_ = sv.Replace(nil)
+					return
+				}
+				_ = flag.Value.Set(flag.DefValue)
+			})
+		}
+		resetCommandFlags(subCmd)
+	}
+}
+
 // executeCommand 执行命令并捕获输出.
 func executeCommand(rootCmd *cobra.Command, args ...string) (string, string, error) {
 	// 捕获标准输出
@@ -106,16 +132,12 @@ func executeCommand(rootCmd *cobra.Command, args ...string) (string, string, err
 	// 创建新的命令实例以避免状态污染
 	cmd := rootCmd
 
-	// 重置所有子命令的标志
-	for _, subCmd := range cmd.Commands() {
-		if subCmd.Flags() != nil {
-			subCmd.Flags().VisitAll(func(flag *pflag.Flag) {
-				flag.Changed = false
-				// 重置标志值到默认值
-				_ = flag.Value.Set(flag.DefValue)
-			})
-		}
-	}
+	// 重置全局标志和所有子命令的标志
+	cmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		flag.Changed = false
+		_ = flag.Value.Set(flag.DefValue)
+	})
+	resetCommandFlags(cmd)
 
 	// 设置参数
 	cmd.SetArgs(args)
@@ -203,6 +225,36 @@ func TestAddCommand(t *testing.T) {
 			},
 			setupFunc: nil,
 		},
+		{
+			name:        "添加带测试请求头的镜像源",
+			args:        []string{"add", "test-headers", "https://api.test.com", "sk-test", "--test-header", "X-Org-Id=org-123"},
+			expectError: false,
+			checkOutput: func(t *testing.T, stdout, stderr string) {
+				if !strings.Contains(stdout, "X-Org-Id: org-123") {
+					t.Errorf("Expected test header in output, got stdout: %s", stdout)
+				}
+			},
+		},
+		{
+			name:        "添加带级别模型的 Claude 镜像源",
+			args:        []string{"add", "tiered", "https://api.test.com", "sk-test", "--type", "claude", "--haiku-model", "fast-model"},
+			expectError: false,
+			checkOutput: func(t *testing.T, stdout, stderr string) {
+				if !strings.Contains(stdout, "haiku 模型: fast-model") {
+					t.Errorf("Expected haiku model in output, got stdout: %s", stdout)
+				}
+			},
+		},
+		{
+			name:        "Codex 镜像源不支持级别模型",
+			args:        []string{"add", "tiered-codex", "https://api.test.com", "sk-test", "--opus-model", "smart-model"},
+			expectError: true,
+		},
+		{
+			name:        "无效的测试请求头",
+			args:        []string{"add", "bad-header", "https://api.test.com", "sk-test", "--test-header", "X Org=1"},
+			expectError: true,
+		},
 		{
 			name:        "无效的工具类型",
 			args:        []string{"add", "invalid-type", "https://api.test.com", "sk-test", "--type", "invalid"},
@@ -261,7 +313,7 @@ func TestListCommand(t *testing.T) {
 	if err1 != nil {
 		t.Fatalf("Failed to add test1 mirror: %v, stdout: %s, stderr: %s", err1, stdout1, stderr1)
 	}
-	stdout2, stderr2, err2 := executeCommand(rootCmd, "add", "test2", "https://api.test2.com", "sk-test2", "--type", "claude")
+	stdout2, stderr2, err2 := executeCommand(rootCmd, "add", "test2", "https://api.test2.com", "sk-test2", "--type", "claude", "--tag", "work")
 	if err2 != nil {
 		t.Fatalf("Failed to add test2 mirror: %v, stdout: %s, stderr: %s", err2, stdout2, stderr2)
 	}
@@ -285,6 +337,16 @@ func TestListCommand(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:        "按标签列出镜像源",
+			args:        []string{"list", "--tag", "work"},
+			expectError: false,
+			checkOutput: func(t *testing.T, stdout, stderr string) {
+				if !strings.Contains(stdout, "test2") || strings.Contains(stdout, "test1 ") {
+					t.Errorf("Expected only test2 in tag-filtered output, got: %s", stdout)
+				}
+			},
+		},
 		{
 			name:        "列出Codex镜像源",
 			args:        []string{"list", "--type", "codex"},
@@ -841,3 +903,1603 @@ func TestCommandFlags(t *testing.T) {
 		})
 	}
 }
+
+// TestCurrentCommand 测试current命令.
+func TestCurrentCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	// 尚未添加 Claude 镜像源时应返回错误
+	if _, _, err := executeCommand(rootCmd, "current", "--type", "claude"); err == nil {
+		t.Fatal("Expected error when no Claude mirror is active")
+	}
+
+	if _, _, err := executeCommand(rootCmd, "add", "cur-claude", "https://api.claude.com", "sk-test", "--type", "claude"); err != nil {
+		t.Fatalf("Failed to add mirror: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectError bool
+		expected    string
+	}{
+		{name: "默认输出名称", args: []string{"current"}, expected: "codex: official\nclaude: cur-claude\n"},
+		{name: "指定类型输出名称", args: []string{"current", "--type", "claude"}, expected: "cur-claude\n"},
+		{name: "输出URL", args: []string{"current", "--type", "claude", "--url"}, expected: "https://api.claude.com\n"},
+		{name: "JSON输出", args: []string{"current", "--type", "claude", "--json"}, expected: "{\n  \"claude\": {\n    \"name\": \"cur-claude\",\n    \"base_url\": \"https://api.claude.com\"\n  }\n}\n"},
+		{name: "无效类型", args: []string{"current", "--type", "invalid"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := executeCommand(rootCmd, tt.args...)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("executeCommand() error = %v, expectError %v, stderr: %s", err, tt.expectError, stderr)
+			}
+			if !tt.expectError && stdout != tt.expected {
+				t.Errorf("stdout = %q, expected %q", stdout, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEnvCommand 测试env命令.
+func TestEnvCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, _, err := executeCommand(rootCmd, "add", "env-claude", "https://api.claude.com", "sk-claude", "--type", "claude",
+		"--extra-env", "ANTHROPIC_DEFAULT_HAIKU_MODEL=haiku"); err != nil {
+		t.Fatalf("Failed to add mirror: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		contains []string
+	}{
+		{
+			name: "bash格式",
+			args: []string{"env", "--shell", "bash"},
+			contains: []string{
+				"export ANTHROPIC_BASE_URL='https://api.claude.com'\n",
+				"export ANTHROPIC_AUTH_TOKEN='sk-claude'\n",
+				"export ANTHROPIC_DEFAULT_HAIKU_MODEL='haiku'\n",
+				"unset ANTHROPIC_MODEL\n",
+				"unset CODEX_SWITCH_OPENAI_API_KEY\n",
+			},
+		},
+		{
+			name:     "fish格式",
+			args:     []string{"env", "--shell", "fish"},
+			contains: []string{"set -gx ANTHROPIC_BASE_URL https://api.claude.com\n"},
+		},
+		{
+			name:     "powershell格式",
+			args:     []string{"env", "--shell", "powershell"},
+			contains: []string{"$Env:ANTHROPIC_AUTH_TOKEN = \"sk-claude\"\n"},
+		},
+		{
+			name:     "清除变量",
+			args:     []string{"env", "--shell", "bash", "--unset"},
+			contains: []string{"unset ANTHROPIC_BASE_URL\n", "unset ANTHROPIC_DEFAULT_HAIKU_MODEL\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := executeCommand(rootCmd, tt.args...)
+			if err != nil {
+				t.Fatalf("executeCommand() error = %v, stderr: %s", err, stderr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("Expected %q in output, got: %s", want, stdout)
+				}
+			}
+		})
+	}
+}
+
+// TestStatusJSON 测试status命令的JSON输出.
+func TestStatusJSON(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, _, err := executeCommand(rootCmd, "add", "status-claude", "https://api.claude.com", "sk-claude", "--type", "claude"); err != nil {
+		t.Fatalf("Failed to add mirror: %v", err)
+	}
+	if _, _, err := executeCommand(rootCmd, "switch", "status-claude", "--no-backup"); err != nil {
+		t.Fatalf("Failed to switch mirror: %v", err)
+	}
+
+	stdout, stderr, err := executeCommand(rootCmd, "status", "--json")
+	if err != nil {
+		t.Fatalf("executeCommand() error = %v, stderr: %s", err, stderr)
+	}
+
+	var status internal.StatusDTO
+	if err := json.Unmarshal([]byte(stdout), &status); err != nil {
+		t.Fatalf("Invalid JSON output: %v, got: %s", err, stdout)
+	}
+	if status.CurrentClaude != "status-claude" || status.CurrentCodex != "official" {
+		t.Errorf("Unexpected current mirrors: codex=%s, claude=%s", status.CurrentCodex, status.CurrentClaude)
+	}
+	if status.ClaudeStatus.Path != filepath.Join(tempDir, ".claude", "settings.json") || !status.ClaudeStatus.Exists {
+		t.Errorf("Unexpected claude_status: %+v", status.ClaudeStatus)
+	}
+	if status.CodexStatus.Exists {
+		t.Errorf("Codex config should not exist: %+v", status.CodexStatus)
+	}
+	if status.ConfigPath == "" {
+		t.Error("config_path is empty")
+	}
+	if status.Sync == nil || status.Sync.Enabled {
+		t.Errorf("Unexpected sync status: %+v", status.Sync)
+	}
+}
+
+// TestWhichCommand 测试which命令.
+func TestWhichCommand(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	stdout, stderr, err := executeCommand(rootCmd, "which", "--json")
+	if err != nil {
+		t.Fatalf("executeCommand() error = %v, stderr: %s", err, stderr)
+	}
+
+	var result map[string]internal.ManagedPath
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Invalid JSON output: %v, got: %s", err, stdout)
+	}
+
+	claude := result["claude_settings"]
+	if claude.Path != filepath.Join(tempDir, ".claude", "settings.json") || claude.Exists {
+		t.Errorf("Unexpected claude_settings entry: %+v", claude)
+	}
+	if _, ok := result["mirrors"]; !ok {
+		t.Errorf("Expected mirrors entry, got: %s", stdout)
+	}
+}
+
+// TestConfigPathCommand 测试config path命令输出镜像源配置文件路径.
+func TestConfigPathCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	stdout, stderr, err := executeCommand(rootCmd, "config", "path")
+	if err != nil {
+		t.Fatalf("executeCommand() error = %v, stderr: %s", err, stderr)
+	}
+
+	expected, err := internal.GetMirrorConfigPath()
+	if err != nil {
+		t.Fatalf("GetMirrorConfigPath() error = %v", err)
+	}
+	if strings.TrimSpace(stdout) != expected {
+		t.Errorf("config path output = %q, expected %q", strings.TrimSpace(stdout), expected)
+	}
+}
+
+// TestListCommandJSON 测试list命令的JSON输出.
+func TestListCommandJSON(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, _, err := executeCommand(rootCmd, "add", "json-claude", "https://api.claude.com", "sk-claude-12345678", "--type", "claude"); err != nil {
+		t.Fatalf("Failed to add mirror: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantNames []string
+	}{
+		{name: "全部镜像源", args: []string{"list", "--json"}, wantNames: []string{"official", "json-claude"}},
+		{name: "按类型过滤", args: []string{"list", "--type", "claude", "--json"}, wantNames: []string{"json-claude"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := executeCommand(rootCmd, tt.args...)
+			if err != nil {
+				t.Fatalf("executeCommand() error = %v, stderr: %s", err, stderr)
+			}
+
+			var result []listMirrorJSON
+			if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+				t.Fatalf("Invalid JSON output: %v, got: %s", err, stdout)
+			}
+			if len(result) != len(tt.wantNames) {
+				t.Fatalf("Expected %d mirrors, got %d: %s", len(tt.wantNames), len(result), stdout)
+			}
+			for i, name := range tt.wantNames {
+				if result[i].Name != name || !result[i].IsCurrent {
+					t.Errorf("Unexpected entry %d: %+v", i, result[i])
+				}
+			}
+			if strings.Contains(stdout, "sk-claude-12345678") {
+				t.Errorf("API key should be masked, got: %s", stdout)
+			}
+		})
+	}
+}
+
+// TestUseCommand 测试use命令.
+func TestUseCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, _, err := executeCommand(rootCmd, "add", "use-claude", "https://api.claude.com", "sk-claude", "--type", "claude"); err != nil {
+		t.Fatalf("Failed to add mirror: %v", err)
+	}
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	before, _ := os.ReadFile(mm.GetConfigPath())
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectError bool
+		contains    string
+	}{
+		{name: "输出导出语句", args: []string{"use", "use-claude", "--shell", "bash"}, contains: "export ANTHROPIC_AUTH_TOKEN='sk-claude'\n"},
+		{name: "指定匹配的类型", args: []string{"use", "use-claude", "--type", "claude", "--shell", "bash"}, contains: "export ANTHROPIC_BASE_URL='https://api.claude.com'\n"},
+		{name: "类型不匹配", args: []string{"use", "use-claude", "--type", "codex"}, expectError: true},
+		{name: "镜像源不存在", args: []string{"use", "missing"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := executeCommand(rootCmd, tt.args...)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("executeCommand() error = %v, expectError %v, stderr: %s", err, tt.expectError, stderr)
+			}
+			if !strings.Contains(stdout, tt.contains) {
+				t.Errorf("Expected %q in output, got: %s", tt.contains, stdout)
+			}
+		})
+	}
+
+	// use 不应修改持久化配置
+	after, _ := os.ReadFile(mm.GetConfigPath())
+	if !bytes.Equal(before, after) {
+		t.Error("use command should not modify mirrors.toml")
+	}
+	if _, err := os.Stat(filepath.Join(os.Getenv("HOME"), ".claude", "settings.json")); !os.IsNotExist(err) {
+		t.Error("use command should not write Claude settings")
+	}
+}
+
+// TestRestoreCommand 测试restore命令.
+func TestRestoreCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, _, err := executeCommand(rootCmd, "add", "restore-test", "https://api.test.com", "sk-test"); err != nil {
+		t.Fatalf("Failed to add mirror: %v", err)
+	}
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	backupPath, err := mm.CreateBackup("backup")
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if _, _, err := executeCommand(rootCmd, "remove", "restore-test"); err != nil {
+		t.Fatalf("Failed to remove mirror: %v", err)
+	}
+
+	stdout, _, err := executeCommand(rootCmd, "restore", "--list")
+	if err != nil || !strings.Contains(stdout, filepath.Base(backupPath)) {
+		t.Fatalf("Expected backup in list, err: %v, got: %s", err, stdout)
+	}
+
+	if _, stderr, err := executeCommand(rootCmd, "restore", filepath.Base(backupPath)); err != nil {
+		t.Fatalf("restore failed: %v, stderr: %s", err, stderr)
+	}
+	stdout, _, _ = executeCommand(rootCmd, "list")
+	if !strings.Contains(stdout, "restore-test") {
+		t.Errorf("Expected restored mirror in list, got: %s", stdout)
+	}
+}
+
+// TestSearchCommand 测试搜索镜像源.
+func TestSearchCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, _, err := executeCommand(rootCmd, "add", "search-codex", "https://gateway.search.com", "sk-test"); err != nil {
+		t.Fatalf("Failed to add mirror: %v", err)
+	}
+	if _, _, err := executeCommand(rootCmd, "add", "search-claude", "https://gateway.search.com", "sk-test", "--type", "claude"); err != nil {
+		t.Fatalf("Failed to add mirror: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		contains []string
+		excludes []string
+	}{
+		{name: "按URL搜索", args: []string{"search", "GATEWAY.search"}, contains: []string{"search-codex", "search-claude"}},
+		{name: "按类型过滤", args: []string{"search", "gateway", "--type", "claude"}, contains: []string{"search-claude"}, excludes: []string{"search-codex"}},
+		{name: "无匹配", args: []string{"search", "nothing-here"}, contains: []string{"没有匹配"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, err := executeCommand(rootCmd, tt.args...)
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("Expected %q in output, got: %s", want, stdout)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(stdout, unwanted) {
+					t.Errorf("Did not expect %q in output, got: %s", unwanted, stdout)
+				}
+			}
+		})
+	}
+}
+
+// TestReorderCommand 测试调整镜像源显示顺序.
+func TestReorderCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	for _, name := range []string{"first", "second"} {
+		if _, _, err := executeCommand(rootCmd, "add", name, "https://"+name+".test.com", "sk-test"); err != nil {
+			t.Fatalf("Failed to add mirror %s: %v", name, err)
+		}
+	}
+
+	activeNames := func() []string {
+		mm, err := internal.NewMirrorManager()
+		if err != nil {
+			t.Fatalf("Failed to create mirror manager: %v", err)
+		}
+		var names []string
+		for _, mirror := range mm.ListActiveMirrors() {
+			names = append(names, mirror.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		expectError bool
+		expected    []string
+	}{
+		{name: "移动到最前", args: []string{"reorder", "second", "--top"}, expected: []string{"second", internal.DefaultMirrorName, "first"}},
+		{name: "移动到最后", args: []string{"reorder", "second", "--bottom"}, expected: []string{internal.DefaultMirrorName, "first", "second"}},
+		{name: "移动到指定位置", args: []string{"reorder", "first", "--to", "1"}, expected: []string{"first", internal.DefaultMirrorName, "second"}},
+		{name: "未指定位置", args: []string{"reorder", "first"}, expectError: true},
+		{name: "位置超出范围", args: []string{"reorder", "first", "--to", "9"}, expectError: true},
+		{name: "镜像源不存在", args: []string{"reorder", "missing", "--top"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := executeCommand(rootCmd, tt.args...)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("executeCommand(%v) error = %v, expectError %v", tt.args, err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+			if got := activeNames(); !slices.Equal(got, tt.expected) {
+				t.Errorf("镜像源顺序 = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// concurrencyTrackingTransport 记录同时进行中的请求数量的测试传输层.
+type concurrencyTrackingTransport struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	total    atomic.Int32
+}
+
+func (c *concurrencyTrackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	current := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	c.total.Add(1)
+	for {
+		peak := c.peak.Load()
+		if current <= peak || c.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+// TestRunTestsConcurrently 测试并行测试的并发上限和结果顺序.
+func TestRunTestsConcurrently(t *testing.T) {
+	transport := &concurrencyTrackingTransport{}
+	oldTransport := testTransport
+	testTransport = transport
+	defer func() { testTransport = oldTransport }()
+
+	const maxConcurrency = 3
+	mirrors := make([]internal.MirrorConfig, 12)
+	for i := range mirrors {
+		mirrors[i] = internal.MirrorConfig{
+			Name:     fmt.Sprintf("mirror-%d", i),
+			BaseURL:  fmt.Sprintf("https://api%d.test.com", i),
+			APIKey:   "sk-test",
+			ToolType: internal.ToolTypeCodex,
+		}
+	}
+
+	results := runTestsConcurrently(nil, mirrors, 5, 0, maxConcurrency)
+
+	if got := transport.peak.Load(); got > maxConcurrency {
+		t.Errorf("Peak concurrency = %d, want <= %d", got, maxConcurrency)
+	}
+	if got := transport.total.Load(); got != int32(len(mirrors)) {
+		t.Errorf("Total requests = %d, want %d", got, len(mirrors))
+	}
+	if len(results) != len(mirrors) {
+		t.Fatalf("Got %d results, want %d", len(results), len(mirrors))
+	}
+	for i, result := range results {
+		if result == nil || result.Name != mirrors[i].Name {
+			t.Errorf("Result %d = %+v, want mirror %s", i, result, mirrors[i].Name)
+			continue
+		}
+		if !result.Success {
+			t.Errorf("Result %d should succeed, got error: %s", i, result.Error)
+		}
+	}
+}
+
+// statusByHostTransport 按请求主机返回指定状态码的测试传输层.
+type statusByHostTransport map[string]int
+
+func (s statusByHostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, ok := s[req.URL.Hostname()]
+	if !ok {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+// TestRemoveInvalidKeysByStatus 测试--remove-all-invalid不会因限流或上游异常清除API Key.
+func TestRemoveInvalidKeysByStatus(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	tests := []struct {
+		name        string
+		status      int
+		wantKeyKept bool
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized},
+		{name: "forbidden", status: http.StatusForbidden},
+		{name: "ratelimited", status: http.StatusTooManyRequests, wantKeyKept: true},
+		{name: "servererror", status: http.StatusInternalServerError, wantKeyKept: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, wantKeyKept: true},
+	}
+
+	transport := statusByHostTransport{}
+	for _, tt := range tests {
+		host := tt.name + ".status.test"
+		transport[host] = tt.status
+		if _, stderr, err := executeCommand(rootCmd, "add", tt.name, "https://"+host, "sk-"+tt.name+"-12345678"); err != nil {
+			t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+		}
+	}
+
+	oldTransport := testTransport
+	testTransport = transport
+	defer func() { testTransport = oldTransport }()
+
+	if _, stderr, err := executeCommand(rootCmd, "test", "--remove-all-invalid", "--all", "--retries", "0"); err != nil {
+		t.Fatalf("test --remove-all-invalid failed: %v, stderr: %s", err, stderr)
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("NewMirrorManager failed: %v", err)
+	}
+	for _, tt := range tests {
+		mirror, err := mm.GetMirrorByName(tt.name)
+		if err != nil {
+			t.Fatalf("GetMirrorByName(%s) failed: %v", tt.name, err)
+		}
+		if got := mirror.APIKey != ""; got != tt.wantKeyKept {
+			t.Errorf("%s (HTTP %d): key kept = %v, want %v", tt.name, tt.status, got, tt.wantKeyKept)
+		}
+	}
+}
+
+// TestTestCommandJSON 测试test命令的JSON输出.
+func TestTestCommandJSON(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	oldTransport := testTransport
+	testTransport = &concurrencyTrackingTransport{}
+	defer func() { testTransport = oldTransport }()
+
+	if _, _, err := executeCommand(rootCmd, "add", "json-test", "https://api.test.com", "sk-test"); err != nil {
+		t.Fatalf("Failed to add mirror: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantCount int
+	}{
+		{name: "single", args: []string{"test", "json-test", "--json"}, wantCount: 1},
+		{name: "all", args: []string{"test", "--all", "--json"}, wantCount: 2},
+		{name: "parallel", args: []string{"test", "--all", "--parallel", "--json"}, wantCount: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, _, err := executeCommand(rootCmd, tt.args...)
+			if err != nil {
+				t.Fatalf("test command failed: %v", err)
+			}
+
+			var results []TestResult
+			if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+				t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+			}
+			if len(results) != tt.wantCount {
+				t.Fatalf("Got %d results, want %d", len(results), tt.wantCount)
+			}
+			for _, r := range results {
+				if !r.Success || r.StatusCode != http.StatusOK {
+					t.Errorf("Expected success result, got %+v", r)
+				}
+			}
+		})
+	}
+}
+
+// TestSelectFastestMirror 测试选择延迟最低的可用镜像源.
+func TestSelectFastestMirror(t *testing.T) {
+	results := []*TestResult{
+		{Name: "codex-slow", ToolType: internal.ToolTypeCodex, Success: true, Latency: 300},
+		{Name: "codex-fast", ToolType: internal.ToolTypeCodex, Success: true, Latency: 100},
+		{Name: "codex-failed", ToolType: internal.ToolTypeCodex, Success: false, Latency: 10},
+		{Name: "claude-failed", ToolType: internal.ToolTypeClaude, Success: false, Latency: 50},
+	}
+
+	tests := []struct {
+		name     string
+		toolType internal.ToolType
+		want     string
+	}{
+		{name: "选择最快的成功镜像源", toolType: internal.ToolTypeCodex, want: "codex-fast"},
+		{name: "没有成功的镜像源", toolType: internal.ToolTypeClaude, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectFastestMirror(results, tt.toolType)
+			gotName := ""
+			if got != nil {
+				gotName = got.Name
+			}
+			if gotName != tt.want {
+				t.Errorf("selectFastestMirror() = %q, want %q", gotName, tt.want)
+			}
+		})
+	}
+}
+
+// TestDoctorFix 测试doctor --fix清除指向不存在镜像源的当前设置.
+func TestDoctorFix(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	mm.GetConfig().CurrentClaude = "ghost"
+	if err := mm.SaveConfig(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	// 不带 --fix 时不修改配置
+	stdout, _, _ := executeCommand(rootCmd, "doctor", "--skip-test")
+	if !strings.Contains(stdout, "'ghost' 不存在") {
+		t.Fatalf("Expected dangling mirror warning, got: %s", stdout)
+	}
+	mm, _ = internal.NewMirrorManager()
+	if mm.GetConfig().CurrentClaude != "ghost" {
+		t.Fatalf("doctor without --fix should not modify config")
+	}
+
+	stdout, _, _ = executeCommand(rootCmd, "doctor", "--skip-test", "--fix")
+	if !strings.Contains(stdout, "已自动修复") {
+		t.Errorf("Expected fix message, got: %s", stdout)
+	}
+	mm, _ = internal.NewMirrorManager()
+	if mm.GetConfig().CurrentClaude != "" {
+		t.Errorf("Expected CurrentClaude to be cleared, got %q", mm.GetConfig().CurrentClaude)
+	}
+}
+
+// TestDoctorJSON 测试doctor命令的JSON输出和检查项选择.
+func TestDoctorJSON(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	stdout, _, err := executeCommand(rootCmd, "doctor", "--json", "--only", "config,env")
+	if err != nil {
+		t.Fatalf("doctor --json failed: %v", err)
+	}
+
+	var results []CheckResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if len(results) != 2 || results[0].ID != "config" || results[1].ID != "env" {
+		t.Fatalf("Expected config and env results, got %+v", results)
+	}
+	for _, r := range results {
+		if r.Status == "" || r.Message == "" {
+			t.Errorf("Expected status and message in result, got %+v", r)
+		}
+	}
+
+	if _, _, err := executeCommand(rootCmd, "doctor", "--only", "bogus"); err == nil {
+		t.Error("Expected error for unknown check ID")
+	}
+}
+
+// TestDoctorConnectivity 测试doctor连通性检查并行测试所有镜像源，并校验--timeout.
+func TestDoctorConnectivity(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	transport := &concurrencyTrackingTransport{}
+	oldTransport := testTransport
+	testTransport = transport
+	defer func() { testTransport = oldTransport }()
+
+	for i := range 3 {
+		name := fmt.Sprintf("doctor-%d", i)
+		if _, _, err := executeCommand(rootCmd, "add", name, fmt.Sprintf("https://doctor%d.test.com", i), "sk-test"); err != nil {
+			t.Fatalf("Failed to add mirror: %v", err)
+		}
+	}
+
+	stdout, _, err := executeCommand(rootCmd, "doctor", "--json", "--only", "connectivity", "--timeout", "5")
+	if err != nil {
+		t.Fatalf("doctor --json failed: %v", err)
+	}
+	var results []CheckResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if len(results) != 1 || results[0].Status != "ok" {
+		t.Fatalf("Expected ok connectivity result, got %+v", results)
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	if got, want := transport.total.Load(), int32(len(mm.ListActiveMirrors())); got != want {
+		t.Errorf("Total requests = %d, want %d", got, want)
+	}
+
+	if _, _, err := executeCommand(rootCmd, "doctor", "--only", "connectivity", "--timeout", "0"); err == nil {
+		t.Error("Expected error for non-positive --timeout")
+	}
+}
+
+// TestAddAzureProvider 测试add命令的--provider-kind和--api-version标志.
+func TestAddAzureProvider(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, _, err := executeCommand(rootCmd, "add", "azure-bad", "https://res.openai.azure.com", "sk-azure-12345678", "--provider-kind", "azure"); err == nil {
+		t.Error("Expected error when --api-version is missing")
+	}
+	if _, _, err := executeCommand(rootCmd, "add", "azure-bad", "https://res.openai.azure.com", "sk-azure-12345678", "--api-version", "2024-10-21"); err == nil {
+		t.Error("Expected error when --api-version is used without azure")
+	}
+
+	stdout, stderr, err := executeCommand(rootCmd, "add", "azure-ok", "https://res.openai.azure.com", "sk-azure-12345678", "--provider-kind", "azure", "--api-version", "2024-10-21")
+	if err != nil {
+		t.Fatalf("add azure failed: %v, stderr: %s", err, stderr)
+	}
+	if !strings.Contains(stdout, "api-version: 2024-10-21") {
+		t.Errorf("Expected provider info in output, got: %s", stdout)
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	mirror, err := mm.GetMirrorByName("azure-ok")
+	if err != nil {
+		t.Fatalf("Mirror not found: %v", err)
+	}
+	if mirror.ProviderKind != internal.ProviderKindAzure || mirror.APIVersion != "2024-10-21" {
+		t.Errorf("Unexpected provider settings: %s %s", mirror.ProviderKind, mirror.APIVersion)
+	}
+}
+
+// TestAddForce 测试 add --force 在镜像源已存在时原地更新.
+func TestAddForce(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, stderr, err := executeCommand(rootCmd, "add", "forced", "https://api.old.com", "sk-old-12345678", "--type", "claude", "--extra-env", "API_TIMEOUT_MS=1000"); err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+
+	// 默认行为：名称已存在时报错
+	if _, _, err := executeCommand(rootCmd, "add", "forced", "https://api.new.com", "sk-new-12345678"); err == nil {
+		t.Fatal("Expected error when adding an existing mirror without --force")
+	}
+
+	stdout, stderr, err := executeCommand(rootCmd, "add", "forced", "https://api.new.com", "sk-new-12345678", "--model", "claude-new", "--extra-env", "API_TIMEOUT_MS=600000", "-f")
+	if err != nil {
+		t.Fatalf("add --force failed: %v, stderr: %s", err, stderr)
+	}
+	if !strings.Contains(stdout, "已原地更新") {
+		t.Errorf("Expected update message, got: %s", stdout)
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	mirror, err := mm.GetMirrorByName("forced")
+	if err != nil {
+		t.Fatalf("Mirror not found: %v", err)
+	}
+	if mirror.BaseURL != "https://api.new.com" || mirror.APIKey != "sk-new-12345678" || mirror.ModelName != "claude-new" {
+		t.Errorf("Mirror not updated: %s %s %s", mirror.BaseURL, mirror.APIKey, mirror.ModelName)
+	}
+	if mirror.ExtraEnv["API_TIMEOUT_MS"] != "600000" {
+		t.Errorf("ExtraEnv not updated: %v", mirror.ExtraEnv)
+	}
+	if mirror.ToolType != internal.ToolTypeClaude {
+		t.Errorf("ToolType = %s, expected claude to be kept without --type", mirror.ToolType)
+	}
+	count := 0
+	for _, m := range mm.ListMirrors() {
+		if m.Name == "forced" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 mirror named forced after --force, got %d", count)
+	}
+
+	// 未指定 --type 时按已有 Claude 镜像源校验级别模型
+	if _, stderr, err := executeCommand(rootCmd, "add", "forced", "https://api.new.com", "--haiku-model", "haiku-new", "-f"); err != nil {
+		t.Fatalf("add --force --haiku-model failed: %v, stderr: %s", err, stderr)
+	}
+	mm, err = internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	if mirror, err = mm.GetMirrorByName("forced"); err != nil || mirror.HaikuModel != "haiku-new" || mirror.ToolType != internal.ToolTypeClaude {
+		t.Errorf("HaikuModel not updated: %+v, err: %v", mirror, err)
+	}
+	if _, _, err := executeCommand(rootCmd, "add", "new-codex", "https://api.codex.com", "--haiku-model", "haiku", "-f"); err == nil {
+		t.Error("Expected error when setting tier models on a new codex mirror")
+	}
+}
+
+// TestAPIKeyInputFlags 测试 add/update 从标准输入或文件读取 API 密钥.
+func TestAPIKeyInputFlags(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	defer rootCmd.SetIn(nil)
+
+	rootCmd.SetIn(strings.NewReader("sk-stdin-12345678\n"))
+	if _, stderr, err := executeCommand(rootCmd, "add", "piped", "https://api.piped.com", "--api-key-stdin"); err != nil {
+		t.Fatalf("add --api-key-stdin failed: %v, stderr: %s", err, stderr)
+	}
+
+	keyFile := filepath.Join(tempDir, "key.txt")
+	if err := os.WriteFile(keyFile, []byte("sk-file-12345678\r\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	if _, stderr, err := executeCommand(rootCmd, "update", "piped", "--api-key-file", keyFile); err != nil {
+		t.Fatalf("update --api-key-file failed: %v, stderr: %s", err, stderr)
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	mirror, err := mm.GetMirrorByName("piped")
+	if err != nil {
+		t.Fatalf("Mirror not found: %v", err)
+	}
+	if mirror.APIKey != "sk-file-12345678" {
+		t.Errorf("APIKey = %q, want sk-file-12345678", mirror.APIKey)
+	}
+
+	emptyFile := filepath.Join(tempDir, "empty.txt")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	errorCases := []struct {
+		name  string
+		stdin string
+		args  []string
+	}{
+		{name: "empty stdin", stdin: "\n", args: []string{"add", "empty", "https://api.empty.com", "--api-key-stdin"}},
+		{name: "empty file", args: []string{"add", "empty", "https://api.empty.com", "--api-key-file", emptyFile}},
+		{name: "missing file", args: []string{"add", "empty", "https://api.empty.com", "--api-key-file", filepath.Join(tempDir, "missing")}},
+		{name: "positional key with stdin", stdin: "sk-x\n", args: []string{"add", "empty", "https://api.empty.com", "sk-arg", "--api-key-stdin"}},
+		{name: "stdin with file", stdin: "sk-x\n", args: []string{"add", "empty", "https://api.empty.com", "--api-key-stdin", "--api-key-file", keyFile}},
+		{name: "update key with file", args: []string{"update", "piped", "--key", "sk-arg", "--api-key-file", keyFile}},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rootCmd.SetIn(strings.NewReader(tc.stdin))
+			if _, _, err := executeCommand(rootCmd, tc.args...); err == nil {
+				t.Errorf("Expected error for %v", tc.args)
+			}
+		})
+	}
+	mm, err = internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	if _, err := mm.GetMirrorByName("empty"); err == nil {
+		t.Error("Mirror 'empty' should not have been added")
+	}
+}
+
+// TestMirrorTimeoutFlags 测试 add/update --timeout 设置镜像源自身的测试超时时间.
+func TestMirrorTimeoutFlags(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	stdout, stderr, err := executeCommand(rootCmd, "add", "slow", "https://slow.example.com", "sk-slow-12345678", "--timeout", "30")
+	if err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+	if !strings.Contains(stdout, "测试超时: 30 秒") {
+		t.Errorf("Expected timeout in output, got: %s", stdout)
+	}
+
+	mirrorTimeout := func() int {
+		mm, err := internal.NewMirrorManager()
+		if err != nil {
+			t.Fatalf("Failed to create mirror manager: %v", err)
+		}
+		mirror, err := mm.GetMirrorByName("slow")
+		if err != nil {
+			t.Fatalf("Mirror not found: %v", err)
+		}
+		return mirror.TimeoutSeconds
+	}
+	if got := mirrorTimeout(); got != 30 {
+		t.Errorf("TimeoutSeconds = %d, expected 30", got)
+	}
+
+	if _, _, err := executeCommand(rootCmd, "update", "slow", "--timeout", "-1"); err == nil {
+		t.Error("Expected error for negative timeout")
+	}
+	if _, stderr, err := executeCommand(rootCmd, "update", "slow", "--timeout", "0"); err != nil {
+		t.Fatalf("update failed: %v, stderr: %s", err, stderr)
+	}
+	if got := mirrorTimeout(); got != 0 {
+		t.Errorf("TimeoutSeconds = %d, expected 0 after reset", got)
+	}
+}
+
+// TestSwitchCodexAndClaude 测试 switch --codex/--claude 在一次调用中分别切换两种镜像源.
+func TestSwitchCodexAndClaude(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	for _, args := range [][]string{
+		{"add", "both-codex", "https://codex.test.com", "sk-codex-12345678"},
+		{"add", "both-claude", "https://claude.test.com", "sk-claude-12345678", "--type", "claude"},
+	} {
+		if _, stderr, err := executeCommand(rootCmd, args...); err != nil {
+			t.Fatalf("%v failed: %v, stderr: %s", args, err, stderr)
+		}
+	}
+
+	errorCases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"类型不匹配", []string{"switch", "--codex", "both-claude"}, "不能用于 --codex"},
+		{"与名称参数同时使用", []string{"switch", "both-codex", "--claude", "both-claude"}, "不能与镜像源名称参数同时使用"},
+		{"同时指定模型", []string{"switch", "--codex", "both-codex", "--claude", "both-claude", "--model", "m"}, "--model"},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := executeCommand(rootCmd, tc.args...)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	if _, stderr, err := executeCommand(rootCmd, "switch", "--codex", "both-codex", "--claude", "both-claude", "--no-backup"); err != nil {
+		t.Fatalf("switch --codex --claude failed: %v, stderr: %s", err, stderr)
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	if config := mm.GetConfig(); config.CurrentCodex != "both-codex" || config.CurrentClaude != "both-claude" {
+		t.Errorf("Current mirrors = codex %q, claude %q", config.CurrentCodex, config.CurrentClaude)
+	}
+
+	codexConfig, err := os.ReadFile(filepath.Join(tempDir, ".codex", "config.toml"))
+	if err != nil || !strings.Contains(string(codexConfig), "https://codex.test.com") {
+		t.Errorf("Codex config not updated: %v\n%s", err, codexConfig)
+	}
+	claudeSettings, err := os.ReadFile(filepath.Join(tempDir, ".claude", "settings.json"))
+	if err != nil || !strings.Contains(string(claudeSettings), "https://claude.test.com") {
+		t.Errorf("Claude settings not updated: %v\n%s", err, claudeSettings)
+	}
+}
+
+// TestSwitchNoEnv 测试 --no-env 和 config no-env 只更新配置文件，不写入 shell 配置文件.
+func TestSwitchNoEnv(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	t.Setenv(internal.CodexSwitchAPIKeyEnv, "")
+
+	if _, stderr, err := executeCommand(rootCmd, "add", "no-env", "https://no-env.test.com", "sk-no-env-12345678"); err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+
+	if _, _, err := executeCommand(rootCmd, "switch", "no-env", "--no-env", "--env"); err == nil || !strings.Contains(err.Error(), "--no-env") {
+		t.Errorf("Expected --no-env/--env conflict error, got %v", err)
+	}
+
+	assertNoShellProfiles := func(t *testing.T) {
+		t.Helper()
+		for _, rc := range []string{".bashrc", ".profile", ".zshrc"} {
+			if _, err := os.Stat(filepath.Join(tempDir, rc)); !os.IsNotExist(err) {
+				t.Errorf("%s should not be written", rc)
+			}
+		}
+	}
+
+	t.Run("--no-env", func(t *testing.T) {
+		if _, stderr, err := executeCommand(rootCmd, "switch", "no-env", "--no-env", "--no-backup"); err != nil {
+			t.Fatalf("switch --no-env failed: %v, stderr: %s", err, stderr)
+		}
+		codexConfig, err := os.ReadFile(filepath.Join(tempDir, ".codex", "config.toml"))
+		if err != nil || !strings.Contains(string(codexConfig), "https://no-env.test.com") {
+			t.Errorf("Codex config not updated: %v\n%s", err, codexConfig)
+		}
+		assertNoShellProfiles(t)
+	})
+
+	t.Run("config no-env", func(t *testing.T) {
+		if _, stderr, err := executeCommand(rootCmd, "config", "no-env", "true"); err != nil {
+			t.Fatalf("config no-env failed: %v, stderr: %s", err, stderr)
+		}
+		stdout, _, err := executeCommand(rootCmd, "config", "no-env")
+		if err != nil || strings.TrimSpace(stdout) != "true" {
+			t.Errorf("config no-env = %q, err = %v", stdout, err)
+		}
+		if _, _, err := executeCommand(rootCmd, "config", "no-env", "maybe"); err == nil {
+			t.Error("Expected error for invalid value")
+		}
+
+		if _, stderr, err := executeCommand(rootCmd, "switch", "no-env", "--no-backup"); err != nil {
+			t.Fatalf("switch failed: %v, stderr: %s", err, stderr)
+		}
+		assertNoShellProfiles(t)
+	})
+}
+
+// TestBackupListAndClean 测试backup list和backup clean命令.
+func TestBackupListAndClean(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	backupDir := filepath.Join(tempDir, ".codex-mirror", "backup")
+	if err := os.MkdirAll(backupDir, 0o755); err != nil {
+		t.Fatalf("Failed to create backup dir: %v", err)
+	}
+	for _, name := range []string{"pre-pull-20200101-000000.toml", "pre-pull-20200102-000000.toml"} {
+		if err := os.WriteFile(filepath.Join(backupDir, name), []byte("x"), 0o600); err != nil {
+			t.Fatalf("Failed to write backup: %v", err)
+		}
+	}
+
+	stdout, _, err := executeCommand(rootCmd, "backup", "list")
+	if err != nil {
+		t.Fatalf("backup list failed: %v", err)
+	}
+	if !strings.Contains(stdout, "pre-pull-20200101-000000.toml") || !strings.Contains(stdout, "mirrors") {
+		t.Errorf("backup list output missing backup: %s", stdout)
+	}
+
+	if _, _, err := executeCommand(rootCmd, "backup", "clean"); err == nil {
+		t.Error("Expected error when neither --keep nor --older-than is given")
+	}
+	if _, _, err := executeCommand(rootCmd, "backup", "clean", "--older-than", "soon"); err == nil {
+		t.Error("Expected error for invalid --older-than")
+	}
+
+	stdout, _, err = executeCommand(rootCmd, "backup", "clean", "--keep", "1", "--dry-run")
+	if err != nil || !strings.Contains(stdout, "pre-pull-20200101-000000.toml") {
+		t.Fatalf("backup clean --dry-run = %q, err = %v", stdout, err)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, "pre-pull-20200101-000000.toml")); err != nil {
+		t.Errorf("--dry-run should not delete backups: %v", err)
+	}
+
+	if _, _, err := executeCommand(rootCmd, "backup", "clean", "--keep", "1"); err != nil {
+		t.Fatalf("backup clean failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, "pre-pull-20200101-000000.toml")); !os.IsNotExist(err) {
+		t.Error("Oldest backup should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(backupDir, "pre-pull-20200102-000000.toml")); err != nil {
+		t.Errorf("Newest backup should be kept: %v", err)
+	}
+}
+
+// TestSyncLog 测试sync log命令输出同步历史.
+func TestSyncLog(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	stdout, _, err := executeCommand(rootCmd, "sync", "log")
+	if err != nil {
+		t.Fatalf("sync log failed: %v", err)
+	}
+	if !strings.Contains(stdout, "暂无同步记录") {
+		t.Errorf("Expected empty history message, got: %s", stdout)
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	sm := internal.NewSyncManager(mm)
+	for _, direction := range []string{internal.SyncDirectionPush, internal.SyncDirectionPull} {
+		if err := sm.AppendHistory(internal.SyncHistoryEntry{Timestamp: time.Now(), Direction: direction, Strategy: "auto", DeviceID: "dev-log", Success: true}); err != nil {
+			t.Fatalf("AppendHistory failed: %v", err)
+		}
+	}
+
+	stdout, _, err = executeCommand(rootCmd, "sync", "log", "--limit", "1")
+	if err != nil {
+		t.Fatalf("sync log --limit failed: %v", err)
+	}
+	if strings.Contains(stdout, "push") || !strings.Contains(stdout, "pull") || !strings.Contains(stdout, "dev-log") {
+		t.Errorf("Expected only the latest entry, got: %s", stdout)
+	}
+	syncLogLimit = 20
+}
+
+// TestExitCode 测试同步因冲突取消时使用单独的退出码.
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"generic error", errors.New("失败"), 1},
+		{"sync aborted", fmt.Errorf("推送配置失败: %w", internal.ErrSyncAborted), exitCodeSyncAborted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSyncStatusProfile 测试 sync status 按 --profile 显示对应的同步配置.
+func TestSyncStatusProfile(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	defer func() { syncProfile = internal.DefaultSyncProfile }()
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	mm.GetConfig().SyncProfiles = map[string]*internal.SyncConfig{
+		"work": {Enabled: true, Provider: "gist", Endpoint: "https://api.github.com", DeviceID: "dev-work", DeviceUUID: "uuid-work"},
+	}
+	if err := mm.SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	stdout, _, err := executeCommand(rootCmd, "sync", "status", "--profile", "work")
+	if err != nil {
+		t.Fatalf("sync status --profile work failed: %v", err)
+	}
+	if !strings.Contains(stdout, "同步配置: work") || !strings.Contains(stdout, "dev-work") {
+		t.Errorf("Expected work profile status, got: %s", stdout)
+	}
+
+	stdout, _, err = executeCommand(rootCmd, "sync", "status", "--profile", internal.DefaultSyncProfile)
+	if err != nil {
+		t.Fatalf("sync status failed: %v", err)
+	}
+	if !strings.Contains(stdout, "云同步未启用") || !strings.Contains(stdout, "其他同步配置: work") {
+		t.Errorf("Expected default profile to be unconfigured, got: %s", stdout)
+	}
+
+	if _, _, err := executeCommand(rootCmd, "sync", "push", "--profile", "bad name"); err == nil {
+		t.Error("Expected invalid profile name to fail")
+	}
+	if _, _, err := executeCommand(rootCmd, "sync", "files", "--profile", internal.DefaultSyncProfile); err == nil || !strings.Contains(err.Error(), "云同步未初始化") {
+		t.Errorf("Expected sync files to require initialization, got: %v", err)
+	}
+}
+
+// TestSetModelWithAlias 测试 set-model 修改当前镜像源的模型，并在应用时解析模型别名.
+func TestSetModelWithAlias(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, stderr, err := executeCommand(rootCmd, "config", "no-env", "true"); err != nil {
+		t.Fatalf("config no-env failed: %v, stderr: %s", err, stderr)
+	}
+	if _, stderr, err := executeCommand(rootCmd, "add", "aliased", "https://aliased.test.com", "sk-aliased-12345678"); err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+	if _, stderr, err := executeCommand(rootCmd, "switch", "aliased", "--no-backup"); err != nil {
+		t.Fatalf("switch failed: %v, stderr: %s", err, stderr)
+	}
+
+	codexModel := func(t *testing.T) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(tempDir, ".codex", "config.toml"))
+		if err != nil {
+			t.Fatalf("read codex config: %v", err)
+		}
+		var config struct {
+			Model string `toml:"model"`
+		}
+		if _, err := toml.Decode(string(data), &config); err != nil {
+			t.Fatalf("decode codex config: %v", err)
+		}
+		return config.Model
+	}
+
+	if _, stderr, err := executeCommand(rootCmd, "set-model", "gpt-5"); err != nil {
+		t.Fatalf("set-model failed: %v, stderr: %s", err, stderr)
+	}
+	if got := codexModel(t); got != "gpt-5" {
+		t.Errorf("model = %q, want gpt-5", got)
+	}
+
+	if _, stderr, err := executeCommand(rootCmd, "config", "model-alias", "fast", "gpt-5-mini"); err != nil {
+		t.Fatalf("config model-alias failed: %v, stderr: %s", err, stderr)
+	}
+	stdout, _, err := executeCommand(rootCmd, "config", "model-alias")
+	if err != nil || !strings.Contains(stdout, "fast -> gpt-5-mini") {
+		t.Errorf("config model-alias list = %q, err = %v", stdout, err)
+	}
+	if _, stderr, err := executeCommand(rootCmd, "set-model", "fast"); err != nil {
+		t.Fatalf("set-model alias failed: %v, stderr: %s", err, stderr)
+	}
+	if got := codexModel(t); got != "gpt-5-mini" {
+		t.Errorf("model = %q, want alias resolved to gpt-5-mini", got)
+	}
+
+	// 修改别名后重新切换即可生效，mirrors.toml 中保留别名
+	if _, _, err := executeCommand(rootCmd, "config", "model-alias", "fast", "gpt-5-nano"); err != nil {
+		t.Fatalf("config model-alias update failed: %v", err)
+	}
+	if _, stderr, err := executeCommand(rootCmd, "switch", "aliased", "--no-backup"); err != nil {
+		t.Fatalf("switch failed: %v, stderr: %s", err, stderr)
+	}
+	if got := codexModel(t); got != "gpt-5-nano" {
+		t.Errorf("model = %q, want gpt-5-nano", got)
+	}
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	if mirror, _ := mm.GetMirrorByName("aliased"); mirror.ModelName != "fast" {
+		t.Errorf("stored model = %q, want alias fast", mirror.ModelName)
+	}
+
+	if _, _, err := executeCommand(rootCmd, "set-model", "gpt-5", "--type", "claude"); err == nil {
+		t.Error("Expected error without an active claude mirror")
+	}
+}
+
+// TestSyncUnlinkKeepRemote 测试 sync unlink --keep-remote 停用同步并清除本地凭据.
+func TestSyncUnlinkKeepRemote(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to create mirror manager: %v", err)
+	}
+	mm.GetConfig().SyncProfiles = map[string]*internal.SyncConfig{
+		internal.DefaultSyncProfile: {Enabled: true, Provider: "gist", Token: "ghp-secret", EncryptionPwd: "test-password", GistID: "abc", DeviceID: "dev-1", DeviceUUID: "uuid-1"},
+	}
+	if err := mm.SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	stdout, _, err := executeCommand(rootCmd, "sync", "unlink", "--keep-remote", "--yes")
+	if err != nil {
+		t.Fatalf("sync unlink failed: %v", err)
+	}
+	if !strings.Contains(stdout, "云同步已停用") {
+		t.Errorf("Expected unlink message, got: %s", stdout)
+	}
+
+	reloaded, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("Failed to reload mirror manager: %v", err)
+	}
+	config := reloaded.GetSyncProfile(internal.DefaultSyncProfile)
+	if config.Enabled || config.Token != "" || config.EncryptionPwd != "" || config.GistID != "" {
+		t.Errorf("Expected sync disabled and credentials cleared, got: %+v", config)
+	}
+
+	stdout, _, err = executeCommand(rootCmd, "sync", "status")
+	if err != nil || !strings.Contains(stdout, "云同步未启用") {
+		t.Errorf("Expected sync status to show disabled, got: %s, %v", stdout, err)
+	}
+}
+
+// TestDuplicateBaseURLWarning 测试添加相同地址的镜像源时给出警告，并由doctor列出.
+func TestDuplicateBaseURLWarning(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, stderr, err := executeCommand(rootCmd, "add", "dup-one", "https://dup.example.com", "sk-dup-one-12345678"); err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+	stdout, stderr, err := executeCommand(rootCmd, "add", "dup-two", "https://dup.example.com/", "sk-dup-two-12345678")
+	if err != nil {
+		t.Fatalf("add with duplicate URL should not fail: %v, stderr: %s", err, stderr)
+	}
+	if !strings.Contains(stdout, "dup-one") || !strings.Contains(stdout, "相同的 API 地址") {
+		t.Errorf("Expected duplicate URL warning, got: %s", stdout)
+	}
+
+	stdout, _, err = executeCommand(rootCmd, "doctor", "--json", "--only", "duplicates")
+	if err != nil {
+		t.Fatalf("doctor --json failed: %v", err)
+	}
+	var results []CheckResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if len(results) != 1 || results[0].Status != "warning" || !strings.Contains(results[0].Message, "dup-one, dup-two") {
+		t.Errorf("Expected duplicates warning, got %+v", results)
+	}
+}
+
+// TestClearKeyCommand 测试clear-key命令清除镜像源的API密钥.
+func TestClearKeyCommand(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, stderr, err := executeCommand(rootCmd, "add", "leaked", "https://leaked.example.com", "sk-leaked-12345678"); err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+
+	stdout, _, err := executeCommand(rootCmd, "clear-key", "leaked", "--yes")
+	if err != nil {
+		t.Fatalf("clear-key failed: %v", err)
+	}
+	if !strings.Contains(stdout, "已清除镜像源 'leaked' 的 API 密钥") || strings.Contains(stdout, "环境变量") {
+		t.Errorf("Unexpected output for inactive mirror: %s", stdout)
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("NewMirrorManager() error = %v", err)
+	}
+	if mirror, _ := mm.GetMirrorByName("leaked"); mirror == nil || mirror.APIKey != "" {
+		t.Errorf("API key should be cleared, got %+v", mirror)
+	}
+
+	if _, _, err := executeCommand(rootCmd, "clear-key", "leaked", "--yes"); err == nil {
+		t.Error("clear-key without a key should fail")
+	}
+	if _, _, err := executeCommand(rootCmd, "clear-key", "missing", "--yes"); err == nil {
+		t.Error("clear-key on missing mirror should fail")
+	}
+}
+
+// TestSwitchDryRun 测试switch --dry-run只输出配置变化而不写入文件.
+func TestSwitchDryRun(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	for _, name := range []string{"dry-first", "dry-claude"} {
+		if _, stderr, err := executeCommand(rootCmd, "add", name, "https://claude.dry.com", "sk-dry-claude-12345678", "--type", "claude"); err != nil {
+			t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+		}
+	}
+
+	stdout, stderr, err := executeCommand(rootCmd, "switch", "dry-claude", "--dry-run")
+	if err != nil {
+		t.Fatalf("switch --dry-run failed: %v, stderr: %s", err, stderr)
+	}
+
+	if !strings.Contains(stdout, "+ env.ANTHROPIC_BASE_URL = https://claude.dry.com") {
+		t.Errorf("Expected base URL change in preview, got: %s", stdout)
+	}
+	if strings.Contains(stdout, "sk-dry-claude-12345678") {
+		t.Errorf("Preview should mask API keys, got: %s", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".claude", "settings.json")); !os.IsNotExist(err) {
+		t.Error("Dry run should not create Claude settings.json")
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("NewMirrorManager failed: %v", err)
+	}
+	if mm.GetConfig().CurrentClaude == "dry-claude" {
+		t.Error("Dry run should not change the current Claude mirror")
+	}
+}
+
+// TestSwitchCreatesSnapshot 测试switch在修改文件前创建快照，--no-backup时不创建.
+func TestSwitchCreatesSnapshot(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, stderr, err := executeCommand(rootCmd, "add", "snap-claude", "https://claude.snap.com", "sk-snap", "--type", "claude"); err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+
+	countSnapshots := func() int {
+		mm, err := internal.NewMirrorManager()
+		if err != nil {
+			t.Fatalf("NewMirrorManager failed: %v", err)
+		}
+		snapshots, err := mm.ListSwitchSnapshots()
+		if err != nil {
+			t.Fatalf("ListSwitchSnapshots failed: %v", err)
+		}
+		return len(snapshots)
+	}
+
+	if _, stderr, err := executeCommand(rootCmd, "switch", "snap-claude", "--no-backup"); err != nil {
+		t.Fatalf("switch failed: %v, stderr: %s", err, stderr)
+	}
+	if n := countSnapshots(); n != 0 {
+		t.Errorf("Expected no snapshot with --no-backup, got %d", n)
+	}
+
+	if _, stderr, err := executeCommand(rootCmd, "switch", "snap-claude"); err != nil {
+		t.Fatalf("switch failed: %v, stderr: %s", err, stderr)
+	}
+	if n := countSnapshots(); n != 1 {
+		t.Errorf("Expected one snapshot after switch, got %d", n)
+	}
+}
+
+// TestLogOutputModes 测试--quiet和--log-format控制进度信息的输出.
+func TestLogOutputModes(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, stderr, err := executeCommand(rootCmd, "add", "log-claude", "https://claude.log.com", "sk-log-claude-12345678", "--type", "claude"); err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout bool
+		wantJSON   bool
+	}{
+		{name: "默认文本输出", args: nil, wantStdout: true},
+		{name: "quiet", args: []string{"--quiet"}},
+		{name: "json", args: []string{"--log-format", "json"}, wantJSON: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"switch", "log-claude", "--no-backup"}, tt.args...)
+			stdout, stderr, err := executeCommand(rootCmd, args...)
+			if err != nil {
+				t.Fatalf("switch failed: %v, stderr: %s", err, stderr)
+			}
+			if got := strings.Contains(stdout, "成功切换到镜像源"); got != tt.wantStdout {
+				t.Errorf("progress in stdout = %v, want %v; stdout: %s", got, tt.wantStdout, stdout)
+			}
+			if !tt.wantJSON {
+				return
+			}
+			var found bool
+			for _, line := range strings.Split(strings.TrimSpace(stderr), "\n") {
+				var entry struct {
+					Level string `json:"level"`
+					Msg   string `json:"msg"`
+				}
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					continue
+				}
+				if entry.Level == "info" && strings.Contains(entry.Msg, "成功切换到镜像源") {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected JSON progress log in stderr, got: %s", stderr)
+			}
+		})
+	}
+
+	if _, _, err := executeCommand(rootCmd, "list", "--log-format", "xml"); err == nil {
+		t.Error("Expected error for invalid --log-format")
+	}
+}
+
+// TestConfigDirFlag 测试--config-dir指定镜像源配置目录.
+func TestConfigDirFlag(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	configDir := filepath.Join(tempDir, "portable")
+	if _, stderr, err := executeCommand(rootCmd, "add", "portable", "https://portable.test.com", "sk-portable-12345678", "--config-dir", configDir); err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+
+	mm, err := internal.NewMirrorManagerWithPath(filepath.Join(configDir, "mirrors.toml"))
+	if err != nil {
+		t.Fatalf("NewMirrorManagerWithPath failed: %v", err)
+	}
+	if _, err := mm.GetMirrorByName("portable"); err != nil {
+		t.Errorf("Expected mirror in --config-dir config: %v", err)
+	}
+
+	// 未指定 --config-dir 时仍使用默认配置（命令运行结束后全局设置仍保留，需要手动清除）
+	internal.SetConfigDir("")
+	mm, err = internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("NewMirrorManager failed: %v", err)
+	}
+	if _, err := mm.GetMirrorByName("portable"); err == nil {
+		t.Error("Mirror should not be added to the default config")
+	}
+}
+
+// TestSwitchModelValidation 测试switch --model保存模型，并在模型不在缓存列表中时警告.
+func TestSwitchModelValidation(t *testing.T) {
+	_, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if _, stderr, err := executeCommand(rootCmd, "add", "model-claude", "https://claude.model.com", "sk-model-claude-12345678", "--type", "claude"); err != nil {
+		t.Fatalf("add failed: %v, stderr: %s", err, stderr)
+	}
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		t.Fatalf("NewMirrorManager failed: %v", err)
+	}
+	if err := mm.SetMirrorModels("model-claude", []string{"claude-sonnet-4"}); err != nil {
+		t.Fatalf("SetMirrorModels failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		model    string
+		wantWarn bool
+	}{
+		{name: "模型在列表中", model: "claude-sonnet-4"},
+		{name: "模型不在列表中", model: "claude-unknown", wantWarn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := executeCommand(rootCmd, "switch", "model-claude", "--no-backup", "--model", tt.model)
+			if err != nil {
+				t.Fatalf("switch failed: %v, stderr: %s", err, stderr)
+			}
+			if got := strings.Contains(stdout, "不在镜像源 'model-claude' 的可用模型列表中"); got != tt.wantWarn {
+				t.Errorf("warning = %v, want %v; stdout: %s", got, tt.wantWarn, stdout)
+			}
+
+			mm, err := internal.NewMirrorManager()
+			if err != nil {
+				t.Fatalf("NewMirrorManager failed: %v", err)
+			}
+			mirror, _ := mm.GetMirrorByName("model-claude")
+			if mirror.ModelName != tt.model {
+				t.Errorf("ModelName = %q, want %q", mirror.ModelName, tt.model)
+			}
+		})
+	}
+}
+
+// TestSwitchCodexToClaudeClearsVSCode 测试从Codex切换到Claude时清除VS Code配置.
+func TestSwitchCodexToClaudeClearsVSCode(t *testing.T) {
+	tempDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	var vscodeDir string
+	switch runtime.GOOS {
+	case "windows":
+		vscodeDir = filepath.Join(tempDir, "AppData", "Roaming", "Code", "User")
+	case "darwin":
+		vscodeDir = filepath.Join(tempDir, "Library", "Application Support", "Code", "User")
+	default:
+		vscodeDir = filepath.Join(tempDir, ".config", "Code", "User")
+	}
+	if err := os.MkdirAll(vscodeDir, 0o755); err != nil {
+		t.Fatalf("Failed to create vscode dir: %v", err)
+	}
+	settingsPath := filepath.Join(vscodeDir, "settings.json")
+	if err := os.WriteFile(settingsPath, []byte("{}"), 0o644); err != nil {
+		t.Fatalf("Failed to create settings.json: %v", err)
+	}
+
+	readAPIBase := func() (interface{}, bool) {
+		data, err := os.ReadFile(settingsPath)
+		if err != nil {
+			t.Fatalf("Failed to read settings.json: %v", err)
+		}
+		var settings map[string]interface{}
+		if err := json.Unmarshal(data, &settings); err != nil {
+			t.Fatalf("Failed to parse settings.json: %v", err)
+		}
+		v, ok := settings["chatgpt.apiBase"]
+		return v, ok
+	}
+
+	for _, args := range [][]string{
+		{"add", "vs-codex", "https://codex.test.com", "sk-codex"},
+		{"add", "vs-claude", "https://claude.test.com", "sk-claude", "--type", "claude"},
+		{"switch", "vs-codex", "--no-backup"},
+	} {
+		if _, stderr, err := executeCommand(rootCmd, args...); err != nil {
+			t.Fatalf("%v failed: %v, stderr: %s", args, err, stderr)
+		}
+	}
+	if _, ok := readAPIBase(); !ok {
+		t.Fatal("Expected chatgpt.apiBase after switching to codex mirror")
+	}
+
+	if _, stderr, err := executeCommand(rootCmd, "switch", "vs-claude", "--no-backup"); err != nil {
+		t.Fatalf("switch to claude failed: %v, stderr: %s", err, stderr)
+	}
+	if v, ok := readAPIBase(); ok {
+		t.Errorf("Expected chatgpt.apiBase to be cleared, got %v", v)
+	}
+}
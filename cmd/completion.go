@@ -123,6 +123,40 @@ func getMirrorNamesForCompletion(toComplete string) []string {
 	return names
 }
 
+// completeMirrorsOfType 返回只补全指定工具类型镜像源名称的补全函数.
+func completeMirrorsOfType(toolType internal.ToolType) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		mm, err := internal.NewMirrorManager()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var names []string
+		for _, mirror := range mm.ListActiveMirrors() {
+			if mirror.ToolType == toolType && hasPrefix(mirror.Name, toComplete) {
+				names = append(names, mirror.Name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeTags 为 --tag 标志补全已有的标签.
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var tags []string
+	for _, tag := range mm.ListTags() {
+		if hasPrefix(tag, toComplete) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, cobra.ShellCompDirectiveNoFileComp
+}
+
 // hasPrefix 检查字符串是否以指定前缀开头（不区分大小写）.
 func hasPrefix(s, prefix string) bool {
 	if len(s) < len(prefix) {
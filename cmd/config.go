@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// configCmd 代表config命令.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "管理镜像源配置文件",
+	Long:  `查看镜像源配置文件 (mirrors.toml) 的位置等信息`,
+}
+
+// configNoEnvCmd 代表config no-env命令.
+var configNoEnvCmd = &cobra.Command{
+	Use:   "no-env [true|false]",
+	Short: "设置切换时是否跳过环境变量的持久化",
+	Long: `设置切换镜像源时是否跳过环境变量的持久化，相当于每次 switch 都使用 --no-env。
+
+开启后切换只修改 config.toml、auth.json 等配置文件，不再写入 shell 配置文件
+（.zshrc/.bashrc/.profile）或 Windows 用户环境变量（setx），适合用其他工具管理环境变量的用户。
+代价是新打开的终端不会继承密钥，需要自行设置镜像源的环境变量。
+
+不带参数时显示当前设置。
+
+示例：
+  codex-mirror config no-env
+  codex-mirror config no-env true
+  codex-mirror config no-env false`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"true", "false"},
+	RunE:      runConfigNoEnvCommand,
+}
+
+// runConfigNoEnvCommand 执行config no-env命令.
+func runConfigNoEnvCommand(cmd *cobra.Command, args []string) error {
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	if len(args) == 0 {
+		fmt.Println(mm.NoEnv())
+		return nil
+	}
+
+	noEnv, err := strconv.ParseBool(args[0])
+	if err != nil {
+		return fmt.Errorf("无效的值 '%s'，请使用 true 或 false", args[0])
+	}
+	if err := mm.SetNoEnv(noEnv); err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+
+	if noEnv {
+		fmt.Println("已开启 no-env：切换时只更新配置文件，不持久化环境变量，新打开的终端不会继承密钥")
+	} else {
+		fmt.Println("已关闭 no-env：切换时会把环境变量写入 shell 配置文件或系统环境变量")
+	}
+	return nil
+}
+
+// configModelAliasCmd 代表config model-alias命令.
+var configModelAliasCmd = &cobra.Command{
+	Use:   "model-alias [alias] [model]",
+	Short: "管理模型别名",
+	Long: `管理模型别名（如 fast -> gpt-5-mini），别名保存在 mirrors.toml 的 model_aliases 中。
+
+镜像源的模型名称为别名时，切换或重新应用配置时替换为实际模型；修改别名后使用
+'codex-mirror set-model <别名>' 或重新切换即可生效。别名不支持嵌套。
+
+不带参数时列出所有别名，只给出别名时显示其实际模型，模型为空字符串时删除该别名。
+
+示例：
+  codex-mirror config model-alias
+  codex-mirror config model-alias fast gpt-5-mini
+  codex-mirror config model-alias fast ""`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runConfigModelAliasCommand,
+}
+
+// runConfigModelAliasCommand 执行config model-alias命令.
+func runConfigModelAliasCommand(cmd *cobra.Command, args []string) error {
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	aliases := mm.ModelAliases()
+	switch len(args) {
+	case 0:
+		if len(aliases) == 0 {
+			fmt.Println("没有设置模型别名")
+			return nil
+		}
+		names := make([]string, 0, len(aliases))
+		for alias := range aliases {
+			names = append(names, alias)
+		}
+		sort.Strings(names)
+		for _, alias := range names {
+			fmt.Printf("%s -> %s\n", alias, aliases[alias])
+		}
+		return nil
+	case 1:
+		model, ok := aliases[args[0]]
+		if !ok {
+			return fmt.Errorf("模型别名 '%s' 不存在", args[0])
+		}
+		fmt.Println(model)
+		return nil
+	}
+
+	if err := mm.SetModelAlias(args[0], args[1]); err != nil {
+		return fmt.Errorf("设置模型别名失败: %w", err)
+	}
+	if strings.TrimSpace(args[1]) == "" {
+		fmt.Printf("✅ 已删除模型别名 '%s'\n", args[0])
+	} else {
+		fmt.Printf("✅ 模型别名 '%s' -> %s\n", args[0], args[1])
+	}
+	return nil
+}
+
+// configPathCmd 代表config path命令.
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "显示镜像源配置文件路径",
+	Long: `显示镜像源配置文件 (mirrors.toml) 的路径。
+
+使用 --open 在系统文件管理器中打开配置目录（macOS 使用 open，
+Windows 使用 explorer，Linux 使用 xdg-open）。
+
+示例：
+  codex-mirror config path
+  codex-mirror config path --open`,
+	Args: cobra.NoArgs,
+	RunE: runConfigPathCommand,
+}
+
+// runConfigPathCommand 执行config path命令的实际逻辑.
+func runConfigPathCommand(cmd *cobra.Command, args []string) error {
+	open, _ := cmd.Flags().GetBool("open")
+
+	configPath, err := internal.GetMirrorConfigPath()
+	if err != nil {
+		return fmt.Errorf("获取配置路径失败: %w", err)
+	}
+	fmt.Println(configPath)
+
+	if !open {
+		return nil
+	}
+
+	configDir := filepath.Dir(configPath)
+	if err := internal.OpenInFileManager(configDir); err != nil {
+		// 无法启动文件管理器时只提示，路径已输出，用户可手动打开
+		fmt.Printf("⚠️  无法打开文件管理器: %v\n", err)
+		fmt.Printf("请手动打开配置目录: %s\n", configDir)
+	}
+	return nil
+}
+
+func init() {
+	configPathCmd.Flags().Bool("open", false, "在文件管理器中打开配置目录")
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configNoEnvCmd)
+	configCmd.AddCommand(configModelAliasCmd)
+	rootCmd.AddCommand(configCmd)
+}
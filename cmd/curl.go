@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// curlCmd 代表 curl 命令.
+var curlCmd = &cobra.Command{
+	Use:   "curl [name]",
+	Short: "输出测试镜像源的 curl 命令",
+	Long: `输出一条可直接运行的 curl 命令，请求与 test 命令相同的测试端点，便于在工具之外调试镜像源。
+
+Codex 镜像源请求 /v1/models 并使用 Authorization: Bearer 请求头；
+Claude 镜像源请求 /v1/messages 并使用 x-api-key 和 anthropic-version 请求头；
+配置了测试路径、测试请求头、代理或 Azure 提供商时同样生效。
+
+默认只显示密钥的掩码，使用 --reveal 输出明文密钥（配置了密钥命令时会执行该命令）。
+未指定名称时使用当前激活的镜像源。
+
+示例：
+  codex-mirror curl
+  codex-mirror curl myapi
+  eval "$(codex-mirror curl myapi --reveal)"`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return getMirrorNamesForCompletion(toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: runCurlCommand,
+}
+
+// runCurlCommand 执行 curl 命令.
+func runCurlCommand(cmd *cobra.Command, args []string) error {
+	reveal, _ := cmd.Flags().GetBool("reveal")
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	var mirror *internal.MirrorConfig
+	if len(args) == 0 {
+		mirror = currentActiveMirror(mm)
+		if mirror == nil {
+			return fmt.Errorf("未找到当前激活的镜像源，请指定镜像源名称或使用 'codex-mirror switch' 先切换")
+		}
+	} else {
+		mirror, err = mm.GetMirrorByName(args[0])
+		if err != nil {
+			return fmt.Errorf("错误: %w", err)
+		}
+	}
+
+	command, err := internal.CurlCommand(mirror, reveal)
+	if err != nil {
+		return fmt.Errorf("生成 curl 命令失败: %w", err)
+	}
+	fmt.Println(command)
+	return nil
+}
+
+func init() {
+	curlCmd.Flags().Bool("reveal", false, "输出明文密钥")
+	rootCmd.AddCommand(curlCmd)
+}
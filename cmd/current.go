@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// currentMirrorInfo current 命令 JSON 输出中的单个镜像源信息.
+type currentMirrorInfo struct {
+	Name    string `json:"name"`
+	BaseURL string `json:"base_url"`
+}
+
+// currentCmd 代表current命令.
+var currentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "显示当前激活的镜像源",
+	Long: `显示当前激活的 Codex 和 Claude 镜像源，适合在 shell 提示符和脚本中使用。
+
+默认只输出名称；使用 --url 输出 API 地址，使用 --json 输出包含两者的 JSON。
+指定类型没有激活的镜像源时以非零状态退出。
+
+示例：
+  codex-mirror current
+  codex-mirror current --type claude
+  codex-mirror current --type codex --url
+  codex-mirror current --json`,
+	Args: cobra.NoArgs,
+	RunE: runCurrentCommand,
+}
+
+// runCurrentCommand 执行current命令的实际逻辑.
+func runCurrentCommand(cmd *cobra.Command, args []string) error {
+	toolType, _ := cmd.Flags().GetString("type")
+	showURL, _ := cmd.Flags().GetBool("url")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	var types []internal.ToolType
+	switch toolType {
+	case "":
+		types = []internal.ToolType{internal.ToolTypeCodex, internal.ToolTypeClaude}
+	case string(internal.ToolTypeCodex), string(internal.ToolTypeClaude):
+		types = []internal.ToolType{internal.ToolType(toolType)}
+	default:
+		return fmt.Errorf("无效的工具类型 '%s'，支持: %s, %s", toolType, internal.ToolTypeCodex, internal.ToolTypeClaude)
+	}
+
+	// 以下错误均为运行时状态，不需要打印用法
+	cmd.SilenceUsage = true
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("初始化失败: %w", err)
+	}
+
+	current := make(map[internal.ToolType]*internal.MirrorConfig)
+	for _, t := range types {
+		var mirror *internal.MirrorConfig
+		if t == internal.ToolTypeCodex {
+			mirror, err = mm.GetCurrentCodexMirror()
+		} else {
+			mirror, err = mm.GetCurrentClaudeMirror()
+		}
+		if err == nil {
+			current[t] = mirror
+		}
+	}
+
+	if len(current) == 0 {
+		if toolType != "" {
+			return fmt.Errorf("没有激活的 %s 镜像源", toolType)
+		}
+		return fmt.Errorf("没有激活的镜像源")
+	}
+
+	if asJSON {
+		result := make(map[string]currentMirrorInfo, len(current))
+		for t, mirror := range current {
+			result[string(t)] = currentMirrorInfo{Name: mirror.Name, BaseURL: mirror.BaseURL}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	for _, t := range types {
+		mirror, ok := current[t]
+		if !ok {
+			continue
+		}
+		value := mirror.Name
+		if showURL {
+			value = mirror.BaseURL
+		}
+		// 指定类型时只输出值，便于脚本直接使用
+		if toolType != "" {
+			fmt.Println(value)
+		} else {
+			fmt.Printf("%s: %s\n", t, value)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	currentCmd.Flags().StringP("type", "t", "", "工具类型 (codex|claude)")
+	currentCmd.Flags().Bool("url", false, "输出 API 地址而不是名称")
+	currentCmd.Flags().Bool("name", false, "输出镜像源名称 (默认)")
+	currentCmd.Flags().Bool("json", false, "以 JSON 格式输出名称和 API 地址")
+	currentCmd.MarkFlagsMutuallyExclusive("url", "name", "json")
+	rootCmd.AddCommand(currentCmd)
+}
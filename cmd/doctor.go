@@ -1,9 +1,10 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 
 	"codex-mirror/internal"
@@ -22,81 +23,198 @@ var doctorCmd = &cobra.Command{
 - 环境变量一致性
 - 镜像源有效性
 - VS Code / Codex 配置状态
+- 同类型镜像源是否使用相同的 API 地址
+
+使用 --fix 时会自动执行可修复项的修复操作，并在修复后重新检查。
+使用 --only 按 ID 选择检查项: config, env, vscode, codex, duplicates, connectivity。
+使用 --json 时标准输出仅包含 JSON 结果，检查过程信息输出到标准错误。
+连通性检查并行测试所有镜像源，使用 --timeout 设置单个镜像源的超时时间（秒）；
+镜像源通过 add/update --timeout 设置了自己的超时时间时优先使用，显式指定的 --timeout 作为其上限。
 
 示例：
-  codex-mirror doctor           # 运行所有检查
-  codex-mirror doctor --verbose # 详细输出`,
+  codex-mirror doctor                   # 运行所有检查
+  codex-mirror doctor --verbose         # 详细输出
+  codex-mirror doctor --fix             # 自动修复可修复的问题
+  codex-mirror doctor --only config,env # 只运行指定检查
+  codex-mirror doctor --json            # 以 JSON 格式输出结果
+  codex-mirror doctor --timeout 5       # 连通性检查超时 5 秒`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		verbose, _ := cmd.Flags().GetBool("verbose")
-		skipTest, _ := cmd.Flags().GetBool("skip-test")
+		var opts doctorOptions
+		opts.verbose, _ = cmd.Flags().GetBool("verbose")
+		opts.skipTest, _ = cmd.Flags().GetBool("skip-test")
+		opts.fix, _ = cmd.Flags().GetBool("fix")
+		opts.asJSON, _ = cmd.Flags().GetBool("json")
+		opts.only, _ = cmd.Flags().GetStringSlice("only")
+		opts.timeout, _ = cmd.Flags().GetInt("timeout")
+		if opts.timeout < 1 {
+			return fmt.Errorf("--timeout 必须大于 0")
+		}
+		opts.maxTimeout = explicitTimeoutCap(cmd, opts.timeout)
 
-		return runDoctor(verbose, skipTest)
+		checks, err := selectDoctorChecks(opts.only, opts.skipTest)
+		if err != nil {
+			return err
+		}
+
+		// 检查未通过属于运行结果，不需要打印用法
+		cmd.SilenceUsage = true
+		return runDoctor(checks, opts)
 	},
 }
 
 // CheckResult 健康检查结果.
 type CheckResult struct {
-	Name        string
-	Description string
-	Status      string // "ok", "warning", "error", "skipped"
-	Message     string
-	Fix         string
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"` // "ok", "warning", "error", "skipped"
+	Message     string       `json:"message"`
+	Fix         string       `json:"fix,omitempty"`
+	FixFunc     func() error `json:"-"` // 可自动执行的修复操作，为 nil 表示无法自动修复
+}
+
+// HealthCheckFunc 健康检查函数类型，mm 为本次运行中各检查项共用的镜像源管理器.
+type HealthCheckFunc func(mm *internal.MirrorManager, verbose bool) CheckResult
+
+// doctorCheck 带有稳定 ID 的健康检查项.
+type doctorCheck struct {
+	ID  string
+	Run HealthCheckFunc
+}
+
+// doctorConnectivityCheckID 连通性检查的 ID（受 --skip-test 控制）.
+const doctorConnectivityCheckID = "connectivity"
+
+// defaultDoctorTimeout 连通性检查中单个镜像源的默认超时时间（秒）.
+const defaultDoctorTimeout = 10
+
+// doctorTimeout 本次运行连通性检查的超时时间（秒），由 --timeout 设置.
+var doctorTimeout = defaultDoctorTimeout
+
+// doctorChecks 所有健康检查项，按执行顺序排列.
+var doctorChecks = []doctorCheck{
+	{ID: "config", Run: checkConfigFile},
+	{ID: "env", Run: checkEnvironmentVariables},
+	{ID: "vscode", Run: checkVSCodeConfig},
+	{ID: "codex", Run: checkCodexConfig},
+	{ID: "duplicates", Run: checkDuplicateBaseURLs},
+	{ID: doctorConnectivityCheckID, Run: checkMirrorConnectivity},
 }
 
-// HealthCheckFunc 健康检查函数类型.
-type HealthCheckFunc func(verbose bool) CheckResult
+// doctorOptions doctor 命令选项.
+type doctorOptions struct {
+	verbose  bool
+	skipTest bool
+	fix      bool
+	asJSON   bool
+	only     []string
+	timeout  int
+	// 显式指定 --timeout 时作为镜像源自身超时时间的上限
+	maxTimeout int
+}
 
 func init() {
 	doctorCmd.Flags().Bool("verbose", false, "显示详细输出")
 	doctorCmd.Flags().Bool("skip-test", false, "跳过镜像源连通性测试")
+	doctorCmd.Flags().Bool("fix", false, "自动执行可修复项的修复操作")
+	doctorCmd.Flags().Bool("json", false, "以 JSON 格式输出检查结果")
+	doctorCmd.Flags().StringSlice("only", nil, "只运行指定 ID 的检查 (config,env,vscode,codex,duplicates,connectivity)")
+	doctorCmd.Flags().Int("timeout", defaultDoctorTimeout, "连通性检查中单个镜像源的超时时间（秒）")
 	rootCmd.AddCommand(doctorCmd)
 }
 
-// runDoctor 运行健康检查.
-func runDoctor(verbose, skipTest bool) error {
-	fmt.Println("🔍 正在运行健康检查...")
-	fmt.Println()
+// selectDoctorChecks 根据 --only 和 --skip-test 选择要运行的检查项.
+func selectDoctorChecks(only []string, skipTest bool) ([]doctorCheck, error) {
+	selected := make(map[string]bool, len(only))
+	for _, id := range only {
+		id = strings.TrimSpace(id)
+		if !slices.ContainsFunc(doctorChecks, func(c doctorCheck) bool { return c.ID == id }) {
+			ids := make([]string, 0, len(doctorChecks))
+			for _, c := range doctorChecks {
+				ids = append(ids, c.ID)
+			}
+			return nil, fmt.Errorf("未知的检查项 '%s'，支持: %s", id, strings.Join(ids, ", "))
+		}
+		selected[id] = true
+	}
 
-	checks := []HealthCheckFunc{
-		checkConfigFile,
-		checkEnvironmentVariables,
-		checkVSCodeConfig,
-		checkCodexConfig,
+	var checks []doctorCheck
+	for _, c := range doctorChecks {
+		if len(selected) > 0 && !selected[c.ID] {
+			continue
+		}
+		if skipTest && c.ID == doctorConnectivityCheckID {
+			continue
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+// runDoctor 运行健康检查.
+func runDoctor(checks []doctorCheck, opts doctorOptions) error {
+	// JSON 模式下检查过程的提示信息输出到标准错误，保证标准输出为纯 JSON
+	stdout := os.Stdout
+	if opts.asJSON {
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
 	}
 
-	if !skipTest {
-		checks = append(checks, checkMirrorConnectivity)
+	doctorTimeout = opts.timeout
+	testTimeoutCap = opts.maxTimeout
+	defer func() { testTimeoutCap = 0 }()
+
+	// 只加载一次配置，避免各检查项重复读取配置和触发环境变量发现
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("创建镜像源管理器失败: %w", err)
 	}
 
-	var results []CheckResult
+	fmt.Println("🔍 正在运行健康检查...")
+	fmt.Println()
+
+	results := make([]CheckResult, 0, len(checks))
 	hasError := false
 	hasWarning := false
 
 	for i, check := range checks {
-		result := check(verbose)
-		results = append(results, result)
+		result := check.Run(mm, opts.verbose)
 
 		fmt.Printf("[%d/%d] %s\n", i+1, len(checks), result.Name)
+		printCheckResult(result)
+
+		// 自动修复并重新检查
+		if opts.fix && result.FixFunc != nil && (result.Status == "warning" || result.Status == "error") {
+			if err := result.FixFunc(); err != nil {
+				fmt.Printf("    ❌ 自动修复失败: %v\n", err)
+			} else {
+				fmt.Println("    🔧 已自动修复，重新检查:")
+				result = check.Run(mm, opts.verbose)
+				printCheckResult(result)
+			}
+		}
+		fmt.Println()
 
+		result.ID = check.ID
+		results = append(results, result)
 		switch result.Status {
-		case "ok":
-			fmt.Printf("    ✅ %s\n", result.Message)
 		case "warning":
-			fmt.Printf("    ⚠️  %s\n", result.Message)
-			if result.Fix != "" {
-				fmt.Printf("    💡 建议: %s\n", result.Fix)
-			}
 			hasWarning = true
 		case "error":
-			fmt.Printf("    ❌ %s\n", result.Message)
-			if result.Fix != "" {
-				fmt.Printf("    🔧 修复: %s\n", result.Fix)
-			}
 			hasError = true
-		case "skipped":
-			fmt.Printf("    ⏭️  %s\n", result.Message)
 		}
-		fmt.Println()
+	}
+
+	if opts.asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+		if hasError {
+			return fmt.Errorf("健康检查未通过")
+		}
+		return nil
 	}
 
 	// 汇总
@@ -135,19 +253,41 @@ func runDoctor(verbose, skipTest bool) error {
 	return nil
 }
 
-// checkConfigFile 检查配置文件完整性.
-func checkConfigFile(verbose bool) CheckResult {
-	mm, err := internal.NewMirrorManager()
-	if err != nil {
-		return CheckResult{
-			Name:        "配置文件检查",
-			Description: "检查 mirrors.toml 是否存在",
-			Status:      "error",
-			Message:     fmt.Sprintf("无法加载配置: %v", err),
-			Fix:         "运行 'codex-mirror add <name> <url> <api-key>' 添加镜像源",
+// printCheckResult 输出单个检查结果.
+func printCheckResult(result CheckResult) {
+	switch result.Status {
+	case "ok":
+		fmt.Printf("    ✅ %s\n", result.Message)
+	case "warning":
+		fmt.Printf("    ⚠️  %s\n", result.Message)
+		if result.Fix != "" {
+			fmt.Printf("    💡 建议: %s\n", result.Fix)
 		}
+	case "error":
+		fmt.Printf("    ❌ %s\n", result.Message)
+		if result.Fix != "" {
+			fmt.Printf("    🔧 修复: %s\n", result.Fix)
+		}
+	case "skipped":
+		fmt.Printf("    ⏭️  %s\n", result.Message)
+	}
+}
+
+// fixEnvKeyFormat 修复镜像源配置和 Codex 配置中的 env_key 格式.
+func fixEnvKeyFormat(mm *internal.MirrorManager) error {
+	if err := mm.FixEnvKeyFormat(); err != nil {
+		return err
 	}
 
+	ccm, err := internal.NewCodexConfigManager()
+	if err != nil {
+		return err
+	}
+	return ccm.FixEnvKeyFormat()
+}
+
+// checkConfigFile 检查配置文件完整性.
+func checkConfigFile(mm *internal.MirrorManager, verbose bool) CheckResult {
 	config := mm.GetConfig()
 	activeMirrors := mm.ListActiveMirrors()
 
@@ -174,6 +314,9 @@ func checkConfigFile(verbose bool) CheckResult {
 				Status:      "warning",
 				Message:     fmt.Sprintf("当前 Claude 镜像 '%s' 不存在", currentClaude),
 				Fix:         "运行 'codex-mirror switch <name>' 切换到其他镜像",
+				FixFunc: func() error {
+					return mm.ClearCurrentMirror(internal.ToolTypeClaude)
+				},
 			}
 		}
 	}
@@ -187,6 +330,9 @@ func checkConfigFile(verbose bool) CheckResult {
 				Status:      "warning",
 				Message:     fmt.Sprintf("当前 Codex 镜像 '%s' 不存在", currentCodex),
 				Fix:         "运行 'codex-mirror switch <name>' 切换到其他镜像",
+				FixFunc: func() error {
+					return mm.ClearCurrentMirror(internal.ToolTypeCodex)
+				},
 			}
 		}
 	}
@@ -200,17 +346,7 @@ func checkConfigFile(verbose bool) CheckResult {
 }
 
 // checkEnvironmentVariables 检查环境变量一致性.
-func checkEnvironmentVariables(verbose bool) CheckResult {
-	mm, err := internal.NewMirrorManager()
-	if err != nil {
-		return CheckResult{
-			Name:        "环境变量检查",
-			Description: "加载配置失败",
-			Status:      "error",
-			Message:     fmt.Sprintf("无法加载配置: %v", err),
-		}
-	}
-
+func checkEnvironmentVariables(mm *internal.MirrorManager, verbose bool) CheckResult {
 	config := mm.GetConfig()
 	var warnings []string
 
@@ -224,7 +360,8 @@ func checkEnvironmentVariables(verbose bool) CheckResult {
 			if envBaseURL != "" && envBaseURL != mirror.BaseURL {
 				warnings = append(warnings, fmt.Sprintf("ANTHROPIC_BASE_URL 与配置不一致 (环境: %s, 配置: %s)", envBaseURL, mirror.BaseURL))
 			}
-			if envToken != "" && envToken != mirror.APIKey {
+			// 密钥通过命令获取时不保存在配置中，无法比较
+			if envToken != "" && mirror.APIKeyCommand == "" && envToken != mirror.APIKey {
 				warnings = append(warnings, "ANTHROPIC_AUTH_TOKEN 与配置不一致")
 			}
 		}
@@ -235,7 +372,7 @@ func checkEnvironmentVariables(verbose bool) CheckResult {
 		mirror, err := mm.GetCurrentCodexMirror()
 		if err == nil {
 			envKey := os.Getenv(internal.CodexSwitchAPIKeyEnv)
-			if envKey != "" && envKey != mirror.APIKey {
+			if envKey != "" && mirror.APIKeyCommand == "" && envKey != mirror.APIKey {
 				warnings = append(warnings, fmt.Sprintf("%s 与配置不一致", internal.CodexSwitchAPIKeyEnv))
 			}
 		}
@@ -254,7 +391,8 @@ func checkEnvironmentVariables(verbose bool) CheckResult {
 			Description: "检查环境变量与配置一致性",
 			Status:      "warning",
 			Message:     msg,
-			Fix:         "运行 'codex-mirror switch <name>' 重新应用配置",
+			Fix:         "运行 'codex-mirror doctor --fix' 重新应用当前镜像源配置",
+			FixFunc:     mm.ReapplyCurrentMirrors,
 		}
 	}
 
@@ -266,18 +404,40 @@ func checkEnvironmentVariables(verbose bool) CheckResult {
 	}
 }
 
+// checkDuplicateBaseURLs 检查是否有多个同类型镜像源使用相同的 API 地址.
+func checkDuplicateBaseURLs(mm *internal.MirrorManager, verbose bool) CheckResult {
+	result := CheckResult{
+		Name:        "重复地址检查",
+		Description: "检查同类型镜像源是否使用相同的 API 地址",
+	}
+
+	groups := mm.FindDuplicateBaseURLs()
+	if len(groups) == 0 {
+		result.Status = "ok"
+		result.Message = "没有使用相同 API 地址的镜像源"
+		return result
+	}
+
+	descriptions := make([]string, 0, len(groups))
+	for _, group := range groups {
+		descriptions = append(descriptions, fmt.Sprintf("%s [%s]: %s", group.BaseURL, group.ToolType, strings.Join(group.Names, ", ")))
+	}
+	result.Status = "warning"
+	result.Message = fmt.Sprintf("%d 组镜像源使用相同的 API 地址: %s", len(groups), strings.Join(descriptions, "; "))
+	result.Fix = "如非有意复用，请运行 'codex-mirror remove <name>' 删除多余的镜像源"
+	return result
+}
+
 // checkVSCodeConfig 检查 VS Code 配置.
-func checkVSCodeConfig(verbose bool) CheckResult {
-	platform := internal.GetCurrentPlatform()
-
-	var settingsPath string
-	switch platform {
-	case internal.PlatformWindows:
-		settingsPath = filepath.Join(os.Getenv("APPDATA"), "Code", "User", "settings.json")
-	case internal.PlatformMac:
-		settingsPath = filepath.Join(os.Getenv("HOME"), "Library", "Application Support", "Code", "User", "settings.json")
-	default:
-		settingsPath = filepath.Join(os.Getenv("HOME"), ".config", "Code", "User", "settings.json")
+func checkVSCodeConfig(mm *internal.MirrorManager, verbose bool) CheckResult {
+	settingsPath, err := internal.GetVSCodeSettingsPath()
+	if err != nil {
+		return CheckResult{
+			Name:        "VS Code 配置检查",
+			Description: "获取 settings.json 路径",
+			Status:      "warning",
+			Message:     fmt.Sprintf("无法获取 VS Code 配置路径: %v", err),
+		}
 	}
 
 	// 检查文件是否存在
@@ -321,6 +481,7 @@ func checkVSCodeConfig(verbose bool) CheckResult {
 			Status:      "warning",
 			Message:     "未配置 chatgpt.apiBase 或类型错误",
 			Fix:         "运行 'codex-mirror switch <codex-mirror>' 应用 VS Code 配置",
+			FixFunc:     mm.ReapplyCurrentMirrors,
 		}
 	}
 
@@ -333,17 +494,7 @@ func checkVSCodeConfig(verbose bool) CheckResult {
 }
 
 // checkCodexConfig 检查 Codex CLI 配置.
-func checkCodexConfig(verbose bool) CheckResult {
-	mm, err := internal.NewMirrorManager()
-	if err != nil {
-		return CheckResult{
-			Name:        "Codex CLI 配置检查",
-			Description: "加载配置失败",
-			Status:      "error",
-			Message:     fmt.Sprintf("无法加载配置: %v", err),
-		}
-	}
-
+func checkCodexConfig(mm *internal.MirrorManager, verbose bool) CheckResult {
 	config := mm.GetConfig()
 	if config.CurrentCodex == "" {
 		return CheckResult{
@@ -394,6 +545,23 @@ func checkCodexConfig(verbose bool) CheckResult {
 			Status:      "warning",
 			Message:     "Codex 配置中未找到模型提供商",
 			Fix:         "运行 'codex-mirror switch <codex-mirror>' 重新应用配置",
+			FixFunc:     mm.ReapplyCurrentMirrors,
+		}
+	}
+
+	// 检查 env_key 格式
+	for name, provider := range codexConfig.ModelProviders {
+		if provider.EnvKey != internal.CodexSwitchAPIKeyEnv {
+			return CheckResult{
+				Name:        "Codex CLI 配置检查",
+				Description: "检查 Codex env_key 格式",
+				Status:      "warning",
+				Message:     fmt.Sprintf("模型提供商 '%s' 的 env_key 为 '%s'，期望 '%s'", name, provider.EnvKey, internal.CodexSwitchAPIKeyEnv),
+				Fix:         "运行 'codex-mirror doctor --fix' 修复 env_key 格式",
+				FixFunc: func() error {
+					return fixEnvKeyFormat(mm)
+				},
+			}
 		}
 	}
 
@@ -406,17 +574,7 @@ func checkCodexConfig(verbose bool) CheckResult {
 }
 
 // checkMirrorConnectivity 检查镜像源连通性.
-func checkMirrorConnectivity(verbose bool) CheckResult {
-	mm, err := internal.NewMirrorManager()
-	if err != nil {
-		return CheckResult{
-			Name:        "镜像源连通性检查",
-			Description: "加载配置失败",
-			Status:      "error",
-			Message:     fmt.Sprintf("无法加载配置: %v", err),
-		}
-	}
-
+func checkMirrorConnectivity(mm *internal.MirrorManager, verbose bool) CheckResult {
 	mirrors := mm.ListActiveMirrors()
 	if len(mirrors) == 0 {
 		return CheckResult{
@@ -429,8 +587,8 @@ func checkMirrorConnectivity(verbose bool) CheckResult {
 
 	fmt.Println("    测试镜像源连通性...")
 
-	// 使用 test 命令的测试函数
-	results := GetTestResultsFromAll(mm, 10)
+	// 使用 test 命令的测试函数，并行测试所有镜像源
+	results := GetTestResultsFromAll(mm, doctorTimeout)
 
 	var okMirrors []string
 	var errorMirrors []string
@@ -440,7 +598,7 @@ func checkMirrorConnectivity(verbose bool) CheckResult {
 		switch {
 		case r.Success:
 			okMirrors = append(okMirrors, r.Name)
-		case r.Error == "需要 API Key (401)":
+		case r.Error == internal.NeedAPIKey401Msg:
 			skippedMirrors++
 		default:
 			errorMirrors = append(errorMirrors, r.Name)
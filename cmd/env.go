@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// envCmd 代表env命令.
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "输出当前镜像源的环境变量导出语句",
+	Long: `根据当前激活的 Codex 和 Claude 镜像源输出 shell 环境变量导出语句，
+包括 API 密钥、Claude 的 API 地址、模型名称以及额外环境变量。
+
+默认根据 SHELL 环境变量自动检测 shell 类型。
+
+示例：
+  eval "$(codex-mirror env)"
+  codex-mirror env --shell fish | source
+  codex-mirror env --shell powershell | iex
+  eval "$(codex-mirror env --unset)"     # 清除这些环境变量`,
+	Args: cobra.NoArgs,
+	RunE: runEnvCommand,
+}
+
+// runEnvCommand 执行env命令的实际逻辑.
+func runEnvCommand(cmd *cobra.Command, args []string) error {
+	shell, _ := cmd.Flags().GetString("shell")
+	unset, _ := cmd.Flags().GetBool("unset")
+
+	if shell == "" {
+		shell = detectShell(internal.GetCurrentPlatform())
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("初始化失败: %w", err)
+	}
+
+	vars := make(map[string]string)
+	if mirror, err := mm.GetCurrentCodexMirror(); err == nil {
+		codexVars, err := internal.MirrorEnvVars(mirror)
+		if err != nil {
+			return err
+		}
+		for k, v := range codexVars {
+			vars[k] = v
+		}
+	}
+	if mirror, err := mm.GetCurrentClaudeMirror(); err == nil {
+		claudeVars, err := internal.MirrorEnvVars(mirror)
+		if err != nil {
+			return err
+		}
+		for k, v := range claudeVars {
+			vars[k] = v
+		}
+	}
+
+	// 空值会被输出为清除语句
+	if unset {
+		for k := range vars {
+			vars[k] = ""
+		}
+	}
+
+	emitShellExports(vars, shell)
+	return nil
+}
+
+func init() {
+	envCmd.Flags().String("shell", "", "输出格式 (bash|zsh|fish|powershell|cmd)，默认自动检测")
+	envCmd.Flags().Bool("unset", false, "输出清除环境变量的语句")
+	rootCmd.AddCommand(envCmd)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// exportCmd 代表export命令.
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "导出镜像源为 JSON 或 CSV",
+	Long: `导出所有镜像源配置，用于备份或迁移到其他设备。
+
+默认掩码 API 密钥、敏感的额外环境变量和测试请求头，隐藏代理地址中的密码，
+不导出密钥命令（导入时沿用已有镜像源的原值），使用 --include-keys 原样导出。
+JSON 格式可直接用于 import 命令导入。
+
+示例：
+  codex-mirror export > mirrors.json
+  codex-mirror export --include-keys -o mirrors.json
+  codex-mirror export --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runExportCommand,
+}
+
+// runExportCommand 执行export命令的实际逻辑.
+func runExportCommand(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	includeKeys, _ := cmd.Flags().GetBool("include-keys")
+	output, _ := cmd.Flags().GetString("output")
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("创建镜像源管理器失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := mm.ExportMirrors(&buf, format, includeKeys); err != nil {
+		return fmt.Errorf("导出镜像源失败: %v", err)
+	}
+
+	if output == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	// 导出文件可能包含密钥，仅允许当前用户读写
+	if err := os.WriteFile(output, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("写入导出文件失败: %v", err)
+	}
+	fmt.Printf("✅ 已导出到 %s\n", output)
+
+	return nil
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", internal.ExportFormatJSON, "导出格式 (json|csv)")
+	exportCmd.Flags().Bool("include-keys", false, "原样导出 API 密钥等敏感值")
+	exportCmd.Flags().StringP("output", "o", "", "输出文件路径 (默认输出到标准输出)")
+	rootCmd.AddCommand(exportCmd)
+}
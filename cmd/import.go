@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// importCmd 代表import命令.
+var importCmd = &cobra.Command{
+	Use:   "import <file.json>",
+	Short: "从 JSON 文件批量导入镜像源",
+	Long: `从 JSON 文件批量导入镜像源配置。
+
+文件内容为镜像源对象数组，支持字段：
+  name, base_url, api_key, tool_type, model_name, extra_env
+
+已存在的同名镜像源默认跳过，使用 --overwrite 覆盖。
+文件名为 - 时从标准输入读取。
+
+示例：
+  codex-mirror import mirrors.json
+  codex-mirror import mirrors.json --overwrite
+  cat mirrors.json | codex-mirror import -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportCommand,
+}
+
+// runImportCommand 执行import命令的实际逻辑.
+func runImportCommand(cmd *cobra.Command, args []string) error {
+	overwrite, _ := cmd.Flags().GetBool("overwrite")
+
+	var reader io.Reader
+	if args[0] == "-" {
+		reader = os.Stdin
+	} else {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("打开导入文件失败: %v", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("创建镜像源管理器失败: %v", err)
+	}
+
+	added, skipped, err := mm.ImportMirrors(reader, overwrite)
+	if err != nil {
+		return fmt.Errorf("导入镜像源失败: %v", err)
+	}
+
+	fmt.Printf("✅ 导入完成: 导入 %d 个，跳过 %d 个\n", added, skipped)
+	if skipped > 0 && !overwrite {
+		fmt.Println("💡 使用 --overwrite 覆盖已存在的同名镜像源")
+	}
+
+	return nil
+}
+
+func init() {
+	importCmd.Flags().Bool("overwrite", false, "覆盖已存在的同名镜像源")
+	rootCmd.AddCommand(importCmd)
+}
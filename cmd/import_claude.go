@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// importClaudeCmd 代表 import-claude 命令.
+var importClaudeCmd = &cobra.Command{
+	Use:   "import-claude",
+	Short: "从 ~/.claude/settings.json 导入 Claude 镜像源",
+	Long: `读取现有的 Claude Code 配置文件 (~/.claude/settings.json)，将 env 字段中的
+` + internal.AnthropicBaseURLEnv + `、` + internal.AnthropicAuthTokenEnv + `（或 ANTHROPIC_API_KEY）和模型设置导入为 Claude 镜像源。
+镜像源名称根据 API 地址自动生成。
+
+已有相同 API 地址的 Claude 镜像源时跳过，不会覆盖已有配置。
+
+示例：
+  codex-mirror import-claude`,
+	Args: cobra.NoArgs,
+	RunE: runImportClaudeCommand,
+}
+
+// runImportClaudeCommand 执行 import-claude 命令.
+func runImportClaudeCommand(cmd *cobra.Command, args []string) error {
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("创建镜像源管理器失败: %w", err)
+	}
+
+	report, err := mm.ImportClaudeSettings()
+	if err != nil {
+		return fmt.Errorf("导入 Claude 配置失败: %w", err)
+	}
+	printDiscoveryImportReport(report)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(importClaudeCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// importCodexMerge 为同名镜像源补全缺失字段.
+var importCodexMerge bool
+
+// importCodexCmd 代表 import-codex 命令.
+var importCodexCmd = &cobra.Command{
+	Use:   "import-codex",
+	Short: "从 ~/.codex/config.toml 导入模型提供商",
+	Long: `读取现有的 Codex 配置文件 (~/.codex/config.toml)，将其中尚未管理的模型提供商导入为 Codex 镜像源。
+API 密钥取自 ` + internal.CodexSwitchAPIKeyEnv + ` 环境变量或 ~/.codex/auth.json。
+
+同名或 API 地址相同的镜像源视为重复而跳过，不会覆盖已有配置；
+使用 --merge 时为同名镜像源补全为空的 API 密钥和模型名称。
+
+示例：
+  codex-mirror import-codex
+  codex-mirror import-codex --merge`,
+	Args: cobra.NoArgs,
+	RunE: runImportCodexCommand,
+}
+
+// runImportCodexCommand 执行 import-codex 命令.
+func runImportCodexCommand(cmd *cobra.Command, args []string) error {
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("创建镜像源管理器失败: %w", err)
+	}
+
+	report, err := mm.ImportCodexConfig(importCodexMerge)
+	if err != nil {
+		return fmt.Errorf("导入 Codex 配置失败: %w", err)
+	}
+	printDiscoveryImportReport(report)
+	return nil
+}
+
+// printDiscoveryImportReport 输出从已有工具配置导入镜像源的结果.
+func printDiscoveryImportReport(report *internal.DiscoveryImportReport) {
+	fmt.Printf("✅ 导入完成: 导入 %d 个，补全 %d 个，跳过 %d 个\n",
+		len(report.Imported), len(report.Merged), len(report.Skipped))
+	if len(report.Imported) > 0 {
+		fmt.Printf("  已导入: %s\n", strings.Join(report.Imported, ", "))
+	}
+	if len(report.Merged) > 0 {
+		fmt.Printf("  已补全: %s\n", strings.Join(report.Merged, ", "))
+	}
+	if len(report.Skipped) > 0 {
+		fmt.Printf("  重复跳过: %s\n", strings.Join(report.Skipped, ", "))
+	}
+}
+
+func init() {
+	importCodexCmd.Flags().BoolVar(&importCodexMerge, "merge", false, "为同名镜像源补全为空的 API 密钥和模型名称")
+	rootCmd.AddCommand(importCodexCmd)
+}
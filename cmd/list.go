@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"codex-mirror/internal"
@@ -16,7 +18,10 @@ var listCmd = &cobra.Command{
 	Long: `列出所有已配置的镜像源，并显示当前激活的配置。
 
 示例：
-  codex-mirror list`,
+  codex-mirror list
+  codex-mirror list --type claude
+  codex-mirror list --tag work
+  codex-mirror list --type claude --json | jq '.[].name'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 创建镜像源管理器
 		mm, err := internal.NewMirrorManager()
@@ -24,21 +29,22 @@ var listCmd = &cobra.Command{
 			return fmt.Errorf("初始化失败: %w", err)
 		}
 
-		// 获取过滤器类型
+		// 获取过滤器类型和标签
 		filterType, _ := cmd.Flags().GetString("type")
+		filterTag, _ := cmd.Flags().GetString("tag")
 
-		// 获取所有镜像源
-		mirrors := mm.ListMirrors()
+		// 获取所有镜像源（按标签过滤）
+		mirrors := mm.ListByTag(filterTag)
 
 		// 根据类型过滤
-		if filterType != "" {
-			var filtered []internal.MirrorConfig
-			for _, mirror := range mirrors {
-				if string(mirror.ToolType) == filterType {
-					filtered = append(filtered, mirror)
-				}
-			}
-			mirrors = filtered
+		mirrors = filterMirrorsByType(mirrors, filterType)
+
+		// 获取当前激活的配置
+		currentCodex, _ := mm.GetCurrentCodexMirror()
+		currentClaude, _ := mm.GetCurrentClaudeMirror()
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return printMirrorsAsJSON(mirrors, currentCodex, currentClaude)
 		}
 
 		if len(mirrors) == 0 {
@@ -46,38 +52,8 @@ var listCmd = &cobra.Command{
 			return nil
 		}
 
-		// 获取当前激活的配置
-		currentCodex, _ := mm.GetCurrentCodexMirror()
-		currentClaude, _ := mm.GetCurrentClaudeMirror()
-
 		fmt.Println("可用的镜像源:")
-		fmt.Println(strings.Repeat("-", 70))
-		fmt.Printf("%-20s %-10s %-40s %s\n", "名称", "类型", "URL", "状态")
-		fmt.Println(strings.Repeat("-", 70))
-
-		for _, mirror := range mirrors {
-			// 确定状态
-			status := ""
-			if mirror.ToolType == internal.ToolTypeCodex && currentCodex != nil && mirror.Name == currentCodex.Name {
-				status = "*"
-			} else if mirror.ToolType == internal.ToolTypeClaude && currentClaude != nil && mirror.Name == currentClaude.Name {
-				status = "*"
-			}
-
-			// 截断过长的URL
-			url := mirror.BaseURL
-			if len(url) > 38 {
-				url = url[:35] + "..."
-			}
-
-			fmt.Printf("%-20s %-10s %-40s %s\n",
-				mirror.Name,
-				mirror.ToolType,
-				url,
-				status)
-		}
-
-		fmt.Println(strings.Repeat("-", 70))
+		printMirrorTable(mirrors, currentCodex, currentClaude)
 
 		// 显示当前激活的配置
 		fmt.Println("\n当前激活的配置:")
@@ -97,7 +73,93 @@ var listCmd = &cobra.Command{
 	},
 }
 
+// filterMirrorsByType 按工具类型过滤镜像源，类型为空时不过滤.
+func filterMirrorsByType(mirrors []internal.MirrorConfig, toolType string) []internal.MirrorConfig {
+	if toolType == "" {
+		return mirrors
+	}
+
+	var filtered []internal.MirrorConfig
+	for _, mirror := range mirrors {
+		if string(mirror.ToolType) == toolType {
+			filtered = append(filtered, mirror)
+		}
+	}
+	return filtered
+}
+
+// printMirrorTable 以表格形式输出镜像源，当前激活的镜像源以 * 标记.
+func printMirrorTable(mirrors []internal.MirrorConfig, currentCodex, currentClaude *internal.MirrorConfig) {
+	fmt.Println(strings.Repeat("-", 70))
+	fmt.Printf("%-20s %-10s %-40s %-4s %s\n", "名称", "类型", "URL", "状态", "标签")
+	fmt.Println(strings.Repeat("-", 70))
+
+	for _, mirror := range mirrors {
+		// 确定状态
+		status := ""
+		if mirror.ToolType == internal.ToolTypeCodex && currentCodex != nil && mirror.Name == currentCodex.Name {
+			status = "*"
+		} else if mirror.ToolType == internal.ToolTypeClaude && currentClaude != nil && mirror.Name == currentClaude.Name {
+			status = "*"
+		}
+
+		// 截断过长的URL
+		url := mirror.BaseURL
+		if len(url) > 38 {
+			url = url[:35] + "..."
+		}
+
+		fmt.Printf("%-20s %-10s %-40s %-4s %s\n",
+			mirror.Name,
+			mirror.ToolType,
+			url,
+			status,
+			strings.Join(mirror.Tags, ","))
+	}
+
+	fmt.Println(strings.Repeat("-", 70))
+}
+
+// listMirrorJSON list 命令 JSON 输出中的单个镜像源.
+type listMirrorJSON struct {
+	Name      string   `json:"name"`
+	BaseURL   string   `json:"base_url"`
+	ToolType  string   `json:"tool_type"`
+	ModelName string   `json:"model_name,omitempty"`
+	APIKey    string   `json:"api_key,omitempty"` // 已掩码
+	Tags      []string `json:"tags,omitempty"`
+	IsCurrent bool     `json:"is_current"`
+	HasAPIKey bool     `json:"has_api_key"`
+}
+
+// printMirrorsAsJSON 以 JSON 数组输出镜像源列表，API 密钥已掩码.
+func printMirrorsAsJSON(mirrors []internal.MirrorConfig, currentCodex, currentClaude *internal.MirrorConfig) error {
+	result := make([]listMirrorJSON, 0, len(mirrors))
+	for i := range mirrors {
+		mirror := &mirrors[i]
+		isCurrent := (mirror.ToolType == internal.ToolTypeCodex && currentCodex != nil && mirror.Name == currentCodex.Name) ||
+			(mirror.ToolType == internal.ToolTypeClaude && currentClaude != nil && mirror.Name == currentClaude.Name)
+		result = append(result, listMirrorJSON{
+			Name:      mirror.Name,
+			BaseURL:   mirror.BaseURL,
+			ToolType:  string(mirror.ToolType),
+			ModelName: mirror.ModelName,
+			APIKey:    internal.MaskAPIKey(mirror.APIKey),
+			Tags:      mirror.Tags,
+			IsCurrent: isCurrent,
+			HasAPIKey: mirror.HasAPIKey(),
+		})
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
 func init() {
 	listCmd.Flags().StringP("type", "t", "", "过滤工具类型 (codex|claude)")
+	listCmd.Flags().String("tag", "", "只列出带有指定标签的镜像源")
+	_ = listCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	listCmd.Flags().Bool("json", false, "以 JSON 格式输出 (API 密钥已掩码)")
 	rootCmd.AddCommand(listCmd)
 }
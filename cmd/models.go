@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// modelsTimeout 获取模型列表的超时时间（秒）.
+var modelsTimeout int
+
+// modelsCmd 代表 models 命令.
+var modelsCmd = &cobra.Command{
+	Use:   "models [name]",
+	Short: "获取并缓存镜像源的可用模型列表",
+	Long: `请求镜像源的 /v1/models 接口，列出可用模型并缓存到配置中。
+
+缓存的模型列表用于 switch 时校验镜像源配置的模型名称，模型不在列表中时给出警告。
+test 命令在接口返回模型列表时也会自动更新缓存。
+
+示例：
+  codex-mirror models myapi
+  codex-mirror models myapi --timeout 5`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return getMirrorNamesForCompletion(toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: runModelsCommand,
+}
+
+// runModelsCommand 执行 models 命令.
+func runModelsCommand(cmd *cobra.Command, args []string) error {
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	mirror, err := mm.GetMirrorByName(args[0])
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	models, err := newConnectivityTester().ListModels(mirror, modelsTimeout)
+	if err != nil {
+		return fmt.Errorf("获取镜像源 '%s' 的模型列表失败: %w", mirror.Name, err)
+	}
+	if err := mm.SetMirrorModels(mirror.Name, models); err != nil {
+		return fmt.Errorf("缓存模型列表失败: %w", err)
+	}
+
+	fmt.Printf("镜像源 '%s' 可用模型 (%d 个):\n", mirror.Name, len(models))
+	for _, model := range models {
+		marker := "  "
+		if model == mirror.ModelName {
+			marker = "* "
+		}
+		fmt.Printf("  %s%s\n", marker, model)
+	}
+
+	mirror.Models = models
+	warnUnsupportedModel(mirror)
+	return nil
+}
+
+// warnUnsupportedModel 镜像源配置的模型不在已缓存的模型列表中时给出警告.
+func warnUnsupportedModel(mirror *internal.MirrorConfig) {
+	if mirror.SupportsModel(mirror.ModelName) {
+		return
+	}
+	internal.LogWarnf("⚠️  模型 '%s' 不在镜像源 '%s' 的可用模型列表中，请求可能失败\n", mirror.ModelName, mirror.Name)
+	internal.LogWarnf("   运行 'codex-mirror models %s' 刷新模型列表，或使用 'codex-mirror switch %s --model <模型>' 更换模型\n", mirror.Name, mirror.Name)
+}
+
+func init() {
+	modelsCmd.Flags().IntVarP(&modelsTimeout, "timeout", "t", internal.DefaultTestTimeout, "超时时间（秒）")
+	rootCmd.AddCommand(modelsCmd)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"codex-mirror/internal"
@@ -17,6 +18,7 @@ var removeCmd = &cobra.Command{
 注意：
 - 不能删除官方镜像源
 - 如果删除的是当前使用的镜像源，会自动切换到官方镜像源
+- 删除后会清除该镜像源残留的持久化环境变量（启用 no_env 时跳过）
 
 参数：
   name  要删除的镜像源名称
@@ -34,20 +36,6 @@ var removeCmd = &cobra.Command{
 			return fmt.Errorf("错误: %w", err)
 		}
 
-		// 检查镜像源是否存在（只检查未删除的）
-		mirrors := mm.ListActiveMirrors()
-		found := false
-		for _, mirror := range mirrors {
-			if mirror.Name == mirrorName {
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			return fmt.Errorf("错误: 镜像源 '%s' 不存在", mirrorName)
-		}
-
 		// 检查是否为当前使用的镜像源
 		currentMirror, err := mm.GetCurrentMirror()
 		if err != nil {
@@ -58,6 +46,9 @@ var removeCmd = &cobra.Command{
 
 		// 删除镜像源
 		if err := mm.RemoveMirror(mirrorName); err != nil {
+			if errors.Is(err, internal.ErrMirrorNotFound) || errors.Is(err, internal.ErrCannotRemoveOfficial) {
+				return fmt.Errorf("错误: %w", err)
+			}
 			return fmt.Errorf("删除镜像源失败: %w", err)
 		}
 
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// reorderCmd 代表reorder命令.
+var reorderCmd = &cobra.Command{
+	Use:   "reorder [name]",
+	Short: "调整镜像源的显示顺序",
+	Long: `调整镜像源在 list 命令和 TUI 中的显示顺序。
+
+位置从 1 开始计数，只在未删除的镜像源之间排序。
+
+示例：
+  codex-mirror reorder myapi --to 2
+  codex-mirror reorder myapi --top
+  codex-mirror reorder myapi --bottom`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return getMirrorNamesForCompletion(toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: runReorderCommand,
+}
+
+// runReorderCommand 执行reorder命令的实际逻辑.
+func runReorderCommand(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	to, _ := cmd.Flags().GetInt("to")
+	top, _ := cmd.Flags().GetBool("top")
+	bottom, _ := cmd.Flags().GetBool("bottom")
+
+	if !cmd.Flags().Changed("to") && !top && !bottom {
+		return fmt.Errorf("请指定目标位置 (--to, --top, --bottom)")
+	}
+
+	// 以下错误均为运行时状态，不需要打印用法
+	cmd.SilenceUsage = true
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("初始化失败: %w", err)
+	}
+
+	index := to - 1
+	switch {
+	case top:
+		index = 0
+	case bottom:
+		index = len(mm.ListActiveMirrors()) - 1
+	}
+
+	if err := mm.MoveMirror(name, index); err != nil {
+		return fmt.Errorf("调整顺序失败: %w", err)
+	}
+
+	fmt.Printf("✅ 已将镜像源 '%s' 移动到第 %d 位\n", name, index+1)
+	return nil
+}
+
+func init() {
+	reorderCmd.Flags().Int("to", 0, "目标位置 (从 1 开始)")
+	reorderCmd.Flags().Bool("top", false, "移动到最前面")
+	reorderCmd.Flags().Bool("bottom", false, "移动到最后面")
+	reorderCmd.MarkFlagsMutuallyExclusive("to", "top", "bottom")
+	rootCmd.AddCommand(reorderCmd)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// restoreCmd 代表restore命令.
+var restoreCmd = &cobra.Command{
+	Use:   "restore [backup-file]",
+	Short: "从备份恢复镜像源配置",
+	Long: `从 ~/.codex-mirror/backup 中的备份恢复 mirrors.toml。
+
+恢复前会自动备份当前配置（前缀 pre-restore），无法解析的备份文件会被拒绝。
+备份文件可以是备份目录中的文件名，也可以是完整路径。
+不带参数或使用 --list 时列出可用的备份。
+
+每次 switch 前会将 mirrors.toml、Codex、Claude 和 VS Code 的配置文件
+一并备份到 ~/.codex-mirror/backup/switch-<时间戳>/ 快照目录。
+指定快照目录名时，会将其中所有文件恢复到原始位置。
+
+示例：
+  codex-mirror restore --list
+  codex-mirror restore pre-pull-20250101-120000.toml
+  codex-mirror restore switch-20250101-120000`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRestoreCommand,
+}
+
+// runRestoreCommand 执行restore命令的实际逻辑.
+func runRestoreCommand(cmd *cobra.Command, args []string) error {
+	listOnly, _ := cmd.Flags().GetBool("list")
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("初始化失败: %w", err)
+	}
+
+	if listOnly || len(args) == 0 {
+		return printBackupList(mm)
+	}
+
+	if isSnapshotDir(mm, args[0]) {
+		if err := mm.RestoreSwitchSnapshot(args[0]); err != nil {
+			return fmt.Errorf("恢复快照失败: %w", err)
+		}
+		fmt.Printf("✅ 已从快照恢复所有配置文件: %s\n", args[0])
+		return nil
+	}
+
+	if err := mm.RestoreFromBackup(args[0]); err != nil {
+		return fmt.Errorf("恢复配置失败: %w", err)
+	}
+
+	fmt.Printf("✅ 已从备份恢复配置: %s\n", args[0])
+	fmt.Println("💡 运行 codex-mirror switch <名称> 将恢复的配置应用到各工具")
+	return nil
+}
+
+// isSnapshotDir 判断参数是否指向切换快照目录（备份目录中的目录名或完整路径）.
+func isSnapshotDir(mm *internal.MirrorManager, name string) bool {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(mm.GetBackupDir(), name)
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// printBackupList 打印可用的备份列表.
+func printBackupList(mm *internal.MirrorManager) error {
+	backups, err := mm.ListBackups()
+	if err != nil {
+		return err
+	}
+	snapshots, err := mm.ListSwitchSnapshots()
+	if err != nil {
+		return err
+	}
+
+	if len(backups) == 0 && len(snapshots) == 0 {
+		fmt.Printf("没有可用的备份 (%s)\n", mm.GetBackupDir())
+		return nil
+	}
+
+	if len(snapshots) > 0 {
+		printSnapshotList(snapshots)
+		if len(backups) == 0 {
+			return nil
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("可用的备份 (%s):\n", mm.GetBackupDir())
+	fmt.Println(strings.Repeat("-", 70))
+	fmt.Printf("%-40s %-12s %s\n", "文件名", "类型", "时间")
+	fmt.Println(strings.Repeat("-", 70))
+	for _, backup := range backups {
+		fmt.Printf("%-40s %-12s %s\n", backup.Name, backup.Prefix, backup.Timestamp.Format("2006-01-02 15:04:05"))
+	}
+
+	return nil
+}
+
+// printSnapshotList 打印切换快照列表.
+func printSnapshotList(snapshots []internal.SnapshotInfo) {
+	fmt.Println("切换前快照:")
+	fmt.Println(strings.Repeat("-", 70))
+	fmt.Printf("%-40s %-6s %s\n", "目录名", "文件数", "时间")
+	fmt.Println(strings.Repeat("-", 70))
+	for _, snapshot := range snapshots {
+		fmt.Printf("%-40s %-6d %s\n", snapshot.Name, len(snapshot.Files), snapshot.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+}
+
+func init() {
+	restoreCmd.Flags().BoolP("list", "l", false, "列出可用的备份")
+	rootCmd.AddCommand(restoreCmd)
+}
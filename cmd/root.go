@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 
 	"codex-mirror/internal"
@@ -8,6 +9,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// exitCodeSyncAborted 同步因冲突按 abort 策略取消时的退出码，便于脚本与其他失败区分.
+const exitCodeSyncAborted = 3
+
 // rootCmd 代表基础命令，当不带任何子命令调用时执行.
 var rootCmd = &cobra.Command{
 	Use:   "codex-mirror",
@@ -24,28 +28,48 @@ var rootCmd = &cobra.Command{
   codex-mirror add myapi https://api.example.com sk-1234567890
   codex-mirror list
   codex-mirror switch myapi
-  codex-mirror status`,
+  codex-mirror status
+
+使用 --config-dir 或 ` + internal.MirrorHomeEnv + ` 环境变量指定镜像源配置目录（默认 ~/.codex-mirror），
+两者同时设置时 --config-dir 优先。
+使用 --quiet 只输出警告和错误；使用 --log-format json 将进度信息以 JSON 行输出到标准错误，便于脚本处理。`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		internal.SetConfigDir(configDir)
+		return internal.ConfigureLogger(logQuiet, logFormat)
+	},
 }
 
+// 全局参数.
+var (
+	configDir string
+	logQuiet  bool
+	logFormat string
+)
+
 // Execute 添加所有子命令到根命令并设置标志.
 // 这由main.main()调用。只需要对rootCmd执行一次.
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
-// maskAPIKey 遮蔽API密钥，只显示前4位和后4位.
-// 委托给 internal.MaskAPIKey 避免重复代码.
-func maskAPIKey(apiKey string) string {
-	return internal.MaskAPIKey(apiKey)
+// exitCode 返回命令失败时的退出码.
+func exitCode(err error) int {
+	if errors.Is(err, internal.ErrSyncAborted) {
+		return exitCodeSyncAborted
+	}
+	return 1
 }
 
 func init() {
 	// 在这里可以定义标志和配置设置.
 	// Cobra支持持久标志，如果在这里定义，将对所有子命令全局可用.
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.codex-mirror.yaml)")
+	rootCmd.PersistentFlags().StringVar(&configDir, "config-dir", "", "镜像源配置目录 (优先于 "+internal.MirrorHomeEnv+" 环境变量，默认 ~/.codex-mirror)")
+	rootCmd.PersistentFlags().BoolVarP(&logQuiet, "quiet", "q", false, "只输出警告和错误，不输出进度信息")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", internal.LogFormatText, "进度信息的输出格式 (text|json)")
 
 	// Cobra也支持本地标志，只对特定命令运行.
 	// rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
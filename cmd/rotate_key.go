@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// rotate-key 命令的标志.
+var (
+	rotateOldKey string
+	rotateNewKey string
+)
+
+// rotateKeyCmd 代表 rotate-key 命令.
+var rotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "批量替换多个镜像源共用的 API 密钥",
+	Long: `将所有 API 密钥与 --old 完全相同的镜像源替换为 --new，适用于多个镜像源共用的密钥泄露后统一轮换。
+
+只替换完全匹配的密钥；受影响的镜像源如果是当前激活的配置，会重新应用到 Codex/Claude 配置文件。
+
+示例：
+  codex-mirror rotate-key --old sk-leaked --new sk-fresh`,
+	Args: cobra.NoArgs,
+	RunE: runRotateKeyCommand,
+}
+
+// runRotateKeyCommand 执行 rotate-key 命令.
+func runRotateKeyCommand(cmd *cobra.Command, args []string) error {
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	// 替换前记录受影响的当前激活镜像源
+	config := mm.GetConfig()
+	var active []string
+	for _, name := range []string{config.CurrentCodex, config.CurrentClaude} {
+		if mirror, err := mm.GetMirrorByName(name); err == nil && mirror.APIKey == rotateOldKey {
+			active = append(active, name)
+		}
+	}
+
+	count, err := mm.RotateAPIKey(rotateOldKey, rotateNewKey)
+	if err != nil {
+		return fmt.Errorf("替换密钥失败: %w", err)
+	}
+	if count == 0 {
+		fmt.Println("没有镜像源使用该密钥")
+		return nil
+	}
+	fmt.Printf("✅ 已替换 %d 个镜像源的 API 密钥 (%s -> %s)\n",
+		count, internal.MaskAPIKey(rotateOldKey), internal.MaskAPIKey(rotateNewKey))
+
+	for _, name := range active {
+		mirror, err := mm.GetMirrorByName(name)
+		if err != nil {
+			return fmt.Errorf("获取镜像源配置失败: %w", err)
+		}
+		fmt.Printf("正在重新应用当前激活的镜像源 '%s' (%s)...\n", name, mirror.ToolType)
+		if err := applyMirrorAndSwitch(mm, mirror); err != nil {
+			return fmt.Errorf("重新应用镜像源 '%s' 失败: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rotateKeyCmd.Flags().StringVar(&rotateOldKey, "old", "", "要替换的旧密钥 (必需，精确匹配)")
+	rotateKeyCmd.Flags().StringVar(&rotateNewKey, "new", "", "新密钥 (必需)")
+	_ = rotateKeyCmd.MarkFlagRequired("old")
+	_ = rotateKeyCmd.MarkFlagRequired("new")
+	rootCmd.AddCommand(rotateKeyCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// searchCmd 代表search命令.
+var searchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "按名称、URL 或模型搜索镜像源",
+	Long: `按名称、API 地址或模型名称搜索镜像源（不区分大小写的子串匹配），
+输出格式与 list 命令相同。
+
+示例：
+  codex-mirror search openai
+  codex-mirror search api.example.com
+  codex-mirror search sonnet --type claude
+  codex-mirror search gpt --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSearchCommand,
+}
+
+// runSearchCommand 执行search命令的实际逻辑.
+func runSearchCommand(cmd *cobra.Command, args []string) error {
+	query := args[0]
+	filterType, _ := cmd.Flags().GetString("type")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("初始化失败: %w", err)
+	}
+
+	mirrors := filterMirrorsByType(mm.SearchMirrors(query), filterType)
+
+	currentCodex, _ := mm.GetCurrentCodexMirror()
+	currentClaude, _ := mm.GetCurrentClaudeMirror()
+
+	if asJSON {
+		return printMirrorsAsJSON(mirrors, currentCodex, currentClaude)
+	}
+
+	if len(mirrors) == 0 {
+		fmt.Printf("没有匹配 '%s' 的镜像源\n", query)
+		return nil
+	}
+
+	fmt.Printf("匹配 '%s' 的镜像源 (%d 个):\n", query, len(mirrors))
+	printMirrorTable(mirrors, currentCodex, currentClaude)
+	return nil
+}
+
+func init() {
+	searchCmd.Flags().StringP("type", "t", "", "过滤工具类型 (codex|claude)")
+	searchCmd.Flags().Bool("json", false, "以 JSON 格式输出 (API 密钥已掩码)")
+	rootCmd.AddCommand(searchCmd)
+}
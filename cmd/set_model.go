@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// setModelType set-model 修改的工具类型.
+var setModelType string
+
+// setModelCmd 代表 set-model 命令.
+var setModelCmd = &cobra.Command{
+	Use:   "set-model <model>",
+	Short: "修改当前镜像源的模型并重新应用配置",
+	Long: `修改当前激活镜像源的模型名称并保存，随后重新应用配置（Codex 同时更新 config.toml 和 VS Code 设置）。
+
+模型名称可以是模型别名（通过 'codex-mirror config model-alias' 设置），应用配置时替换为实际模型，
+修改别名后重新应用即可让使用该别名的镜像源统一切换模型。
+
+示例：
+  codex-mirror set-model gpt-5
+  codex-mirror set-model fast
+  codex-mirror set-model claude-sonnet-4-5 --type claude`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSetModelCommand,
+}
+
+// runSetModelCommand 执行 set-model 命令.
+func runSetModelCommand(cmd *cobra.Command, args []string) error {
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	mirror, err := mm.SetCurrentModel(internal.ToolType(setModelType), args[0])
+	if err != nil {
+		return fmt.Errorf("设置模型失败: %w", err)
+	}
+	resolved, err := mm.ResolveModelAlias(mirror)
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	backupBeforeSwitch(mm)
+	if err := mm.ReapplyCurrentMirror(mirror.ToolType); err != nil {
+		return fmt.Errorf("重新应用配置失败: %w", err)
+	}
+
+	if resolved.ModelName != mirror.ModelName {
+		fmt.Printf("✅ 镜像源 '%s' 的模型已设置为 %s (别名，实际模型: %s)\n", mirror.Name, mirror.ModelName, resolved.ModelName)
+	} else {
+		fmt.Printf("✅ 镜像源 '%s' 的模型已设置为 %s\n", mirror.Name, mirror.ModelName)
+	}
+	warnUnsupportedModel(resolved)
+	return nil
+}
+
+func init() {
+	setModelCmd.Flags().StringVarP(&setModelType, "type", "t", string(internal.ToolTypeCodex), "工具类型 (codex|claude)")
+	rootCmd.AddCommand(setModelCmd)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -9,6 +10,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// keyCommandStatus 密钥通过命令获取时的状态说明，status 不执行命令，因此不比较密钥.
+const keyCommandStatus = "[OK] 已设置 (密钥通过命令获取，未比较)"
+
 // statusCmd 代表status命令.
 var statusCmd = &cobra.Command{
 	Use:   "status",
@@ -18,8 +22,12 @@ var statusCmd = &cobra.Command{
 - Codex CLI配置状态
 - VS Code配置状态
 
+--json 输出结构化的状态快照：当前 Codex/Claude 镜像源、镜像源配置文件路径、
+各目标配置文件是否存在以及云同步状态，便于监控面板等工具读取。
+
 示例：
-  codex-mirror status`,
+  codex-mirror status
+  codex-mirror status --json | jq '.sync.last_sync'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 创建镜像源管理器
 		mm, err := internal.NewMirrorManager()
@@ -27,6 +35,12 @@ var statusCmd = &cobra.Command{
 			return fmt.Errorf("初始化失败: %w", err)
 		}
 
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(mm.Status())
+		}
+
 		fmt.Println("当前配置状态:")
 		fmt.Println("==================================================")
 
@@ -89,6 +103,8 @@ func checkClaudeStatus(mm *internal.MirrorManager) {
 	switch {
 	case authToken == "":
 		fmt.Printf("❌ 未设置\n")
+	case currentClaude.APIKeyCommand != "":
+		fmt.Printf("%s\n", keyCommandStatus)
 	case tokenMatch:
 		fmt.Printf("[OK] 正确\n")
 	default:
@@ -153,7 +169,9 @@ func checkCodexStatus(mm *internal.MirrorManager) {
 	}
 
 	fmt.Printf("  认证文件 (~/.codex/auth.json): ")
-	if authMatch {
+	if currentCodex.APIKeyCommand != "" {
+		fmt.Printf("%s\n", keyCommandStatus)
+	} else if authMatch {
 		fmt.Printf("[OK] 正确\n")
 	} else {
 		fmt.Printf("⚠️  不匹配\n")
@@ -163,6 +181,8 @@ func checkCodexStatus(mm *internal.MirrorManager) {
 	switch {
 	case envKey == "":
 		fmt.Printf("❌ 未设置\n")
+	case currentCodex.APIKeyCommand != "":
+		fmt.Printf("%s\n", keyCommandStatus)
 	case envMatch:
 		fmt.Printf("[OK] 正确\n")
 	default:
@@ -215,5 +235,6 @@ func checkVSCodeStatus(mm *internal.MirrorManager) {
 }
 
 func init() {
+	statusCmd.Flags().Bool("json", false, "以 JSON 格式输出完整状态")
 	rootCmd.AddCommand(statusCmd)
 }
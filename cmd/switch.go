@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -20,6 +21,15 @@ var (
 	shellFmt   string
 	useEnvVar  bool // 使用环境变量方式设置 Claude 配置（默认使用配置文件）
 	dryRun     bool // 预览模式，不实际修改配置
+
+	vscodeInsiders bool // 将配置应用到 VS Code Insiders
+
+	switchModel string // 切换时更换镜像源使用的模型
+
+	switchCodexName  string // --codex 指定的 Codex 镜像源
+	switchClaudeName string // --claude 指定的 Claude 镜像源
+
+	switchNoEnv bool // 只更新配置文件，不持久化环境变量
 )
 
 // switchCmd 代表switch命令.
@@ -32,6 +42,9 @@ Claude 配置：
   - 默认：修改 ~/.claude/settings.json 配置文件中的 env 字段
   - --env：使用系统环境变量方式 (ANTHROPIC_BASE_URL, ANTHROPIC_AUTH_TOKEN)
 Codex 配置：修改配置文件并设置环境变量
+  - --no-env：只修改 config.toml 和 auth.json，不写入 shell 配置文件（.zshrc/.bashrc）
+    或 Windows 用户环境变量，环境变量只在本次运行中生效，新打开的终端不会继承密钥；
+    可用 'codex-mirror config no-env true' 设为默认行为
 
 参数：
   name  要切换到的镜像源名称（省略时进入交互式选择）
@@ -41,7 +54,15 @@ Codex 配置：修改配置文件并设置环境变量
   codex-mirror switch myclaude --env        # 使用环境变量方式
   codex-mirror switch mycodex
   codex-mirror switch mycodex --no-backup
+  codex-mirror switch mycodex --no-env      # 不修改 shell 配置文件中的环境变量
   codex-mirror switch mycodex --dry-run     # 预览切换效果，不实际修改
+  codex-mirror switch mycodex --model gpt-5 # 切换并更换镜像源使用的模型
+  codex-mirror switch --codex mycodex --claude myclaude  # 一次同时切换 Codex 和 Claude
+
+使用 --codex/--claude 时分别指定 Codex 和 Claude 的镜像源，可只指定其中一个，
+镜像源的类型必须与所用的标志一致；此时不能再传入镜像源名称参数。
+
+镜像源缓存了可用模型列表（见 models 命令）时，会校验模型名称，不在列表中时给出警告。
 
 即时刷新当前终端环境变量：
   eval "$(codex-mirror switch myclaude --shell bash)"
@@ -52,6 +73,18 @@ Codex 配置：修改配置文件并设置环境变量
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var mirrorName string
 
+		if switchNoEnv && useEnvVar {
+			return fmt.Errorf("--no-env 和 --env 不能同时使用")
+		}
+
+		// --codex/--claude 在一次调用中分别切换 Codex 和 Claude 的镜像源
+		if switchCodexName != "" || switchClaudeName != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("--codex/--claude 不能与镜像源名称参数同时使用")
+			}
+			return runDualSwitch(switchCodexName, switchClaudeName)
+		}
+
 		// 如果没有提供参数，进入交互式选择
 		if len(args) == 0 {
 			if dryRun {
@@ -78,34 +111,26 @@ Codex 配置：修改配置文件并设置环境变量
 			return fmt.Errorf("获取镜像源配置失败: %w", err)
 		}
 
+		// --model 只修改本次使用的副本，实际切换时再保存到配置
+		if switchModel != "" {
+			mirror.ModelName = switchModel
+		}
+
 		// 预览模式
 		if dryRun {
+			warnUnsupportedModel(mirror)
 			return showDryRunPreview(mm, mirror)
 		}
 
 		// 如果是shell输出模式，只收集环境变量并输出shell导出语句
 		if shellFmt != "" {
-			envToEmit := map[string]string{}
-
-			switch mirror.ToolType {
-			case internal.ToolTypeClaude:
-				envToEmit[internal.AnthropicBaseURLEnv] = mirror.BaseURL
-				envToEmit[internal.AnthropicAuthTokenEnv] = mirror.APIKey
-				if strings.TrimSpace(mirror.ModelName) != "" {
-					envToEmit[internal.AnthropicModelEnv] = mirror.ModelName
-				} else {
-					// 如果目标镜像没有模型名称，明确清除 ANTHROPIC_MODEL
-					envToEmit[internal.AnthropicModelEnv] = ""
-				}
-			case internal.ToolTypeCodex:
-				// Codex 使用镜像EnvKey来读取API KEY
-				envKey := mirror.EnvKey
-				if strings.TrimSpace(envKey) == "" {
-					envKey = internal.CodexSwitchAPIKeyEnv
-				}
-				envToEmit[envKey] = mirror.APIKey
-			default:
-				return fmt.Errorf("错误: 不支持的配置类型 '%s'", mirror.ToolType)
+			resolved, err := mm.ResolveModelAlias(mirror)
+			if err != nil {
+				return fmt.Errorf("错误: %w", err)
+			}
+			envToEmit, err := internal.MirrorEnvVars(resolved)
+			if err != nil {
+				return fmt.Errorf("错误: %w", err)
 			}
 
 			// 输出shell导出语句并退出
@@ -114,42 +139,231 @@ Codex 配置：修改配置文件并设置环境变量
 		}
 
 		// 非shell模式：正常执行配置应用和状态切换
-		fmt.Printf("正在切换到镜像源 '%s' (%s)...\n", mirrorName, mirror.ToolType)
+		if err := prepareSwitch(mm, mirror); err != nil {
+			return err
+		}
+		if err := applyMirrorAndSwitch(mm, mirror); err != nil {
+			return err
+		}
+		printSwitchResult(mirror)
+		return nil
+	},
+}
 
-		// 根据工具类型应用配置
-		switch mirror.ToolType {
-		case internal.ToolTypeClaude:
-			// 获取当前 Claude 镜像的 ExtraEnv 用于清理
-			var oldExtraEnv map[string]string
-			if currentClaude := mm.GetConfig().CurrentClaude; currentClaude != "" {
-				if oldMirror, err := mm.GetMirrorByName(currentClaude); err == nil {
-					oldExtraEnv = oldMirror.ExtraEnv
-				}
+// prepareSwitch 输出切换提示和模型校验警告，并保存 --model 指定的模型.
+func prepareSwitch(mm *internal.MirrorManager, mirror *internal.MirrorConfig) error {
+	internal.LogInfof("正在切换到镜像源 '%s' (%s)...\n", mirror.Name, mirror.ToolType)
+	if resolved, err := mm.ResolveModelAlias(mirror); err == nil {
+		warnUnsupportedModel(resolved)
+	}
+	if switchModel != "" {
+		if err := mm.UpdateMirrorFull(mirror.Name, "", "", switchModel, ""); err != nil {
+			return fmt.Errorf("更新模型失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// printSwitchResult 输出切换成功后的镜像源信息.
+func printSwitchResult(mirror *internal.MirrorConfig) {
+	internal.LogInfof("\n成功切换到镜像源 '%s'\n", mirror.Name)
+	internal.LogInfof("  类型: %s\n", mirror.ToolType)
+	internal.LogInfof("  URL: %s\n", mirror.BaseURL)
+	if mirror.APIKey != "" {
+		internal.LogInfof("  API密钥: %s\n", internal.MaskAPIKey(mirror.APIKey))
+	} else if mirror.APIKeyCommand != "" {
+		internal.LogInfof("  API密钥: 通过命令获取\n")
+	}
+}
+
+// runDualSwitch 在一次调用中分别切换 Codex 和 Claude 的镜像源，名称为空的一方保持不变.
+// 先校验全部镜像源并获取密钥，任一镜像源有问题时不修改任何配置.
+func runDualSwitch(codexName, claudeName string) error {
+	if switchModel != "" && codexName != "" && claudeName != "" {
+		return fmt.Errorf("同时使用 --codex 和 --claude 时不能指定 --model")
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("错误: %w", err)
+	}
+
+	// Claude 先于 Codex 应用：应用 Claude 时会清理上一个 Codex 镜像源在 VS Code 中的配置，随后由 Codex 重新写入
+	targets := []struct {
+		flag     string
+		name     string
+		toolType internal.ToolType
+	}{
+		{flag: "--claude", name: claudeName, toolType: internal.ToolTypeClaude},
+		{flag: "--codex", name: codexName, toolType: internal.ToolTypeCodex},
+	}
+
+	var mirrors []*internal.MirrorConfig
+	for _, target := range targets {
+		if target.name == "" {
+			continue
+		}
+		mirror, err := mm.GetMirrorByName(target.name)
+		if err != nil {
+			return fmt.Errorf("获取镜像源配置失败: %w", err)
+		}
+		if mirror.ToolType != target.toolType {
+			return fmt.Errorf("镜像源 '%s' 的类型是 %s，不能用于 %s (需要 %s 类型的镜像源)", mirror.Name, mirror.ToolType, target.flag, target.toolType)
+		}
+		if switchModel != "" {
+			mirror.ModelName = switchModel
+		}
+		mirrors = append(mirrors, mirror)
+	}
+
+	if dryRun {
+		for i, mirror := range mirrors {
+			if i > 0 {
+				fmt.Println()
 			}
-			if err := applyClaudeConfig(mirror, oldExtraEnv); err != nil {
-				return fmt.Errorf("应用Claude配置失败: %w", err)
+			warnUnsupportedModel(mirror)
+			if err := showDryRunPreview(mm, mirror); err != nil {
+				return err
 			}
-		case internal.ToolTypeCodex:
-			if err := applyCodexConfig(mirror); err != nil {
-				return fmt.Errorf("应用Codex配置失败: %w", err)
+		}
+		return nil
+	}
+
+	if shellFmt != "" {
+		envToEmit := make(map[string]string)
+		for _, mirror := range mirrors {
+			resolved, err := mm.ResolveModelAlias(mirror)
+			if err != nil {
+				return fmt.Errorf("错误: %w", err)
+			}
+			vars, err := internal.MirrorEnvVars(resolved)
+			if err != nil {
+				return fmt.Errorf("错误: %w", err)
+			}
+			for key, value := range vars {
+				envToEmit[key] = value
 			}
-		default:
-			return fmt.Errorf("错误: 不支持的配置类型 '%s'", mirror.ToolType)
 		}
+		emitShellExports(envToEmit, shellFmt)
+		return nil
+	}
 
-		// 切换镜像源状态
-		if err := mm.SwitchMirror(mirrorName); err != nil {
-			return fmt.Errorf("切换镜像源状态失败: %w", err)
+	resolved := make([]*internal.MirrorConfig, 0, len(mirrors))
+	for _, mirror := range mirrors {
+		r, err := resolveMirrorForApply(mm, mirror)
+		if err != nil {
+			return err
 		}
+		resolved = append(resolved, r)
+	}
 
-		fmt.Printf("\n成功切换到镜像源 '%s'\n", mirrorName)
-		fmt.Printf("  类型: %s\n", mirror.ToolType)
-		fmt.Printf("  URL: %s\n", mirror.BaseURL)
-		if mirror.APIKey != "" {
-			fmt.Printf("  API密钥: %s\n", maskAPIKey(mirror.APIKey))
+	// 两个镜像源共用一个快照，restore 可以一次恢复
+	if !noBackup {
+		backupBeforeSwitch(mm)
+	}
+	for i, mirror := range mirrors {
+		if err := prepareSwitch(mm, mirror); err != nil {
+			return err
 		}
-		return nil
-	},
+		if err := applyResolvedMirror(mm, resolved[i]); err != nil {
+			return err
+		}
+		printSwitchResult(mirror)
+	}
+	return nil
+}
+
+// applyMirrorAndSwitch 根据工具类型应用镜像源配置，并将其设为当前镜像源.
+func applyMirrorAndSwitch(mm *internal.MirrorManager, mirror *internal.MirrorConfig) error {
+	// 配置了密钥命令时只执行一次，解析出的密钥和模型别名仅用于写入 Codex/Claude 配置，不会保存到 mirrors.toml
+	mirror, err := resolveMirrorForApply(mm, mirror)
+	if err != nil {
+		return err
+	}
+
+	// 修改任何文件前，将所有可能被修改的文件备份到同一个快照目录
+	if !noBackup {
+		backupBeforeSwitch(mm)
+	}
+	return applyResolvedMirror(mm, mirror)
+}
+
+// resolveMirrorForApply 获取镜像源的 API 密钥并解析模型别名，返回用于写入配置的副本.
+func resolveMirrorForApply(mm *internal.MirrorManager, mirror *internal.MirrorConfig) (*internal.MirrorConfig, error) {
+	mirror, err := internal.ResolveMirrorAPIKey(mirror)
+	if err != nil {
+		return nil, err
+	}
+	return mm.ResolveModelAlias(mirror)
+}
+
+// backupBeforeSwitch 备份切换可能修改的所有文件，失败时只给出警告.
+func backupBeforeSwitch(mm *internal.MirrorManager) {
+	snapshotDir, err := createSwitchSnapshot(mm)
+	if err != nil {
+		internal.LogWarnf("警告: 备份现有配置失败: %v\n", err)
+	} else {
+		internal.LogInfof("[OK] 已备份现有配置到 %s\n", snapshotDir)
+	}
+}
+
+// applyResolvedMirror 根据工具类型应用已获取密钥的镜像源配置，并将其设为当前镜像源.
+func applyResolvedMirror(mm *internal.MirrorManager, mirror *internal.MirrorConfig) error {
+	// 根据工具类型应用配置
+	switch mirror.ToolType {
+	case internal.ToolTypeClaude:
+		// 获取当前 Claude 镜像的 ExtraEnv 用于清理
+		var oldExtraEnv map[string]string
+		if currentClaude := mm.GetConfig().CurrentClaude; currentClaude != "" {
+			if oldMirror, err := mm.GetMirrorByName(currentClaude); err == nil {
+				oldExtraEnv = oldMirror.ExtraEnv
+			}
+		}
+		if err := applyClaudeConfig(mirror, oldExtraEnv); err != nil {
+			return fmt.Errorf("应用Claude配置失败: %w", err)
+		}
+		// 从 Codex 镜像源切换过来时，清理 VS Code 中指向旧 Codex 端点的配置
+		if previous, err := mm.GetCurrentMirror(); err == nil && previous.ToolType == internal.ToolTypeCodex {
+			if err := removeVSCodeChatGPTConfig(); err != nil {
+				internal.LogWarnf("警告: 清理VS Code配置失败: %v\n", err)
+			}
+		}
+	case internal.ToolTypeCodex:
+		if err := applyCodexConfig(mirror, skipEnvPersist(mm)); err != nil {
+			return fmt.Errorf("应用Codex配置失败: %w", err)
+		}
+	default:
+		return fmt.Errorf("错误: 不支持的配置类型 '%s'", mirror.ToolType)
+	}
+
+	// 切换镜像源状态
+	if err := mm.SwitchMirror(mirror.Name); err != nil {
+		return fmt.Errorf("切换镜像源状态失败: %w", err)
+	}
+	return nil
+}
+
+// createSwitchSnapshot 将镜像源配置、Codex、Claude 和 VS Code 的配置文件备份到同一个快照目录.
+func createSwitchSnapshot(mm *internal.MirrorManager) (string, error) {
+	targets, err := internal.GetManagedPaths()
+	if err != nil {
+		return "", err
+	}
+
+	for i := range targets {
+		switch targets[i].Name {
+		case "mirrors":
+			targets[i].Path = mm.GetConfigPath()
+		case "vscode_settings":
+			vcm, err := newVSCodeConfigManager()
+			if err != nil {
+				return "", err
+			}
+			targets[i].Path = vcm.GetSettingsPath()
+		}
+	}
+
+	return mm.CreateSwitchSnapshot(targets)
 }
 
 // applyClaudeConfig 应用Claude配置（默认使用配置文件，--env 时使用环境变量）.
@@ -164,9 +378,9 @@ func applyClaudeConfig(mirror *internal.MirrorConfig, oldExtraEnv map[string]str
 		}
 
 		// 显示设置的环境变量
-		fmt.Println("[OK] Claude Code环境变量已设置")
+		internal.LogInfof("[OK] Claude Code环境变量已设置\n")
 		if mirror.ModelName != "" {
-			fmt.Printf("  模型: %s\n", mirror.ModelName)
+			internal.LogInfof("  模型: %s\n", mirror.ModelName)
 		}
 		return nil
 	}
@@ -177,28 +391,26 @@ func applyClaudeConfig(mirror *internal.MirrorConfig, oldExtraEnv map[string]str
 		return err
 	}
 
-	// 备份现有配置
-	if !noBackup {
-		if err := ccm.BackupSettings(); err != nil {
-			fmt.Printf("警告: 备份Claude配置失败: %v\n", err)
-		}
-	}
-
 	// 应用新配置（同时清理旧镜像的额外环境变量）
 	if err := ccm.ApplyMirrorWithCleanup(mirror, oldExtraEnv); err != nil {
 		return err
 	}
 
-	fmt.Println("[OK] Claude Code配置文件已更新")
-	fmt.Printf("  配置文件: %s\n", ccm.GetSettingsPath())
+	internal.LogInfof("[OK] Claude Code配置文件已更新\n")
+	internal.LogInfof("  配置文件: %s\n", ccm.GetSettingsPath())
 	if mirror.ModelName != "" {
-		fmt.Printf("  模型: %s\n", mirror.ModelName)
+		internal.LogInfof("  模型: %s\n", mirror.ModelName)
 	}
 	return nil
 }
 
-// applyCodexConfig 应用Codex配置（修改配置文件并设置环境变量）.
-func applyCodexConfig(mirror *internal.MirrorConfig) error {
+// skipEnvPersist 返回本次切换是否跳过环境变量的持久化（--no-env 或配置中的 no_env）.
+func skipEnvPersist(mm *internal.MirrorManager) bool {
+	return switchNoEnv || mm.NoEnv()
+}
+
+// applyCodexConfig 应用Codex配置（修改配置文件并设置环境变量，noEnv 时不持久化环境变量）.
+func applyCodexConfig(mirror *internal.MirrorConfig, noEnv bool) error {
 	// 检查标志互斥
 	if codexOnly && vscodeOnly {
 		return fmt.Errorf("--codex-only 和 --vscode-only 不能同时使用")
@@ -209,9 +421,12 @@ func applyCodexConfig(mirror *internal.MirrorConfig) error {
 
 	if !vscodeOnly {
 		pt.Add(func() error {
-			err := updateCodexConfig(mirror)
+			err := updateCodexConfig(mirror, noEnv)
 			if err == nil {
-				fmt.Println("[OK] Codex CLI配置已更新")
+				internal.LogInfof("[OK] Codex CLI配置已更新\n")
+				if noEnv {
+					internal.LogInfof("  未持久化环境变量，新打开的终端不会继承密钥\n")
+				}
 			}
 			return err
 		})
@@ -221,7 +436,7 @@ func applyCodexConfig(mirror *internal.MirrorConfig) error {
 		pt.Add(func() error {
 			err := updateVSCodeConfig(mirror)
 			if err == nil {
-				fmt.Println("[OK] VS Code配置已更新")
+				internal.LogInfof("[OK] VS Code配置已更新\n")
 			}
 			return err
 		})
@@ -242,80 +457,183 @@ func applyCodexConfig(mirror *internal.MirrorConfig) error {
 }
 
 // updateCodexConfig 更新Codex配置.
-func updateCodexConfig(mirror *internal.MirrorConfig) error {
+func updateCodexConfig(mirror *internal.MirrorConfig, noEnv bool) error {
 	ccm, err := internal.NewCodexConfigManager()
 	if err != nil {
 		return err
 	}
-
-	// 备份现有配置
-	if !noBackup {
-		if err := ccm.BackupConfig(); err != nil {
-			fmt.Printf("警告: 备份Codex配置失败: %v\n", err)
-		}
-	}
+	ccm.SetPersistEnv(!noEnv)
 
 	// 应用新配置
 	return ccm.ApplyMirror(mirror)
 }
 
+// newVSCodeConfigManager 根据 --vscode-insiders 标志创建VS Code配置管理器.
+func newVSCodeConfigManager() (*internal.VSCodeConfigManager, error) {
+	if vscodeInsiders {
+		return internal.NewVSCodeConfigManagerForVariant(internal.VSCodeInsiders)
+	}
+	return internal.NewVSCodeConfigManager()
+}
+
 // updateVSCodeConfig 更新VS Code配置.
 func updateVSCodeConfig(mirror *internal.MirrorConfig) error {
-	vcm, err := internal.NewVSCodeConfigManager()
+	vcm, err := newVSCodeConfigManager()
 	if err != nil {
 		return err
 	}
 
-	// 备份现有配置
-	if !noBackup {
-		if err := vcm.BackupSettings(); err != nil {
-			fmt.Printf("警告: 备份VS Code配置失败: %v\n", err)
-		}
-	}
-
 	// 应用新配置
 	return vcm.ApplyMirror(mirror)
 }
 
+// removeVSCodeChatGPTConfig 移除VS Code中的ChatGPT配置（设置文件不存在时不做任何操作）.
+func removeVSCodeChatGPTConfig() error {
+	vcm, err := newVSCodeConfigManager()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(vcm.GetSettingsPath()); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := vcm.RemoveChatGPTConfig(); err != nil {
+		return err
+	}
+	internal.LogInfof("[OK] 已清除VS Code中的Codex配置\n")
+	return nil
+}
+
 // showDryRunPreview 预览切换效果（不实际修改配置）.
-func showDryRunPreview(_ *internal.MirrorManager, mirror *internal.MirrorConfig) error {
-	fmt.Printf("[DRY-RUN] 预览切换到 '%s' (%s)\n\n", mirror.Name, mirror.ToolType)
+func showDryRunPreview(mm *internal.MirrorManager, mirror *internal.MirrorConfig) error {
+	changes, err := planMirrorChanges(mm, mirror)
+	if err != nil {
+		return fmt.Errorf("计算配置变化失败: %w", err)
+	}
+
+	fmt.Printf("[DRY-RUN] 预览切换到 '%s' (%s)，不会修改任何文件\n", mirror.Name, mirror.ToolType)
+	printConfigChanges(changes)
+
+	previous := mm.GetConfig().CurrentCodex
+	if mirror.ToolType == internal.ToolTypeClaude {
+		previous = mm.GetConfig().CurrentClaude
+	}
+	fmt.Println("\n将更新的系统配置:")
+	fmt.Printf("  当前 %s 镜像源: %s -> %s\n", mirror.ToolType, displayValue(previous), mirror.Name)
+
+	return nil
+}
+
+// planMirrorChanges 按与实际切换相同的规则计算各配置文件和环境变量的变化.
+func planMirrorChanges(mm *internal.MirrorManager, mirror *internal.MirrorConfig) ([]internal.ConfigChange, error) {
+	mirror, err := resolveMirrorForApply(mm, mirror)
+	if err != nil {
+		return nil, err
+	}
 
 	switch mirror.ToolType {
 	case internal.ToolTypeClaude:
-		fmt.Println("将修改 Claude Code 配置文件:")
-		ccm, _ := internal.NewClaudeConfigManager()
-		fmt.Printf("  配置文件: %s\n", ccm.GetSettingsPath())
-		fmt.Printf("  %s = %s\n", internal.AnthropicBaseURLEnv, mirror.BaseURL)
-		if mirror.APIKey != "" {
-			fmt.Printf("  %s = %s\n", internal.AnthropicAuthTokenEnv, internal.MaskAPIKey(mirror.APIKey))
+		if useEnvVar {
+			vars := map[string]string{
+				internal.AnthropicBaseURLEnv:   mirror.BaseURL,
+				internal.AnthropicAuthTokenEnv: mirror.APIKey,
+				internal.AnthropicModelEnv:     mirror.ModelName,
+			}
+			return internal.PlanEnvVars(vars), nil
 		}
-		if mirror.ModelName != "" {
-			fmt.Printf("  %s = %s\n", internal.AnthropicModelEnv, mirror.ModelName)
+
+		var oldExtraEnv map[string]string
+		if currentClaude := mm.GetConfig().CurrentClaude; currentClaude != "" {
+			if oldMirror, err := mm.GetMirrorByName(currentClaude); err == nil {
+				oldExtraEnv = oldMirror.ExtraEnv
+			}
+		}
+		ccm, err := internal.NewClaudeConfigManager()
+		if err != nil {
+			return nil, err
 		}
+		return ccm.PlanMirror(mirror, oldExtraEnv)
 
 	case internal.ToolTypeCodex:
-		fmt.Println("将修改以下配置:")
+		if codexOnly && vscodeOnly {
+			return nil, fmt.Errorf("--codex-only 和 --vscode-only 不能同时使用")
+		}
 
+		var changes []internal.ConfigChange
 		if !vscodeOnly {
-			fmt.Println("  Codex CLI:")
-			ccm, _ := internal.NewCodexConfigManager()
-			fmt.Printf("    配置文件: %s\n", ccm.GetConfigPath())
-			fmt.Printf("    %s = %s\n", mirror.EnvKey, internal.MaskAPIKey(mirror.APIKey))
+			ccm, err := internal.NewCodexConfigManager()
+			if err != nil {
+				return nil, err
+			}
+			ccm.SetPersistEnv(!skipEnvPersist(mm))
+			codexChanges, err := ccm.PlanMirror(mirror)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, codexChanges...)
 		}
-
 		if !codexOnly {
-			fmt.Println("  VS Code:")
-			vcm, _ := internal.NewVSCodeConfigManager()
-			fmt.Printf("    配置文件: %s\n", vcm.GetSettingsPath())
-			fmt.Printf("    chatgpt.apiBase = %s\n", mirror.BaseURL)
+			vcm, err := newVSCodeConfigManager()
+			if err != nil {
+				return nil, err
+			}
+			vscodeChanges, err := vcm.PlanMirror(mirror)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, vscodeChanges...)
 		}
+		return changes, nil
+
+	default:
+		return nil, fmt.Errorf("不支持的配置类型 '%s'", mirror.ToolType)
 	}
+}
 
-	fmt.Println("\n将更新的系统配置:")
-	fmt.Printf("  当前镜像源 -> %s\n", mirror.Name)
+// printConfigChanges 按文件分组输出配置变化，只显示会改变的项.
+func printConfigChanges(changes []internal.ConfigChange) {
+	var files []string
+	byFile := make(map[string][]internal.ConfigChange)
+	for _, change := range changes {
+		if _, ok := byFile[change.File]; !ok {
+			files = append(files, change.File)
+		}
+		byFile[change.File] = append(byFile[change.File], change)
+	}
 
-	return nil
+	for _, file := range files {
+		fmt.Printf("\n%s:\n", file)
+		changed := 0
+		for _, change := range byFile[file] {
+			if !change.Changed() {
+				continue
+			}
+			changed++
+			oldValue, newValue := change.Old, change.New
+			if change.Secret {
+				oldValue, newValue = internal.MaskAPIKey(oldValue), internal.MaskAPIKey(newValue)
+			}
+			switch {
+			case change.Old == "":
+				fmt.Printf("  + %s = %s\n", change.Key, newValue)
+			case change.New == "":
+				fmt.Printf("  - %s (原值: %s)\n", change.Key, oldValue)
+			default:
+				fmt.Printf("  ~ %s: %s -> %s\n", change.Key, oldValue, newValue)
+			}
+		}
+		if changed == 0 {
+			fmt.Println("  (无变化)")
+		}
+	}
+}
+
+// displayValue 返回用于显示的值，空值显示为 (无).
+func displayValue(value string) string {
+	if value == "" {
+		return "(无)"
+	}
+	return value
 }
 
 // interactiveSelectMirror 交互式选择镜像源.
@@ -413,7 +731,14 @@ func init() {
 	switchCmd.Flags().BoolVar(&noBackup, "no-backup", false, "不备份现有配置")
 	switchCmd.Flags().StringVar(&shellFmt, "shell", "", "输出适配当前shell的导出语句(bash|zsh|fish|powershell|cmd)")
 	switchCmd.Flags().BoolVar(&useEnvVar, "env", false, "Claude类型使用系统环境变量方式（默认使用配置文件）")
+	switchCmd.Flags().BoolVar(&switchNoEnv, "no-env", false, "只更新配置文件，不把环境变量写入shell配置文件或系统环境变量")
 	switchCmd.Flags().BoolVar(&dryRun, "dry-run", false, "预览切换效果，不实际修改配置")
+	switchCmd.Flags().StringVar(&switchModel, "model", "", "切换时更换镜像源使用的模型（会保存到镜像源配置）")
+	switchCmd.Flags().StringVar(&switchCodexName, "codex", "", "要切换到的 Codex 镜像源（可与 --claude 一起使用）")
+	switchCmd.Flags().StringVar(&switchClaudeName, "claude", "", "要切换到的 Claude 镜像源（可与 --codex 一起使用）")
+	_ = switchCmd.RegisterFlagCompletionFunc("codex", completeMirrorsOfType(internal.ToolTypeCodex))
+	_ = switchCmd.RegisterFlagCompletionFunc("claude", completeMirrorsOfType(internal.ToolTypeClaude))
+	switchCmd.Flags().BoolVar(&vscodeInsiders, "vscode-insiders", false, "将配置应用到 VS Code Insiders（默认仅安装 Insiders 时自动使用）")
 }
 
 // emitShellExports 将环境变量以指定shell格式输出到stdout。
@@ -423,9 +748,16 @@ func emitShellExports(vars map[string]string, shell string) {
 		return
 	}
 
+	// 按变量名排序，保证输出稳定
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	exportFunc := getShellExportFunc(strings.ToLower(shell))
-	for k, v := range vars {
-		exportFunc(k, v)
+	for _, k := range keys {
+		exportFunc(k, vars[k])
 	}
 }
 
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"codex-mirror/internal"
@@ -20,24 +21,52 @@ var syncCmd = &cobra.Command{
 var syncInitCmd = &cobra.Command{
 	Use:   "init",
 	Short: "初始化云同步",
-	Long:  `初始化云同步功能，配置GitHub Token和加密密码。使用 'codex-mirror sync help' 查看详细帮助。`,
-	RunE:  runSyncInit,
+	Long: `初始化云同步功能，配置访问令牌和加密密码。使用 'codex-mirror sync help' 查看详细帮助。
+
+提供商 (--provider)：
+  gist   GitHub Gist（默认）
+  gitee  Gitee 代码片段，适合中国大陆用户，令牌需勾选 gists 权限
+  gitlab GitLab 个人代码片段，令牌需具备 api 权限；自建实例使用 --endpoint 指定 API 地址
+
+示例：
+  codex-mirror sync init --provider gitlab --endpoint https://gitlab.example.com/api/v4 --token <PAT> --password <加密密码>`,
+	RunE: runSyncInit,
 }
 
 // syncPushCmd 推送配置命令.
 var syncPushCmd = &cobra.Command{
 	Use:   "push",
 	Short: "推送配置到云端",
-	Long:  `将当前配置推送到云端存储`,
-	RunE:  runSyncPush,
+	Long: `将当前配置推送到云端存储
+
+冲突处理策略 (--strategy)：
+  auto/merge  智能合并冲突
+  force       覆盖云端配置
+  manual      交互式选择
+  abort       检测到冲突时取消推送，不上传也不修改本地配置，并以退出码 3 退出
+
+字段冲突默认逐个询问；使用 --non-interactive 或标准输入不是终端（脚本、管道、CI）时，
+按修改时间自动保留较新的值，manual 策略不可用。
+
+上传前会再次检查云端配置，若在本次推送开始后被其他设备修改则取消推送（包括 force），
+避免覆盖其他设备的修改，重新执行推送即可。
+
+在 CI 等非交互场景中建议使用 --strategy=abort，发现冲突后由人工处理。`,
+	RunE: runSyncPush,
 }
 
 // syncPullCmd 拉取配置命令.
 var syncPullCmd = &cobra.Command{
 	Use:   "pull",
 	Short: "从云端拉取配置",
-	Long:  `从云端存储拉取配置并应用到本地`,
-	RunE:  runSyncPull,
+	Long: `从云端存储拉取配置并应用到本地
+
+使用 merge 策略时字段冲突默认逐个询问；使用 --non-interactive 或标准输入不是终端
+（脚本、管道、CI）时，按修改时间自动保留较新的值，不会等待输入。
+
+示例：
+  codex-mirror sync pull --strategy merge --non-interactive`,
+	RunE: runSyncPull,
 }
 
 // syncStatusCmd 查看同步状态命令.
@@ -66,6 +95,12 @@ var (
 	resolveStrategy string
 	pushStrategy    string
 	syncGistID      string
+	syncProfile     string
+	pushToolType    string
+	syncProvider    string
+	syncEndpoint    string
+
+	syncNonInteractive bool // 冲突解决时不从标准输入询问
 )
 
 func init() {
@@ -77,9 +112,11 @@ func init() {
 	syncCmd.AddCommand(syncConfigCmd)
 
 	// syncInitCmd 参数
-	syncInitCmd.Flags().StringVarP(&syncToken, "token", "t", "", "GitHub访问令牌 (必需)")
+	syncInitCmd.Flags().StringVarP(&syncToken, "token", "t", "", "访问令牌 (GitHub 或 Gitee，必需)")
 	syncInitCmd.Flags().StringVarP(&syncEncryptPwd, "password", "p", "", "加密密码 (必需)")
 	syncInitCmd.Flags().StringVar(&syncGistID, "gist-id", "", "现有的Gist ID (可选，用于连接到现有配置)")
+	syncInitCmd.Flags().StringVar(&syncProvider, "provider", "gist", "同步提供商 (gist|gitee|gitlab)")
+	syncInitCmd.Flags().StringVar(&syncEndpoint, "endpoint", "", "GitLab 实例的 API 地址 (仅 gitlab，默认 https://gitlab.com/api/v4)")
 	_ = syncInitCmd.MarkFlagRequired("token")
 	_ = syncInitCmd.MarkFlagRequired("password")
 
@@ -89,36 +126,96 @@ func init() {
 	syncConfigCmd.Flags().BoolVar(&syncDisable, "disable", false, "禁用云同步")
 	syncConfigCmd.Flags().StringVar(&syncEncryptPwd, "password", "", "更改加密密码")
 
+	// --profile 选择同步配置
+	for _, cmd := range []*cobra.Command{syncInitCmd, syncPushCmd, syncPullCmd, syncStatusCmd, syncConfigCmd} {
+		addSyncProfileFlag(cmd)
+	}
+
 	// syncPushCmd 参数
-	syncPushCmd.Flags().StringVar(&pushStrategy, "strategy", "auto", "推送策略 (auto|merge|force|manual)")
+	syncPushCmd.Flags().StringVar(&pushStrategy, "strategy", "auto", "推送策略 (auto|merge|force|manual|abort)")
+	syncPushCmd.Flags().StringVarP(&pushToolType, "type", "t", "", "只推送指定工具类型的镜像源 (codex|claude)，云端其他类型保持不变")
 
 	// syncPullCmd 参数
 	syncPullCmd.Flags().StringVar(&resolveStrategy, "strategy", "auto", "冲突解决策略 (auto|local|remote|merge)")
 
+	// --non-interactive 字段冲突按修改时间自动选择，不询问用户
+	for _, cmd := range []*cobra.Command{syncPushCmd, syncPullCmd} {
+		cmd.Flags().BoolVar(&syncNonInteractive, "non-interactive", false, "不询问字段冲突，按修改时间保留较新的值（标准输入不是终端时自动启用）")
+	}
+
 	// 将 sync 命令添加到根命令
 	rootCmd.AddCommand(syncCmd)
 }
 
+// addSyncProfileFlag 为命令添加 --profile 参数.
+func addSyncProfileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&syncProfile, "profile", internal.DefaultSyncProfile, "使用的同步配置名（可为不同的 Gist 分别配置）")
+}
+
+// newProfileSyncManager 校验 --profile 并创建使用该同步配置的同步管理器.
+func newProfileSyncManager(mirrorManager *internal.MirrorManager) (*internal.SyncManager, error) {
+	if err := internal.ValidateSyncProfileName(syncProfile); err != nil {
+		return nil, err
+	}
+	syncManager := internal.NewSyncManager(mirrorManager)
+	syncManager.SetProfile(syncProfile)
+	return syncManager, nil
+}
+
+// syncInteractive 返回推送/拉取时是否可以询问字段冲突：指定 --non-interactive 或标准输入不是终端时不询问.
+func syncInteractive() bool {
+	return !syncNonInteractive && stdinIsTerminal()
+}
+
+// stdinIsTerminal 判断标准输入是否为终端；脚本、管道和 CI 中不是终端，此时无法询问用户.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// syncInitHint 返回初始化当前同步配置的命令提示.
+func syncInitHint() string {
+	hint := "codex-mirror sync init --token <GitHub-Token> --password <加密密码>"
+	if syncProfile != internal.DefaultSyncProfile {
+		hint += " --profile " + syncProfile
+	}
+	return hint
+}
+
 // runSyncInit 执行同步初始化.
 func runSyncInit(cmd *cobra.Command, args []string) error {
 	// 验证参数
+	providerInfo, err := internal.SyncProviderInfo(syncProvider)
+	if err != nil {
+		return err
+	}
+	if syncEndpoint != "" {
+		if syncProvider != "gitlab" {
+			return fmt.Errorf("--endpoint 仅适用于 gitlab 提供商")
+		}
+		providerInfo.Endpoint = strings.TrimSuffix(syncEndpoint, "/")
+	}
+
 	if syncToken == "" {
-		fmt.Printf("❌ GitHub访问令牌不能为空\n\n")
-		fmt.Printf("💡 如何获取GitHub Token:\n")
-		fmt.Printf("   1. 访问: https://github.com/settings/tokens\n")
-		fmt.Printf("   2. 点击 'Generate new token (classic)'\n")
-		fmt.Printf("   3. 勾选 'gist' 权限\n")
-		fmt.Printf("   4. 复制生成的Token\n\n")
-		fmt.Printf("📖 详细帮助: codex-mirror sync help\n")
+		internal.LogErrorf("❌ GitHub访问令牌不能为空\n\n")
+		internal.LogInfof("💡 如何获取GitHub Token:\n")
+		internal.LogInfof("   1. 访问: https://github.com/settings/tokens\n")
+		internal.LogInfof("   2. 点击 'Generate new token (classic)'\n")
+		internal.LogInfof("   3. 勾选 'gist' 权限\n")
+		internal.LogInfof("   4. 复制生成的Token\n\n")
+		internal.LogInfof("📖 详细帮助: codex-mirror sync help\n")
 		return fmt.Errorf("GitHub访问令牌不能为空")
 	}
 
 	if syncEncryptPwd == "" {
-		fmt.Printf("❌ 加密密码不能为空\n\n")
-		fmt.Printf("💡 密码要求:\n")
-		fmt.Printf("   - 长度至少8位\n")
-		fmt.Printf("   - 建议包含字母和数字\n")
-		fmt.Printf("   - 请妥善保管，忘记密码将无法解密云端数据\n")
+		internal.LogErrorf("❌ 加密密码不能为空\n\n")
+		internal.LogInfof("💡 密码要求:\n")
+		internal.LogInfof("   - 长度至少8位\n")
+		internal.LogInfof("   - 建议包含字母和数字\n")
+		internal.LogInfof("   - 请妥善保管，忘记密码将无法解密云端数据\n")
 		return fmt.Errorf("加密密码不能为空")
 	}
 
@@ -133,30 +230,34 @@ func runSyncInit(cmd *cobra.Command, args []string) error {
 	}
 
 	// 创建同步管理器
-	syncManager := internal.NewSyncManager(mirrorManager)
+	syncManager, err := newProfileSyncManager(mirrorManager)
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("🔧 正在初始化云同步...\n")
-	fmt.Printf("   提供商: GitHub Gist\n")
-	fmt.Printf("   端点: https://api.github.com\n")
-	fmt.Printf("   🔐 全量同步: 启用（包含加密的API密钥）\n")
+	internal.LogInfof("🔧 正在初始化云同步...\n")
+	internal.LogInfof("   同步配置: %s\n", syncManager.Profile())
+	internal.LogInfof("   提供商: %s\n", providerInfo.Name)
+	internal.LogInfof("   端点: %s\n", providerInfo.Endpoint)
+	internal.LogInfof("   🔐 全量同步: 启用（包含加密的API密钥）\n")
 
-	fmt.Printf("\n🛡️  安全说明:\n")
-	fmt.Printf("   - 所有数据使用AES-256加密\n")
-	fmt.Printf("   - 使用你提供的密码进行加密\n")
-	fmt.Printf("   - 存储在私有GitHub Gist中\n")
-	fmt.Printf("   - 请妥善保管你的密码和GitHub Token\n")
+	internal.LogInfof("\n🛡️  安全说明:\n")
+	internal.LogInfof("   - 所有数据使用AES-256加密\n")
+	internal.LogInfof("   - 使用你提供的密码进行加密\n")
+	internal.LogInfof("   - 存储在私有%s中\n", providerInfo.Name)
+	internal.LogInfof("   - 请妥善保管你的密码和访问令牌\n")
 
 	// 初始化同步
-	if err := syncManager.InitSyncWithPasswordAndGist("gist", "https://api.github.com", syncToken, syncEncryptPwd, syncGistID); err != nil {
+	if err := syncManager.InitSyncWithPasswordAndGist(syncProvider, providerInfo.Endpoint, syncToken, syncEncryptPwd, syncGistID); err != nil {
 		return fmt.Errorf("初始化云同步失败: %w", err)
 	}
 
-	fmt.Printf("\n💡 使用提示:\n")
-	fmt.Printf("   - 使用 'codex-mirror sync push' 推送配置到云端\n")
-	fmt.Printf("   - 使用 'codex-mirror sync pull' 从云端拉取配置\n")
-	fmt.Printf("   - 使用 'codex-mirror sync status' 查看同步状态\n")
-	fmt.Printf("   - 在其他设备上使用相同的密码初始化同步\n")
-	fmt.Printf("   - 查看详细帮助: 'codex-mirror sync help'\n")
+	internal.LogInfof("\n💡 使用提示:\n")
+	internal.LogInfof("   - 使用 'codex-mirror sync push' 推送配置到云端\n")
+	internal.LogInfof("   - 使用 'codex-mirror sync pull' 从云端拉取配置\n")
+	internal.LogInfof("   - 使用 'codex-mirror sync status' 查看同步状态\n")
+	internal.LogInfof("   - 在其他设备上使用相同的密码初始化同步\n")
+	internal.LogInfof("   - 查看详细帮助: 'codex-mirror sync help'\n")
 
 	return nil
 }
@@ -169,38 +270,42 @@ func runSyncPush(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("创建镜像源管理器失败: %w", err)
 	}
 
-	// 检查是否已初始化
-	if mirrorManager.GetConfig().Sync == nil {
-		fmt.Printf("❌ 云同步未初始化\n\n")
-		fmt.Printf("💡 请先初始化云同步:\n")
-		fmt.Printf("   codex-mirror sync init --token <GitHub-Token> --password <加密密码>\n\n")
-		fmt.Printf("📖 详细帮助: codex-mirror sync help\n")
-		return fmt.Errorf("云同步未初始化，请先运行 'codex-mirror sync init'")
+	// 创建同步管理器
+	syncManager, err := newProfileSyncManager(mirrorManager)
+	if err != nil {
+		return err
 	}
+	syncManager.SetInteractive(syncInteractive())
 
-	// 创建同步管理器
-	syncManager := internal.NewSyncManager(mirrorManager)
+	// 检查是否已初始化
+	if mirrorManager.GetSyncProfile(syncProfile) == nil {
+		internal.LogErrorf("❌ 云同步未初始化 (同步配置: %s)\n\n", syncProfile)
+		internal.LogInfof("💡 请先初始化云同步:\n")
+		internal.LogInfof("   %s\n\n", syncInitHint())
+		internal.LogInfof("📖 详细帮助: codex-mirror sync help\n")
+		return fmt.Errorf("云同步未初始化，请先运行 '%s'", syncInitHint())
+	}
 
 	// 推送配置（使用策略参数）
-	if err := syncManager.PushWithStrategy(pushStrategy); err != nil {
+	if err := pushConfig(syncManager); err != nil {
 		if strings.Contains(err.Error(), "GitHub API 错误 (401)") {
-			fmt.Printf("❌ GitHub认证失败\n\n")
-			fmt.Printf("💡 可能的原因:\n")
-			fmt.Printf("   - Token无效或已过期\n")
-			fmt.Printf("   - Token没有gist权限\n\n")
-			fmt.Printf("🔧 解决方法:\n")
-			fmt.Printf("   - 重新生成Token: https://github.com/settings/tokens\n")
-			fmt.Printf("   - 确保勾选了'gist'权限\n")
-			fmt.Printf("   - 使用新Token重新初始化同步\n")
+			internal.LogErrorf("❌ GitHub认证失败\n\n")
+			internal.LogInfof("💡 可能的原因:\n")
+			internal.LogInfof("   - Token无效或已过期\n")
+			internal.LogInfof("   - Token没有gist权限\n\n")
+			internal.LogInfof("🔧 解决方法:\n")
+			internal.LogInfof("   - 重新生成Token: https://github.com/settings/tokens\n")
+			internal.LogInfof("   - 确保勾选了'gist'权限\n")
+			internal.LogInfof("   - 使用新Token重新初始化同步\n")
 			return fmt.Errorf("GitHub认证失败")
 		}
 		if strings.Contains(err.Error(), "加密失败") {
-			fmt.Printf("❌ 数据加密失败\n\n")
-			fmt.Printf("💡 可能的原因:\n")
-			fmt.Printf("   - 密码配置异常\n")
-			fmt.Printf("   - 系统加密组件故障\n\n")
-			fmt.Printf("🔧 解决方法:\n")
-			fmt.Printf("   - 重新初始化同步: codex-mirror sync init\n")
+			internal.LogErrorf("❌ 数据加密失败\n\n")
+			internal.LogInfof("💡 可能的原因:\n")
+			internal.LogInfof("   - 密码配置异常\n")
+			internal.LogInfof("   - 系统加密组件故障\n\n")
+			internal.LogInfof("🔧 解决方法:\n")
+			internal.LogInfof("   - 重新初始化同步: codex-mirror sync init\n")
 			return fmt.Errorf("数据加密失败")
 		}
 		return fmt.Errorf("推送配置失败: %w", err)
@@ -209,6 +314,18 @@ func runSyncPush(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// pushConfig 按 --type 推送全部或指定工具类型的镜像源.
+func pushConfig(syncManager *internal.SyncManager) error {
+	switch pushToolType {
+	case "":
+		return syncManager.PushWithStrategy(pushStrategy)
+	case string(internal.ToolTypeCodex), string(internal.ToolTypeClaude):
+		return syncManager.PushToolWithStrategy(internal.ToolType(pushToolType), pushStrategy)
+	default:
+		return fmt.Errorf("无效的工具类型 '%s'，支持: %s, %s", pushToolType, internal.ToolTypeCodex, internal.ToolTypeClaude)
+	}
+}
+
 // runSyncPull 执行拉取配置.
 func runSyncPull(cmd *cobra.Command, args []string) error {
 	// 创建镜像源管理器
@@ -217,55 +334,59 @@ func runSyncPull(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("创建镜像源管理器失败: %w", err)
 	}
 
-	// 检查是否已初始化
-	if mirrorManager.GetConfig().Sync == nil {
-		fmt.Printf("❌ 云同步未初始化\n\n")
-		fmt.Printf("💡 请先初始化云同步:\n")
-		fmt.Printf("   codex-mirror sync init --token <GitHub-Token> --password <加密密码>\n\n")
-		fmt.Printf("🔑 如何获取GitHub Token:\n")
-		fmt.Printf("   1. 访问: https://github.com/settings/tokens\n")
-		fmt.Printf("   2. 点击 'Generate new token (classic)'\n")
-		fmt.Printf("   3. 勾选 'gist' 权限\n")
-		fmt.Printf("   4. 复制生成的Token\n\n")
-		fmt.Printf("📖 详细帮助: codex-mirror sync help\n")
-		return fmt.Errorf("云同步未初始化，请先运行 'codex-mirror sync init'")
+	// 创建同步管理器
+	syncManager, err := newProfileSyncManager(mirrorManager)
+	if err != nil {
+		return err
 	}
+	syncManager.SetInteractive(syncInteractive())
 
-	// 创建同步管理器
-	syncManager := internal.NewSyncManager(mirrorManager)
+	// 检查是否已初始化
+	if mirrorManager.GetSyncProfile(syncProfile) == nil {
+		internal.LogErrorf("❌ 云同步未初始化 (同步配置: %s)\n\n", syncProfile)
+		internal.LogInfof("💡 请先初始化云同步:\n")
+		internal.LogInfof("   %s\n\n", syncInitHint())
+		internal.LogInfof("🔑 如何获取GitHub Token:\n")
+		internal.LogInfof("   1. 访问: https://github.com/settings/tokens\n")
+		internal.LogInfof("   2. 点击 'Generate new token (classic)'\n")
+		internal.LogInfof("   3. 勾选 'gist' 权限\n")
+		internal.LogInfof("   4. 复制生成的Token\n\n")
+		internal.LogInfof("📖 详细帮助: codex-mirror sync help\n")
+		return fmt.Errorf("云同步未初始化，请先运行 '%s'", syncInitHint())
+	}
 
 	// 拉取配置
 	if err := syncManager.PullWithStrategy(resolveStrategy); err != nil {
 		if strings.Contains(err.Error(), "解密失败") {
-			fmt.Printf("❌ 解密失败\n\n")
-			fmt.Printf("💡 可能的原因:\n")
-			fmt.Printf("   - 密码不正确\n")
-			fmt.Printf("   - 云端数据损坏\n")
-			fmt.Printf("   - 使用了不同的密码\n\n")
-			fmt.Printf("🔧 解决方法:\n")
-			fmt.Printf("   - 检查密码是否正确\n")
-			fmt.Printf("   - 如果忘记密码，请重新初始化: codex-mirror sync init\n")
+			internal.LogErrorf("❌ 解密失败\n\n")
+			internal.LogInfof("💡 可能的原因:\n")
+			internal.LogInfof("   - 密码不正确\n")
+			internal.LogInfof("   - 云端数据损坏\n")
+			internal.LogInfof("   - 使用了不同的密码\n\n")
+			internal.LogInfof("🔧 解决方法:\n")
+			internal.LogInfof("   - 检查密码是否正确\n")
+			internal.LogInfof("   - 如果忘记密码，请重新初始化: codex-mirror sync init\n")
 			return fmt.Errorf("解密失败，请检查密码是否正确")
 		}
 		if strings.Contains(err.Error(), "GitHub API 错误 (401)") {
-			fmt.Printf("❌ GitHub认证失败\n\n")
-			fmt.Printf("💡 可能的原因:\n")
-			fmt.Printf("   - Token无效或已过期\n")
-			fmt.Printf("   - Token没有gist权限\n\n")
-			fmt.Printf("🔧 解决方法:\n")
-			fmt.Printf("   - 检查Token是否正确\n")
-			fmt.Printf("   - 重新生成Token: https://github.com/settings/tokens\n")
-			fmt.Printf("   - 确保勾选了'gist'权限\n")
+			internal.LogErrorf("❌ GitHub认证失败\n\n")
+			internal.LogInfof("💡 可能的原因:\n")
+			internal.LogInfof("   - Token无效或已过期\n")
+			internal.LogInfof("   - Token没有gist权限\n\n")
+			internal.LogInfof("🔧 解决方法:\n")
+			internal.LogInfof("   - 检查Token是否正确\n")
+			internal.LogInfof("   - 重新生成Token: https://github.com/settings/tokens\n")
+			internal.LogInfof("   - 确保勾选了'gist'权限\n")
 			return fmt.Errorf("GitHub认证失败")
 		}
 		if strings.Contains(err.Error(), "未找到文件") {
-			fmt.Printf("❌ 云端没有找到配置文件\n\n")
-			fmt.Printf("💡 可能的原因:\n")
-			fmt.Printf("   - 这是第一次使用云同步\n")
-			fmt.Printf("   - 还没有从其他设备推送过配置\n\n")
-			fmt.Printf("🔧 解决方法:\n")
-			fmt.Printf("   - 先在一台设备上配置镜像源\n")
-			fmt.Printf("   - 使用 'codex-mirror sync push' 推送配置\n")
+			internal.LogErrorf("❌ 云端没有找到配置文件\n\n")
+			internal.LogInfof("💡 可能的原因:\n")
+			internal.LogInfof("   - 这是第一次使用云同步\n")
+			internal.LogInfof("   - 还没有从其他设备推送过配置\n\n")
+			internal.LogInfof("🔧 解决方法:\n")
+			internal.LogInfof("   - 先在一台设备上配置镜像源\n")
+			internal.LogInfof("   - 使用 'codex-mirror sync push' 推送配置\n")
 			return fmt.Errorf("云端没有找到配置文件")
 		}
 		return fmt.Errorf("拉取配置失败: %w", err)
@@ -283,7 +404,10 @@ func runSyncStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// 创建同步管理器
-	syncManager := internal.NewSyncManager(mirrorManager)
+	syncManager, err := newProfileSyncManager(mirrorManager)
+	if err != nil {
+		return err
+	}
 
 	// 获取同步状态
 	status, err := syncManager.GetStatus()
@@ -297,12 +421,15 @@ func runSyncStatus(cmd *cobra.Command, args []string) error {
 
 	if !status.Enabled {
 		fmt.Printf("❌ 云同步未启用\n")
+		fmt.Printf("   同步配置: %s\n", status.Profile)
 		fmt.Printf("   %s\n", status.Message)
-		fmt.Printf("\n💡 使用 'codex-mirror sync init' 初始化云同步\n")
+		fmt.Printf("\n💡 使用 '%s' 初始化云同步\n", syncInitHint())
+		printOtherSyncProfiles(mirrorManager, status.Profile)
 		return nil
 	}
 
 	fmt.Printf("✅ 云同步已启用\n")
+	fmt.Printf("   同步配置: %s\n", status.Profile)
 	fmt.Printf("   提供商: %s\n", status.Provider)
 	fmt.Printf("   端点: %s\n", status.Endpoint)
 	fmt.Printf("   设备ID: %s\n", status.DeviceID)
@@ -313,15 +440,35 @@ func runSyncStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("   %s\n", status.Message)
+	if status.LastSyncError != "" {
+		fmt.Printf("   ❌ 最近一次同步失败: %s\n", status.LastSyncError)
+	} else if status.LastSyncSuccess {
+		fmt.Printf("   最近一次同步: 成功\n")
+	}
+	if status.LastRemoteDevice != "" {
+		fmt.Printf("   云端最后由 %s 于 %s 更新\n", status.LastRemoteDevice, status.LastRemoteTimestamp.Format("2006-01-02 15:04:05"))
+	}
 
 	// 显示加密状态
-	if mirrorManager.GetConfig().Sync != nil {
-		fmt.Printf("   全量同步: 是（包含加密的API密钥）\n")
-	}
+	fmt.Printf("   全量同步: 是（包含加密的API密钥）\n")
+	printOtherSyncProfiles(mirrorManager, status.Profile)
 
 	return nil
 }
 
+// printOtherSyncProfiles 列出除当前外的其他同步配置.
+func printOtherSyncProfiles(mirrorManager *internal.MirrorManager, current string) {
+	var others []string
+	for _, name := range mirrorManager.SyncProfileNames() {
+		if name != current {
+			others = append(others, name)
+		}
+	}
+	if len(others) > 0 {
+		fmt.Printf("\n其他同步配置: %s（使用 --profile 切换）\n", strings.Join(others, ", "))
+	}
+}
+
 // runSyncConfig 执行配置同步设置.
 func runSyncConfig(cmd *cobra.Command, args []string) error {
 	// 创建镜像源管理器
@@ -330,12 +477,15 @@ func runSyncConfig(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("创建镜像源管理器失败: %w", err)
 	}
 
-	// 检查是否已初始化同步
-	if mirrorManager.GetConfig().Sync == nil {
-		return fmt.Errorf("云同步未初始化，请先运行 'codex-mirror sync init'")
+	if err := internal.ValidateSyncProfileName(syncProfile); err != nil {
+		return err
 	}
 
-	config := mirrorManager.GetConfig().Sync
+	// 检查是否已初始化同步
+	config := mirrorManager.GetSyncProfile(syncProfile)
+	if config == nil {
+		return fmt.Errorf("云同步未初始化，请先运行 '%s'", syncInitHint())
+	}
 
 	// 处理禁用同步
 	if syncDisable {
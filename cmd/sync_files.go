@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// syncFilesCmd 管理云端文件命令.
+var syncFilesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "列出或删除云端文件",
+	Long: `列出云端存储（如 Gist）中的文件，可用于清理失败实验遗留的无用文件。
+
+删除主配置文件 ` + internal.ConfigFileName + ` 会清空云端配置，需要确认（或使用 --force）。
+
+示例：
+  codex-mirror sync files
+  codex-mirror sync files --delete old-config.json`,
+	Args: cobra.NoArgs,
+	RunE: runSyncFiles,
+}
+
+// 云端文件参数.
+var (
+	syncFilesDelete string
+	syncFilesForce  bool
+)
+
+func init() {
+	syncFilesCmd.Flags().StringVar(&syncFilesDelete, "delete", "", "删除指定的云端文件")
+	syncFilesCmd.Flags().BoolVarP(&syncFilesForce, "force", "f", false, "删除主配置文件时不询问确认")
+	addSyncProfileFlag(syncFilesCmd)
+	syncCmd.AddCommand(syncFilesCmd)
+}
+
+// runSyncFiles 执行云端文件列出或删除.
+func runSyncFiles(cmd *cobra.Command, args []string) error {
+	mirrorManager, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("创建镜像源管理器失败: %w", err)
+	}
+
+	syncManager, err := newProfileSyncManager(mirrorManager)
+	if err != nil {
+		return err
+	}
+	if mirrorManager.GetSyncProfile(syncProfile) == nil {
+		return fmt.Errorf("云同步未初始化，请先运行 '%s'", syncInitHint())
+	}
+
+	if syncFilesDelete != "" {
+		return deleteSyncFile(syncManager, syncFilesDelete)
+	}
+
+	files, err := syncManager.ListRemoteFiles()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Println("云端暂无文件")
+		return nil
+	}
+
+	fmt.Printf("云端文件 (同步配置: %s):\n", syncManager.Profile())
+	for _, file := range files {
+		if file == internal.ConfigFileName {
+			fmt.Printf("  %s  (主配置文件)\n", file)
+			continue
+		}
+		fmt.Printf("  %s\n", file)
+	}
+	return nil
+}
+
+// deleteSyncFile 删除云端文件，删除主配置文件前需要确认.
+func deleteSyncFile(syncManager *internal.SyncManager, filename string) error {
+	if filename == internal.ConfigFileName && !syncFilesForce {
+		fmt.Printf("⚠️  %s 是云端主配置文件，删除后其他设备将无法拉取配置\n", filename)
+		fmt.Printf("是否继续删除？(y/N): ")
+		var confirm string
+		_, _ = fmt.Scanln(&confirm)
+		if confirm != "y" && confirm != "Y" {
+			fmt.Printf("已取消删除\n")
+			return nil
+		}
+	}
+
+	if err := syncManager.DeleteRemoteFile(filename); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已删除云端文件: %s\n", filename)
+	return nil
+}
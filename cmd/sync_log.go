@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// syncLogCmd 查看同步历史命令.
+var syncLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "查看同步历史",
+	Long: `查看最近的推送/拉取记录，包括时间、方向、策略、设备、镜像源数量和结果。
+
+示例：
+  codex-mirror sync log
+  codex-mirror sync log --limit 50`,
+	Args: cobra.NoArgs,
+	RunE: runSyncLog,
+}
+
+// syncLogLimit 显示的历史记录条数.
+var syncLogLimit int
+
+func init() {
+	syncLogCmd.Flags().IntVarP(&syncLogLimit, "limit", "n", 20, "显示最近的记录条数 (0 表示全部)")
+	syncCmd.AddCommand(syncLogCmd)
+}
+
+// runSyncLog 执行同步历史查看.
+func runSyncLog(cmd *cobra.Command, args []string) error {
+	if syncLogLimit < 0 {
+		return fmt.Errorf("--limit 不能为负数")
+	}
+
+	mirrorManager, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("创建镜像源管理器失败: %w", err)
+	}
+
+	entries, err := internal.NewSyncManager(mirrorManager).ReadHistory(syncLogLimit)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("暂无同步记录")
+		return nil
+	}
+
+	for _, entry := range entries {
+		result := "✅ 成功"
+		if !entry.Success {
+			result = "❌ 失败: " + entry.Error
+		}
+		fmt.Printf("%s  %-4s  策略: %-6s  设备: %s  镜像源: %d  %s\n",
+			entry.Timestamp.Format("2006-01-02 15:04:05"), entry.Direction, entry.Strategy,
+			entry.DeviceID, entry.MirrorCount, result)
+	}
+	return nil
+}
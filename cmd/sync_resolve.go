@@ -28,6 +28,7 @@ func init() {
 	syncResolveCmd.Flags().StringVarP(&resolveStrategy, "strategy", "s", "auto", "冲突解决策略 (auto|local|remote|merge)")
 	syncResolveCmd.Flags().BoolVarP(&resolvePreview, "preview", "p", false, "预览冲突，不实际解决")
 	syncResolveCmd.Flags().BoolVar(&resolveForce, "force", false, "强制解决冲突，不询问确认")
+	addSyncProfileFlag(syncResolveCmd)
 
 	// 将命令添加到 sync
 	syncCmd.AddCommand(syncResolveCmd)
@@ -41,13 +42,16 @@ func runSyncResolve(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("创建镜像源管理器失败: %w", err)
 	}
 
-	// 检查是否已初始化
-	if mirrorManager.GetConfig().Sync == nil {
-		return fmt.Errorf("云同步未初始化，请先运行 'codex-mirror sync init'")
+	// 创建同步管理器
+	syncManager, err := newProfileSyncManager(mirrorManager)
+	if err != nil {
+		return err
 	}
 
-	// 创建同步管理器
-	syncManager := internal.NewSyncManager(mirrorManager)
+	// 检查是否已初始化
+	if mirrorManager.GetSyncProfile(syncProfile) == nil {
+		return fmt.Errorf("云同步未初始化，请先运行 '%s'", syncInitHint())
+	}
 
 	fmt.Printf("🔍 正在检测配置冲突...\n")
 
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// syncUnlinkCmd 停用并清除云同步命令.
+var syncUnlinkCmd = &cobra.Command{
+	Use:   "unlink",
+	Short: "停用云同步并清除本地凭据",
+	Long: `停用云同步，并从本地配置中清除访问令牌、加密密码和 Gist ID，适用于在共用设备上注销。
+
+默认同时删除云端主配置文件 ` + internal.ConfigFileName + `，其他设备将无法再拉取该配置；
+使用 --keep-remote 保留云端配置，仅清除本机的凭据。
+
+会清除凭据，需要确认（或使用 --yes）。
+
+示例：
+  codex-mirror sync unlink
+  codex-mirror sync unlink --keep-remote --yes`,
+	Args: cobra.NoArgs,
+	RunE: runSyncUnlink,
+}
+
+// 停用云同步参数.
+var (
+	syncUnlinkKeepRemote bool
+	syncUnlinkYes        bool
+)
+
+func init() {
+	syncUnlinkCmd.Flags().BoolVar(&syncUnlinkKeepRemote, "keep-remote", false, "保留云端配置，仅清除本地凭据")
+	syncUnlinkCmd.Flags().BoolVarP(&syncUnlinkYes, "yes", "y", false, "跳过确认直接清除")
+	addSyncProfileFlag(syncUnlinkCmd)
+	syncCmd.AddCommand(syncUnlinkCmd)
+}
+
+// runSyncUnlink 执行停用云同步.
+func runSyncUnlink(cmd *cobra.Command, args []string) error {
+	mirrorManager, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("创建镜像源管理器失败: %w", err)
+	}
+
+	syncManager, err := newProfileSyncManager(mirrorManager)
+	if err != nil {
+		return err
+	}
+	if mirrorManager.GetSyncProfile(syncProfile) == nil {
+		return fmt.Errorf("云同步未初始化 (同步配置: %s)", syncProfile)
+	}
+
+	if !syncUnlinkYes {
+		fmt.Printf("⚠️  将停用云同步并清除本地保存的访问令牌和加密密码 (同步配置: %s)\n", syncManager.Profile())
+		if !syncUnlinkKeepRemote {
+			fmt.Printf("⚠️  同时删除云端主配置文件 %s，其他设备将无法拉取配置\n", internal.ConfigFileName)
+		}
+		fmt.Printf("是否继续？(y/N): ")
+		var confirm string
+		_, _ = fmt.Scanln(&confirm)
+		if confirm != "y" && confirm != "Y" {
+			fmt.Printf("已取消\n")
+			return nil
+		}
+	}
+
+	if err := syncManager.Unlink(syncUnlinkKeepRemote); err != nil {
+		return fmt.Errorf("停用云同步失败: %w", err)
+	}
+
+	fmt.Printf("✅ 云同步已停用，本地凭据已清除\n")
+	fmt.Printf("💡 如需重新启用，请运行 '%s'\n", syncInitHint())
+	return nil
+}
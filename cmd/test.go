@@ -1,11 +1,9 @@
 package cmd
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"codex-mirror/internal"
@@ -13,8 +11,20 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// needAPIKey401Msg 需要API Key的错误消息.
-const needAPIKey401Msg = "需要 API Key (401)"
+// defaultMaxConcurrency 并行测试时默认的最大并发数.
+const defaultMaxConcurrency = internal.DefaultMaxConcurrency
+
+// defaultTestRetries 网络错误时默认的重试次数.
+const defaultTestRetries = internal.DefaultTestRetries
+
+// testTransport 测试请求使用的 HTTP 传输层（测试中可替换以便观测请求）.
+var testTransport http.RoundTripper = http.DefaultTransport
+
+// testRetryBackoff 重试之间的基础等待时间，第 n 次重试等待 n 倍.
+var testRetryBackoff = 500 * time.Millisecond
+
+// testTimeoutCap 本次运行中镜像源超时时间的上限（秒），命令行显式指定 --timeout 时设置，0 表示不限制.
+var testTimeoutCap int
 
 // testCmd represents the test command.
 var testCmd = &cobra.Command{
@@ -31,6 +41,10 @@ var testCmd = &cobra.Command{
   codex-mirror test mymirror           # 测试指定镜像源
   codex-mirror test --all              # 测试所有镜像源
   codex-mirror test --all --parallel   # 并行测试所有镜像源
+  codex-mirror test --all --parallel --max-concurrency 4
+  codex-mirror test --all --json       # 以 JSON 格式输出结果
+  codex-mirror test --all --timeout 5  # 超时 5 秒，镜像源自身设置的超时也不超过 5 秒
+  codex-mirror test --all --switch-fastest --type codex  # 切换到延迟最低的可用镜像源
   codex-mirror test --remove-invalid   # 测试并移除无效的 API Key`,
 	Aliases: []string{"check", "verify"},
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -51,183 +65,164 @@ var testCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		allMirrors, _ := cmd.Flags().GetBool("all")
 		parallel, _ := cmd.Flags().GetBool("parallel")
+		maxConcurrency, _ := cmd.Flags().GetInt("max-concurrency")
 		timeout, _ := cmd.Flags().GetInt("timeout")
+		retries, _ := cmd.Flags().GetInt("retries")
 		removeInvalid, _ := cmd.Flags().GetBool("remove-invalid")
 		removeAllInvalid, _ := cmd.Flags().GetBool("remove-all-invalid")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		switchFastest, _ := cmd.Flags().GetBool("switch-fastest")
+		toolType, _ := cmd.Flags().GetString("type")
+		tag, _ := cmd.Flags().GetString("tag")
+
+		// 镜像源可以设置自己的超时时间，显式指定的 --timeout 同时作为它们的上限
+		testTimeoutCap = explicitTimeoutCap(cmd, timeout)
+		defer func() { testTimeoutCap = 0 }()
 
 		mm, err := internal.NewMirrorManager()
 		if err != nil {
 			return fmt.Errorf("无法创建镜像管理器: %v", err)
 		}
 
+		// 测试全部镜像源并切换到最快的可用镜像源
+		if switchFastest {
+			return testAndSwitchFastest(mm, internal.ToolType(toolType), timeout)
+		}
+
 		// 如果指定了移除无效 key 的选项
 		if removeInvalid || removeAllInvalid {
-			return testAndRemoveInvalidKeys(mm, allMirrors, removeAllInvalid, timeout)
+			return testAndRemoveInvalidKeys(mm, allMirrors, removeAllInvalid, timeout, retries)
 		}
 
 		// 如果没有指定镜像名且没有 --all，测试当前激活的镜像
 		if len(args) == 0 && !allMirrors {
-			var currentMirror *internal.MirrorConfig
-			switch {
-			case mm.GetConfig().CurrentClaude != "":
-				currentMirror, _ = mm.GetCurrentClaudeMirror()
-			default:
-				currentMirror, _ = mm.GetCurrentCodexMirror()
-			}
-			if currentMirror != nil {
-				return testMirror(mm, currentMirror, timeout)
+			if currentMirror := currentActiveMirror(mm); currentMirror != nil {
+				return testMirror(mm, currentMirror, timeout, retries, asJSON)
 			}
 			return fmt.Errorf("未找到当前激活的镜像源，请使用 'codex-mirror switch' 先切换")
 		}
 
 		// 测试所有镜像源
 		if allMirrors {
-			return testAllMirrors(mm, parallel, timeout)
+			return testAllMirrors(mm, tag, parallel, timeout, retries, maxConcurrency, asJSON)
 		}
 
 		// 测试指定镜像源
 		mirror, err := mm.GetMirrorByName(args[0])
 		if err != nil {
-			return fmt.Errorf("镜像源 '%s' 不存在", args[0])
+			return err
 		}
-		return testMirror(mm, mirror, timeout)
+		return testMirror(mm, mirror, timeout, retries, asJSON)
 	},
 }
 
 // TestResult 测试结果.
-type TestResult struct {
-	Name         string            `json:"name"`
-	URL          string            `json:"url"`
-	ToolType     internal.ToolType `json:"tool_type"`
-	Success      bool              `json:"success"`
-	Latency      int64             `json:"latency_ms"` // 改为 int64 毫秒
-	StatusCode   int               `json:"status_code,omitempty"`
-	Error        string            `json:"error,omitempty"`
-	HasAPIKey    bool              `json:"has_api_key"`
-	NetworkError bool              `json:"network_error,omitempty"` // 新增字段区分网络错误
-}
+type TestResult = internal.TestResult
 
 // OpenAIModelsResponse OpenAI models API 响应.
-type OpenAIModelsResponse struct {
-	Data   []interface{} `json:"data"`
-	Object string        `json:"object"`
-}
+type OpenAIModelsResponse = internal.OpenAIModelsResponse
 
 // AnthropicMessagesResponse Anthropic messages API 响应 (错误时).
-type AnthropicMessagesResponse struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
+type AnthropicMessagesResponse = internal.AnthropicMessagesResponse
+
+// explicitTimeoutCap 命令行显式指定 --timeout 时返回该值作为超时上限，否则返回 0.
+func explicitTimeoutCap(cmd *cobra.Command, timeout int) int {
+	if cmd.Flags().Changed("timeout") {
+		return timeout
+	}
+	return 0
+}
+
+// currentActiveMirror 返回当前激活的镜像源，Claude 和 Codex 都已激活时优先 Claude，没有时返回 nil.
+func currentActiveMirror(mm *internal.MirrorManager) *internal.MirrorConfig {
+	var mirror *internal.MirrorConfig
+	switch {
+	case mm.GetConfig().CurrentClaude != "":
+		mirror, _ = mm.GetCurrentClaudeMirror()
+	default:
+		mirror, _ = mm.GetCurrentCodexMirror()
+	}
+	return mirror
 }
 
 func init() {
 	testCmd.Flags().BoolP("all", "a", false, "测试所有镜像源")
 	testCmd.Flags().BoolP("parallel", "p", false, "并行测试所有镜像源 (与 --all 配合使用)")
-	testCmd.Flags().IntP("timeout", "t", 10, "超时时间（秒）")
+	testCmd.Flags().IntP("timeout", "t", internal.DefaultTestTimeout, "超时时间（秒）")
+	testCmd.Flags().Int("retries", defaultTestRetries, "网络错误时的重试次数")
+	testCmd.Flags().Int("max-concurrency", defaultMaxConcurrency, "并行测试时的最大并发数 (与 --parallel 配合使用)")
+	testCmd.Flags().String("tag", "", "只测试带有指定标签的镜像源 (与 --all 配合使用)")
+	_ = testCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	testCmd.Flags().Bool("json", false, "以 JSON 格式输出测试结果")
+	testCmd.Flags().Bool("switch-fastest", false, "测试所有镜像源后切换到延迟最低的可用镜像源")
+	testCmd.Flags().String("type", "", "与 --switch-fastest 配合使用的工具类型 (codex|claude)，默认每种类型分别切换")
 	testCmd.Flags().Bool("remove-invalid", false, "测试后移除无效的 API Key (仅移除已失效的)")
-	testCmd.Flags().Bool("remove-all-invalid", false, "测试后移除所有无效的 API Key (包括认证失败)")
+	testCmd.Flags().Bool("remove-all-invalid", false, "测试后移除所有无效的 API Key (包括连接失败和 403，限流和 5xx 除外)")
 	rootCmd.AddCommand(testCmd)
 }
 
 // testMirror 测试单个镜像源.
-func testMirror(_ *internal.MirrorManager, mirror *internal.MirrorConfig, timeout int) error {
-	result := &TestResult{
-		Name:      mirror.Name,
-		URL:       mirror.BaseURL,
-		ToolType:  mirror.ToolType,
-		HasAPIKey: mirror.APIKey != "",
-	}
-
-	startTime := time.Now()
-
-	// 测试基础连通性
-	reachable, statusCode, err := testConnectivity(mirror, timeout)
-	result.Latency = time.Since(startTime).Milliseconds()
-	result.StatusCode = statusCode
-
-	if err != nil {
-		// 网络错误
-		result.Success = false
-		result.NetworkError = true
-		result.Error = fmt.Sprintf("连接失败: %v", err)
-		printTestResult(result)
-		return nil
-	}
-
-	// 网络可达，根据状态码判断
-	if !reachable {
-		// 理论上不应该走到这里，因为 testConnectivity 已处理
-		result.Success = false
-		result.NetworkError = true
-		result.Error = "网络不可达"
-		printTestResult(result)
-		return nil
-	}
-
-	// 网络可达，判断 HTTP 状态码
-	if statusCode == 200 {
-		result.Success = true
-		result.NetworkError = false
-		printTestResult(result)
+func testMirror(mm *internal.MirrorManager, mirror *internal.MirrorConfig, timeout, retries int, asJSON bool) error {
+	result := runTest(mm, mirror, timeout, retries)
+	cacheTestedModels(mm, []*TestResult{result})
+	if asJSON {
+		PrintResultsAsJSON([]*TestResult{result})
 		return nil
 	}
-
-	if statusCode == 401 {
-		result.Success = false
-		result.NetworkError = false
-		if mirror.APIKey != "" {
-			result.Error = "API Key 无效 (401)"
-		} else {
-			result.Error = needAPIKey401Msg
-		}
-		printTestResult(result)
-		return nil
-	}
-
-	// 其他非 200 状态码
-	result.Success = false
-	result.NetworkError = false
-	result.Error = fmt.Sprintf("HTTP %d", statusCode)
 	printTestResult(result)
-
 	return nil
 }
 
 // testAllMirrors 测试所有镜像源.
-func testAllMirrors(mm *internal.MirrorManager, parallel bool, timeout int) error {
-	mirrors := mm.ListActiveMirrors()
+func testAllMirrors(mm *internal.MirrorManager, tag string, parallel bool, timeout, retries, maxConcurrency int, asJSON bool) error {
+	mirrors := mm.ListByTag(tag)
 
 	if len(mirrors) == 0 {
+		if tag != "" {
+			return fmt.Errorf("没有带标签 '%s' 的镜像源", tag)
+		}
 		return fmt.Errorf("未配置任何镜像源")
 	}
 
-	fmt.Printf("🧪 开始测试 %d 个镜像源...\n\n", len(mirrors))
+	// JSON 模式下先收集全部结果，只输出纯 JSON
+	if asJSON {
+		var results []*TestResult
+		if parallel {
+			results = runTestsConcurrently(mm, mirrors, timeout, retries, maxConcurrency)
+		} else {
+			results = make([]*TestResult, 0, len(mirrors))
+			for i := range mirrors {
+				results = append(results, runTest(mm, &mirrors[i], timeout, retries))
+			}
+		}
+		cacheTestedModels(mm, results)
+		PrintResultsAsJSON(results)
+		return nil
+	}
+
+	internal.LogInfof("🧪 开始测试 %d 个镜像源...\n\n", len(mirrors))
 
 	var results []*TestResult
 
 	if parallel {
-		// 并行测试
-		resultCh := make(chan *TestResult, len(mirrors))
-		for i := range mirrors {
-			mirror := &mirrors[i] // Create pointer to avoid race condition
-			go func() {
-				result := runTest(mm, mirror, timeout)
-				resultCh <- result
-			}()
-		}
-
-		for i := 0; i < len(mirrors); i++ {
-			results = append(results, <-resultCh)
+		// 并行测试，结果按镜像源顺序输出
+		results = runTestsConcurrently(mm, mirrors, timeout, retries, maxConcurrency)
+		for _, result := range results {
+			printTestResult(result)
+			fmt.Println()
 		}
 	} else {
 		// 顺序测试
 		for i := range mirrors {
-			result := runTest(mm, &mirrors[i], timeout)
+			result := runTest(mm, &mirrors[i], timeout, retries)
 			results = append(results, result)
 			printTestResult(result)
 			fmt.Println()
 		}
 	}
 
+	cacheTestedModels(mm, results)
+
 	// 汇总统计
 	successCount := 0
 	for _, r := range results {
@@ -251,115 +246,35 @@ func testAllMirrors(mm *internal.MirrorManager, parallel bool, timeout int) erro
 	return nil
 }
 
-// runTest 执行测试（供并行调用）.
-func runTest(_ *internal.MirrorManager, mirror *internal.MirrorConfig, timeout int) *TestResult {
-	result := &TestResult{
-		Name:      mirror.Name,
-		URL:       mirror.BaseURL,
-		ToolType:  mirror.ToolType,
-		HasAPIKey: mirror.APIKey != "",
-	}
-
-	startTime := time.Now()
-
-	// 测试基础连通性
-	reachable, statusCode, err := testConnectivity(mirror, timeout)
-	result.Latency = time.Since(startTime).Milliseconds()
-	result.StatusCode = statusCode
-
-	// 网络错误
-	if err != nil {
-		result.Success = false
-		result.NetworkError = true
-		result.Error = fmt.Sprintf("连接失败: %v", err)
-		return result
-	}
-
-	// 网络不可达（防御性检查）
-	if !reachable {
-		result.Success = false
-		result.NetworkError = true
-		result.Error = "网络不可达"
-		return result
-	}
-
-	// 根据状态码判断
-	switch statusCode {
-	case 200:
-		result.Success = true
-		result.NetworkError = false
-	case 401:
-		result.Success = false
-		result.NetworkError = false
-		if mirror.APIKey != "" {
-			result.Error = "API Key 无效 (401)"
-		} else {
-			result.Error = "需要 API Key (401)"
-		}
-	default:
-		result.Success = false
-		result.NetworkError = false
-		result.Error = fmt.Sprintf("HTTP %d", statusCode)
-	}
-
-	return result
+// newConnectivityTester 创建命令行使用的连通性测试器，探测逻辑与 GUI、TUI 共用 internal.ConnectivityTester.
+func newConnectivityTester() *internal.ConnectivityTester {
+	return &internal.ConnectivityTester{Transport: testTransport, RetryBackoff: testRetryBackoff, MaxTimeout: testTimeoutCap}
 }
 
-// testConnectivity 测试基础连通性（不验证认证）.
-// 返回: reachable (网络是否可达), statusCode (HTTP 状态码), err (错误).
-// 注意: statusCode 仅在网络可达时有效.
-func testConnectivity(mirror *internal.MirrorConfig, timeout int) (reachable bool, statusCode int, err error) {
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
-	}
-
-	// 测试端点 - Claude 用 messages, Codex 用 models
-	var testURL string
-	switch mirror.ToolType {
-	case internal.ToolTypeClaude:
-		testURL = strings.TrimSuffix(mirror.BaseURL, "/") + "/v1/messages"
-	default:
-		testURL = strings.TrimSuffix(mirror.BaseURL, "/") + "/v1/models"
-	}
+// runTestsConcurrently 使用有限的并发数测试镜像源，结果顺序与 mirrors 一致.
+func runTestsConcurrently(_ *internal.MirrorManager, mirrors []internal.MirrorConfig, timeout, retries, maxConcurrency int) []*TestResult {
+	return newConnectivityTester().TestAll(mirrors, timeout, retries, maxConcurrency)
+}
 
-	// Claude API 必须用 POST，其他用 GET
-	var req *http.Request
-	var httpErr error
+// runTest 执行测试，仅在网络错误时按 retries 重试（401 等明确的 HTTP 结果不重试）.
+func runTest(_ *internal.MirrorManager, mirror *internal.MirrorConfig, timeout, retries int) *TestResult {
+	return newConnectivityTester().Test(mirror, timeout, retries)
+}
 
-	if mirror.ToolType == internal.ToolTypeClaude {
-		// Claude: 发送最小化的 POST 请求
-		body := `{"model": "claude-sonnet-4-20250514", "max_tokens": 1, "messages": [{"role": "user", "content": "test"}]}`
-		req, httpErr = http.NewRequest("POST", testURL, bytes.NewBufferString(body))
-		if httpErr != nil {
-			return false, 0, httpErr
-		}
-		req.Header.Set("Content-Type", "application/json")
-		// 如果有 key 就加上，没有也没关系
-		if mirror.APIKey != "" {
-			req.Header.Set("x-api-key", mirror.APIKey)
-		}
-		req.Header.Set("anthropic-version", "2023-06-01")
-	} else {
-		// Codex/OpenAI: 使用 GET 请求
-		req, httpErr = http.NewRequest("GET", testURL, http.NoBody)
-		if httpErr != nil {
-			return false, 0, httpErr
-		}
-		if mirror.APIKey != "" {
-			req.Header.Set("Authorization", "Bearer "+mirror.APIKey)
+// cacheTestedModels 缓存测试结果中返回的模型列表，供切换时校验模型名称.
+func cacheTestedModels(mm *internal.MirrorManager, results []*TestResult) {
+	models := make(map[string][]string)
+	for _, r := range results {
+		if len(r.Models) > 0 {
+			models[r.Name] = r.Models
 		}
 	}
-
-	resp, httpErr := client.Do(req)
-	if httpErr != nil {
-		return false, 0, httpErr
+	if len(models) == 0 {
+		return
+	}
+	if err := mm.CacheMirrorModels(models); err != nil {
+		internal.LogWarnf("⚠️  缓存模型列表失败: %v\n", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	// 网络成功到达，返回状态码和响应体（可能用于错误信息）.
-	// 所有 HTTP 状态码都视为网络可达，由调用方判断语义.
-	// 注意：这里不返回错误，只用于状态码判断.
-	return true, resp.StatusCode, nil
 }
 
 // printTestResult 打印测试结果.
@@ -383,10 +298,18 @@ func printTestResult(result *TestResult) {
 		fmt.Printf("   延迟: %dms\n", result.Latency)
 	}
 
+	if result.Attempts > 1 {
+		fmt.Printf("   尝试次数: %d\n", result.Attempts)
+	}
+
 	if result.StatusCode > 0 {
 		fmt.Printf("   HTTP 状态: %d\n", result.StatusCode)
 	}
 
+	if result.Models != nil {
+		fmt.Printf("   可用模型: %d 个\n", result.ModelCount)
+	}
+
 	if result.Error != "" {
 		fmt.Printf("   错误: %s\n", result.Error)
 	}
@@ -396,23 +319,71 @@ func printTestResult(result *TestResult) {
 	}
 }
 
+// testAndSwitchFastest 测试所有镜像源，并为每种工具类型切换到延迟最低的可用镜像源.
+func testAndSwitchFastest(mm *internal.MirrorManager, toolType internal.ToolType, timeout int) error {
+	types := []internal.ToolType{internal.ToolTypeCodex, internal.ToolTypeClaude}
+	switch toolType {
+	case "":
+	case internal.ToolTypeCodex, internal.ToolTypeClaude:
+		types = []internal.ToolType{toolType}
+	default:
+		return fmt.Errorf("无效的工具类型 '%s'，支持: %s, %s", toolType, internal.ToolTypeCodex, internal.ToolTypeClaude)
+	}
+
+	internal.LogInfof("🧪 开始测试镜像源...\n\n")
+	results := GetTestResultsFromAll(mm, timeout)
+	for _, result := range results {
+		if toolType == "" || result.ToolType == toolType {
+			printTestResult(result)
+			fmt.Println()
+		}
+	}
+
+	for _, t := range types {
+		fastest := selectFastestMirror(results, t)
+		if fastest == nil {
+			internal.LogWarnf("⚠️  没有可用的 %s 镜像源，跳过切换\n", t)
+			continue
+		}
+
+		mirror, err := mm.GetMirrorByName(fastest.Name)
+		if err != nil {
+			return fmt.Errorf("获取镜像源配置失败: %w", err)
+		}
+
+		internal.LogInfof("⚡ 延迟最低的 %s 镜像源: %s (%dms)\n", t, mirror.Name, fastest.Latency)
+		if err := applyMirrorAndSwitch(mm, mirror); err != nil {
+			return err
+		}
+		internal.LogInfof("✅ 已切换到镜像源 '%s'\n\n", mirror.Name)
+	}
+
+	return nil
+}
+
+// selectFastestMirror 从测试结果中选出指定工具类型下延迟最低的成功镜像源，没有则返回 nil.
+func selectFastestMirror(results []*TestResult, toolType internal.ToolType) *TestResult {
+	var fastest *TestResult
+	for _, r := range results {
+		if !r.Success || r.ToolType != toolType {
+			continue
+		}
+		if fastest == nil || r.Latency < fastest.Latency {
+			fastest = r
+		}
+	}
+	return fastest
+}
+
 // PrintResultsAsJSON 将结果打印为 JSON 格式.
 func PrintResultsAsJSON(results []*TestResult) {
 	data, _ := json.MarshalIndent(results, "", "  ")
 	fmt.Println(string(data))
 }
 
-// GetTestResultsFromAll 测试所有镜像源并返回结果（供程序使用）.
+// GetTestResultsFromAll 以有限的并发数测试所有镜像源并返回结果（供程序使用），结果顺序与镜像源列表一致.
 func GetTestResultsFromAll(mm *internal.MirrorManager, timeout int) []*TestResult {
-	mirrors := mm.ListActiveMirrors()
-	results := make([]*TestResult, 0, len(mirrors))
-
-	for i := range mirrors {
-		result := runTest(mm, &mirrors[i], timeout)
-		results = append(results, result)
-	}
-
-	return results
+	return runTestsConcurrently(mm, mm.ListActiveMirrors(), timeout, defaultTestRetries, defaultMaxConcurrency)
 }
 
 // IsAnyMirrorReachable 检查是否有任何镜像源可达.
@@ -427,7 +398,7 @@ func IsAnyMirrorReachable(mm *internal.MirrorManager, timeout int) bool {
 }
 
 // testAndRemoveInvalidKeys 测试并移除无效的 API Key.
-func testAndRemoveInvalidKeys(mm *internal.MirrorManager, testAll, removeAll bool, timeout int) error {
+func testAndRemoveInvalidKeys(mm *internal.MirrorManager, testAll, removeAll bool, timeout, retries int) error {
 	var mirrors []internal.MirrorConfig
 
 	if testAll {
@@ -451,7 +422,7 @@ func testAndRemoveInvalidKeys(mm *internal.MirrorManager, testAll, removeAll boo
 		return fmt.Errorf("未配置任何镜像源")
 	}
 
-	fmt.Printf("🔍 开始测试并清理无效 API Key...\n\n")
+	internal.LogInfof("🔍 开始测试并清理无效 API Key...\n\n")
 
 	var removedKeys []string
 	var invalidMirrors []string
@@ -464,7 +435,7 @@ func testAndRemoveInvalidKeys(mm *internal.MirrorManager, testAll, removeAll boo
 
 		fmt.Printf("测试: %s (%s)\n", mirror.Name, mirror.ToolType)
 
-		result := runTest(mm, mirror, timeout)
+		result := runTest(mm, mirror, timeout, retries)
 
 		if result.Success {
 			fmt.Printf("   ✅ API Key 有效\n\n")
@@ -475,11 +446,14 @@ func testAndRemoveInvalidKeys(mm *internal.MirrorManager, testAll, removeAll boo
 			shouldRemove := false
 			reason := ""
 
+			// 限流 (429) 和上游异常 (5xx) 不代表密钥失效，即使 --remove-all-invalid 也不移除
 			switch {
-			case strings.Contains(result.Error, "401") || strings.Contains(result.Error, "Unauthorized"):
+			case result.Category == internal.TestCategoryUnauthorized:
 				shouldRemove = true
 				reason = "API Key 已失效 (401)"
-			case strings.Contains(result.Error, "连接失败"):
+			case result.Category.Transient():
+				reason = "暂时性故障 (跳过，密钥可能仍然有效)"
+			case result.Category == internal.TestCategoryNetwork:
 				if removeAll {
 					shouldRemove = true
 					reason = "连接失败 (移除全部无效)"
@@ -530,7 +504,7 @@ func testAndRemoveInvalidKeys(mm *internal.MirrorManager, testAll, removeAll boo
 	}
 
 	if len(invalidMirrors) > 0 && len(removedKeys) < len(invalidMirrors) {
-		fmt.Println("\n⏭️  跳过的镜像源 (连接失败):")
+		fmt.Println("\n⏭️  跳过的镜像源 (连接失败、限流或上游异常等):")
 		for _, name := range invalidMirrors {
 			found := false
 			for _, r := range removedKeys {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"codex-mirror/internal"
 
@@ -10,10 +11,25 @@ import (
 
 // update 命令的标志.
 var (
-	updateURL   string
-	updateKey   string
-	updateModel string
-	updateType  string
+	updateURL        string
+	updateKey        string
+	updateKeyCommand string
+	updateModel      string
+	updateType       string
+	updateProxy      string
+	updateHealthPath string
+
+	updateTestHeaders      []string
+	updateClearTestHeaders bool
+
+	updateTags      []string
+	updateClearTags bool
+
+	updateNoValidateURL bool
+
+	updateResponseStorage string
+
+	updateTimeout int
 )
 
 // updateCmd 代表 update 命令.
@@ -24,9 +40,22 @@ var updateCmd = &cobra.Command{
 
 可更新的字段：
   --url    API 基础 URL
-  --key    API 密钥
+  --key    API 密钥 (同时清除密钥命令)
+  --api-key-command  获取 API 密钥的命令 (清空保存的密钥，传入空字符串清除命令)
+  --api-key-stdin  从标准输入读取一行作为 API 密钥 (与 --key 互斥)
+  --api-key-file   从文件读取 API 密钥 (与 --key 互斥)
   --model  模型名称
+  --haiku-model/--sonnet-model/--opus-model  各级别使用的模型 (仅 Claude，传入空字符串清除)
   --type   工具类型 (codex|claude)
+  --proxy  HTTP 代理地址 (传入空字符串清除代理)
+  --tag    分组标签 (可多次使用，替换原有标签)
+  --clear-tags  清除所有标签
+  --health-path  连通性测试路径 (传入空字符串恢复默认探测端点)
+  --timeout  连通性测试超时时间 (秒，0 表示使用 test/doctor 的默认超时)
+  --test-header  连通性测试附加的请求头 (格式: KEY=VALUE，可多次使用，替换原有设置)
+  --clear-test-headers  清除所有测试请求头
+  --no-validate-url  跳过 URL 格式校验 (用于特殊的内网地址)
+  --response-storage  Codex 响应存储 (enable|disable|default)，default 恢复默认的 disable_response_storage = true
 
 注意：
 - 至少需要指定一个要更新的字段
@@ -36,7 +65,17 @@ var updateCmd = &cobra.Command{
   codex-mirror update myapi --url https://new-api.example.com
   codex-mirror update myapi --key sk-new-key
   codex-mirror update myapi --url https://api.example.com --key sk-key
-  codex-mirror update myclaude --model claude-3-5-sonnet-20241022`,
+  codex-mirror update myapi --api-key-command "op read op://vault/myapi/key"
+  pass show ai/myapi | codex-mirror update myapi --api-key-stdin
+  codex-mirror update myapi --api-key-file ~/.secrets/myapi-key
+  codex-mirror update myclaude --model claude-3-5-sonnet-20241022
+  codex-mirror update myclaude --haiku-model gemini-2.5-flash-lite --opus-model ""
+  codex-mirror update myapi --proxy http://127.0.0.1:7890
+  codex-mirror update myapi --proxy ""
+  codex-mirror update myapi --test-header X-Org-Id=org-123
+  codex-mirror update slowapi --timeout 30
+  codex-mirror update myapi --tag work --tag cheap
+  codex-mirror update myapi --response-storage enable`,
 	Args: cobra.ExactArgs(1),
 	RunE: runUpdateCommand,
 }
@@ -45,15 +84,50 @@ var updateCmd = &cobra.Command{
 func runUpdateCommand(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
+	// --proxy 允许传入空字符串以清除代理
+	proxyChanged := cmd.Flags().Changed("proxy")
+	keyCommandChanged := cmd.Flags().Changed("api-key-command")
+	healthPathChanged := cmd.Flags().Changed("health-path")
+	headersChanged := len(updateTestHeaders) > 0 || updateClearTestHeaders
+	tagsChanged := len(updateTags) > 0 || updateClearTags
+	storageChanged := cmd.Flags().Changed("response-storage")
+	timeoutChanged := cmd.Flags().Changed("timeout")
+
+	// 各级别模型同样允许传入空字符串以清除
+	tierModels := make(map[internal.ModelTier]string)
+	for _, tier := range internal.ModelTiers {
+		if flag := tierModelFlag(tier); cmd.Flags().Changed(flag) {
+			tierModels[tier], _ = cmd.Flags().GetString(flag)
+		}
+	}
+
+	// 从标准输入或文件读取密钥，与 --key 互斥由标志定义保证
+	apiKey := updateKey
+	inputKey, fromInput, err := readAPIKeyInput(cmd)
+	if err != nil {
+		return err
+	}
+	if fromInput {
+		apiKey = inputKey
+	}
+
 	// 检查是否有任何更新
-	if updateURL == "" && updateKey == "" && updateModel == "" && updateType == "" {
-		return fmt.Errorf("请至少指定一个要更新的字段 (--url, --key, --model, --type)")
+	if updateURL == "" && apiKey == "" && updateModel == "" && updateType == "" && !keyCommandChanged && !proxyChanged && !healthPathChanged && !headersChanged && !tagsChanged && !storageChanged && !timeoutChanged && len(tierModels) == 0 {
+		return fmt.Errorf("请至少指定一个要更新的字段 (--url, --key, --api-key-command, --model, --type, --proxy, --health-path, --timeout, --test-header, --tag, --haiku-model, --sonnet-model, --opus-model, --response-storage)")
+	}
+	if timeoutChanged && updateTimeout < 0 {
+		return fmt.Errorf("--timeout 不能为负数")
+	}
+
+	testHeaders, err := parseTestHeaders(updateTestHeaders)
+	if err != nil {
+		return err
 	}
 
-	// 验证 URL 格式
-	if updateURL != "" {
-		if err := internal.ValidateBaseURL(updateURL); err != nil {
-			return fmt.Errorf("无效的 API 地址: %v", err)
+	var disableStorage *bool
+	if storageChanged {
+		if disableStorage, err = parseResponseStorage(updateResponseStorage); err != nil {
+			return err
 		}
 	}
 
@@ -71,18 +145,59 @@ func runUpdateCommand(cmd *cobra.Command, args []string) error {
 	// 检查镜像源是否存在
 	mirror, err := mm.GetMirrorByName(name)
 	if err != nil {
-		return fmt.Errorf("镜像源 '%s' 不存在", name)
+		return err
 	}
 
 	// 不能更新官方镜像源
-	if name == internal.DefaultMirrorName {
+	if mm.IsOfficialMirror(name) {
 		return fmt.Errorf("不能更新官方镜像源")
 	}
 
-	// 更新镜像源
-	if err := mm.UpdateMirrorFull(name, updateURL, updateKey, updateModel, updateType); err != nil {
+	// 更新镜像源（默认校验并规范化 URL）
+	mm.SetURLValidation(!updateNoValidateURL)
+	if err := mm.UpdateMirrorFull(name, updateURL, apiKey, updateModel, updateType); err != nil {
 		return fmt.Errorf("更新镜像源失败: %w", err)
 	}
+	for tier, model := range tierModels {
+		if err := mm.SetMirrorTierModel(name, tier, model); err != nil {
+			return fmt.Errorf("更新 %s 模型失败: %w", tier, err)
+		}
+	}
+	if keyCommandChanged {
+		if err := mm.SetMirrorAPIKeyCommand(name, updateKeyCommand); err != nil {
+			return fmt.Errorf("更新密钥命令失败: %w", err)
+		}
+	}
+	if proxyChanged {
+		if err := mm.SetMirrorProxy(name, updateProxy); err != nil {
+			return fmt.Errorf("更新代理失败: %w", err)
+		}
+	}
+	if healthPathChanged {
+		if err := mm.SetMirrorHealthPath(name, updateHealthPath); err != nil {
+			return fmt.Errorf("更新测试路径失败: %w", err)
+		}
+	}
+	if timeoutChanged {
+		if err := mm.SetMirrorTimeout(name, updateTimeout); err != nil {
+			return fmt.Errorf("更新测试超时时间失败: %w", err)
+		}
+	}
+	if headersChanged {
+		if err := mm.SetMirrorTestHeaders(name, testHeaders); err != nil {
+			return fmt.Errorf("更新测试请求头失败: %w", err)
+		}
+	}
+	if tagsChanged {
+		if err := mm.SetMirrorTags(name, updateTags); err != nil {
+			return fmt.Errorf("更新标签失败: %w", err)
+		}
+	}
+	if storageChanged {
+		if err := mm.SetMirrorResponseStorage(name, disableStorage); err != nil {
+			return fmt.Errorf("更新响应存储设置失败: %w", err)
+		}
+	}
 
 	fmt.Printf("成功更新镜像源 '%s'\n", name)
 
@@ -92,11 +207,36 @@ func runUpdateCommand(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  类型: %s\n", updatedMirror.ToolType)
 		fmt.Printf("  URL: %s\n", updatedMirror.BaseURL)
 		if updatedMirror.APIKey != "" {
-			fmt.Printf("  API密钥: %s\n", maskAPIKey(updatedMirror.APIKey))
+			fmt.Printf("  API密钥: %s\n", internal.MaskAPIKey(updatedMirror.APIKey))
+		}
+		if updatedMirror.APIKeyCommand != "" {
+			fmt.Printf("  密钥命令: %s\n", updatedMirror.APIKeyCommand)
 		}
 		if updatedMirror.ModelName != "" {
 			fmt.Printf("  模型: %s\n", updatedMirror.ModelName)
 		}
+		printTierModels(updatedMirror)
+		if updatedMirror.Proxy != "" {
+			fmt.Printf("  代理: %s\n", internal.RedactURL(updatedMirror.Proxy))
+		}
+		if len(updatedMirror.Tags) > 0 {
+			fmt.Printf("  标签: %s\n", strings.Join(updatedMirror.Tags, ", "))
+		}
+		if updatedMirror.HealthPath != "" {
+			fmt.Printf("  测试路径: %s\n", updatedMirror.HealthPath)
+		}
+		if updatedMirror.TimeoutSeconds > 0 {
+			fmt.Printf("  测试超时: %d 秒\n", updatedMirror.TimeoutSeconds)
+		}
+		if updatedMirror.DisableResponseStorage != nil {
+			fmt.Printf("  disable_response_storage: %t\n", *updatedMirror.DisableResponseStorage)
+		}
+		if len(updatedMirror.TestHeaders) > 0 {
+			fmt.Println("  测试请求头:")
+			for key, value := range updatedMirror.TestHeaders {
+				fmt.Printf("    %s: %s\n", key, displayEnvValue(key, value))
+			}
+		}
 	}
 
 	// 提示是否需要重新应用
@@ -113,7 +253,39 @@ func runUpdateCommand(cmd *cobra.Command, args []string) error {
 func init() {
 	updateCmd.Flags().StringVar(&updateURL, "url", "", "API 基础 URL")
 	updateCmd.Flags().StringVar(&updateKey, "key", "", "API 密钥")
+	updateCmd.Flags().StringVar(&updateKeyCommand, "api-key-command", "", "获取 API 密钥的命令 (空字符串表示清除)")
+	registerAPIKeyInputFlags(updateCmd)
+	updateCmd.MarkFlagsMutuallyExclusive("key", "api-key-command", "api-key-stdin", "api-key-file")
 	updateCmd.Flags().StringVar(&updateModel, "model", "", "模型名称")
+	registerTierModelFlags(updateCmd)
 	updateCmd.Flags().StringVar(&updateType, "type", "", "工具类型 (codex|claude)")
+	updateCmd.Flags().StringVar(&updateProxy, "proxy", "", "HTTP 代理地址 (空字符串表示清除)")
+	updateCmd.Flags().StringArrayVar(&updateTags, "tag", nil, "分组标签 (可多次使用，替换原有标签)")
+	updateCmd.Flags().BoolVar(&updateClearTags, "clear-tags", false, "清除所有标签")
+	_ = updateCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	updateCmd.MarkFlagsMutuallyExclusive("tag", "clear-tags")
+	updateCmd.Flags().StringVar(&updateHealthPath, "health-path", "", "连通性测试路径 (空字符串表示恢复默认)")
+	updateCmd.Flags().IntVar(&updateTimeout, "timeout", 0, "连通性测试超时时间（秒），0 表示使用默认超时")
+	updateCmd.Flags().StringArrayVar(&updateTestHeaders, "test-header", nil, "连通性测试附加的请求头 (格式: KEY=VALUE，可多次使用)")
+	updateCmd.Flags().BoolVar(&updateClearTestHeaders, "clear-test-headers", false, "清除所有测试请求头")
+	updateCmd.MarkFlagsMutuallyExclusive("test-header", "clear-test-headers")
+	updateCmd.Flags().BoolVar(&updateNoValidateURL, "no-validate-url", false, "跳过 URL 格式校验")
+	updateCmd.Flags().StringVar(&updateResponseStorage, "response-storage", "", "Codex 响应存储 (enable|disable|default)")
 	rootCmd.AddCommand(updateCmd)
 }
+
+// parseResponseStorage 解析 --response-storage 参数，返回 disable_response_storage 的值，default 返回 nil.
+func parseResponseStorage(value string) (*bool, error) {
+	var disable bool
+	switch value {
+	case "enable":
+		disable = false
+	case "disable":
+		disable = true
+	case "default":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("无效的响应存储设置 '%s'，支持: enable, disable, default", value)
+	}
+	return &disable, nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// useCmd 代表use命令.
+var useCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "输出指定镜像源的环境变量（不修改任何配置）",
+	Long: `输出指定镜像源的 shell 环境变量导出语句，仅对当前 shell 会话生效。
+
+与 switch 不同，use 不会修改 mirrors.toml、Codex/Claude/VS Code 配置文件
+或 shell 配置文件，适合在 CI 或临时会话中使用。
+
+示例：
+  eval "$(codex-mirror use myclaude)"
+  codex-mirror use mycodex --shell fish | source
+  eval "$(codex-mirror use proxy --type claude)"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUseCommand,
+}
+
+// runUseCommand 执行use命令的实际逻辑.
+func runUseCommand(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	toolType, _ := cmd.Flags().GetString("type")
+	shell, _ := cmd.Flags().GetString("shell")
+
+	if toolType != "" && toolType != string(internal.ToolTypeCodex) && toolType != string(internal.ToolTypeClaude) {
+		return fmt.Errorf("无效的工具类型 '%s'，支持: %s, %s", toolType, internal.ToolTypeCodex, internal.ToolTypeClaude)
+	}
+	if shell == "" {
+		shell = detectShell(internal.GetCurrentPlatform())
+	}
+
+	mm, err := internal.NewMirrorManager()
+	if err != nil {
+		return fmt.Errorf("初始化失败: %w", err)
+	}
+
+	var mirror *internal.MirrorConfig
+	mirrors := mm.ListActiveMirrors()
+	for i := range mirrors {
+		if mirrors[i].Name == name && (toolType == "" || string(mirrors[i].ToolType) == toolType) {
+			mirror = &mirrors[i]
+			break
+		}
+	}
+	if mirror == nil {
+		if toolType != "" {
+			return fmt.Errorf("%s 镜像源 '%s' 不存在", toolType, name)
+		}
+		return fmt.Errorf("镜像源 '%s' 不存在", name)
+	}
+
+	vars, err := internal.MirrorEnvVars(mirror)
+	if err != nil {
+		return err
+	}
+
+	emitShellExports(vars, shell)
+	return nil
+}
+
+func init() {
+	useCmd.Flags().StringP("type", "t", "", "工具类型 (codex|claude)，用于区分同名镜像源")
+	useCmd.Flags().String("shell", "", "输出格式 (bash|zsh|fish|powershell|cmd)，默认自动检测")
+	rootCmd.AddCommand(useCmd)
+}
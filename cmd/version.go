@@ -1,45 +1,72 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"runtime"
 
-	"github.com/spf13/cobra"
-)
+	"codex-mirror/internal"
 
-// 版本信息变量，通过 ldflags 在构建时注入.
-// 构建命令示例:
-// go build -ldflags "-X codex-mirror/cmd.Version=1.2.0 -X codex-mirror/cmd.GitCommit=abc123 -X codex-mirror/cmd.BuildTime=2024-01-01T00:00:00Z" .
-var (
-	// Version 版本号.
-	Version = "dev"
-	// GitCommit Git 提交 hash.
-	GitCommit = "unknown"
-	// BuildTime 构建时间.
-	BuildTime = "unknown"
+	"github.com/spf13/cobra"
 )
 
 // versionCmd 代表 version 命令.
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "显示版本信息",
-	Long:  `显示 codex-mirror 的版本、构建时间和 Git 提交信息。`,
-	Run: func(cmd *cobra.Command, args []string) {
-		short, _ := cmd.Flags().GetBool("short")
-		if short {
-			fmt.Println(Version)
-			return
-		}
+	Long: `显示 codex-mirror 的版本、构建时间和 Git 提交信息。
+
+使用 --check 通过 GitHub Releases 检查是否有新版本（需要网络，最多等待数秒）。
+
+示例：
+  codex-mirror version
+  codex-mirror version --short
+  codex-mirror version --check`,
+	Args: cobra.NoArgs,
+	RunE: runVersionCommand,
+}
 
-		fmt.Printf("codex-mirror %s\n", Version)
-		fmt.Printf("  Git Commit: %s\n", GitCommit)
-		fmt.Printf("  Build Time: %s\n", BuildTime)
-		fmt.Printf("  Go Version: %s\n", runtime.Version())
-		fmt.Printf("  OS/Arch:    %s/%s\n", runtime.GOOS, runtime.GOARCH)
-	},
+// runVersionCommand 执行version命令的实际逻辑.
+func runVersionCommand(cmd *cobra.Command, args []string) error {
+	short, _ := cmd.Flags().GetBool("short")
+	check, _ := cmd.Flags().GetBool("check")
+
+	info := internal.GetBuildInfo()
+	if short {
+		fmt.Println(info.Version)
+	} else {
+		fmt.Printf("codex-mirror %s\n", info.Version)
+		fmt.Printf("  Git Commit: %s\n", info.GitCommit)
+		fmt.Printf("  Build Time: %s\n", info.BuildTime)
+		fmt.Printf("  Go Version: %s\n", info.GoVersion)
+		fmt.Printf("  OS/Arch:    %s\n", info.Platform)
+	}
+
+	if !check {
+		return nil
+	}
+
+	// 检查更新失败属于运行时错误，不需要打印用法
+	cmd.SilenceUsage = true
+
+	checker := &internal.UpdateChecker{}
+	update, err := checker.Check(context.Background())
+	if err != nil {
+		return fmt.Errorf("检查更新失败: %w", err)
+	}
+
+	if update.UpdateAvailable {
+		fmt.Printf("\n🆕 发现新版本 %s（当前 %s）\n", update.LatestVersion, update.CurrentVersion)
+		if update.ReleaseURL != "" {
+			fmt.Printf("   下载地址: %s\n", update.ReleaseURL)
+		}
+	} else {
+		fmt.Printf("\n✅ 已是最新版本 (%s)\n", update.LatestVersion)
+	}
+	return nil
 }
 
 func init() {
 	versionCmd.Flags().BoolP("short", "s", false, "只显示版本号")
+	versionCmd.Flags().Bool("check", false, "检查 GitHub 上是否有新版本")
 	rootCmd.AddCommand(versionCmd)
 }
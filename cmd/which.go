@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"codex-mirror/internal"
+
+	"github.com/spf13/cobra"
+)
+
+// whichCmd 代表which命令.
+var whichCmd = &cobra.Command{
+	Use:   "which",
+	Short: "显示所有受管理的配置文件路径",
+	Long: `显示本工具读写的所有配置文件路径及其是否存在，包括：
+- 镜像源配置 (mirrors.toml)
+- Codex CLI 配置 (config.toml) 和认证文件 (auth.json)
+- Claude Code 配置 (settings.json)
+- VS Code 配置 (settings.json)
+
+示例：
+  codex-mirror which
+  codex-mirror which --json`,
+	Args: cobra.NoArgs,
+	RunE: runWhichCommand,
+}
+
+// runWhichCommand 执行which命令的实际逻辑.
+func runWhichCommand(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	paths, err := internal.GetManagedPaths()
+	if err != nil {
+		return fmt.Errorf("获取配置路径失败: %w", err)
+	}
+
+	if asJSON {
+		result := make(map[string]internal.ManagedPath, len(paths))
+		for _, p := range paths {
+			result[p.Name] = p
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	for _, p := range paths {
+		indicator := "✅"
+		if !p.Exists {
+			indicator = "❌"
+		}
+		fmt.Printf("%s %-16s %s\n", indicator, p.Name, p.Path)
+	}
+
+	return nil
+}
+
+func init() {
+	whichCmd.Flags().Bool("json", false, "以 JSON 格式输出")
+	rootCmd.AddCommand(whichCmd)
+}
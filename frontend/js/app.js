@@ -28,6 +28,8 @@ function mirrorApp() {
         },
         loading: false,
         filterType: 'all',
+        version: '',
+        updateHint: '',
 
         // 表单状态
         showForm: false,
@@ -60,6 +62,8 @@ function mirrorApp() {
             message: ''
         },
         syncing: false,
+        syncProgress: { stage: '', message: '', percent: 0 },
+        testResults: {},
         showSyncModal: false,
         syncForm: {
             token: '',
@@ -71,9 +75,43 @@ function mirrorApp() {
 
         async init() {
             await waitForWails();
+            this.subscribeEvents();
             await this.refreshMirrors();
             await this.refreshStatus();
             await this.refreshSyncStatus();
+            await this.loadVersion();
+        },
+
+        // 加载版本信息，并在后台检查更新
+        async loadVersion() {
+            try {
+                const info = await window.go.main.App.GetBuildInfo();
+                this.version = info.version;
+            } catch (error) {
+                return;
+            }
+            window.go.main.App.CheckForUpdate().then((update) => {
+                if (update.update_available) {
+                    this.updateHint = `发现新版本 ${update.latest_version}`;
+                    this.showToast(`发现新版本 ${update.latest_version}`, 'success');
+                }
+            }).catch(() => {});
+        },
+
+        // 订阅后端事件：同步进度、冲突和测试结果
+        subscribeEvents() {
+            if (!window.runtime || !window.runtime.EventsOn) {
+                return;
+            }
+            window.runtime.EventsOn('sync:progress', (progress) => {
+                this.syncProgress = progress;
+            });
+            window.runtime.EventsOn('sync:conflict', (conflicts) => {
+                this.showToast(`检测到 ${conflicts.length} 个配置冲突，正在按所选策略解决`, 'success');
+            });
+            window.runtime.EventsOn('test:result', (result) => {
+                this.testResults = { ...this.testResults, [result.name]: result };
+            });
         },
 
         // 获取过滤后的镜像列表
@@ -314,6 +352,15 @@ function mirrorApp() {
             }
         },
 
+        // 在文件管理器中打开配置目录
+        async openConfigDir() {
+            try {
+                await window.go.main.App.OpenConfigDir();
+            } catch (error) {
+                this.showToast('打开配置目录失败: ' + error, 'error');
+            }
+        },
+
         // 确认删除
         confirmDeleteMirror(mirror) {
             this.deleteTarget = mirror;
@@ -368,7 +415,7 @@ function mirrorApp() {
         // 刷新同步状态
         async refreshSyncStatus() {
             try {
-                const status = await window.go.main.App.GetSyncStatus();
+                const status = await window.go.main.App.SyncStatus();
                 this.syncStatus = status;
             } catch (error) {
                 console.error('获取同步状态失败:', error);
@@ -464,12 +511,46 @@ function mirrorApp() {
             }
         },
 
+        // 检测云端冲突，有冲突时由用户选择解决策略，返回 null 表示取消
+        async chooseSyncStrategy() {
+            let conflicts = [];
+            try {
+                conflicts = await window.go.main.App.GetSyncConflicts();
+            } catch (error) {
+                // 云端暂无配置等情况交给推送/拉取自行处理
+                return 'auto';
+            }
+            if (!conflicts || conflicts.length === 0) {
+                return 'auto';
+            }
+
+            const details = conflicts.map(c => `- ${c.name}: ${c.description}`).join('\n');
+            const choice = prompt(
+                `检测到 ${conflicts.length} 个配置冲突:\n${details}\n\n请输入解决策略 (merge | local | remote)，取消则放弃操作:`,
+                'merge'
+            );
+            if (choice === null) {
+                return null;
+            }
+            const strategy = choice.trim().toLowerCase();
+            if (!['merge', 'local', 'remote'].includes(strategy)) {
+                this.showToast(`不支持的策略: ${choice}`, 'error');
+                return null;
+            }
+            return strategy;
+        },
+
         // 推送配置到云端
         async syncPush() {
             this.syncing = true;
+            this.syncProgress = { stage: '', message: '', percent: 0 };
             try {
-                const result = await window.go.main.App.SyncPush();
-                this.showToast(result, 'success');
+                const strategy = await this.chooseSyncStrategy();
+                if (strategy === null) {
+                    return;
+                }
+                await window.go.main.App.SyncPush(strategy);
+                this.showToast('配置已推送到云端', 'success');
                 await this.refreshSyncStatus();
                 await this.refreshMirrors();
             } catch (error) {
@@ -482,9 +563,14 @@ function mirrorApp() {
         // 从云端拉取配置
         async syncPull() {
             this.syncing = true;
+            this.syncProgress = { stage: '', message: '', percent: 0 };
             try {
-                const result = await window.go.main.App.SyncPull();
-                this.showToast(result, 'success');
+                const strategy = await this.chooseSyncStrategy();
+                if (strategy === null) {
+                    return;
+                }
+                await window.go.main.App.SyncPull(strategy);
+                this.showToast('配置已从云端拉取', 'success');
                 await this.refreshSyncStatus();
                 await this.refreshMirrors();
                 await this.refreshStatus();
@@ -0,0 +1,62 @@
+package internal
+
+// ReapplyCurrentMirrors 重新应用当前激活的镜像源配置（Codex CLI、VS Code 和 Claude Code），
+// 用于修复配置文件或环境变量与 mirrors.toml 不一致的问题。没有激活的镜像源时不做任何操作.
+func (mm *MirrorManager) ReapplyCurrentMirrors() error {
+	var errs []error
+	for _, toolType := range []ToolType{ToolTypeCodex, ToolTypeClaude} {
+		if err := mm.ReapplyCurrentMirror(toolType); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return CombinedError(errs)
+}
+
+// ReapplyCurrentMirror 重新应用指定工具类型当前激活的镜像源配置，模型别名在此时解析.
+// Codex 同时写入 Codex CLI 和 VS Code 配置。没有激活的镜像源时不做任何操作.
+func (mm *MirrorManager) ReapplyCurrentMirror(toolType ToolType) error {
+	var errs []error
+
+	switch toolType {
+	case ToolTypeCodex:
+		mirror, err := mm.GetCurrentCodexMirror()
+		if err != nil {
+			return nil
+		}
+		if mirror, err = mm.ResolveModelAlias(mirror); err != nil {
+			return err
+		}
+
+		if ccm, err := NewCodexConfigManager(); err != nil {
+			errs = append(errs, err)
+		} else {
+			ccm.SetPersistEnv(!mm.config.NoEnv)
+			if err := ccm.ApplyMirror(mirror); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if vcm, err := NewVSCodeConfigManager(); err != nil {
+			errs = append(errs, err)
+		} else if err := vcm.ApplyMirror(mirror); err != nil {
+			errs = append(errs, err)
+		}
+
+	case ToolTypeClaude:
+		mirror, err := mm.GetCurrentClaudeMirror()
+		if err != nil {
+			return nil
+		}
+		if mirror, err = mm.ResolveModelAlias(mirror); err != nil {
+			return err
+		}
+
+		if ccm, err := NewClaudeConfigManager(); err != nil {
+			errs = append(errs, err)
+		} else if err := ccm.ApplyMirror(mirror); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return CombinedError(errs)
+}
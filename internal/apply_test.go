@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReapplyCurrentMirrors 测试重新应用当前激活的镜像源配置.
+func TestReapplyCurrentMirrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		activate   bool
+		wantClaude bool
+	}{
+		{name: "没有激活的镜像源", activate: false, wantClaude: false},
+		{name: "重新应用Claude镜像源", activate: true, wantClaude: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			mm := createTestMirrorManager(t, tempDir)
+			mm.config.CurrentCodex = ""
+			mm.config.CurrentMirror = ""
+
+			if tt.activate {
+				if err := mm.AddMirrorWithType("reapply-claude", "https://claude.test.com", "sk-reapply", ToolTypeClaude); err != nil {
+					t.Fatalf("添加测试镜像源失败: %v", err)
+				}
+				if err := mm.SwitchMirror("reapply-claude"); err != nil {
+					t.Fatalf("切换镜像源失败: %v", err)
+				}
+			}
+
+			if err := mm.ReapplyCurrentMirrors(); err != nil {
+				t.Fatalf("ReapplyCurrentMirrors() error = %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(tempDir, ".claude", "settings.json"))
+			if !tt.wantClaude {
+				if err == nil {
+					t.Errorf("没有激活的镜像源时不应写入 Claude 配置")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("读取 Claude 配置失败: %v", err)
+			}
+			if !strings.Contains(string(data), "https://claude.test.com") {
+				t.Errorf("Claude 配置未包含镜像源地址:\n%s", data)
+			}
+		})
+	}
+}
+
+// TestApplyMirrorRejectsToolTypeMismatch 测试镜像源类型与配置管理器不匹配时拒绝应用且不写入配置.
+func TestApplyMirrorRejectsToolTypeMismatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		toolType ToolType
+		apply    func(t *testing.T, tempDir string, mirror *MirrorConfig) (string, error)
+	}{
+		{
+			name:     "Claude镜像源应用到Codex",
+			toolType: ToolTypeClaude,
+			apply: func(t *testing.T, tempDir string, mirror *MirrorConfig) (string, error) {
+				ccm := createTestCodexConfigManager(t, tempDir)
+				if _, err := ccm.PlanMirror(mirror); !errors.Is(err, ErrToolTypeMismatch) {
+					t.Errorf("PlanMirror() error = %v, want ErrToolTypeMismatch", err)
+				}
+				return ccm.configPath, ccm.ApplyMirror(mirror)
+			},
+		},
+		{
+			name:     "Claude镜像源应用到VS Code",
+			toolType: ToolTypeClaude,
+			apply: func(t *testing.T, tempDir string, mirror *MirrorConfig) (string, error) {
+				vcm := &VSCodeConfigManager{settingsPath: filepath.Join(tempDir, "Code", "User", "settings.json")}
+				if _, err := vcm.PlanMirror(mirror); !errors.Is(err, ErrToolTypeMismatch) {
+					t.Errorf("PlanMirror() error = %v, want ErrToolTypeMismatch", err)
+				}
+				return vcm.settingsPath, vcm.ApplyMirror(mirror)
+			},
+		},
+		{
+			name:     "Codex镜像源应用到Claude",
+			toolType: ToolTypeCodex,
+			apply: func(t *testing.T, tempDir string, mirror *MirrorConfig) (string, error) {
+				ccm := &ClaudeConfigManager{settingsPath: filepath.Join(tempDir, ".claude", "settings.json")}
+				if _, err := ccm.PlanMirror(mirror, nil); !errors.Is(err, ErrToolTypeMismatch) {
+					t.Errorf("PlanMirror() error = %v, want ErrToolTypeMismatch", err)
+				}
+				return ccm.settingsPath, ccm.ApplyMirror(mirror)
+			},
+		},
+		{
+			name:     "未设置类型的镜像源应用到Claude",
+			toolType: "",
+			apply: func(t *testing.T, tempDir string, mirror *MirrorConfig) (string, error) {
+				ccm := &ClaudeConfigManager{settingsPath: filepath.Join(tempDir, ".claude", "settings.json")}
+				return ccm.settingsPath, ccm.ApplyMirror(mirror)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			mirror := &MirrorConfig{
+				Name:     "mismatch",
+				BaseURL:  "https://mismatch.test.com",
+				APIKey:   "sk-mismatch",
+				ToolType: tt.toolType,
+			}
+
+			path, err := tt.apply(t, tempDir, mirror)
+			if !errors.Is(err, ErrToolTypeMismatch) {
+				t.Fatalf("ApplyMirror() error = %v, want ErrToolTypeMismatch", err)
+			}
+			if !strings.Contains(err.Error(), "mismatch") {
+				t.Errorf("错误信息应包含镜像源名称: %v", err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("类型不匹配时不应写入配置文件 %s", path)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// azureAPIKeyHeader Azure OpenAI 使用的认证请求头.
+const azureAPIKeyHeader = "api-key"
+
+// azureDeploymentsSegment Azure 部署地址中部署名之前的路径段.
+const azureDeploymentsSegment = "/deployments/"
+
+// ParseProviderKind 解析提供商形式，空字符串视为 openai.
+func ParseProviderKind(kind string) (ProviderKind, error) {
+	switch ProviderKind(strings.ToLower(strings.TrimSpace(kind))) {
+	case "", ProviderKindOpenAI:
+		return ProviderKindOpenAI, nil
+	case ProviderKindAzure:
+		return ProviderKindAzure, nil
+	default:
+		return "", fmt.Errorf("无效的提供商形式 '%s'，支持: %s, %s", kind, ProviderKindOpenAI, ProviderKindAzure)
+	}
+}
+
+// IsAzureMirror 判断镜像源是否为 Azure OpenAI 形式.
+func IsAzureMirror(mirror *MirrorConfig) bool {
+	return mirror.ProviderKind == ProviderKindAzure
+}
+
+// validateProviderKind 校验提供商形式与 API 版本的组合.
+func validateProviderKind(toolType ToolType, kind ProviderKind, apiVersion string) error {
+	switch kind {
+	case "", ProviderKindOpenAI:
+		if apiVersion != "" {
+			return fmt.Errorf("api_version 仅适用于 azure 提供商")
+		}
+	case ProviderKindAzure:
+		if toolType != ToolTypeCodex {
+			return fmt.Errorf("azure 提供商仅适用于 Codex 镜像源")
+		}
+		if apiVersion == "" {
+			return fmt.Errorf("azure 提供商必须指定 api_version")
+		}
+	default:
+		return fmt.Errorf("无效的提供商形式 '%s'，支持: %s, %s", kind, ProviderKindOpenAI, ProviderKindAzure)
+	}
+	return nil
+}
+
+// SetMirrorProvider 设置 Codex 镜像源的提供商形式；azure 必须同时指定 API 版本.
+func (mm *MirrorManager) SetMirrorProvider(name string, kind ProviderKind, apiVersion string) error {
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	apiVersion = strings.TrimSpace(apiVersion)
+	if err := validateProviderKind(mirror.ToolType, kind, apiVersion); err != nil {
+		return err
+	}
+
+	// openai 是默认形式，不写入配置文件
+	if kind == ProviderKindOpenAI {
+		kind = ""
+	}
+	mirror.ProviderKind = kind
+	mirror.APIVersion = apiVersion
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
+
+// azureDeploymentName 从 ".../openai/deployments/<name>" 形式的地址中提取部署名.
+func azureDeploymentName(baseURL string) string {
+	idx := strings.Index(baseURL, azureDeploymentsSegment)
+	if idx < 0 {
+		return ""
+	}
+	name, _, _ := strings.Cut(baseURL[idx+len(azureDeploymentsSegment):], "/")
+	return name
+}
+
+// azureResourceURL 返回 Azure 资源的 API 根地址（以 /openai 结尾）.
+// 部署地址会被截断到 /openai，未包含 /openai 的资源地址会自动补全.
+func azureResourceURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if idx := strings.Index(baseURL, azureDeploymentsSegment); idx >= 0 {
+		return baseURL[:idx]
+	}
+	if strings.HasSuffix(baseURL, "/openai") {
+		return baseURL
+	}
+	return baseURL + "/openai"
+}
+
+// applyAzureProviderConfig 将 Codex 提供商配置调整为 Azure OpenAI 形式.
+// 部署地址使用 chat 接口（Codex 会追加 /chat/completions），资源地址使用 responses 接口.
+func applyAzureProviderConfig(providerConfig *ModelProviderConfig, mirror *MirrorConfig) {
+	if azureDeploymentName(mirror.BaseURL) != "" {
+		providerConfig.BaseURL = strings.TrimSuffix(mirror.BaseURL, "/")
+		providerConfig.WireAPI = "chat"
+	} else {
+		providerConfig.BaseURL = azureResourceURL(mirror.BaseURL)
+		providerConfig.WireAPI = "responses"
+	}
+	providerConfig.QueryParams = map[string]string{"api-version": mirror.APIVersion}
+	providerConfig.EnvHTTPHeaders = map[string]string{azureAPIKeyHeader: providerConfig.EnvKey}
+	providerConfig.RequiresOpenAIAuth = false
+}
+
+// azureTestURL 返回 Azure 镜像源连通性测试使用的地址.
+// 设置了 HealthPath 时探测该路径，否则探测资源下的 /models.
+func azureTestURL(mirror *MirrorConfig) string {
+	target := azureResourceURL(mirror.BaseURL) + "/models"
+	if mirror.HealthPath != "" {
+		target = strings.TrimSuffix(mirror.BaseURL, "/") + mirror.HealthPath
+	}
+
+	separator := "?"
+	if strings.Contains(target, "?") {
+		separator = "&"
+	}
+	return target + separator + "api-version=" + url.QueryEscape(mirror.APIVersion)
+}
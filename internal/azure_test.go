@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// TestApplyAzureMirror 测试 Azure 镜像源写入 Codex 配置时调整地址、接口和认证方式.
+func TestApplyAzureMirror(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseURL     string
+		wantBaseURL string
+		wantWireAPI string
+		wantModel   string
+	}{
+		{"resource", "https://res.openai.azure.com", "https://res.openai.azure.com/openai", "responses", DefaultModelGPT4},
+		{"deployment", "https://res.openai.azure.com/openai/deployments/gpt-4o", "https://res.openai.azure.com/openai/deployments/gpt-4o", "chat", "gpt-4o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ccm := createTestCodexConfigManager(t, setupTestDir(t))
+			mirror := &MirrorConfig{
+				Name:         "azure-" + tt.name,
+				BaseURL:      tt.baseURL,
+				APIKey:       "azure-key",
+				EnvKey:       CodexSwitchAPIKeyEnv,
+				ToolType:     ToolTypeCodex,
+				ProviderKind: ProviderKindAzure,
+				APIVersion:   "2025-04-01-preview",
+			}
+			if err := ccm.ApplyMirror(mirror); err != nil {
+				t.Fatalf("ApplyMirror() error = %v", err)
+			}
+
+			config, err := ccm.GetCurrentConfig()
+			if err != nil {
+				t.Fatalf("GetCurrentConfig() error = %v", err)
+			}
+			provider := config.ModelProviders[mirror.Name]
+			if provider.BaseURL != tt.wantBaseURL || provider.WireAPI != tt.wantWireAPI {
+				t.Errorf("provider = %+v, want base_url %s and wire_api %s", provider, tt.wantBaseURL, tt.wantWireAPI)
+			}
+			if provider.QueryParams["api-version"] != "2025-04-01-preview" {
+				t.Errorf("query_params = %v, want api-version", provider.QueryParams)
+			}
+			if provider.EnvHTTPHeaders["api-key"] != CodexSwitchAPIKeyEnv {
+				t.Errorf("env_http_headers = %v, want api-key -> %s", provider.EnvHTTPHeaders, CodexSwitchAPIKeyEnv)
+			}
+			if provider.RequiresOpenAIAuth {
+				t.Error("Azure provider should not require OpenAI auth")
+			}
+			if config.Model != tt.wantModel {
+				t.Errorf("model = %s, want %s", config.Model, tt.wantModel)
+			}
+		})
+	}
+}
+
+// TestAzureConnectivityRequest 测试 Azure 镜像源的连通性探测使用 api-key 请求头和 api-version 参数.
+func TestAzureConnectivityRequest(t *testing.T) {
+	var receivedURL, receivedKey, receivedAuth atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedURL.Store(r.URL.String())
+		receivedKey.Store(r.Header.Get("api-key"))
+		receivedAuth.Store(r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mirror := &MirrorConfig{
+		Name:         "azure-probe",
+		BaseURL:      server.URL + "/openai/deployments/gpt-4o",
+		APIKey:       "azure-key",
+		ToolType:     ToolTypeCodex,
+		ProviderKind: ProviderKindAzure,
+		APIVersion:   "2024-10-21",
+	}
+	if _, err := (&ConnectivityTester{}).Probe(mirror, 5); err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+
+	if got := receivedURL.Load(); got != "/openai/models?api-version=2024-10-21" {
+		t.Errorf("request URL = %v, want /openai/models?api-version=2024-10-21", got)
+	}
+	if got := receivedKey.Load(); got != "azure-key" {
+		t.Errorf("api-key = %v, want azure-key", got)
+	}
+	if got := receivedAuth.Load(); got != "" {
+		t.Errorf("Authorization = %v, want empty", got)
+	}
+}
+
+// TestSetMirrorProvider 测试设置提供商形式时的校验.
+func TestSetMirrorProvider(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.AddMirrorWithModel("azure-codex", "https://res.openai.azure.com", "sk-azure", ToolTypeCodex, ""); err != nil {
+		t.Fatalf("AddMirrorWithModel() error = %v", err)
+	}
+	if err := mm.AddMirrorWithModel("azure-claude", "https://claude.example.com", "sk-claude", ToolTypeClaude, ""); err != nil {
+		t.Fatalf("AddMirrorWithModel() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		mirror     string
+		kind       ProviderKind
+		apiVersion string
+		wantErr    bool
+	}{
+		{"azure without version", "azure-codex", ProviderKindAzure, "", true},
+		{"openai with version", "azure-codex", ProviderKindOpenAI, "2024-10-21", true},
+		{"azure on claude", "azure-claude", ProviderKindAzure, "2024-10-21", true},
+		{"azure", "azure-codex", ProviderKindAzure, "2024-10-21", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mm.SetMirrorProvider(tt.mirror, tt.kind, tt.apiVersion)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetMirrorProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("读取配置失败: %v", err)
+	}
+	if !strings.Contains(string(data), `provider_kind = "azure"`) || !strings.Contains(string(data), `api_version = "2024-10-21"`) {
+		t.Errorf("配置文件中缺少提供商信息:\n%s", data)
+	}
+}
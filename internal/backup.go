@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// backupTimestampFormat 备份文件名中的时间戳格式（字典序等于时间序）.
+const backupTimestampFormat = "20060102-150405"
+
+// defaultBackupKeepCount 每种前缀默认保留的备份数量.
+const defaultBackupKeepCount = 10
+
+// BackupInfo 镜像源配置备份文件信息.
+type BackupInfo struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Prefix    string    `json:"prefix"`
+	Timestamp time.Time `json:"timestamp"`
+	Size      int64     `json:"size"`
+}
+
+// GetBackupDir 返回镜像源配置的备份目录.
+func (mm *MirrorManager) GetBackupDir() string {
+	return filepath.Join(filepath.Dir(mm.configPath), "backup")
+}
+
+// CreateBackup 以指定前缀备份当前配置文件，并清理该前缀下多余的旧备份.
+func (mm *MirrorManager) CreateBackup(prefix string) (string, error) {
+	backupDir := mm.GetBackupDir()
+	if err := EnsureDir(backupDir); err != nil {
+		return "", fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	backupFileName := fmt.Sprintf("%s-%s.toml", prefix, time.Now().Format(backupTimestampFormat))
+	backupPath := filepath.Join(backupDir, backupFileName)
+	if err := copyFile(mm.configPath, backupPath); err != nil {
+		return "", fmt.Errorf("创建备份失败: %w", err)
+	}
+
+	pruneBackups(backupDir, prefix, defaultBackupKeepCount)
+	return backupPath, nil
+}
+
+// ListBackups 列出备份目录中的所有备份，按时间从新到旧排序.
+func (mm *MirrorManager) ListBackups() ([]BackupInfo, error) {
+	backupDir := mm.GetBackupDir()
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	var backups []BackupInfo
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		prefix, timestamp, ok := parseBackupName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupInfo{
+			Name:      entry.Name(),
+			Path:      filepath.Join(backupDir, entry.Name()),
+			Prefix:    prefix,
+			Timestamp: timestamp,
+			Size:      info.Size(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].Timestamp.Equal(backups[j].Timestamp) {
+			return backups[i].Name > backups[j].Name
+		}
+		return backups[i].Timestamp.After(backups[j].Timestamp)
+	})
+
+	return backups, nil
+}
+
+// RestoreFromBackup 从备份文件恢复镜像源配置并重新加载.
+// 恢复前会先备份当前配置；无法解析为有效配置的文件会被拒绝.
+func (mm *MirrorManager) RestoreFromBackup(path string) error {
+	// 相对文件名优先在备份目录中查找
+	if !filepath.IsAbs(path) {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			path = filepath.Join(mm.GetBackupDir(), path)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取备份文件失败: %w", err)
+	}
+
+	restored := &SystemConfig{}
+	if _, err := toml.Decode(string(data), restored); err != nil {
+		return fmt.Errorf("备份文件不是有效的配置: %w", err)
+	}
+	if len(restored.Mirrors) == 0 {
+		return fmt.Errorf("备份文件不包含任何镜像源")
+	}
+
+	// 恢复前备份当前状态
+	if _, err := os.Stat(mm.configPath); err == nil {
+		if _, err := mm.CreateBackup("pre-restore"); err != nil {
+			return fmt.Errorf("备份当前配置失败: %w", err)
+		}
+	}
+
+	if err := WriteFileAtomic(mm.configPath, 0o600, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return fmt.Errorf("恢复配置文件失败: %w", err)
+	}
+
+	mm.config = restored
+	mm.migrateSyncProfiles()
+	return nil
+}
+
+// parseBackupName 解析 "<prefix>-<timestamp>.toml" 格式的备份文件名.
+func parseBackupName(name string) (prefix string, timestamp time.Time, ok bool) {
+	base, found := strings.CutSuffix(name, ".toml")
+	if !found || len(base) < len(backupTimestampFormat)+2 {
+		return "", time.Time{}, false
+	}
+
+	sep := len(base) - len(backupTimestampFormat) - 1
+	if base[sep] != '-' {
+		return "", time.Time{}, false
+	}
+
+	timestamp, err := time.ParseInLocation(backupTimestampFormat, base[sep+1:], time.Local)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	return base[:sep], timestamp, true
+}
+
+// pruneBackups 清理旧备份文件，保留指定前缀下数量为 keepCount 的最新备份.
+func pruneBackups(backupDir, prefix string, keepCount int) {
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		return
+	}
+
+	// 筛选匹配前缀的备份文件
+	var backupFiles []os.DirEntry
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix+"-") && strings.HasSuffix(entry.Name(), ".toml") {
+			backupFiles = append(backupFiles, entry)
+		}
+	}
+
+	// 如果备份数量超过限制，删除最旧的
+	if len(backupFiles) > keepCount {
+		// 按名称排序（时间戳格式保证字典序等于时间序），不依赖 ReadDir 的返回顺序
+		sort.Slice(backupFiles, func(i, j int) bool {
+			return backupFiles[i].Name() < backupFiles[j].Name()
+		})
+		for i := 0; i < len(backupFiles)-keepCount; i++ {
+			_ = os.Remove(filepath.Join(backupDir, backupFiles[i].Name()))
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 备份来源.
+const (
+	BackupSourceMirrors = "mirrors" // 镜像源配置备份 (~/.codex-mirror/backup/<前缀>-<时间戳>.toml)
+	BackupSourceSwitch  = "switch"  // 切换前快照 (~/.codex-mirror/backup/switch-<时间戳>/)
+	BackupSourceCodex   = "codex"   // Codex CLI 配置备份 (~/.codex/backup/*.bak)
+	BackupSourceClaude  = "claude"  // Claude Code 配置备份 (~/.claude/backup/*.bak)
+	BackupSourceVSCode  = "vscode"  // VS Code 设置备份 (<VS Code 用户目录>/backup/*.bak)
+)
+
+// BackupEntry 已知备份目录中的一个备份（备份文件、切换快照目录或配置管理器的 .bak 文件）.
+type BackupEntry struct {
+	Source    string    `json:"source"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Timestamp time.Time `json:"timestamp"`
+	Size      int64     `json:"size"`
+	// 同一分组内按时间保留最新的若干个：镜像源配置备份按前缀分组，切换快照为一组，.bak 文件各自一组
+	group string
+}
+
+// ListAllBackups 列出所有已知备份目录中的备份，按时间从新到旧排序.
+func (mm *MirrorManager) ListAllBackups() ([]BackupEntry, error) {
+	var entries []BackupEntry
+
+	backups, err := mm.ListBackups()
+	if err != nil {
+		return nil, err
+	}
+	for _, backup := range backups {
+		entries = append(entries, BackupEntry{
+			Source:    BackupSourceMirrors,
+			Name:      backup.Name,
+			Path:      backup.Path,
+			Timestamp: backup.Timestamp,
+			Size:      backup.Size,
+			group:     BackupSourceMirrors + "/" + backup.Prefix,
+		})
+	}
+
+	snapshots, err := mm.ListSwitchSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		entries = append(entries, BackupEntry{
+			Source:    BackupSourceSwitch,
+			Name:      snapshot.Name,
+			Path:      snapshot.Path,
+			Timestamp: snapshot.CreatedAt,
+			Size:      dirSize(snapshot.Path),
+			group:     BackupSourceSwitch,
+		})
+	}
+
+	for source, dir := range managerBackupDirs() {
+		files, err := listBakFiles(source, dir)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, files...)
+	}
+
+	sortBackupEntries(entries)
+	return entries, nil
+}
+
+// PlanCleanBackups 返回 CleanBackups 将删除的备份，不删除任何文件.
+// keep > 0 时每个分组保留最新的 keep 个备份；olderThan > 0 时只删除早于该时长的备份；
+// 两者同时指定时，只删除既超出保留数量又早于该时长的备份.
+func (mm *MirrorManager) PlanCleanBackups(keep int, olderThan time.Duration) ([]BackupEntry, error) {
+	if keep < 0 {
+		return nil, fmt.Errorf("保留数量不能为负数: %d", keep)
+	}
+	if olderThan < 0 {
+		return nil, fmt.Errorf("时长不能为负数: %s", olderThan)
+	}
+	if keep == 0 && olderThan == 0 {
+		return nil, fmt.Errorf("请指定保留数量或时长")
+	}
+
+	entries, err := mm.ListAllBackups()
+	if err != nil {
+		return nil, err
+	}
+	return selectBackupsToClean(entries, keep, olderThan, time.Now()), nil
+}
+
+// CleanBackups 按 PlanCleanBackups 的规则清理所有已知备份目录中的旧备份，返回已删除的备份.
+func (mm *MirrorManager) CleanBackups(keep int, olderThan time.Duration) ([]BackupEntry, error) {
+	selected, err := mm.PlanCleanBackups(keep, olderThan)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []BackupEntry
+	var errs []error
+	for _, entry := range selected {
+		if err := os.RemoveAll(entry.Path); err != nil {
+			errs = append(errs, fmt.Errorf("删除备份 %s 失败: %w", entry.Path, err))
+			continue
+		}
+		removed = append(removed, entry)
+	}
+	return removed, CombinedError(errs)
+}
+
+// selectBackupsToClean 返回按保留规则应删除的备份，entries 需按时间从新到旧排序.
+func selectBackupsToClean(entries []BackupEntry, keep int, olderThan time.Duration, now time.Time) []BackupEntry {
+	seen := make(map[string]int)
+	var selected []BackupEntry
+	for _, entry := range entries {
+		rank := seen[entry.group]
+		seen[entry.group] = rank + 1
+
+		if keep > 0 && rank < keep {
+			continue
+		}
+		if olderThan > 0 && now.Sub(entry.Timestamp) <= olderThan {
+			continue
+		}
+		selected = append(selected, entry)
+	}
+	return selected
+}
+
+// managerBackupDirs 返回各配置管理器 BackupConfig/BackupSettings 使用的备份目录（来源 -> 目录）.
+func managerBackupDirs() map[string][]string {
+	dirs := make(map[string][]string)
+	if path, err := GetCodexConfigPath(); err == nil {
+		dirs[BackupSourceCodex] = append(dirs[BackupSourceCodex], filepath.Join(filepath.Dir(path), "backup"))
+	}
+	if path, err := GetClaudeSettingsPath(); err == nil {
+		dirs[BackupSourceClaude] = append(dirs[BackupSourceClaude], filepath.Join(filepath.Dir(path), "backup"))
+	}
+	for _, variant := range []VSCodeVariant{VSCodeStable, VSCodeInsiders} {
+		if path, err := GetVSCodeSettingsPathForVariant(variant); err == nil {
+			dirs[BackupSourceVSCode] = append(dirs[BackupSourceVSCode], filepath.Join(filepath.Dir(path), "backup"))
+		}
+	}
+	return dirs
+}
+
+// listBakFiles 列出目录中的 .bak 备份文件，以修改时间作为备份时间；目录不存在时返回空列表.
+func listBakFiles(source string, dirs []string) ([]BackupEntry, error) {
+	var entries []BackupEntry
+	for _, dir := range dirs {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("读取备份目录失败: %w", err)
+		}
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".bak") {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+			path := filepath.Join(dir, file.Name())
+			entries = append(entries, BackupEntry{
+				Source:    source,
+				Name:      file.Name(),
+				Path:      path,
+				Timestamp: info.ModTime(),
+				Size:      info.Size(),
+				group:     path,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// dirSize 返回目录中所有文件的总大小.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// sortBackupEntries 按时间从新到旧排序，时间相同时按来源和名称排序，保证输出稳定.
+func sortBackupEntries(entries []BackupEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			return entries[i].Timestamp.After(entries[j].Timestamp)
+		}
+		if entries[i].Source != entries[j].Source {
+			return entries[i].Source < entries[j].Source
+		}
+		return entries[i].Name > entries[j].Name
+	})
+}
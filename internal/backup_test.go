@@ -0,0 +1,334 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPruneBackups 测试清理旧备份时保留最新的备份.
+func TestPruneBackups(t *testing.T) {
+	backupDir := filepath.Join(t.TempDir(), "backup")
+	if err := os.MkdirAll(backupDir, 0o755); err != nil {
+		t.Fatalf("创建备份目录失败: %v", err)
+	}
+
+	// 以乱序创建备份文件，并混入其他前缀的备份
+	names := []string{
+		"backup-20240105-120000.toml",
+		"backup-20240101-120000.toml",
+		"backup-20240104-120000.toml",
+		"backup-20240102-120000.toml",
+		"backup-20240103-120000.toml",
+		"pre-push-20240101-120000.toml",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(backupDir, name), []byte(""), 0o644); err != nil {
+			t.Fatalf("创建备份文件失败: %v", err)
+		}
+	}
+
+	pruneBackups(backupDir, "backup", 2)
+
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		t.Fatalf("读取备份目录失败: %v", err)
+	}
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+
+	expected := []string{
+		"backup-20240104-120000.toml",
+		"backup-20240105-120000.toml",
+		"pre-push-20240101-120000.toml",
+	}
+	if strings.Join(remaining, ",") != strings.Join(expected, ",") {
+		t.Errorf("剩余备份 = %v, 期望 %v", remaining, expected)
+	}
+}
+
+// TestParseBackupName 测试解析备份文件名.
+func TestParseBackupName(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantPrefix string
+		wantOK     bool
+	}{
+		{"backup-20240105-120000.toml", "backup", true},
+		{"pre-push-20240105-120000.toml", "pre-push", true},
+		{"config.toml.bak", "", false},
+		{"backup-2024-120000.toml", "", false},
+		{"-20240105-120000.toml", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, timestamp, ok := parseBackupName(tt.name)
+			if ok != tt.wantOK || prefix != tt.wantPrefix {
+				t.Fatalf("parseBackupName(%s) = %q, %v, 期望 %q, %v", tt.name, prefix, ok, tt.wantPrefix, tt.wantOK)
+			}
+			if ok && !timestamp.Equal(time.Date(2024, 1, 5, 12, 0, 0, 0, time.Local)) {
+				t.Errorf("时间戳解析错误: %v", timestamp)
+			}
+		})
+	}
+}
+
+// TestRestoreFromBackup 测试从备份恢复配置.
+func TestRestoreFromBackup(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.AddMirror("before-backup", TestAPIURL, "sk-1"); err != nil {
+		t.Fatalf("添加镜像源失败: %v", err)
+	}
+	backupPath, err := mm.CreateBackup("backup")
+	if err != nil {
+		t.Fatalf("创建备份失败: %v", err)
+	}
+	if err := mm.AddMirror("after-backup", TestAPIURL, "sk-2"); err != nil {
+		t.Fatalf("添加镜像源失败: %v", err)
+	}
+
+	invalidPath := filepath.Join(mm.GetBackupDir(), "broken-20240101-000000.toml")
+	if err := os.WriteFile(invalidPath, []byte("mirrors = ["), 0o644); err != nil {
+		t.Fatalf("写入无效备份失败: %v", err)
+	}
+	emptyPath := filepath.Join(mm.GetBackupDir(), "empty-20240101-000000.toml")
+	if err := os.WriteFile(emptyPath, []byte(""), 0o644); err != nil {
+		t.Fatalf("写入空备份失败: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		expectError bool
+	}{
+		{name: "拒绝无法解析的文件", path: invalidPath, expectError: true},
+		{name: "拒绝没有镜像源的文件", path: emptyPath, expectError: true},
+		{name: "拒绝不存在的文件", path: "missing.toml", expectError: true},
+		{name: "按文件名恢复", path: filepath.Base(backupPath)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mm.RestoreFromBackup(tt.path)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("RestoreFromBackup() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				if _, err := mm.GetMirrorByName("after-backup"); err != nil {
+					t.Error("恢复失败时不应修改当前配置")
+				}
+				return
+			}
+
+			if _, err := mm.GetMirrorByName("after-backup"); err == nil {
+				t.Error("恢复后不应包含备份之后添加的镜像源")
+			}
+			reloaded, err := NewMirrorManagerWithPath(mm.GetConfigPath())
+			if err != nil {
+				t.Fatalf("重新加载配置失败: %v", err)
+			}
+			if _, err := reloaded.GetMirrorByName("before-backup"); err != nil {
+				t.Error("恢复后的配置文件应包含备份中的镜像源")
+			}
+		})
+	}
+
+	// 恢复前应备份当前状态
+	backups, err := mm.ListBackups()
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	var prefixes []string
+	for _, b := range backups {
+		prefixes = append(prefixes, b.Prefix)
+	}
+	if !strings.Contains(strings.Join(prefixes, ","), "pre-restore") {
+		t.Errorf("恢复前应创建 pre-restore 备份，实际备份: %v", prefixes)
+	}
+}
+
+// TestSwitchSnapshotRoundTrip 测试切换快照备份多个文件并整体恢复.
+func TestSwitchSnapshotRoundTrip(t *testing.T) {
+	tempDir := setupTestDir(t)
+	mm := createTestMirrorManager(t, tempDir)
+
+	codexPath := filepath.Join(tempDir, ".codex", "config.toml")
+	claudePath := filepath.Join(tempDir, ".claude", "settings.json")
+	for path, content := range map[string]string{codexPath: "model = \"old\"\n", claudePath: `{"env": {}}`} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("写入文件失败: %v", err)
+		}
+	}
+
+	targets := []ManagedPath{
+		{Name: "mirrors", Path: mm.GetConfigPath()},
+		{Name: "codex_config", Path: codexPath},
+		{Name: "claude_settings", Path: claudePath},
+		{Name: "vscode_settings", Path: filepath.Join(tempDir, "missing", "settings.json")},
+	}
+	snapshotDir, err := mm.CreateSwitchSnapshot(targets)
+	if err != nil {
+		t.Fatalf("CreateSwitchSnapshot 失败: %v", err)
+	}
+
+	snapshots, err := mm.ListSwitchSnapshots()
+	if err != nil {
+		t.Fatalf("ListSwitchSnapshots 失败: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Path != snapshotDir || len(snapshots[0].Files) != 3 {
+		t.Fatalf("快照列表不符合预期: %+v", snapshots)
+	}
+
+	// 模拟切换修改文件后整体恢复
+	if err := mm.AddMirror("after-snapshot", "https://after.example.com", "key"); err != nil {
+		t.Fatalf("添加镜像源失败: %v", err)
+	}
+	if err := os.WriteFile(codexPath, []byte("model = \"new\"\n"), 0o644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+	if err := os.WriteFile(claudePath, []byte(`{"env": {"A": "1"}}`), 0o644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+
+	if err := mm.RestoreSwitchSnapshot(filepath.Base(snapshotDir)); err != nil {
+		t.Fatalf("RestoreSwitchSnapshot 失败: %v", err)
+	}
+
+	if data, _ := os.ReadFile(codexPath); string(data) != "model = \"old\"\n" {
+		t.Errorf("Codex 配置未恢复: %q", data)
+	}
+	if data, _ := os.ReadFile(claudePath); string(data) != `{"env": {}}` {
+		t.Errorf("Claude 配置未恢复: %q", data)
+	}
+	if _, err := mm.GetMirrorByName("after-snapshot"); err == nil {
+		t.Error("恢复后不应存在快照之后添加的镜像源")
+	}
+}
+
+// TestPruneSnapshots 测试只保留最新的切换快照.
+func TestPruneSnapshots(t *testing.T) {
+	backupDir := t.TempDir()
+	for _, name := range []string{"switch-20240103-120000", "switch-20240101-120000", "switch-20240102-120000", "switch-20240102-120000-2"} {
+		if err := os.MkdirAll(filepath.Join(backupDir, name), 0o755); err != nil {
+			t.Fatalf("创建快照目录失败: %v", err)
+		}
+	}
+
+	pruneSnapshots(backupDir, 2)
+
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		t.Fatalf("读取备份目录失败: %v", err)
+	}
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	expected := "switch-20240102-120000-2,switch-20240103-120000"
+	if strings.Join(remaining, ",") != expected {
+		t.Errorf("剩余快照 = %v, 期望 %s", remaining, expected)
+	}
+}
+
+// TestCleanBackups 测试跨备份目录列出和清理备份.
+func TestCleanBackups(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+
+	tests := []struct {
+		name        string
+		keep        int
+		olderThan   time.Duration
+		wantRemoved []string
+	}{
+		{
+			name:        "每组保留1个",
+			keep:        1,
+			wantRemoved: []string{"pre-pull-20200101-000000.toml"},
+		},
+		{
+			name:        "删除早于1天的备份",
+			olderThan:   24 * time.Hour,
+			wantRemoved: []string{"config.toml.bak", "pre-pull-20200101-000000.toml", "pre-restore-20200101-000000.toml"},
+		},
+		{
+			name:        "超出保留数量但未早于时长的备份不删除",
+			keep:        1,
+			olderThan:   100000 * time.Hour,
+			wantRemoved: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			mm := createTestMirrorManager(t, tempDir)
+
+			backupDir := mm.GetBackupDir()
+			if err := EnsureDir(backupDir); err != nil {
+				t.Fatalf("创建备份目录失败: %v", err)
+			}
+			recent := "pre-pull-" + now.Format(backupTimestampFormat) + ".toml"
+			for _, name := range []string{recent, "pre-pull-20200101-000000.toml", "pre-restore-20200101-000000.toml"} {
+				if err := os.WriteFile(filepath.Join(backupDir, name), []byte("x"), 0o600); err != nil {
+					t.Fatalf("写入备份失败: %v", err)
+				}
+			}
+
+			codexBackupDir := filepath.Join(tempDir, ".codex", "backup")
+			if err := EnsureDir(codexBackupDir); err != nil {
+				t.Fatalf("创建 Codex 备份目录失败: %v", err)
+			}
+			bakPath := filepath.Join(codexBackupDir, "config.toml.bak")
+			if err := os.WriteFile(bakPath, []byte("model = \"x\""), 0o600); err != nil {
+				t.Fatalf("写入 Codex 备份失败: %v", err)
+			}
+			if err := os.Chtimes(bakPath, old, old); err != nil {
+				t.Fatalf("修改备份时间失败: %v", err)
+			}
+
+			all, err := mm.ListAllBackups()
+			if err != nil {
+				t.Fatalf("ListAllBackups() error = %v", err)
+			}
+			if len(all) != 4 {
+				t.Fatalf("ListAllBackups() 返回 %d 个备份，期望 4 个: %+v", len(all), all)
+			}
+			if all[0].Name != recent || all[0].Source != BackupSourceMirrors {
+				t.Errorf("最新的备份应排在最前: %+v", all[0])
+			}
+
+			removed, err := mm.CleanBackups(tt.keep, tt.olderThan)
+			if err != nil {
+				t.Fatalf("CleanBackups() error = %v", err)
+			}
+			var names []string
+			for _, entry := range removed {
+				names = append(names, entry.Name)
+				if _, err := os.Stat(entry.Path); !os.IsNotExist(err) {
+					t.Errorf("%s 应已被删除", entry.Path)
+				}
+			}
+			sort.Strings(names)
+			if strings.Join(names, ",") != strings.Join(tt.wantRemoved, ",") {
+				t.Errorf("删除的备份 = %v, want %v", names, tt.wantRemoved)
+			}
+		})
+	}
+
+	t.Run("未指定规则", func(t *testing.T) {
+		mm := createTestMirrorManager(t, setupTestDir(t))
+		if _, err := mm.CleanBackups(0, 0); err == nil {
+			t.Error("未指定保留数量和时长时应返回错误")
+		}
+	})
+}
@@ -3,8 +3,11 @@ package internal
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // ClaudeSettings Claude Code settings.json 结构.
@@ -14,6 +17,9 @@ type ClaudeSettings struct {
 	OtherSettings map[string]interface{} `json:"-"`
 }
 
+// claudeManagedEnvFileName 记录本工具写入 settings.json 的额外环境变量名的标记文件.
+const claudeManagedEnvFileName = ".codex-mirror-managed-env.json"
+
 // ClaudeConfigManager Claude Code 配置管理器.
 type ClaudeConfigManager struct {
 	settingsPath string
@@ -65,7 +71,7 @@ func (ccm *ClaudeConfigManager) LoadSettings() (*ClaudeSettings, error) {
 
 	// 先解析到通用 map 以保留所有字段
 	var rawSettings map[string]interface{}
-	if err := json.Unmarshal(data, &rawSettings); err != nil {
+	if err := ParseJSONC(data, &rawSettings); err != nil {
 		return nil, fmt.Errorf("解析Claude配置文件失败: %v", err)
 	}
 
@@ -158,46 +164,147 @@ func (ccm *ClaudeConfigManager) ApplyMirror(mirror *MirrorConfig) error {
 	return ccm.ApplyMirrorWithCleanup(mirror, nil)
 }
 
+// ClaudeModelEnvVars 返回镜像源模型相关的环境变量，值为空表示应清除该变量.
+func ClaudeModelEnvVars(mirror *MirrorConfig) map[string]string {
+	return map[string]string{
+		AnthropicModelEnv:              strings.TrimSpace(mirror.ModelName),
+		AnthropicDefaultHaikuModelEnv:  strings.TrimSpace(mirror.HaikuModel),
+		AnthropicDefaultSonnetModelEnv: strings.TrimSpace(mirror.SonnetModel),
+		AnthropicDefaultOpusModelEnv:   strings.TrimSpace(mirror.OpusModel),
+	}
+}
+
 // ApplyMirrorWithCleanup 应用镜像源配置，并清理旧镜像的额外环境变量.
 func (ccm *ClaudeConfigManager) ApplyMirrorWithCleanup(mirror *MirrorConfig, oldExtraEnv map[string]string) error {
+	if err := checkMirrorToolType(mirror, ToolTypeClaude); err != nil {
+		return err
+	}
+
+	// 配置了密钥命令时先获取密钥，失败时不修改任何文件
+	mirror, err := ResolveMirrorAPIKey(mirror)
+	if err != nil {
+		return err
+	}
+
 	settings, err := ccm.LoadSettings()
 	if err != nil {
 		return err
 	}
+	settings.Env = ccm.mirrorEnv(settings.Env, mirror, oldExtraEnv)
+
+	// 只修改 env 字段，保留文件中的注释和其他内容
+	if err := EditJSONCFile(ccm.settingsPath, func(src string) (string, error) {
+		if len(settings.Env) == 0 {
+			return DeleteJSONCMember(src, "env")
+		}
+		return SetJSONCMember(src, "env", settings.Env)
+	}); err != nil {
+		return err
+	}
+
+	return ccm.saveManagedEnvKeys(mirror.ExtraEnv)
+}
+
+// PlanMirror 计算应用镜像源后 settings.json 中 env 字段的变化，不写入任何文件.
+func (ccm *ClaudeConfigManager) PlanMirror(mirror *MirrorConfig, oldExtraEnv map[string]string) ([]ConfigChange, error) {
+	if err := checkMirrorToolType(mirror, ToolTypeClaude); err != nil {
+		return nil, err
+	}
+
+	mirror, err := ResolveMirrorAPIKey(mirror)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := ccm.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	return diffEnvMaps(ccm.settingsPath, "env.", settings.Env, ccm.mirrorEnv(settings.Env, mirror, oldExtraEnv)), nil
+}
 
-	// 确保 env map 存在
-	if settings.Env == nil {
-		settings.Env = make(map[string]string)
+// mirrorEnv 返回应用镜像源后的 env 字段内容，不修改传入的 current.
+func (ccm *ClaudeConfigManager) mirrorEnv(current map[string]string, mirror *MirrorConfig, oldExtraEnv map[string]string) map[string]string {
+	env := make(map[string]string, len(current))
+	for key, value := range current {
+		env[key] = value
 	}
 
-	// 清理旧镜像的额外环境变量（只清理不在新配置中的）
+	// 清理之前由本工具写入、但不在新配置中的额外环境变量，用户自行设置的变量不受影响
+	staleKeys := ccm.loadManagedEnvKeys()
 	for key := range oldExtraEnv {
+		staleKeys = append(staleKeys, key)
+	}
+	for _, key := range staleKeys {
 		if _, existsInNew := mirror.ExtraEnv[key]; !existsInNew {
-			delete(settings.Env, key)
+			delete(env, key)
 		}
 	}
 
 	// 设置 Claude 相关环境变量
-	settings.Env[AnthropicBaseURLEnv] = mirror.BaseURL
-	settings.Env[AnthropicAuthTokenEnv] = mirror.APIKey
+	env[AnthropicBaseURLEnv] = mirror.BaseURL
+	env[AnthropicAuthTokenEnv] = mirror.APIKey
 
-	// 设置或清除模型名称
-	if mirror.ModelName != "" {
-		settings.Env[AnthropicModelEnv] = mirror.ModelName
-	} else {
-		delete(settings.Env, AnthropicModelEnv)
+	// 设置或清除模型名称（包括各级别模型）
+	for key, value := range ClaudeModelEnvVars(mirror) {
+		if value != "" {
+			env[key] = value
+		} else {
+			delete(env, key)
+		}
 	}
 
-	// 应用额外的环境变量配置 (如 ANTHROPIC_DEFAULT_HAIKU_MODEL 等)
+	// 应用额外的环境变量配置，同名时覆盖上面的模型设置
 	for key, value := range mirror.ExtraEnv {
 		if value != "" {
-			settings.Env[key] = value
+			env[key] = value
 		} else {
-			delete(settings.Env, key)
+			delete(env, key)
+		}
+	}
+
+	return env
+}
+
+// managedEnvPath 返回托管环境变量标记文件的路径（与 settings.json 位于同一目录）.
+func (ccm *ClaudeConfigManager) managedEnvPath() string {
+	return filepath.Join(filepath.Dir(ccm.settingsPath), claudeManagedEnvFileName)
+}
+
+// loadManagedEnvKeys 读取上次应用镜像源时写入的额外环境变量名，文件不存在或损坏时返回空列表.
+func (ccm *ClaudeConfigManager) loadManagedEnvKeys() []string {
+	data, err := os.ReadFile(ccm.managedEnvPath())
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil
+	}
+	return keys
+}
+
+// saveManagedEnvKeys 记录本次写入的额外环境变量名，供下次应用时清理.
+func (ccm *ClaudeConfigManager) saveManagedEnvKeys(extraEnv map[string]string) error {
+	keys := make([]string, 0, len(extraEnv))
+	for key, value := range extraEnv {
+		if value != "" {
+			keys = append(keys, key)
 		}
 	}
+	sort.Strings(keys)
 
-	return ccm.SaveSettings(settings)
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化托管环境变量列表失败: %v", err)
+	}
+	if err := WriteFileAtomic(ccm.managedEnvPath(), 0o600, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return fmt.Errorf("保存托管环境变量列表失败: %v", err)
+	}
+	return nil
 }
 
 // GetCurrentEnv 获取当前配置的环境变量.
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -281,6 +282,60 @@ func TestClaudeConfigManager_ApplyMirror_ClearModel(t *testing.T) {
 	}
 }
 
+func TestClaudeConfigManager_ApplyMirror_TierModels(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), ".claude", "settings.json")
+	ccm := &ClaudeConfigManager{
+		settingsPath: settingsPath,
+	}
+
+	mirror := &MirrorConfig{
+		Name:        "tiered",
+		BaseURL:     "https://api.tiered.com",
+		APIKey:      "tier-key",
+		ToolType:    ToolTypeClaude,
+		HaikuModel:  "fast-model",
+		SonnetModel: "balanced-model",
+		OpusModel:   "smart-model",
+	}
+	if err := ccm.ApplyMirror(mirror); err != nil {
+		t.Fatalf("ApplyMirror failed: %v", err)
+	}
+
+	settings, err := ccm.LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	expected := map[string]string{
+		AnthropicDefaultHaikuModelEnv:  "fast-model",
+		AnthropicDefaultSonnetModelEnv: "balanced-model",
+		AnthropicDefaultOpusModelEnv:   "smart-model",
+	}
+	for key, value := range expected {
+		if settings.Env[key] != value {
+			t.Errorf("%s = %q, expected %q", key, settings.Env[key], value)
+		}
+	}
+
+	// 清空级别模型后应删除对应的环境变量
+	mirror.HaikuModel = ""
+	mirror.OpusModel = ""
+	if err := ccm.ApplyMirror(mirror); err != nil {
+		t.Fatalf("ApplyMirror failed: %v", err)
+	}
+	settings, err = ccm.LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	for _, key := range []string{AnthropicDefaultHaikuModelEnv, AnthropicDefaultOpusModelEnv} {
+		if _, exists := settings.Env[key]; exists {
+			t.Errorf("%s should be cleared when the tier model is empty", key)
+		}
+	}
+	if settings.Env[AnthropicDefaultSonnetModelEnv] != "balanced-model" {
+		t.Errorf("%s should be kept", AnthropicDefaultSonnetModelEnv)
+	}
+}
+
 func TestClaudeConfigManager_BackupSettings(t *testing.T) {
 	tempDir := t.TempDir()
 	configDir := filepath.Join(tempDir, ".claude")
@@ -405,3 +460,139 @@ func TestClaudeConfigManager_ApplyMirror_WithExtraEnv(t *testing.T) {
 		t.Errorf("model = %v, expected opus", settings.OtherSettings["model"])
 	}
 }
+
+func TestClaudeConfigManager_ApplyMirror_PreservesComments(t *testing.T) {
+	tempDir := t.TempDir()
+	settingsPath := filepath.Join(tempDir, "settings.json")
+	original := `{
+  // 权限配置
+  "permissions": {
+    "allow": ["Bash(npm:*)"],
+  },
+  "env": {
+    "ANTHROPIC_BASE_URL": "https://old.example.com"
+  }
+}
+`
+	if err := os.WriteFile(settingsPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	ccm := &ClaudeConfigManager{settingsPath: settingsPath}
+	mirror := &MirrorConfig{Name: "jsonc", BaseURL: "https://new.example.com", APIKey: "key", ToolType: ToolTypeClaude}
+	if err := ccm.ApplyMirror(mirror); err != nil {
+		t.Fatalf("ApplyMirror failed: %v", err)
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("Failed to read settings: %v", err)
+	}
+	if !strings.Contains(string(data), "// 权限配置") {
+		t.Errorf("Comment should be preserved:\n%s", data)
+	}
+
+	settings, err := ccm.LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	if settings.Env[AnthropicBaseURLEnv] != "https://new.example.com" || settings.Permissions == nil {
+		t.Errorf("Unexpected settings after ApplyMirror: %+v", settings)
+	}
+}
+
+func TestClaudeConfigManager_ApplyMirror_RemovesStaleExtraEnv(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".claude")
+	settingsPath := filepath.Join(configDir, "settings.json")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(settingsPath, []byte(`{"env": {"USER_VAR": "keep-me"}}`), 0o644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	ccm := &ClaudeConfigManager{settingsPath: settingsPath}
+	mirror := &MirrorConfig{
+		Name:     "test-mirror",
+		BaseURL:  "https://api.proxy.com",
+		APIKey:   "test-key",
+		ToolType: ToolTypeClaude,
+		ExtraEnv: map[string]string{
+			"API_TIMEOUT_MS":    "3000000",
+			"DISABLE_TELEMETRY": "1",
+		},
+	}
+	if err := ccm.ApplyMirror(mirror); err != nil {
+		t.Fatalf("ApplyMirror failed: %v", err)
+	}
+
+	// 关闭一个额外变量后重新应用，不传入旧配置
+	delete(mirror.ExtraEnv, "DISABLE_TELEMETRY")
+	if err := ccm.ApplyMirror(mirror); err != nil {
+		t.Fatalf("ApplyMirror failed: %v", err)
+	}
+
+	settings, err := ccm.LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+	if _, exists := settings.Env["DISABLE_TELEMETRY"]; exists {
+		t.Error("DISABLE_TELEMETRY should be removed after it was dropped from the mirror")
+	}
+	if settings.Env["API_TIMEOUT_MS"] != "3000000" {
+		t.Errorf("API_TIMEOUT_MS = %q, expected 3000000", settings.Env["API_TIMEOUT_MS"])
+	}
+	if settings.Env["USER_VAR"] != "keep-me" {
+		t.Error("USER_VAR is not managed by the tool and should be preserved")
+	}
+}
+
+func TestClaudeConfigManager_PlanMirror(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".claude")
+	settingsPath := filepath.Join(configDir, "settings.json")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	original := `{"env": {"ANTHROPIC_BASE_URL": "https://old.example.com", "ANTHROPIC_MODEL": "old-model", "USER_VAR": "1"}}`
+	if err := os.WriteFile(settingsPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	ccm := &ClaudeConfigManager{settingsPath: settingsPath}
+	mirror := &MirrorConfig{
+		Name:     "plan-mirror",
+		BaseURL:  "https://new.example.com",
+		APIKey:   "new-key",
+		ToolType: ToolTypeClaude,
+	}
+
+	changes, err := ccm.PlanMirror(mirror, nil)
+	if err != nil {
+		t.Fatalf("PlanMirror failed: %v", err)
+	}
+
+	byKey := make(map[string]ConfigChange)
+	for _, change := range changes {
+		byKey[change.Key] = change
+	}
+	if c := byKey["env."+AnthropicBaseURLEnv]; c.Old != "https://old.example.com" || c.New != "https://new.example.com" {
+		t.Errorf("unexpected base URL change: %+v", c)
+	}
+	if c := byKey["env."+AnthropicModelEnv]; c.Old != "old-model" || c.New != "" {
+		t.Errorf("model should be cleared: %+v", c)
+	}
+	if c := byKey["env."+AnthropicAuthTokenEnv]; !c.Secret || c.New != "new-key" {
+		t.Errorf("auth token change should be marked secret: %+v", c)
+	}
+	if c := byKey["env.USER_VAR"]; c.Changed() {
+		t.Errorf("USER_VAR should not change: %+v", c)
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("Failed to read settings: %v", err)
+	}
+	if string(data) != original {
+		t.Error("PlanMirror should not modify settings.json")
+	}
+}
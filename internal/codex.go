@@ -1,11 +1,16 @@
 package internal
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -14,6 +19,8 @@ import (
 type CodexConfigManager struct {
 	configPath string
 	authPath   string
+	// 为 true 时应用镜像源只在当前进程中设置环境变量，不写入 shell 配置文件或 Windows 用户环境变量
+	noPersistEnv bool
 }
 
 // NewCodexConfigManager 创建新的Codex配置管理器.
@@ -44,33 +51,39 @@ func NewCodexConfigManager() (*CodexConfigManager, error) {
 // FixEnvKeyFormat 修复所有镜像源的env_key格式为CODEX_XXX_API_KEY.
 func (ccm *CodexConfigManager) FixEnvKeyFormat() error {
 	// 读取现有配置
-	var config CodexConfig
 	if _, err := os.Stat(ccm.configPath); err != nil {
 		return nil // 配置文件不存在，无需修复
 	}
 
-	if _, err := toml.DecodeFile(ccm.configPath, &config); err != nil {
-		return fmt.Errorf("读取配置文件失败: %v", err)
+	var config CodexConfig
+	rawConfig, err := ccm.decodeConfigFiles(&config)
+	if err != nil {
+		return err
 	}
 
-	if config.ModelProviders == nil {
+	providers := rawModelProviders(rawConfig)
+	if providers == nil {
 		return nil // 没有镜像源配置
 	}
 
-	// 检查并修复每个镜像源的env_key格式
+	// 检查并修复每个镜像源的env_key格式，只修改 env_key，提供商中的其他设置原样保留
 	updated := false
-	for name, provider := range config.ModelProviders {
+	for _, value := range providers {
+		provider, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
 		expectedEnvKey := CodexSwitchAPIKeyEnv // Codex 固定使用专用的环境变量名
-		if provider.EnvKey != expectedEnvKey {
-			provider.EnvKey = expectedEnvKey
-			config.ModelProviders[name] = provider
+		if envKey, _ := provider["env_key"].(string); envKey != expectedEnvKey {
+			provider["env_key"] = expectedEnvKey
 			updated = true
 		}
 	}
 
 	// 如果有更新，保存配置文件
 	if updated {
-		if err := ccm.saveConfig(&config); err != nil {
+		rawConfig["model_providers"] = providers
+		if err := ccm.writeConfigFile(rawConfig); err != nil {
 			return fmt.Errorf("保存配置文件失败: %v", err)
 		}
 	}
@@ -124,6 +137,19 @@ func (ccm *CodexConfigManager) decodeConfigFiles(config *CodexConfig) (map[strin
 		return nil, fmt.Errorf("读取现有配置文件失败: %v", err)
 	}
 
+	// [[model_providers]] 表数组无法直接解码到结构体，先按名称转换为表，再用转换后的配置解码
+	if _, isArray := rawConfig["model_providers"].([]map[string]interface{}); isArray {
+		rawConfig["model_providers"] = rawModelProviders(rawConfig)
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(rawConfig); err != nil {
+			return nil, fmt.Errorf("解析现有配置文件失败: %v", err)
+		}
+		if _, err := toml.Decode(buf.String(), config); err != nil {
+			return nil, fmt.Errorf("解析现有配置文件失败: %v", err)
+		}
+		return rawConfig, nil
+	}
+
 	if _, err := toml.DecodeFile(ccm.configPath, config); err != nil {
 		return nil, fmt.Errorf("解析现有配置文件失败: %v", err)
 	}
@@ -149,6 +175,11 @@ func (ccm *CodexConfigManager) createProviderConfig(mirror *MirrorConfig, config
 		ccm.mergeExistingProviderConfig(&providerConfig, existingProvider)
 	}
 
+	// Azure 的地址形式和认证方式由镜像源决定，不沿用已有配置
+	if IsAzureMirror(mirror) {
+		applyAzureProviderConfig(&providerConfig, mirror)
+	}
+
 	return providerConfig
 }
 
@@ -172,12 +203,6 @@ func (ccm *CodexConfigManager) updateConfigStructures(config *CodexConfig, rawCo
 		config.ModelProviders = make(map[string]ModelProviderConfig)
 	}
 
-	// 保存现有的 provider 配置
-	existingProviders := make(map[string]ModelProviderConfig)
-	for k, v := range config.ModelProviders {
-		existingProviders[k] = v
-	}
-
 	// 添加或更新当前镜像的配置
 	config.ModelProviders[mirror.Name] = providerConfig
 
@@ -186,7 +211,7 @@ func (ccm *CodexConfigManager) updateConfigStructures(config *CodexConfig, rawCo
 	}
 
 	ccm.updateRawConfigBasicFields(rawConfig, config, mirror)
-	ccm.updateRawConfigModelProviders(rawConfig, mirror.Name, providerConfig, existingProviders)
+	ccm.updateRawConfigModelProviders(rawConfig, mirror.Name, providerConfig)
 }
 
 // updateRawConfigBasicFields 更新原始配置中的基础字段.
@@ -194,11 +219,7 @@ func (ccm *CodexConfigManager) updateRawConfigBasicFields(rawConfig map[string]i
 	rawConfig["model_provider"] = mirror.Name
 
 	// 更新 Model 字段 - 使用 mirror 中的 ModelName，如果没有则使用默认值
-	if mirror.ModelName != "" {
-		config.Model = mirror.ModelName
-	} else {
-		config.Model = DefaultModelGPT4
-	}
+	config.Model = codexModelName(mirror)
 	rawConfig["model"] = config.Model
 
 	// 更新 ModelReasoningEffort 字段
@@ -207,76 +228,129 @@ func (ccm *CodexConfigManager) updateRawConfigBasicFields(rawConfig map[string]i
 	}
 	rawConfig["model_reasoning_effort"] = config.ModelReasoningEffort
 
-	// 总是强制设置 DisableResponseStorage 为 true
-	config.DisableResponseStorage = true
+	// 镜像源未明确设置时保持强制为 true
+	config.DisableResponseStorage = responseStorageDisabled(mirror)
 	rawConfig["disable_response_storage"] = config.DisableResponseStorage
 }
 
-// updateRawConfigModelProviders 更新原始配置中的模型提供商配置.
-func (ccm *CodexConfigManager) updateRawConfigModelProviders(rawConfig map[string]interface{}, mirrorName string, providerConfig ModelProviderConfig, existingProviders map[string]ModelProviderConfig) {
-	// 使用扁平化结构 [model_providers.mirrorname]
-	// 保留现有镜像的配置，只更新当前镜像
-
-	// 先移除旧的嵌套结构（如果存在）
-	delete(rawConfig, "model_providers")
+// responseStorageDisabled 返回镜像源是否禁用响应存储，未设置时默认禁用.
+func responseStorageDisabled(mirror *MirrorConfig) bool {
+	if mirror.DisableResponseStorage == nil {
+		return true
+	}
+	return *mirror.DisableResponseStorage
+}
 
-	// 移除当前镜像的旧扁平化键
-	var keysToDelete []string
-	for key := range rawConfig {
-		if strings.HasPrefix(key, "model_providers.") {
-			// 提取镜像名
-			parts := strings.SplitN(key, ".", 3)
-			if len(parts) >= 3 && parts[1] == mirrorName {
-				keysToDelete = append(keysToDelete, key)
-			}
+// codexModelName 返回镜像源在 Codex 和 VS Code 中使用的模型名称，未设置时使用默认模型.
+func codexModelName(mirror *MirrorConfig) string {
+	if mirror.ModelName != "" {
+		return mirror.ModelName
+	}
+	// Azure 部署地址中的部署名即为请求使用的模型
+	if IsAzureMirror(mirror) {
+		if deployment := azureDeploymentName(mirror.BaseURL); deployment != "" {
+			return deployment
 		}
 	}
-	for _, key := range keysToDelete {
-		delete(rawConfig, key)
+	return DefaultModelGPT4
+}
+
+// checkCodexModelName 确认写入 config.toml 的模型名称不为空（例如只包含空白字符）.
+func checkCodexModelName(mirror *MirrorConfig) error {
+	if strings.TrimSpace(codexModelName(mirror)) == "" {
+		return fmt.Errorf("镜像源 '%s' 的模型名称为空，请使用 'codex-mirror set-model <模型>' 设置", mirror.Name)
 	}
+	return nil
+}
 
-	// 添加所有现有的 provider 配置（包括新添加的）
-	allProviders := make(map[string]ModelProviderConfig)
-	for k, v := range existingProviders {
-		allProviders[k] = v
+// updateRawConfigModelProviders 更新原始配置中的模型提供商配置.
+// 使用扁平化结构 [model_providers.mirrorname]，在已有的提供商表上合并本工具管理的字段，
+// 用户添加的其他键（如 http_headers、自定义参数）和其他提供商的全部内容都原样保留.
+func (ccm *CodexConfigManager) updateRawConfigModelProviders(rawConfig map[string]interface{}, mirrorName string, providerConfig ModelProviderConfig) {
+	// 将嵌套结构（文件中的 [model_providers.x] 解码后的形式）展开为扁平化的键
+	for name, provider := range rawModelProviders(rawConfig) {
+		rawConfig["model_providers."+name] = provider
 	}
-	allProviders[mirrorName] = providerConfig
+	delete(rawConfig, "model_providers")
 
-	// 将所有 provider 配置写入 rawConfig
-	for providerName, provider := range allProviders {
-		sectionName := "model_providers." + providerName
-		rawConfig[sectionName] = map[string]interface{}{
-			"name":                 provider.Name,
-			"base_url":             provider.BaseURL,
-			"wire_api":             provider.WireAPI,
-			"env_key":              provider.EnvKey,
-			"requires_openai_auth": provider.RequiresOpenAIAuth,
+	section, _ := rawConfig["model_providers."+mirrorName].(map[string]interface{})
+	if section == nil {
+		section = make(map[string]interface{})
+	}
+	section["name"] = providerConfig.Name
+	section["base_url"] = providerConfig.BaseURL
+	section["wire_api"] = providerConfig.WireAPI
+	section["env_key"] = providerConfig.EnvKey
+	section["requires_openai_auth"] = providerConfig.RequiresOpenAIAuth
+	if len(providerConfig.QueryParams) > 0 {
+		section["query_params"] = mergeRawTable(section["query_params"], providerConfig.QueryParams)
+	}
+	if len(providerConfig.EnvHTTPHeaders) > 0 {
+		section["env_http_headers"] = mergeRawTable(section["env_http_headers"], providerConfig.EnvHTTPHeaders)
+	}
+	rawConfig["model_providers."+mirrorName] = section
+}
+
+// rawModelProviders 返回原始配置中嵌套形式的模型提供商表.
+// 手写的 [[model_providers]] 表数组按各条目的 name 转换为以名称为键的表，缺少 name 的条目无法转换，会被忽略并给出警告.
+func rawModelProviders(rawConfig map[string]interface{}) map[string]interface{} {
+	switch providers := rawConfig["model_providers"].(type) {
+	case map[string]interface{}:
+		return providers
+	case []map[string]interface{}:
+		result := make(map[string]interface{}, len(providers))
+		for i, provider := range providers {
+			name, _ := provider["name"].(string)
+			if name == "" {
+				LogWarnf("⚠️  忽略第 %d 个 [[model_providers]]：缺少 name\n", i+1)
+				continue
+			}
+			result[name] = provider
 		}
+		return result
+	default:
+		return nil
 	}
 }
 
-// writeConfigFile 将配置写入文件（保留所有原始字段）.
-func (ccm *CodexConfigManager) writeConfigFile(rawConfig map[string]interface{}) error {
-	// 使用原子写入：先写入临时文件，再通过重命名替换原文件
-	configDir := filepath.Dir(ccm.configPath)
-	if err := os.MkdirAll(configDir, 0o755); err != nil {
-		return fmt.Errorf("创建配置目录失败: %v", err)
+// mergeRawTable 将 values 合并到原始配置中已有的表，保留表中其他的键.
+func mergeRawTable(existing interface{}, values map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	if table, ok := existing.(map[string]interface{}); ok {
+		for k, v := range table {
+			result[k] = v
+		}
+	}
+	for k, v := range values {
+		result[k] = v
 	}
+	return result
+}
 
-	tmpFile, err := os.CreateTemp(configDir, "config-*.toml")
-	if err != nil {
-		return fmt.Errorf("创建临时配置文件失败: %v", err)
+// stringMapToInterface 将字符串 map 转换为写入 TOML 使用的通用 map.
+func stringMapToInterface(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
 	}
-	tmpPath := tmpFile.Name()
+	return result
+}
 
-	defer func() {
-		_ = os.Remove(tmpPath)
-	}()
+// writeConfigFile 将配置写入文件（保留所有原始字段）.
+func (ccm *CodexConfigManager) writeConfigFile(rawConfig map[string]interface{}) error {
+	// 使用原子写入：先写入临时文件，再通过重命名替换原文件
+	return WriteFileAtomic(ccm.configPath, 0o600, func(w io.Writer) error {
+		return writeConfigContent(w, rawConfig)
+	})
+}
 
+// writeConfigContent 将原始配置以 TOML 格式写入 w.
+func writeConfigContent(w io.Writer, rawConfig map[string]interface{}) error {
 	// 分离不同类型的键
-	basicKeys := make(map[string]bool)    // 不包含点的简单键
-	dottedKeys := make(map[string]bool)   // 包含点的键（如 model_providers.xxx）
-	topLevelMaps := make(map[string]bool) // 顶级map键（如 projects, mcp）
+	basicKeys := make(map[string]bool)      // 不包含点的简单键
+	dottedKeys := make(map[string]bool)     // 包含点的键（如 model_providers.xxx）
+	topLevelMaps := make(map[string]bool)   // 顶级map键（如 projects, mcp）
+	tableArrayKeys := make(map[string]bool) // 顶级表数组键（[[key]]）
 
 	for key, value := range rawConfig {
 		switch {
@@ -284,6 +358,8 @@ func (ccm *CodexConfigManager) writeConfigFile(rawConfig map[string]interface{})
 			dottedKeys[key] = true
 		case isMap(value):
 			topLevelMaps[key] = true
+		case isTableArray(value):
+			tableArrayKeys[key] = true
 		default:
 			basicKeys[key] = true
 		}
@@ -292,7 +368,7 @@ func (ccm *CodexConfigManager) writeConfigFile(rawConfig map[string]interface{})
 	// 1. 写入基本配置项（不包含点的简单值）
 	for key, value := range rawConfig {
 		if basicKeys[key] {
-			if err := writeTOMLValue(tmpFile, key, value, ""); err != nil {
+			if err := writeTOMLValue(w, key, value, ""); err != nil {
 				return err
 			}
 		}
@@ -302,10 +378,7 @@ func (ccm *CodexConfigManager) writeConfigFile(rawConfig map[string]interface{})
 	for key, value := range rawConfig {
 		if dottedKeys[key] {
 			if subMap, ok := value.(map[string]interface{}); ok {
-				if _, err := fmt.Fprintf(tmpFile, "\n[%s]\n", key); err != nil {
-					return err
-				}
-				if err := writeTOMLMap(tmpFile, subMap, "  "); err != nil {
+				if err := writeTableSection(w, "["+key+"]", key, subMap); err != nil {
 					return err
 				}
 			}
@@ -316,51 +389,56 @@ func (ccm *CodexConfigManager) writeConfigFile(rawConfig map[string]interface{})
 	for key, value := range rawConfig {
 		if topLevelMaps[key] {
 			if subMap, ok := value.(map[string]interface{}); ok {
-				if err := writeTopLevelMapAsSections(tmpFile, key, subMap); err != nil {
+				if err := writeTopLevelMapAsSections(w, key, subMap); err != nil {
 					return err
 				}
 			}
 		}
 	}
 
-	// 将数据刷入磁盘
-	if err := tmpFile.Sync(); err != nil {
-		_ = tmpFile.Close()
-		return fmt.Errorf("同步临时配置文件失败: %v", err)
-	}
-
-	// 关闭临时文件句柄，避免在 Windows 上影响重命名
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("关闭临时配置文件失败: %v", err)
-	}
-
-	// 原子替换目标文件
-	if err := os.Rename(tmpPath, ccm.configPath); err != nil {
-		return fmt.Errorf("替换配置文件失败: %v", err)
+	// 4. 写入顶级表数组
+	for key, value := range rawConfig {
+		if tableArrayKeys[key] {
+			if err := writeTableArray(w, quoteTOMLKey(key), value.([]map[string]interface{})); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
 // writeTopLevelMapAsSections 将顶级map写入为带点的节.
-// 例如: projects map 转换为 [projects."/path"] 节.
-func writeTopLevelMapAsSections(file *os.File, prefix string, m map[string]interface{}) error {
+// 例如: projects map 转换为 [projects."/path"] 节；map 自身的简单值（如 [tools] 下的 web_search）写在 [prefix] 节内.
+func writeTopLevelMapAsSections(file io.Writer, prefix string, m map[string]interface{}) error {
+	ownValues := make(map[string]interface{})
 	for key, value := range m {
-		// 如果key包含特殊字符，需要用引号包裹
-		quotedKey := key
-		if needsQuoting(key) {
-			quotedKey = `"` + key + `"`
+		if !isMap(value) {
+			ownValues[key] = value
+		}
+	}
+	if len(ownValues) > 0 || len(m) == 0 {
+		if err := writeTableSection(file, "["+prefix+"]", prefix, ownValues); err != nil {
+			return err
 		}
-		fullKey := prefix + "." + quotedKey
+	}
+
+	for key, value := range m {
+		// 如果key包含特殊字符，需要用引号包裹
+		fullKey := prefix + "." + quoteTOMLKey(key)
 		if subMap, ok := value.(map[string]interface{}); ok {
-			// 分离嵌套map和简单值
+			// 分离嵌套map、表数组和简单值
 			nestedMaps := make(map[string]map[string]interface{})
+			tableArrays := make(map[string][]map[string]interface{})
 			simpleValues := make(map[string]interface{})
 
 			for k, v := range subMap {
-				if nestedMap, isMap := v.(map[string]interface{}); isMap {
-					nestedMaps[k] = nestedMap
-				} else {
+				switch t := v.(type) {
+				case map[string]interface{}:
+					nestedMaps[k] = t
+				case []map[string]interface{}:
+					tableArrays[k] = t
+				default:
 					simpleValues[k] = v
 				}
 			}
@@ -397,70 +475,120 @@ func writeTopLevelMapAsSections(file *os.File, prefix string, m map[string]inter
 					}
 				}
 			}
-		} else {
-			// 不应该发生，跳过
+
+			// 表数组写为 [[key]] 节
+			for k, items := range tableArrays {
+				if err := writeTableArray(file, fullKey+"."+quoteTOMLKey(k), items); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeTableSection 写入 header 指定的节（[key] 或 [[key]]）.
+// 简单值和只包含简单值的表写在节内，无法内联的嵌套表和表数组写为 key 下的子节.
+func writeTableSection(w io.Writer, header, key string, m map[string]interface{}) error {
+	if _, err := fmt.Fprintf(w, "\n%s\n", header); err != nil {
+		return err
+	}
+
+	subTables := make(map[string]interface{})
+	for k, v := range m {
+		if isSubTable(v) {
+			subTables[k] = v
 			continue
 		}
+		if err := writeTOMLValue(w, k, v, "  "); err != nil {
+			return err
+		}
+	}
+
+	for k, v := range subTables {
+		subKey := key + "." + quoteTOMLKey(k)
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if err := writeTableSection(w, "["+subKey+"]", subKey, t); err != nil {
+				return err
+			}
+		case []map[string]interface{}:
+			if err := writeTableArray(w, subKey, t); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
+// writeTableArray 将表数组的每个元素写为 [[key]] 节.
+func writeTableArray(w io.Writer, key string, items []map[string]interface{}) error {
+	for _, item := range items {
+		if err := writeTableSection(w, "[["+key+"]]", key, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isSubTable 判断值是否需要写为子节（无法内联的嵌套表或表数组）.
+func isSubTable(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return !shouldUseInlineTable(v)
+	case []map[string]interface{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// quoteTOMLKey 在键包含特殊字符时用引号包裹.
+func quoteTOMLKey(key string) string {
+	if needsQuoting(key) {
+		return `"` + key + `"`
+	}
+	return key
+}
+
 // isMap 判断给定的值是否为 map[string]interface{}.
 func isMap(value interface{}) bool {
 	_, ok := value.(map[string]interface{})
 	return ok
 }
 
+// isTableArray 判断给定的值是否为表数组 []map[string]interface{}.
+func isTableArray(value interface{}) bool {
+	_, ok := value.([]map[string]interface{})
+	return ok
+}
+
 // needsQuoting 判断TOML键是否需要引号包裹.
-// 如果包含特殊字符（如/、空格等）则需要引号.
+// 只包含字母、数字和下划线的键可以直接书写，其他字符（如/、空格、-、.）需要引号.
 func needsQuoting(key string) bool {
 	// 如果已经有引号，不需要再加
-	if strings.HasPrefix(key, `"`) && strings.HasSuffix(key, `"`) {
+	if len(key) >= 2 && strings.HasPrefix(key, `"`) && strings.HasSuffix(key, `"`) {
 		return false
 	}
+	if key == "" {
+		return true
+	}
 	// 检查是否包含需要引号的字符
 	for _, ch := range key {
-		if ch == '/' || ch == ' ' || ch == '\\' || ch == '-' || ch == '.' {
+		isBare := (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch == '_'
+		if !isBare {
 			return true
 		}
 	}
 	return false
 }
 
-// writeTOMLMap 将 map[string]interface{} 写入 TOML 文件（标准格式）.
-func writeTOMLMap(file *os.File, m map[string]interface{}, indent string) error {
-	// 标准格式：每个键值对单独一行
-	// 但对于env字段，如果是简单map则使用内联表格式
-	for key, value := range m {
-		// 特殊处理：env字段使用内联表格式
-		if key == "env" {
-			if envMap, ok := value.(map[string]interface{}); ok && shouldUseInlineTable(envMap) {
-				// 使用内联表格式
-				if _, err := fmt.Fprintf(file, "%senv = ", indent); err != nil {
-					return err
-				}
-				if err := writeInlineTable(envMap, file); err != nil {
-					return err
-				}
-				if _, err := fmt.Fprintf(file, "\n"); err != nil {
-					return err
-				}
-				continue
-			}
-		}
-		if err := writeTOMLValue(file, key, value, indent); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 // shouldUseInlineTable 判断是否应该使用内联表格式.
 func shouldUseInlineTable(m map[string]interface{}) bool {
-	// 如果map只包含简单类型（字符串、数字、布尔值、数组），使用内联表
+	// 如果map只包含简单类型（字符串、数字、布尔值、日期时间、数组），使用内联表
 	for _, value := range m {
 		switch value.(type) {
-		case string, int, int32, int64, float32, float64, bool, []interface{}:
+		case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool, time.Time, []interface{}:
 			// 简单类型，适合内联表
 			continue
 		case map[string]interface{}:
@@ -473,150 +601,151 @@ func shouldUseInlineTable(m map[string]interface{}) bool {
 	return true
 }
 
-// writeInlineTableValue 写入内联表的值.
-func writeInlineTableValue(file *os.File, key string, value interface{}) error {
-	switch v := value.(type) {
-	case string:
-		_, err := fmt.Fprintf(file, "%s = %q", key, v)
-		return err
-	case bool:
-		_, err := fmt.Fprintf(file, "%s = %t", key, v)
-		return err
-	case int, int32, int64:
-		_, err := fmt.Fprintf(file, "%s = %d", key, v)
-		return err
-	case float32, float64:
-		_, err := fmt.Fprintf(file, "%s = %f", key, v)
-		return err
-	case []interface{}:
-		// 内联数组
-		if _, err := fmt.Fprintf(file, "%s = [", key); err != nil {
-			return err
-		}
-		for i, item := range v {
-			if i > 0 {
-				if _, err := fmt.Fprintf(file, ", "); err != nil {
-					return err
-				}
-			}
-			if s, ok := item.(string); ok {
-				if _, err := fmt.Fprintf(file, "%q", s); err != nil {
-					return err
-				}
-			} else {
-				if _, err := fmt.Fprintf(file, "%v", item); err != nil {
-					return err
-				}
-			}
-		}
-		_, err := fmt.Fprintf(file, "]")
-		return err
-	default:
-		_, err := fmt.Fprintf(file, "%s = %v", key, v)
+// writeTOMLValue 将单个键值对写入 TOML 文件.
+func writeTOMLValue(file io.Writer, key string, value interface{}, indent string) error {
+	// 复杂map，不应该直接作为值，需要写为单独的节
+	if m, ok := value.(map[string]interface{}); ok && !shouldUseInlineTable(m) {
+		return fmt.Errorf("复杂map值不支持直接写入: %s", key)
+	}
+
+	formatted, err := formatTOMLValue(value)
+	if err != nil {
+		return fmt.Errorf("写入配置项 %s 失败: %w", key, err)
+	}
+	_, err = fmt.Fprintf(file, "%s%s = %s\n", indent, quoteTOMLKey(key), formatted)
+	return err
+}
+
+// writeInlineTable 写入内联表格式: { key1 = val1, key2 = val2 }.
+func writeInlineTable(m map[string]interface{}, file io.Writer) error {
+	formatted, err := formatInlineTable(m)
+	if err != nil {
 		return err
 	}
+	_, err = io.WriteString(file, formatted)
+	return err
 }
 
-// writeTOMLValue 将单个键值对写入 TOML 文件.
-func writeTOMLValue(file *os.File, key string, value interface{}, indent string) error {
+// formatTOMLValue 将值格式化为 TOML 字面量，嵌套的数组和表使用内联格式.
+func formatTOMLValue(value interface{}) (string, error) {
 	switch v := value.(type) {
 	case string:
-		_, err := fmt.Fprintf(file, "%s%s = %q\n", indent, key, v)
-		return err
+		return fmt.Sprintf("%q", v), nil
 	case bool:
-		_, err := fmt.Fprintf(file, "%s%s = %t\n", indent, key, v)
-		return err
+		return strconv.FormatBool(v), nil
 	case int:
-		_, err := fmt.Fprintf(file, "%s%s = %d\n", indent, key, v)
-		return err
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
 	case int32:
-		_, err := fmt.Fprintf(file, "%s%s = %d\n", indent, key, v)
-		return err
+		return strconv.FormatInt(int64(v), 10), nil
 	case int64:
-		_, err := fmt.Fprintf(file, "%s%s = %d\n", indent, key, v)
-		return err
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			// TOML 整数为 64 位有符号整数
+			return "", fmt.Errorf("整数 %d 超出 TOML 整数范围", v)
+		}
+		return strconv.FormatUint(v, 10), nil
 	case float32:
-		_, err := fmt.Fprintf(file, "%s%s = %f\n", indent, key, v)
-		return err
+		return formatTOMLFloat(float64(v), 32), nil
 	case float64:
-		_, err := fmt.Fprintf(file, "%s%s = %f\n", indent, key, v)
-		return err
+		return formatTOMLFloat(v, 64), nil
+	case time.Time:
+		return formatTOMLDatetime(v), nil
 	case []interface{}:
-		// 处理数组
-		return writeTOMLArray(file, key, v, indent)
-	case map[string]interface{}:
-		// map 值：检查是否应该使用内联表
-		if shouldUseInlineTable(v) {
-			// 内联表格式：key = { field1 = val1, field2 = val2 }
-			if _, err := fmt.Fprintf(file, "%s%s = ", indent, key); err != nil {
-				return err
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			formatted, err := formatTOMLValue(item)
+			if err != nil {
+				return "", err
 			}
-			if err := writeInlineTable(v, file); err != nil {
-				return err
+			items = append(items, formatted)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case []map[string]interface{}:
+		// 表数组作为值时使用内联表数组：[{ a = 1 }, { a = 2 }]
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			formatted, err := formatInlineTable(item)
+			if err != nil {
+				return "", err
 			}
-			_, err := fmt.Fprintf(file, "\n")
-			return err
+			items = append(items, formatted)
 		}
-		// 复杂map，不应该直接作为值
-		return fmt.Errorf("复杂map值不支持直接写入: %s", key)
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		return formatInlineTable(v)
 	default:
-		_, err := fmt.Fprintf(file, "%s%s = %v\n", indent, key, v)
-		return err
+		return "", fmt.Errorf("不支持的值类型 %T", value)
 	}
 }
 
-// writeInlineTable 写入内联表格式: { key1 = val1, key2 = val2 }.
-func writeInlineTable(m map[string]interface{}, file *os.File) error {
-	if _, err := fmt.Fprintf(file, "{"); err != nil {
-		return err
-	}
-	first := true
+// formatInlineTable 将 map 格式化为内联表: { key1 = val1, key2 = val2 }.
+func formatInlineTable(m map[string]interface{}) (string, error) {
+	items := make([]string, 0, len(m))
 	for key, value := range m {
-		if !first {
-			if _, err := fmt.Fprintf(file, ", "); err != nil {
-				return err
-			}
-		}
-		first = false
-		if err := writeInlineTableValue(file, key, value); err != nil {
-			return err
-		}
-	}
-	_, err := fmt.Fprintf(file, "}")
-	return err
-}
-
-// writeTOMLArray 将数组写入 TOML 格式.
-func writeTOMLArray(file *os.File, key string, arr []interface{}, indent string) error {
-	if _, err := fmt.Fprintf(file, "%s%s = [", indent, key); err != nil {
-		return err
-	}
-
-	for i, value := range arr {
-		if i > 0 {
-			if _, err := fmt.Fprintf(file, ", "); err != nil {
-				return err
-			}
-		}
-
-		switch v := value.(type) {
-		case string:
-			if _, err := fmt.Fprintf(file, "%q", v); err != nil {
-				return err
-			}
-		case int, int32, int64, float32, float64, bool:
-			if _, err := fmt.Fprintf(file, "%v", v); err != nil {
-				return err
-			}
-		default:
-			if _, err := fmt.Fprintf(file, "%v", v); err != nil {
-				return err
-			}
+		formatted, err := formatTOMLValue(value)
+		if err != nil {
+			return "", err
 		}
+		items = append(items, quoteTOMLKey(key)+" = "+formatted)
+	}
+	if len(items) == 0 {
+		return "{}", nil
+	}
+	return "{ " + strings.Join(items, ", ") + " }", nil
+}
+
+// maxExactFloatInteger 浮点数能精确表示的最大整数（2^53），超过时整数值按指数形式写出.
+const maxExactFloatInteger = 1 << 53
+
+// formatTOMLFloat 以最短且不丢失精度的形式格式化浮点数.
+// 整数值的浮点数按整数书写并补上 ".0"（如 10485760.0），既不出现指数形式，再次读取时也仍是浮点数.
+// bitSize 为原始值的位数（32 或 64），float32 按自身精度取最短表示.
+func formatTOMLFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	if f == math.Trunc(f) && math.Abs(f) <= maxExactFloatInteger {
+		return strconv.FormatFloat(f, 'f', -1, bitSize) + ".0"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".e") {
+		// TOML 浮点数必须包含小数点或指数
+		s += ".0"
+	}
+	return s
+}
+
+// formatTOMLDatetime 格式化日期时间.
+// 解码器用特定名称的时区标记本地日期时间、本地日期和本地时间，按原有的类型写回.
+func formatTOMLDatetime(t time.Time) string {
+	switch t.Location().String() {
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	case "date-local":
+		return t.Format("2006-01-02")
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	default:
+		return t.Format(time.RFC3339Nano)
 	}
-
-	_, err := fmt.Fprintf(file, "]\n")
-	return err
 }
 
 // UpdateAuth 更新Codex认证文件.
@@ -626,41 +755,14 @@ func (ccm *CodexConfigManager) UpdateAuth(mirror *MirrorConfig) error {
 	}
 
 	// 使用原子写入 auth.json：写入临时文件后重命名
-	authDir := filepath.Dir(ccm.authPath)
-	if err := os.MkdirAll(authDir, 0o755); err != nil {
-		return fmt.Errorf("创建认证目录失败: %v", err)
-	}
-
-	tmpFile, err := os.CreateTemp(authDir, "auth-*.json")
-	if err != nil {
-		return fmt.Errorf("创建临时认证文件失败: %v", err)
-	}
-	tmpPath := tmpFile.Name()
-	defer func() {
-		_ = os.Remove(tmpPath)
-	}()
-
-	encoder := json.NewEncoder(tmpFile)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(auth); err != nil {
-		_ = tmpFile.Close()
-		return fmt.Errorf("写入临时认证文件失败: %v", err)
-	}
-
-	if err := tmpFile.Sync(); err != nil {
-		_ = tmpFile.Close()
-		return fmt.Errorf("同步临时认证文件失败: %v", err)
-	}
-
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("关闭临时认证文件失败: %v", err)
-	}
-
-	if err := os.Rename(tmpPath, ccm.authPath); err != nil {
-		return fmt.Errorf("替换认证文件失败: %v", err)
-	}
-
-	return nil
+	return WriteFileAtomic(ccm.authPath, 0o600, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(auth); err != nil {
+			return fmt.Errorf("写入认证文件失败: %v", err)
+		}
+		return nil
+	})
 }
 
 // SetEnvironmentVariable 设置环境变量.
@@ -670,8 +772,33 @@ func (ccm *CodexConfigManager) SetEnvironmentVariable(envKey, apiKey string) err
 	return envManager.SetCodexEnvVar(envKey, apiKey)
 }
 
+// SetPersistEnv 设置应用镜像源时是否持久化环境变量.
+// 为 false 时只修改 config.toml 和 auth.json，环境变量只在当前进程中生效，新打开的终端不会继承密钥.
+func (ccm *CodexConfigManager) SetPersistEnv(persist bool) {
+	ccm.noPersistEnv = !persist
+}
+
+// UnsetEnvironmentVariable 清除 SetEnvironmentVariable 持久化的环境变量.
+func (ccm *CodexConfigManager) UnsetEnvironmentVariable(envKey string) error {
+	envManager := NewEnvManager()
+	return envManager.UnsetEnvVar(envKey)
+}
+
 // ApplyMirror 应用镜像源配置到Codex CLI.
 func (ccm *CodexConfigManager) ApplyMirror(mirror *MirrorConfig) error {
+	if err := checkMirrorToolType(mirror, ToolTypeCodex); err != nil {
+		return err
+	}
+	if err := checkCodexModelName(mirror); err != nil {
+		return err
+	}
+
+	// 配置了密钥命令时先获取密钥，失败时不修改任何文件
+	mirror, err := ResolveMirrorAPIKey(mirror)
+	if err != nil {
+		return err
+	}
+
 	// 首先修复所有镜像源的env_key格式
 	if err := ccm.FixEnvKeyFormat(); err != nil {
 		return fmt.Errorf("修复env_key格式失败: %v", err)
@@ -691,7 +818,11 @@ func (ccm *CodexConfigManager) ApplyMirror(mirror *MirrorConfig) error {
 	config, err := ccm.GetCurrentConfig()
 	if err == nil && config.ModelProviders != nil {
 		if provider, exists := config.ModelProviders[mirror.Name]; exists && provider.EnvKey != "" {
-			if err := ccm.SetEnvironmentVariable(provider.EnvKey, mirror.APIKey); err != nil {
+			if ccm.noPersistEnv {
+				if err := os.Setenv(provider.EnvKey, mirror.APIKey); err != nil {
+					return fmt.Errorf("设置环境变量失败: %v", err)
+				}
+			} else if err := ccm.SetEnvironmentVariable(provider.EnvKey, mirror.APIKey); err != nil {
 				return fmt.Errorf("设置环境变量失败: %v", err)
 			}
 		}
@@ -700,6 +831,52 @@ func (ccm *CodexConfigManager) ApplyMirror(mirror *MirrorConfig) error {
 	return nil
 }
 
+// PlanMirror 计算应用镜像源后 config.toml、auth.json 和环境变量的变化，不写入任何文件.
+func (ccm *CodexConfigManager) PlanMirror(mirror *MirrorConfig) ([]ConfigChange, error) {
+	if err := checkMirrorToolType(mirror, ToolTypeCodex); err != nil {
+		return nil, err
+	}
+	if err := checkCodexModelName(mirror); err != nil {
+		return nil, err
+	}
+
+	mirror, err := ResolveMirrorAPIKey(mirror)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &CodexConfig{}
+	if _, err := os.Stat(ccm.configPath); err == nil {
+		if _, err := ccm.decodeConfigFiles(config); err != nil {
+			return nil, err
+		}
+	}
+
+	oldProvider := config.ModelProviders[mirror.Name]
+	providerConfig := ccm.createProviderConfig(mirror, config)
+	providerPrefix := "model_providers." + mirror.Name + "."
+
+	oldKey := ""
+	if auth, err := ccm.GetCurrentAuth(); err == nil {
+		oldKey = auth.APIKey
+	}
+
+	changes := []ConfigChange{
+		{File: ccm.configPath, Key: "model_provider", Old: config.ModelProvider, New: mirror.Name},
+		{File: ccm.configPath, Key: "model", Old: config.Model, New: codexModelName(mirror)},
+		{File: ccm.configPath, Key: providerPrefix + "base_url", Old: oldProvider.BaseURL, New: providerConfig.BaseURL},
+		{File: ccm.configPath, Key: providerPrefix + "env_key", Old: oldProvider.EnvKey, New: providerConfig.EnvKey},
+		{File: ccm.configPath, Key: providerPrefix + "wire_api", Old: oldProvider.WireAPI, New: providerConfig.WireAPI},
+		{File: ccm.configPath, Key: providerPrefix + "query_params.api-version", Old: oldProvider.QueryParams["api-version"], New: providerConfig.QueryParams["api-version"]},
+		{File: ccm.authPath, Key: "OPENAI_API_KEY", Old: oldKey, New: mirror.APIKey, Secret: true},
+	}
+	if providerConfig.EnvKey != "" && !ccm.noPersistEnv {
+		changes = append(changes, PlanEnvVars(map[string]string{providerConfig.EnvKey: mirror.APIKey})...)
+	}
+
+	return changes, nil
+}
+
 // GetCurrentConfig 获取当前Codex配置.
 func (ccm *CodexConfigManager) GetCurrentConfig() (*CodexConfig, error) {
 	if _, err := os.Stat(ccm.configPath); os.IsNotExist(err) {
@@ -794,44 +971,15 @@ func (ccm *CodexConfigManager) BackupConfig() error {
 	return nil
 }
 
-// copyFile 复制文件.
 // saveConfig 保存配置到文件.
 func (ccm *CodexConfigManager) saveConfig(config *CodexConfig) error {
 	// 使用原子写入：先写入临时文件，再通过重命名替换原文件
-	configDir := filepath.Dir(ccm.configPath)
-	if err := os.MkdirAll(configDir, 0o755); err != nil {
-		return fmt.Errorf("创建配置目录失败: %v", err)
-	}
-
-	tmpFile, err := os.CreateTemp(configDir, "config-*.toml")
-	if err != nil {
-		return fmt.Errorf("创建临时配置文件失败: %v", err)
-	}
-	tmpPath := tmpFile.Name()
-	defer func() {
-		_ = os.Remove(tmpPath)
-	}()
-
-	encoder := toml.NewEncoder(tmpFile)
-	if err := encoder.Encode(config); err != nil {
-		_ = tmpFile.Close()
-		return fmt.Errorf("编码配置失败: %v", err)
-	}
-
-	if err := tmpFile.Sync(); err != nil {
-		_ = tmpFile.Close()
-		return fmt.Errorf("同步临时配置文件失败: %v", err)
-	}
-
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("关闭临时配置文件失败: %v", err)
-	}
-
-	if err := os.Rename(tmpPath, ccm.configPath); err != nil {
-		return fmt.Errorf("替换配置文件失败: %v", err)
-	}
-
-	return nil
+	return WriteFileAtomic(ccm.configPath, 0o600, func(w io.Writer) error {
+		if err := toml.NewEncoder(w).Encode(config); err != nil {
+			return fmt.Errorf("编码配置失败: %v", err)
+		}
+		return nil
+	})
 }
 
 // copyFile 复制文件.
@@ -2,8 +2,10 @@ package internal
 
 import (
 	"encoding/json"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/BurntSushi/toml"
@@ -117,6 +119,49 @@ func TestUpdateConfig(t *testing.T) {
 	}
 }
 
+// TestUpdateConfigResponseStorage 测试镜像源的 disable_response_storage 设置写入 Codex 配置.
+func TestUpdateConfigResponseStorage(t *testing.T) {
+	enabled, disabled := false, true
+	tests := []struct {
+		name    string
+		setting *bool
+		want    bool
+	}{
+		{"未设置时默认禁用", nil, true},
+		{"明确禁用", &disabled, true},
+		{"明确启用响应存储", &enabled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ccm := createTestCodexConfigManager(t, setupTestDir(t))
+			// 已有配置中的值不应影响镜像源的设置
+			if err := os.WriteFile(ccm.configPath, []byte("disable_response_storage = true\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			mirror := &MirrorConfig{
+				Name:                   TestProviderName,
+				BaseURL:                TestAPIURL,
+				EnvKey:                 CodexSwitchAPIKeyEnv,
+				ToolType:               ToolTypeCodex,
+				DisableResponseStorage: tt.setting,
+			}
+			if err := ccm.UpdateConfig(mirror); err != nil {
+				t.Fatalf("UpdateConfig() error = %v", err)
+			}
+
+			var raw map[string]interface{}
+			if _, err := toml.DecodeFile(ccm.configPath, &raw); err != nil {
+				t.Fatalf("Failed to read config file: %v", err)
+			}
+			if got, ok := raw["disable_response_storage"].(bool); !ok || got != tt.want {
+				t.Errorf("disable_response_storage = %v, expected %v", raw["disable_response_storage"], tt.want)
+			}
+		})
+	}
+}
+
 // TestUpdateConfigExisting 测试更新现有配置文件.
 func TestUpdateConfigExisting(t *testing.T) {
 	tempDir := setupTestDir(t)
@@ -195,6 +240,264 @@ func TestUpdateConfigExisting(t *testing.T) {
 	}
 }
 
+// TestApplyMirrorPreservesProviderSettings 测试切换镜像源时保留提供商表中用户自定义的设置.
+func TestApplyMirrorPreservesProviderSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name: "提供商表",
+			config: `model_provider = "custom"
+
+[model_providers.custom]
+name = "custom"
+base_url = "https://api.custom.com/v1"
+wire_api = "responses"
+env_key = "CUSTOM_API_KEY"
+http_headers = { X-Org = "org-123" }
+request_max_retries = 4
+
+[model_providers.custom.query_params]
+api-version = "2025-04-01-preview"
+
+[[model_providers.custom.fallbacks]]
+base_url = "https://backup.custom.com/v1"
+`,
+		},
+		{
+			name: "提供商表数组",
+			config: `model_provider = "custom"
+
+[[model_providers]]
+name = "custom"
+base_url = "https://api.custom.com/v1"
+wire_api = "responses"
+env_key = "CUSTOM_API_KEY"
+http_headers = { X-Org = "org-123" }
+request_max_retries = 4
+query_params = { api-version = "2025-04-01-preview" }
+
+[[model_providers.fallbacks]]
+base_url = "https://backup.custom.com/v1"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			ccm := createTestCodexConfigManager(t, tempDir)
+			if err := os.WriteFile(ccm.configPath, []byte(tt.config), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			for _, mirror := range []*MirrorConfig{
+				{Name: "other", BaseURL: "https://api.other.com", APIKey: "other-key", ToolType: ToolTypeCodex},
+				{Name: "custom", BaseURL: "https://api.custom.com/v2", APIKey: "custom-key", ToolType: ToolTypeCodex},
+			} {
+				if err := ccm.ApplyMirror(mirror); err != nil {
+					t.Fatalf("ApplyMirror(%s) error = %v", mirror.Name, err)
+				}
+			}
+
+			var raw map[string]interface{}
+			if _, err := toml.DecodeFile(ccm.configPath, &raw); err != nil {
+				t.Fatalf("Failed to decode config: %v", err)
+			}
+			providers, _ := raw["model_providers"].(map[string]interface{})
+			custom, _ := providers["custom"].(map[string]interface{})
+			if custom == nil {
+				t.Fatalf("custom provider missing: %v", raw)
+			}
+
+			if custom["base_url"] != "https://api.custom.com/v2" || custom["env_key"] != CodexSwitchAPIKeyEnv {
+				t.Errorf("managed fields not updated: %v", custom)
+			}
+			if headers, _ := custom["http_headers"].(map[string]interface{}); headers["X-Org"] != "org-123" {
+				t.Errorf("http_headers = %v, expected X-Org preserved", custom["http_headers"])
+			}
+			if custom["request_max_retries"] != int64(4) {
+				t.Errorf("request_max_retries = %v, expected 4", custom["request_max_retries"])
+			}
+			if params, _ := custom["query_params"].(map[string]interface{}); params["api-version"] != "2025-04-01-preview" {
+				t.Errorf("query_params = %v, expected api-version preserved", custom["query_params"])
+			}
+			fallbacks, _ := custom["fallbacks"].([]map[string]interface{})
+			if len(fallbacks) != 1 || fallbacks[0]["base_url"] != "https://backup.custom.com/v1" {
+				t.Errorf("fallbacks = %v, expected one preserved entry", custom["fallbacks"])
+			}
+			if _, exists := providers["other"]; !exists {
+				t.Error("other provider should be added")
+			}
+		})
+	}
+}
+
+// TestUpdateConfigPreservesUnknownKeys 测试切换镜像源时本工具不管理的配置项语义保持不变.
+func TestUpdateConfigPreservesUnknownKeys(t *testing.T) {
+	const fixture = `model = "gpt-5"
+model_provider = "custom"
+model_reasoning_effort = "medium"
+approval_policy = "on-request"
+sandbox_mode = "workspace-write"
+model_temperature = 0.7
+model_context_window = 272000
+notify = ["notify-send", "Codex"]
+last_reviewed = 2025-06-01T08:30:00Z
+review_date = 2025-06-01
+quiet_start = 22:30:00
+local_snapshot = 2025-06-01T08:30:00.5
+
+[tools]
+web_search = true
+
+[sandbox_workspace_write]
+network_access = false
+writable_roots = ["/tmp", "/var/cache"]
+
+[shell_environment_policy]
+inherit = "core"
+exclude = ["AWS_*"]
+
+[shell_environment_policy.set]
+PATH = "/usr/bin"
+
+[history]
+persistence = "save-all"
+max_bytes = 10485760
+
+[profiles.fast]
+model = "gpt-5-mini"
+model_reasoning_effort = "low"
+tool_timeout = 1.5
+
+[projects."/home/user/my repo"]
+trust_level = "trusted"
+
+[mcp_servers.docs]
+command = "npx"
+args = ["-y", "docs-mcp"]
+env = { API_TOKEN = "token", "X.Trace" = "on" }
+startup_timeout_sec = 12.5
+
+[[mcp_servers.docs.tools]]
+name = "search"
+
+[[mcp_servers.docs.tools]]
+name = "fetch"
+matrix = [[1, 2], [3, 4]]
+limits = [{ kind = "rate", value = 0.25 }]
+
+[[hooks]]
+event = "start"
+command = ["echo", "hi"]
+
+[model_providers.custom]
+name = "custom"
+base_url = "https://api.custom.com/v1"
+wire_api = "responses"
+env_key = "CODEX_SWITCH_OPENAI_API_KEY"
+`
+
+	tempDir := setupTestDir(t)
+	ccm := createTestCodexConfigManager(t, tempDir)
+	if err := os.WriteFile(ccm.configPath, []byte(fixture), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	var before map[string]interface{}
+	if _, err := toml.Decode(fixture, &before); err != nil {
+		t.Fatalf("Failed to decode fixture: %v", err)
+	}
+
+	mirror := &MirrorConfig{Name: "other", BaseURL: "https://api.other.com", APIKey: "other-key", ToolType: ToolTypeCodex}
+	if err := ccm.UpdateConfig(mirror); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+
+	var after map[string]interface{}
+	if _, err := toml.DecodeFile(ccm.configPath, &after); err != nil {
+		content, _ := os.ReadFile(ccm.configPath)
+		t.Fatalf("Failed to decode updated config: %v\n%s", err, content)
+	}
+
+	// 本工具管理的字段允许变化，其余配置项必须保持不变
+	for _, managed := range []string{"model", "model_provider", "model_reasoning_effort", "disable_response_storage", "model_providers"} {
+		delete(before, managed)
+		delete(after, managed)
+	}
+	for key, want := range before {
+		if got := after[key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %#v, expected %#v", key, got, want)
+		}
+	}
+	for key := range after {
+		if _, exists := before[key]; !exists {
+			t.Errorf("unexpected key %s in updated config", key)
+		}
+	}
+}
+
+// TestFormatTOMLValueNumbers 测试数值写回 TOML 时不丢失精度且保持整数与浮点数类型.
+func TestFormatTOMLValueNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"小数", 0.7, "0.7"},
+		{"高精度小数", 0.123456789012, "0.123456789012"},
+		{"float32 小数", float32(0.1), "0.1"},
+		{"整数值浮点数", 1.0, "1.0"},
+		{"大的整数值浮点数", 10485760.0, "10485760.0"},
+		{"负的整数值浮点数", -3.0, "-3.0"},
+		{"极小值", 1e-7, "1e-07"},
+		{"极大值", 1.5e300, "1.5e+300"},
+		{"超过精确范围的整数值浮点数", 1e20, "1e+20"},
+		{"无穷大", math.Inf(1), "inf"},
+		{"整数", int64(272000), "272000"},
+		{"大整数", int64(9007199254740993), "9007199254740993"},
+		{"负整数", -42, "-42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatTOMLValue(tt.value)
+			if err != nil {
+				t.Fatalf("formatTOMLValue(%v) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("formatTOMLValue(%v) = %s, want %s", tt.value, got, tt.want)
+			}
+
+			// 再次解码应得到相同类型和数值
+			var decoded map[string]interface{}
+			if _, err := toml.Decode("v = "+got, &decoded); err != nil {
+				t.Fatalf("decode %q error = %v", got, err)
+			}
+			switch want := tt.value.(type) {
+			case float64:
+				if decoded["v"] != want {
+					t.Errorf("decoded %#v, want %#v", decoded["v"], want)
+				}
+			case float32:
+				if f, ok := decoded["v"].(float64); !ok || float32(f) != want {
+					t.Errorf("decoded %#v, want %#v", decoded["v"], want)
+				}
+			case int:
+				if decoded["v"] != int64(want) {
+					t.Errorf("decoded %#v, want %#v", decoded["v"], want)
+				}
+			case int64:
+				if decoded["v"] != want {
+					t.Errorf("decoded %#v, want %#v", decoded["v"], want)
+				}
+			}
+		})
+	}
+}
+
 // TestUpdateAuth 测试更新认证文件.
 func TestUpdateAuth(t *testing.T) {
 	tempDir := setupTestDir(t)
@@ -442,6 +745,48 @@ func TestApplyMirror(t *testing.T) {
 	}
 }
 
+// TestApplyMirrorNoPersistEnv 测试关闭环境变量持久化时只更新配置文件，不写入 shell 配置文件.
+func TestApplyMirrorNoPersistEnv(t *testing.T) {
+	tempDir := setupTestDir(t)
+	ccm := createTestCodexConfigManager(t, tempDir)
+	ccm.SetPersistEnv(false)
+	t.Setenv(CodexSwitchAPIKeyEnv, "")
+
+	mirror := &MirrorConfig{
+		Name:     "no-env",
+		BaseURL:  "https://api.no-env.com",
+		APIKey:   "sk-no-env",
+		EnvKey:   CodexSwitchAPIKeyEnv,
+		ToolType: ToolTypeCodex,
+	}
+
+	changes, err := ccm.PlanMirror(mirror)
+	if err != nil {
+		t.Fatalf("PlanMirror() error = %v", err)
+	}
+	for _, change := range changes {
+		if change.File == EnvChangeTarget {
+			t.Errorf("关闭持久化时预览不应包含环境变量变化: %+v", change)
+		}
+	}
+
+	if err := ccm.ApplyMirror(mirror); err != nil {
+		t.Fatalf("ApplyMirror() error = %v", err)
+	}
+
+	if auth, err := ccm.GetCurrentAuth(); err != nil || auth.APIKey != "sk-no-env" {
+		t.Errorf("auth.json 未更新: auth = %+v, err = %v", auth, err)
+	}
+	if got := os.Getenv(CodexSwitchAPIKeyEnv); got != "sk-no-env" {
+		t.Errorf("当前进程的环境变量 = %q, want sk-no-env", got)
+	}
+	for _, rc := range []string{".bashrc", ".profile", ".zshrc"} {
+		if _, err := os.Stat(filepath.Join(tempDir, rc)); !os.IsNotExist(err) {
+			t.Errorf("关闭持久化时不应写入 %s", rc)
+		}
+	}
+}
+
 // TestFixEnvKeyFormat 测试修复环境变量key格式.
 func TestFixEnvKeyFormat(t *testing.T) {
 	tempDir := setupTestDir(t)
@@ -3,6 +3,9 @@ package internal
 import (
 	"encoding/hex"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -28,10 +31,28 @@ const (
 	ConfigFileName string = "codex-mirror-config.json"
 
 	// Field names for conflict resolution.
-	FieldNameToolType string = "ToolType"
-	FieldNameAPIKey   string = "APIKey"
-	FieldNameBaseURL  string = "BaseURL"
-	FieldNameModel    string = "ModelName"
+	FieldNameToolType      string = "ToolType"
+	FieldNameAPIKey        string = "APIKey"
+	FieldNameAPIKeyCommand string = "APIKeyCommand"
+	FieldNameBaseURL       string = "BaseURL"
+	FieldNameModel         string = "ModelName"
+	FieldNameHaikuModel    string = "HaikuModel"
+	FieldNameSonnetModel   string = "SonnetModel"
+	FieldNameOpusModel     string = "OpusModel"
+	FieldNameProxy         string = "Proxy"
+	FieldNameTags          string = "Tags"
+	FieldNameHealthPath    string = "HealthPath"
+	FieldNameTimeout       string = "TimeoutSeconds"
+
+	FieldNameProviderKind string = "ProviderKind"
+	FieldNameAPIVersion   string = "APIVersion"
+
+	FieldNameDisableResponseStorage string = "DisableResponseStorage"
+
+	// FieldNameExtraEnvPrefix 额外环境变量字段名前缀，完整字段名如 ExtraEnv.API_TIMEOUT_MS.
+	FieldNameExtraEnvPrefix string = "ExtraEnv."
+	// FieldNameTestHeadersPrefix 测试请求头字段名前缀，完整字段名如 TestHeaders.X-Org-Id.
+	FieldNameTestHeadersPrefix string = "TestHeaders."
 )
 
 // ConflictItem 冲突项.
@@ -285,9 +306,30 @@ func (cr *ConflictResolver) isMirrorModified(local, remote *MirrorConfig) bool {
 	return local.BaseURL != remote.BaseURL ||
 		local.ToolType != remote.ToolType ||
 		local.ModelName != remote.ModelName ||
+		local.HaikuModel != remote.HaikuModel ||
+		local.SonnetModel != remote.SonnetModel ||
+		local.OpusModel != remote.OpusModel ||
+		local.Proxy != remote.Proxy ||
+		local.APIKeyCommand != remote.APIKeyCommand ||
+		local.HealthPath != remote.HealthPath ||
+		local.TimeoutSeconds != remote.TimeoutSeconds ||
+		local.ProviderKind != remote.ProviderKind ||
+		local.APIVersion != remote.APIVersion ||
+		!equalBoolPtr(local.DisableResponseStorage, remote.DisableResponseStorage) ||
+		!maps.Equal(local.TestHeaders, remote.TestHeaders) ||
+		!slices.Equal(local.Tags, remote.Tags) ||
+		!maps.Equal(local.ExtraEnv, remote.ExtraEnv) ||
 		apiKeyConflict
 }
 
+// equalBoolPtr 比较两个可选布尔值，均未设置也视为相等.
+func equalBoolPtr(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 // DetectFieldConflicts 检测两个镜像源之间的字段级冲突.
 // 返回需要用户选择的冲突字段列表，不包括可以自动合并的字段.
 func (cr *ConflictResolver) DetectFieldConflicts(local, remote *MirrorConfig) []FieldConflict {
@@ -317,6 +359,90 @@ func (cr *ConflictResolver) DetectFieldConflicts(local, remote *MirrorConfig) []
 		})
 	}
 
+	// 检查各级别模型
+	for _, field := range []struct{ name, local, remote string }{
+		{FieldNameHaikuModel, local.HaikuModel, remote.HaikuModel},
+		{FieldNameSonnetModel, local.SonnetModel, remote.SonnetModel},
+		{FieldNameOpusModel, local.OpusModel, remote.OpusModel},
+	} {
+		if field.local != field.remote {
+			conflicts = append(conflicts, FieldConflict{
+				FieldName:    field.name,
+				LocalValue:   field.local,
+				RemoteValue:  field.remote,
+				LocalTime:    local.LastModified,
+				RemoteTime:   remote.LastModified,
+				RemoteDevice: cr.remoteData.DeviceID,
+			})
+		}
+	}
+
+	// 检查 Proxy
+	if local.Proxy != remote.Proxy {
+		conflicts = append(conflicts, FieldConflict{
+			FieldName:    FieldNameProxy,
+			LocalValue:   local.Proxy,
+			RemoteValue:  remote.Proxy,
+			LocalTime:    local.LastModified,
+			RemoteTime:   remote.LastModified,
+			RemoteDevice: cr.remoteData.DeviceID,
+		})
+	}
+
+	// 检查 HealthPath
+	if local.HealthPath != remote.HealthPath {
+		conflicts = append(conflicts, FieldConflict{
+			FieldName:    FieldNameHealthPath,
+			LocalValue:   local.HealthPath,
+			RemoteValue:  remote.HealthPath,
+			LocalTime:    local.LastModified,
+			RemoteTime:   remote.LastModified,
+			RemoteDevice: cr.remoteData.DeviceID,
+		})
+	}
+
+	// 检查 TimeoutSeconds
+	if local.TimeoutSeconds != remote.TimeoutSeconds {
+		conflicts = append(conflicts, FieldConflict{
+			FieldName:    FieldNameTimeout,
+			LocalValue:   strconv.Itoa(local.TimeoutSeconds),
+			RemoteValue:  strconv.Itoa(remote.TimeoutSeconds),
+			LocalTime:    local.LastModified,
+			RemoteTime:   remote.LastModified,
+			RemoteDevice: cr.remoteData.DeviceID,
+		})
+	}
+
+	// 检查提供商形式和 API 版本，提供商形式决定整个 Codex provider 配置块的写法
+	for _, field := range []struct{ name, local, remote string }{
+		{FieldNameProviderKind, string(local.ProviderKind), string(remote.ProviderKind)},
+		{FieldNameAPIVersion, local.APIVersion, remote.APIVersion},
+	} {
+		if field.local != field.remote {
+			conflicts = append(conflicts, FieldConflict{
+				FieldName:    field.name,
+				LocalValue:   field.local,
+				RemoteValue:  field.remote,
+				LocalTime:    local.LastModified,
+				RemoteTime:   remote.LastModified,
+				RemoteDevice: cr.remoteData.DeviceID,
+			})
+		}
+	}
+
+	// 检查 DisableResponseStorage - 两边都设置且不同才是冲突，单方设置由自动合并处理
+	if local.DisableResponseStorage != nil && remote.DisableResponseStorage != nil &&
+		*local.DisableResponseStorage != *remote.DisableResponseStorage {
+		conflicts = append(conflicts, FieldConflict{
+			FieldName:    FieldNameDisableResponseStorage,
+			LocalValue:   strconv.FormatBool(*local.DisableResponseStorage),
+			RemoteValue:  strconv.FormatBool(*remote.DisableResponseStorage),
+			LocalTime:    local.LastModified,
+			RemoteTime:   remote.LastModified,
+			RemoteDevice: cr.remoteData.DeviceID,
+		})
+	}
+
 	// 检查 ToolType
 	if local.ToolType != remote.ToolType {
 		conflicts = append(conflicts, FieldConflict{
@@ -343,6 +469,43 @@ func (cr *ConflictResolver) DetectFieldConflicts(local, remote *MirrorConfig) []
 		})
 	}
 
+	// 检查 APIKeyCommand - 放在 APIKey 之后，选择非空命令时会清空保存的密钥
+	if local.APIKeyCommand != remote.APIKeyCommand {
+		conflicts = append(conflicts, FieldConflict{
+			FieldName:    FieldNameAPIKeyCommand,
+			LocalValue:   local.APIKeyCommand,
+			RemoteValue:  remote.APIKeyCommand,
+			LocalTime:    local.LastModified,
+			RemoteTime:   remote.LastModified,
+			RemoteDevice: cr.remoteData.DeviceID,
+		})
+	}
+
+	// 检查 ExtraEnv 和 TestHeaders - 同一个 key 两边值不同才是冲突，单方存在的 key 由自动合并处理
+	conflicts = append(conflicts, cr.detectMapConflicts(FieldNameExtraEnvPrefix, local.ExtraEnv, remote.ExtraEnv, local, remote)...)
+	conflicts = append(conflicts, cr.detectMapConflicts(FieldNameTestHeadersPrefix, local.TestHeaders, remote.TestHeaders, local, remote)...)
+
+	return conflicts
+}
+
+// detectMapConflicts 检测键值对字段中两边都存在但值不同的 key，字段名为 prefix + key.
+func (cr *ConflictResolver) detectMapConflicts(prefix string, localValues, remoteValues map[string]string, local, remote *MirrorConfig) []FieldConflict {
+	var conflicts []FieldConflict
+	keys := slices.Sorted(maps.Keys(localValues))
+	for _, key := range keys {
+		remoteValue, exists := remoteValues[key]
+		if !exists || remoteValue == localValues[key] {
+			continue
+		}
+		conflicts = append(conflicts, FieldConflict{
+			FieldName:    prefix + key,
+			LocalValue:   localValues[key],
+			RemoteValue:  remoteValue,
+			LocalTime:    local.LastModified,
+			RemoteTime:   remote.LastModified,
+			RemoteDevice: cr.remoteData.DeviceID,
+		})
+	}
 	return conflicts
 }
 
@@ -350,6 +513,8 @@ func (cr *ConflictResolver) DetectFieldConflicts(local, remote *MirrorConfig) []
 // 处理单方修改或单方有值的情况，返回合并后的配置和自动合并的信息.
 func (cr *ConflictResolver) AutoMergeNonConflicting(local, remote *MirrorConfig) (*MirrorConfig, []FieldResolution) {
 	merged := *local // 使用本地作为基础
+	merged.ExtraEnv = maps.Clone(local.ExtraEnv)
+	merged.TestHeaders = maps.Clone(local.TestHeaders)
 	var autoResolutions []FieldResolution
 
 	// APIKey 特殊处理 - 需要先解密远程的 APIKey
@@ -360,15 +525,63 @@ func (cr *ConflictResolver) AutoMergeNonConflicting(local, remote *MirrorConfig)
 		merged.APIKey = remoteAPIKey
 		autoResolutions = append(autoResolutions, FieldResolution{
 			FieldName:     FieldNameAPIKey,
-			ResolvedValue: maskAPIKey(remoteAPIKey), // 显示时脱敏
+			ResolvedValue: MaskAPIKey(remoteAPIKey), // 显示时脱敏
 			Choice:        StrategyAuto,
 		})
-		PrintAutoMergeInfo(FieldNameAPIKey, maskAPIKey(remoteAPIKey), "本地为空，使用远程")
+		PrintAutoMergeInfo(FieldNameAPIKey, MaskAPIKey(remoteAPIKey), "本地为空，使用远程")
 	}
 	// 如果本地有，远程没有 → 保持本地（已经是了）
 	// 如果都有且相同 → 保持本地（已经是了）
 	// 如果都有且不同 → 这是冲突，由交互式解决
 
+	// ExtraEnv 和 TestHeaders 中仅远程存在的 key → 合并到本地
+	for _, field := range []struct {
+		prefix        string
+		local, remote map[string]string
+	}{
+		{FieldNameExtraEnvPrefix, local.ExtraEnv, remote.ExtraEnv},
+		{FieldNameTestHeadersPrefix, local.TestHeaders, remote.TestHeaders},
+	} {
+		for _, key := range slices.Sorted(maps.Keys(field.remote)) {
+			if _, exists := field.local[key]; exists {
+				continue
+			}
+			fieldName := field.prefix + key
+			value := field.remote[key]
+			cr.applyFieldResolution(&merged, fieldName, value)
+			autoResolutions = append(autoResolutions, FieldResolution{
+				FieldName:     fieldName,
+				ResolvedValue: DisplayFieldValue(fieldName, value),
+				Choice:        StrategyAuto,
+			})
+			PrintAutoMergeInfo(fieldName, DisplayFieldValue(fieldName, value), "本地没有，使用远程")
+		}
+	}
+
+	// DisableResponseStorage 仅远程设置 → 使用远程
+	if local.DisableResponseStorage == nil && remote.DisableResponseStorage != nil {
+		value := strconv.FormatBool(*remote.DisableResponseStorage)
+		cr.applyFieldResolution(&merged, FieldNameDisableResponseStorage, value)
+		autoResolutions = append(autoResolutions, FieldResolution{
+			FieldName:     FieldNameDisableResponseStorage,
+			ResolvedValue: value,
+			Choice:        StrategyAuto,
+		})
+		PrintAutoMergeInfo(FieldNameDisableResponseStorage, value, "本地未设置，使用远程")
+	}
+
+	// Tags 取并集：远程新增的标签追加到本地标签之后
+	merged.Tags = NormalizeTags(append(slices.Clone(local.Tags), remote.Tags...))
+	if len(merged.Tags) > len(NormalizeTags(local.Tags)) {
+		value := strings.Join(merged.Tags, ",")
+		autoResolutions = append(autoResolutions, FieldResolution{
+			FieldName:     FieldNameTags,
+			ResolvedValue: value,
+			Choice:        StrategyAuto,
+		})
+		PrintAutoMergeInfo(FieldNameTags, value, "合并本地和远程标签")
+	}
+
 	return &merged, autoResolutions
 }
 
@@ -423,17 +636,14 @@ func (cr *ConflictResolver) findLocalDeletedMirror(name string) *MirrorConfig {
 // selectDefaultMirror 选择默认镜像源（当当前激活源被删除时）.
 func (cr *ConflictResolver) selectDefaultMirror(availableMirrors map[string]MirrorConfig, toolType ToolType) string {
 	// 优先选择官方镜像源
-	for name := range availableMirrors {
-		mirror := availableMirrors[name]
-		if mirror.ToolType == toolType && name == DefaultMirrorName {
-			return name
-		}
+	official := cr.localConfig.OfficialMirror()
+	if mirror, ok := availableMirrors[official]; ok && mirror.ToolType == toolType {
+		return official
 	}
 
-	// 其次选择同类型的第一个可用镜像源
-	for name := range availableMirrors {
-		mirror := availableMirrors[name]
-		if mirror.ToolType == toolType {
+	// 其次按名称顺序选择同类型的第一个可用镜像源，避免结果依赖 map 的遍历顺序
+	for _, name := range slices.Sorted(maps.Keys(availableMirrors)) {
+		if availableMirrors[name].ToolType == toolType {
 			return name
 		}
 	}
@@ -451,6 +661,11 @@ func (cr *ConflictResolver) ResolveConflicts(resolution *ConflictResolution, str
 		CurrentClaude: cr.localConfig.CurrentClaude,
 		Mirrors:       make([]MirrorConfig, len(cr.localConfig.Mirrors)),
 		Sync:          cr.localConfig.Sync,
+		SyncProfiles:  cr.localConfig.SyncProfiles,
+		OfficialName:  cr.localConfig.OfficialName,
+		OfficialURL:   cr.localConfig.OfficialURL,
+		NoEnv:         cr.localConfig.NoEnv,
+		ModelAliases:  cr.localConfig.ModelAliases,
 	}
 	copy(resolvedConfig.Mirrors, cr.localConfig.Mirrors)
 
@@ -650,10 +865,60 @@ func (cr *ConflictResolver) applyFieldResolution(mirror *MirrorConfig, fieldName
 		mirror.BaseURL = value
 	case FieldNameModel:
 		mirror.ModelName = value
+	case FieldNameHaikuModel:
+		mirror.HaikuModel = value
+	case FieldNameSonnetModel:
+		mirror.SonnetModel = value
+	case FieldNameOpusModel:
+		mirror.OpusModel = value
+	case FieldNameProxy:
+		mirror.Proxy = value
+	case FieldNameHealthPath:
+		mirror.HealthPath = value
+	case FieldNameTimeout:
+		// 手动输入的无效值忽略，保留原有设置
+		if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds >= 0 {
+			mirror.TimeoutSeconds = seconds
+		}
+	case FieldNameProviderKind:
+		// openai 是默认形式，不写入配置；手动输入的无效值忽略
+		if kind, err := ParseProviderKind(value); err == nil {
+			if kind == ProviderKindOpenAI {
+				kind = ""
+			}
+			mirror.ProviderKind = kind
+		}
+	case FieldNameAPIVersion:
+		mirror.APIVersion = strings.TrimSpace(value)
+	case FieldNameDisableResponseStorage:
+		// 空值表示恢复默认；手动输入的无效值忽略
+		if strings.TrimSpace(value) == "" {
+			mirror.DisableResponseStorage = nil
+		} else if disable, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			mirror.DisableResponseStorage = &disable
+		}
 	case FieldNameToolType:
 		mirror.ToolType = ToolType(value)
 	case FieldNameAPIKey:
 		mirror.APIKey = value
+	case FieldNameAPIKeyCommand:
+		// 与 SetMirrorAPIKeyCommand 一致：设置命令后不再保存密钥
+		mirror.APIKeyCommand = strings.TrimSpace(value)
+		if mirror.APIKeyCommand != "" {
+			mirror.APIKey = ""
+		}
+	default:
+		if key, ok := strings.CutPrefix(fieldName, FieldNameExtraEnvPrefix); ok {
+			if mirror.ExtraEnv == nil {
+				mirror.ExtraEnv = make(map[string]string)
+			}
+			mirror.ExtraEnv[key] = value
+		} else if key, ok := strings.CutPrefix(fieldName, FieldNameTestHeadersPrefix); ok {
+			if mirror.TestHeaders == nil {
+				mirror.TestHeaders = make(map[string]string)
+			}
+			mirror.TestHeaders[key] = value
+		}
 	}
 }
 
@@ -711,11 +976,26 @@ func (cr *ConflictResolver) handleRemoteDeletedMirrors(mergedMirrors map[string]
 
 // finalizeMergeConfig 完成合并配置.
 func (cr *ConflictResolver) finalizeMergeConfig(config *SystemConfig, mergedMirrors map[string]MirrorConfig) {
-	// 转换为数组
+	// 转换为数组：本地已有的镜像源保持原有顺序（即 reorder 设置的顺序），
+	// 仅存在于云端的镜像源按名称排序后追加，保证合并结果稳定
 	config.Mirrors = make([]MirrorConfig, 0, len(mergedMirrors))
+	added := make(map[string]bool, len(mergedMirrors))
+	for i := range cr.localConfig.Mirrors {
+		name := cr.localConfig.Mirrors[i].Name
+		if mirror, ok := mergedMirrors[name]; ok && !added[name] {
+			config.Mirrors = append(config.Mirrors, mirror)
+			added[name] = true
+		}
+	}
+	remoteOnly := make([]string, 0, len(mergedMirrors)-len(added))
 	for name := range mergedMirrors {
-		mirror := mergedMirrors[name]
-		config.Mirrors = append(config.Mirrors, mirror)
+		if !added[name] {
+			remoteOnly = append(remoteOnly, name)
+		}
+	}
+	slices.Sort(remoteOnly)
+	for _, name := range remoteOnly {
+		config.Mirrors = append(config.Mirrors, mergedMirrors[name])
 	}
 
 	// 智能选择当前激活源
@@ -731,6 +1011,15 @@ func (cr *ConflictResolver) selectCurrentMirrors(config *SystemConfig, mergedMir
 
 // selectCurrentMirror 选择当前激活的镜像源（通用逻辑）.
 func (cr *ConflictResolver) selectCurrentMirror(mergedMirrors map[string]MirrorConfig, localCurrent, remoteCurrent string, toolType ToolType) string {
+	// 两台设备的激活源不同且都可用时，按字段冲突的方式解决
+	if localCurrent != "" && remoteCurrent != "" && localCurrent != remoteCurrent {
+		_, localExists := mergedMirrors[localCurrent]
+		_, remoteExists := mergedMirrors[remoteCurrent]
+		if localExists && remoteExists {
+			return cr.resolveCurrentConflict(mergedMirrors, localCurrent, remoteCurrent, toolType)
+		}
+	}
+
 	// 检查本地激活源
 	if localCurrent != "" {
 		if _, exists := mergedMirrors[localCurrent]; exists {
@@ -749,6 +1038,33 @@ func (cr *ConflictResolver) selectCurrentMirror(mergedMirrors map[string]MirrorC
 	return cr.selectDefaultMirror(mergedMirrors, toolType)
 }
 
+// resolveCurrentConflict 解决两台设备激活源不同的冲突.
+// 交互模式询问用户，选择的镜像源不在合并结果中时回退到默认镜像源；
+// 非交互模式选择最近修改过的镜像源，时间相同时保留本地.
+func (cr *ConflictResolver) resolveCurrentConflict(mergedMirrors map[string]MirrorConfig, localCurrent, remoteCurrent string, toolType ToolType) string {
+	if cr.Interactive {
+		choice := PromptCurrentMirrorChoice(toolType, localCurrent, remoteCurrent, cr.remoteData.DeviceID)
+		if mirror, exists := mergedMirrors[choice]; exists && mirror.ToolType == toolType {
+			return choice
+		}
+		fallback := cr.selectDefaultMirror(mergedMirrors, toolType)
+		fmt.Printf("⚠️  镜像源 '%s' 不可用，使用默认镜像源 '%s'\n", choice, fallback)
+		return fallback
+	}
+
+	var localTime, remoteTime time.Time
+	if local := cr.createMirrorMap(cr.localConfig.Mirrors)[localCurrent]; local != nil {
+		localTime = local.LastModified
+	}
+	if remote := cr.createMirrorMap(cr.remoteData.Mirrors)[remoteCurrent]; remote != nil {
+		remoteTime = remote.LastModified
+	}
+	if remoteTime.After(localTime) {
+		return remoteCurrent
+	}
+	return localCurrent
+}
+
 // decryptRemoteAPIKey 解密远程的 APIKey（如果是加密格式）。
 func (cr *ConflictResolver) decryptRemoteAPIKey(apiKey string) string {
 	// 如果不是加密格式，直接返回
@@ -779,12 +1095,6 @@ func (cr *ConflictResolver) decryptRemoteAPIKey(apiKey string) string {
 	return string(decrypted)
 }
 
-// maskAPIKey 脱敏显示 APIKey.
-// 委托给统一的 MaskAPIKey 函数.
-func maskAPIKey(apiKey string) string {
-	return MaskAPIKey(apiKey)
-}
-
 // FormatConflicts 格式化冲突信息用于显示.
 func (cr *ConflictResolver) FormatConflicts(resolution *ConflictResolution) string {
 	if len(resolution.Conflicts) == 0 {
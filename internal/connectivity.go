@@ -0,0 +1,386 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NeedAPIKey401Msg 镜像源未配置 API Key 且返回 401 时的错误消息.
+const NeedAPIKey401Msg = "需要 API Key (401)"
+
+// DefaultTestTimeout 连通性测试默认的超时时间（秒）.
+const DefaultTestTimeout = 10
+
+// DefaultTestRetries 网络错误时默认的重试次数.
+const DefaultTestRetries = 1
+
+// DefaultMaxConcurrency 并行测试时默认的最大并发数.
+const DefaultMaxConcurrency = 8
+
+// defaultTestRetryBackoff 重试之间默认的基础等待时间.
+const defaultTestRetryBackoff = 500 * time.Millisecond
+
+// TestCategory 连通性测试结果的分类，用于区分密钥失效和暂时性故障.
+type TestCategory string
+
+// 测试结果分类.
+const (
+	TestCategoryOK           TestCategory = "ok"
+	TestCategoryNetwork      TestCategory = "network_error"
+	TestCategoryUnauthorized TestCategory = "unauthorized" // 401：密钥无效或缺失
+	TestCategoryForbidden    TestCategory = "forbidden"    // 403：可能受地区或套餐限制
+	TestCategoryRateLimited  TestCategory = "rate_limited" // 429：被限流，密钥本身有效
+	TestCategoryServerError  TestCategory = "server_error" // 5xx：上游服务异常
+	TestCategoryHTTPError    TestCategory = "http_error"   // 其他 HTTP 错误
+	TestCategoryKeyCommand   TestCategory = "key_command"  // 密钥命令执行失败，未发送请求
+)
+
+// Transient 判断该分类是否为暂时性故障（限流或上游异常），此时不应据此判定密钥失效.
+func (c TestCategory) Transient() bool {
+	return c == TestCategoryRateLimited || c == TestCategoryServerError
+}
+
+// classifyStatus 根据 HTTP 状态码对测试结果分类.
+func classifyStatus(statusCode int) TestCategory {
+	switch {
+	case statusCode == http.StatusOK:
+		return TestCategoryOK
+	case statusCode == http.StatusUnauthorized:
+		return TestCategoryUnauthorized
+	case statusCode == http.StatusForbidden:
+		return TestCategoryForbidden
+	case statusCode == http.StatusTooManyRequests:
+		return TestCategoryRateLimited
+	case statusCode >= http.StatusInternalServerError:
+		return TestCategoryServerError
+	default:
+		return TestCategoryHTTPError
+	}
+}
+
+// TestResult 镜像源连通性测试结果.
+type TestResult struct {
+	Name         string   `json:"name"`
+	URL          string   `json:"url"`
+	ToolType     ToolType `json:"tool_type"`
+	Success      bool     `json:"success"`
+	Latency      int64    `json:"latency_ms"` // 毫秒
+	StatusCode   int      `json:"status_code,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	HasAPIKey    bool     `json:"has_api_key"`
+	NetworkError bool     `json:"network_error,omitempty"` // 区分网络错误和 HTTP 错误
+	Attempts     int      `json:"attempts"`                // 实际尝试次数（含重试）
+	// 响应为模型列表时解析出的可用模型
+	Models []string `json:"models,omitempty"`
+	// 可用模型数量，用于确认端点是真实的 OpenAI 兼容 API 而不是返回 200 的登录页
+	ModelCount int `json:"model_count,omitempty"`
+	// 结果分类，区分密钥失效 (401)、访问受限 (403)、限流 (429) 和上游异常 (5xx)
+	Category TestCategory `json:"category"`
+}
+
+// AnthropicMessagesResponse Anthropic messages API 响应 (错误时).
+type AnthropicMessagesResponse struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Error   *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// anthropicErrorMessage 解析 Anthropic 错误响应中的错误说明，无法解析时返回空字符串.
+func anthropicErrorMessage(body []byte) string {
+	var resp AnthropicMessagesResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ""
+	}
+	if resp.Error != nil && resp.Error.Message != "" {
+		if resp.Error.Type != "" {
+			return resp.Error.Type + ": " + resp.Error.Message
+		}
+		return resp.Error.Message
+	}
+	return resp.Message
+}
+
+// ConnectivityTester 镜像源连通性测试器，零值即可使用.
+type ConnectivityTester struct {
+	// Transport 测试请求使用的 HTTP 传输层，为空时使用 http.DefaultTransport（遵循环境变量中的代理设置）
+	Transport http.RoundTripper
+	// RetryBackoff 重试之间的基础等待时间，第 n 次重试等待 n 倍，为空时使用默认值
+	RetryBackoff time.Duration
+	// OnResult 每个镜像源测试完成时的回调（TestAll 中可能被并发调用），可为空
+	OnResult func(*TestResult)
+	// MaxTimeout 超时时间上限（秒），镜像源自身设置的超时也不会超过它，为 0 表示不限制
+	MaxTimeout int
+}
+
+// Test 测试镜像源，仅在网络错误时按 retries 重试（401 等明确的 HTTP 结果不重试）.
+// timeout 为默认超时时间（秒），镜像源设置了 TimeoutSeconds 时使用镜像源的值.
+// 配置了 APIKeyCommand 时先执行命令获取密钥，重试时不再重复执行.
+func (ct *ConnectivityTester) Test(mirror *MirrorConfig, timeout, retries int) *TestResult {
+	timeout = ct.TimeoutFor(mirror, timeout)
+
+	resolved, err := ResolveMirrorAPIKey(mirror)
+	if err != nil {
+		return &TestResult{
+			Name:      mirror.Name,
+			URL:       mirror.BaseURL,
+			ToolType:  mirror.ToolType,
+			HasAPIKey: true,
+			Category:  TestCategoryKeyCommand,
+			Error:     err.Error(),
+		}
+	}
+	mirror = resolved
+
+	backoff := ct.RetryBackoff
+	if backoff == 0 {
+		backoff = defaultTestRetryBackoff
+	}
+
+	var result *TestResult
+	for attempt := 1; ; attempt++ {
+		result = ct.TestOnce(mirror, timeout)
+		result.Attempts = attempt
+		if !result.NetworkError || attempt > retries {
+			return result
+		}
+		time.Sleep(time.Duration(attempt) * backoff)
+	}
+}
+
+// TimeoutFor 返回测试镜像源实际使用的超时时间（秒）.
+// 镜像源设置了 TimeoutSeconds 时优先使用，否则使用 timeout；结果不超过 MaxTimeout.
+func (ct *ConnectivityTester) TimeoutFor(mirror *MirrorConfig, timeout int) int {
+	if mirror.TimeoutSeconds > 0 {
+		timeout = mirror.TimeoutSeconds
+	}
+	if ct.MaxTimeout > 0 && timeout > ct.MaxTimeout {
+		timeout = ct.MaxTimeout
+	}
+	return timeout
+}
+
+// TestAll 使用有限的并发数测试镜像源，结果顺序与 mirrors 一致.
+func (ct *ConnectivityTester) TestAll(mirrors []MirrorConfig, timeout, retries, maxConcurrency int) []*TestResult {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+
+	results := make([]*TestResult, len(mirrors))
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+
+	for i := range mirrors {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = ct.Test(&mirrors[i], timeout, retries)
+			if ct.OnResult != nil {
+				ct.OnResult(results[i])
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// TestOnce 执行单次测试.
+func (ct *ConnectivityTester) TestOnce(mirror *MirrorConfig, timeout int) *TestResult {
+	result := &TestResult{
+		Name:      mirror.Name,
+		URL:       mirror.BaseURL,
+		ToolType:  mirror.ToolType,
+		HasAPIKey: mirror.APIKey != "",
+		Attempts:  1,
+	}
+
+	startTime := time.Now()
+
+	// 测试基础连通性
+	statusCode, body, err := ct.probe(mirror, timeout)
+	result.Latency = time.Since(startTime).Milliseconds()
+	result.StatusCode = statusCode
+
+	// 网络错误
+	if err != nil {
+		result.NetworkError = true
+		result.Category = TestCategoryNetwork
+		result.Error = fmt.Sprintf("连接失败: %v", err)
+		return result
+	}
+
+	// 根据状态码判断
+	result.Category = classifyStatus(statusCode)
+	switch result.Category {
+	case TestCategoryOK:
+		result.Success = true
+		if models, ok := parseModelIDs(body); ok {
+			result.Models = models
+			result.ModelCount = len(models)
+		}
+	case TestCategoryUnauthorized:
+		if mirror.APIKey != "" {
+			result.Error = "API Key 无效 (401)"
+		} else {
+			result.Error = NeedAPIKey401Msg
+		}
+	case TestCategoryForbidden:
+		result.Error = "访问被拒绝 (403)，可能受地区或套餐限制"
+	case TestCategoryRateLimited:
+		result.Error = "请求被限流 (429)，API Key 可能仍然有效"
+	case TestCategoryServerError:
+		result.Error = fmt.Sprintf("上游服务异常 (HTTP %d)", statusCode)
+	default:
+		result.Error = fmt.Sprintf("HTTP %d", statusCode)
+	}
+
+	// Claude 的错误响应带有说明（如模型不存在），附加到错误信息中便于排查
+	if result.Error != "" && result.Error != NeedAPIKey401Msg && mirror.ToolType == ToolTypeClaude {
+		if message := anthropicErrorMessage(body); message != "" {
+			result.Error += ": " + message
+		}
+	}
+
+	return result
+}
+
+// Probe 测试基础连通性（不验证认证），返回 HTTP 状态码.
+// 所有 HTTP 状态码都视为网络可达，由调用方判断语义；只有网络错误才返回 error.
+func (ct *ConnectivityTester) Probe(mirror *MirrorConfig, timeout int) (int, error) {
+	statusCode, _, err := ct.probe(mirror, timeout)
+	return statusCode, err
+}
+
+// probe 发送连通性测试请求，返回 HTTP 状态码和响应内容.
+func (ct *ConnectivityTester) probe(mirror *MirrorConfig, timeout int) (int, []byte, error) {
+	transport, err := ct.transportFor(mirror)
+	if err != nil {
+		return 0, nil, err
+	}
+	client := &http.Client{
+		Timeout:   time.Duration(timeout) * time.Second,
+		Transport: transport,
+	}
+
+	req, err := newTestRequest(mirror)
+	if err != nil {
+		return 0, nil, err
+	}
+	return doProbeRequest(client, req)
+}
+
+// doProbeRequest 发送请求并读取响应内容（最多 maxModelsResponseSize 字节）.
+// 响应内容读取失败不视为网络错误，此时只返回状态码.
+func doProbeRequest(client *http.Client, req *http.Request) (int, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxModelsResponseSize))
+	if err != nil {
+		return resp.StatusCode, nil, nil
+	}
+	return resp.StatusCode, body, nil
+}
+
+// transportFor 返回测试镜像源使用的 Transport.
+// 镜像源配置了代理时使用该代理，否则使用测试器的 Transport.
+func (ct *ConnectivityTester) transportFor(mirror *MirrorConfig) (http.RoundTripper, error) {
+	base := ct.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if mirror.Proxy == "" {
+		return base, nil
+	}
+
+	proxyURL, err := url.Parse(mirror.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("无效的代理地址: %v", urlParseError(err))
+	}
+
+	httpTransport, ok := base.(*http.Transport)
+	if !ok {
+		httpTransport = http.DefaultTransport.(*http.Transport)
+	}
+	transport := httpTransport.Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	return transport, nil
+}
+
+// newTestRequest 构造连通性测试请求.
+// 镜像源配置了 HealthPath 时对该路径发送简单的 GET 请求，否则 Claude 用 messages、Codex 用 models；
+// Azure 镜像源始终使用 api-key 请求头和 api-version 查询参数.
+func newTestRequest(mirror *MirrorConfig) (*http.Request, error) {
+	baseURL := strings.TrimSuffix(mirror.BaseURL, "/")
+
+	var req *http.Request
+	var err error
+
+	switch {
+	case IsAzureMirror(mirror):
+		// Azure OpenAI: 使用 api-key 请求头，并带上 api-version 查询参数
+		req, err = http.NewRequest("GET", azureTestURL(mirror), http.NoBody)
+		if err != nil {
+			return nil, err
+		}
+		if mirror.APIKey != "" {
+			req.Header.Set(azureAPIKeyHeader, mirror.APIKey)
+		}
+	case mirror.HealthPath != "":
+		req, err = http.NewRequest("GET", baseURL+mirror.HealthPath, http.NoBody)
+		if err != nil {
+			return nil, err
+		}
+		if mirror.APIKey != "" {
+			if mirror.ToolType == ToolTypeClaude {
+				req.Header.Set("x-api-key", mirror.APIKey)
+			} else {
+				req.Header.Set("Authorization", "Bearer "+mirror.APIKey)
+			}
+		}
+	case mirror.ToolType == ToolTypeClaude:
+		// Claude API 必须用 POST，发送最小化的请求
+		body := `{"model": "claude-sonnet-4-20250514", "max_tokens": 1, "messages": [{"role": "user", "content": "test"}]}`
+		req, err = http.NewRequest("POST", baseURL+"/v1/messages", bytes.NewBufferString(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		// 如果有 key 就加上，没有也没关系
+		if mirror.APIKey != "" {
+			req.Header.Set("x-api-key", mirror.APIKey)
+		}
+		req.Header.Set("anthropic-version", "2023-06-01")
+	default:
+		// Codex/OpenAI: 使用 GET 请求
+		req, err = http.NewRequest("GET", baseURL+"/v1/models", http.NoBody)
+		if err != nil {
+			return nil, err
+		}
+		if mirror.APIKey != "" {
+			req.Header.Set("Authorization", "Bearer "+mirror.APIKey)
+		}
+	}
+
+	// 自定义请求头只用于测试，可以覆盖上面的默认认证头
+	for key, value := range mirror.TestHeaders {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
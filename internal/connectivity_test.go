@@ -0,0 +1,300 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestConnectivityTesterRetries 测试网络错误时的重试.
+func TestConnectivityTesterRetries(t *testing.T) {
+	tester := &ConnectivityTester{RetryBackoff: time.Millisecond}
+
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		retries      int
+		wantSuccess  bool
+		wantAttempts int
+	}{
+		{name: "失败一次后重试成功", failures: 1, status: http.StatusOK, retries: 1, wantSuccess: true, wantAttempts: 2},
+		{name: "不重试", failures: 1, status: http.StatusOK, retries: 0, wantSuccess: false, wantAttempts: 1},
+		{name: "重试次数耗尽", failures: 3, status: http.StatusOK, retries: 2, wantSuccess: false, wantAttempts: 3},
+		{name: "401不重试", status: http.StatusUnauthorized, retries: 3, wantSuccess: false, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) <= tt.failures {
+					// 直接断开连接，模拟瞬时网络错误
+					conn, _, err := w.(http.Hijacker).Hijack()
+					if err == nil {
+						_ = conn.Close()
+					}
+					return
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			mirror := &MirrorConfig{
+				Name:     "retry-test",
+				BaseURL:  server.URL,
+				APIKey:   "sk-test",
+				ToolType: ToolTypeCodex,
+			}
+
+			result := tester.Test(mirror, 5, tt.retries)
+			if result.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v (error: %s)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if result.Attempts != tt.wantAttempts {
+				t.Errorf("Attempts = %d, want %d", result.Attempts, tt.wantAttempts)
+			}
+			if int(requests.Load()) != tt.wantAttempts {
+				t.Errorf("Server received %d requests, want %d", requests.Load(), tt.wantAttempts)
+			}
+		})
+	}
+}
+
+// TestConnectivityProxy 测试配置了代理的镜像源通过代理发送测试请求.
+func TestConnectivityProxy(t *testing.T) {
+	var proxiedHost atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 代理收到的是绝对形式的请求 URL
+		proxiedHost.Store(r.URL.Host)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	mirror := &MirrorConfig{
+		Name:     "proxy-test",
+		BaseURL:  "http://mirror.invalid",
+		APIKey:   "sk-test",
+		ToolType: ToolTypeCodex,
+		Proxy:    proxy.URL,
+	}
+
+	tester := &ConnectivityTester{}
+	status, err := tester.Probe(mirror, 5)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("Probe() = %d, %v; want 200, nil", status, err)
+	}
+	if host, _ := proxiedHost.Load().(string); host != "mirror.invalid" {
+		t.Errorf("代理收到的目标主机 = %q, want %q", host, "mirror.invalid")
+	}
+
+	mirror.Proxy = "://bad"
+	if _, err := tester.Probe(mirror, 5); err == nil {
+		t.Error("无效代理地址应返回错误")
+	}
+}
+
+// TestConnectivityHeaders 测试连通性探测附加自定义请求头.
+func TestConnectivityHeaders(t *testing.T) {
+	var received atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(r.Header.Clone())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mirror := &MirrorConfig{
+		Name:     "header-test",
+		BaseURL:  server.URL,
+		APIKey:   "sk-test",
+		ToolType: ToolTypeCodex,
+		TestHeaders: map[string]string{
+			"X-Org-Id":      "org-123",
+			"Authorization": "Token custom",
+		},
+	}
+
+	if _, err := (&ConnectivityTester{}).Probe(mirror, 5); err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+
+	header, _ := received.Load().(http.Header)
+	if got := header.Get("X-Org-Id"); got != "org-123" {
+		t.Errorf("X-Org-Id = %q, want %q", got, "org-123")
+	}
+	// 自定义认证头覆盖默认的 Bearer 认证
+	if got := header.Get("Authorization"); got != "Token custom" {
+		t.Errorf("Authorization = %q, want %q", got, "Token custom")
+	}
+}
+
+// TestConnectivityTimeoutFor 测试镜像源自身的超时时间优先于默认值，并受 MaxTimeout 限制.
+func TestConnectivityTimeoutFor(t *testing.T) {
+	tests := []struct {
+		name           string
+		mirrorTimeout  int
+		defaultTimeout int
+		maxTimeout     int
+		want           int
+	}{
+		{name: "未设置时使用默认值", defaultTimeout: 10, want: 10},
+		{name: "镜像源设置优先", mirrorTimeout: 30, defaultTimeout: 10, want: 30},
+		{name: "显式上限限制镜像源设置", mirrorTimeout: 30, defaultTimeout: 5, maxTimeout: 5, want: 5},
+		{name: "镜像源设置低于上限", mirrorTimeout: 3, defaultTimeout: 20, maxTimeout: 20, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tester := &ConnectivityTester{MaxTimeout: tt.maxTimeout}
+			mirror := &MirrorConfig{Name: "slow", TimeoutSeconds: tt.mirrorTimeout}
+			if got := tester.TimeoutFor(mirror, tt.defaultTimeout); got != tt.want {
+				t.Errorf("TimeoutFor() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConnectivityHealthPath 测试自定义测试路径覆盖默认探测端点.
+func TestConnectivityHealthPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		toolType    ToolType
+		healthPath  string
+		wantSuccess bool
+	}{
+		{name: "Codex自定义路径返回200", toolType: ToolTypeCodex, healthPath: "/healthz", wantSuccess: true},
+		{name: "Claude自定义路径使用GET", toolType: ToolTypeClaude, healthPath: "/healthz", wantSuccess: true},
+		{name: "未设置时探测默认端点", toolType: ToolTypeCodex, healthPath: "", wantSuccess: false},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mirror := &MirrorConfig{
+				Name:       "health-test",
+				BaseURL:    server.URL,
+				APIKey:     "sk-test",
+				ToolType:   tt.toolType,
+				HealthPath: tt.healthPath,
+			}
+
+			result := (&ConnectivityTester{}).TestOnce(mirror, 5)
+			if result.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v (status: %d, error: %s)", result.Success, tt.wantSuccess, result.StatusCode, result.Error)
+			}
+		})
+	}
+}
+
+// TestConnectivityTestAllOnResult 测试 TestAll 按原顺序返回结果并逐个回调.
+func TestConnectivityTestAllOnResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mirrors := []MirrorConfig{
+		{Name: "first", BaseURL: server.URL, APIKey: "sk-1", ToolType: ToolTypeCodex},
+		{Name: "second", BaseURL: server.URL, APIKey: "sk-2", ToolType: ToolTypeCodex},
+		{Name: "third", BaseURL: server.URL, APIKey: "sk-3", ToolType: ToolTypeCodex},
+	}
+
+	var callbacks atomic.Int32
+	tester := &ConnectivityTester{OnResult: func(*TestResult) { callbacks.Add(1) }}
+	results := tester.TestAll(mirrors, 5, 0, 2)
+
+	if int(callbacks.Load()) != len(mirrors) {
+		t.Errorf("OnResult called %d times, want %d", callbacks.Load(), len(mirrors))
+	}
+	for i, result := range results {
+		if result.Name != mirrors[i].Name || !result.Success {
+			t.Errorf("results[%d] = %+v, want successful %s", i, result, mirrors[i].Name)
+		}
+	}
+}
+
+// TestConnectivityAnthropicError 测试Claude镜像源的错误响应说明附加到测试结果中.
+func TestConnectivityAnthropicError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		apiKey string
+		want   string
+	}{
+		{name: "模型不存在", status: http.StatusBadRequest, body: `{"type":"error","error":{"type":"invalid_request_error","message":"model not found"}}`, apiKey: "sk-test", want: "HTTP 400: invalid_request_error: model not found"},
+		{name: "密钥无效", status: http.StatusUnauthorized, body: `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`, apiKey: "sk-test", want: "API Key 无效 (401): authentication_error: invalid x-api-key"},
+		{name: "未配置密钥", status: http.StatusUnauthorized, body: `{"type":"error","error":{"type":"authentication_error","message":"missing key"}}`, want: NeedAPIKey401Msg},
+		{name: "非 JSON 响应", status: http.StatusNotFound, body: `not found`, apiKey: "sk-test", want: "HTTP 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			mirror := &MirrorConfig{Name: "claude-error", BaseURL: server.URL, APIKey: tt.apiKey, ToolType: ToolTypeClaude}
+			result := (&ConnectivityTester{}).TestOnce(mirror, 5)
+			if result.Error != tt.want {
+				t.Errorf("Error = %q, want %q", result.Error, tt.want)
+			}
+		})
+	}
+}
+
+// TestConnectivityCategories 测试按HTTP状态码对测试结果分类.
+func TestConnectivityCategories(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		wantCategory  TestCategory
+		wantTransient bool
+	}{
+		{name: "成功", status: http.StatusOK, wantCategory: TestCategoryOK},
+		{name: "401 密钥无效", status: http.StatusUnauthorized, wantCategory: TestCategoryUnauthorized},
+		{name: "403 访问受限", status: http.StatusForbidden, wantCategory: TestCategoryForbidden},
+		{name: "429 限流", status: http.StatusTooManyRequests, wantCategory: TestCategoryRateLimited, wantTransient: true},
+		{name: "500 上游异常", status: http.StatusInternalServerError, wantCategory: TestCategoryServerError, wantTransient: true},
+		{name: "503 上游异常", status: http.StatusServiceUnavailable, wantCategory: TestCategoryServerError, wantTransient: true},
+		{name: "404 其他错误", status: http.StatusNotFound, wantCategory: TestCategoryHTTPError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			mirror := &MirrorConfig{Name: "category", BaseURL: server.URL, APIKey: "sk-test", ToolType: ToolTypeCodex}
+			result := (&ConnectivityTester{}).TestOnce(mirror, 5)
+			if result.Category != tt.wantCategory {
+				t.Errorf("Category = %q, want %q", result.Category, tt.wantCategory)
+			}
+			if got := result.Category.Transient(); got != tt.wantTransient {
+				t.Errorf("Transient() = %v, want %v", got, tt.wantTransient)
+			}
+			if result.Success != (tt.status == http.StatusOK) || (!result.Success && result.Error == "") {
+				t.Errorf("result = %+v, want success only for 200 and an error message otherwise", result)
+			}
+		})
+	}
+
+	mirror := &MirrorConfig{Name: "category", BaseURL: "http://127.0.0.1:1", APIKey: "sk-test", ToolType: ToolTypeCodex}
+	if result := (&ConnectivityTester{}).TestOnce(mirror, 1); result.Category != TestCategoryNetwork {
+		t.Errorf("Category = %q, want %q", result.Category, TestCategoryNetwork)
+	}
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// maskedKeyCommandValue 未显示密钥时，代替通过命令获取的密钥的占位符.
+const maskedKeyCommandValue = "****"
+
+// CurlCommand 返回与连通性测试发送相同请求的 curl 命令，便于在工具之外调试镜像源.
+// 请求的地址、方法、请求头和请求体与 test 命令一致；reveal 为 false 时密钥和代理密码只显示掩码，
+// 为 true 时输出明文密钥，配置了 APIKeyCommand 的镜像源会执行该命令获取密钥.
+func CurlCommand(mirror *MirrorConfig, reveal bool) (string, error) {
+	if reveal {
+		resolved, err := ResolveMirrorAPIKey(mirror)
+		if err != nil {
+			return "", err
+		}
+		mirror = resolved
+	} else {
+		mirror = maskedMirror(mirror)
+	}
+
+	req, err := newTestRequest(mirror)
+	if err != nil {
+		return "", fmt.Errorf("构造请求失败: %v", err)
+	}
+
+	args := []string{"curl", "-sS"}
+	if req.Method != "GET" {
+		args = append(args, "-X", req.Method)
+	}
+	args = append(args, shellQuote(req.URL.String()))
+
+	headerNames := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+
+	lines := []string{strings.Join(args, " ")}
+	for _, name := range headerNames {
+		for _, value := range req.Header[name] {
+			lines = append(lines, "-H "+shellQuote(name+": "+value))
+		}
+	}
+
+	if mirror.Proxy != "" {
+		proxy := mirror.Proxy
+		if !reveal {
+			proxy = RedactURL(proxy)
+		}
+		lines = append(lines, "--proxy "+shellQuote(proxy))
+	}
+
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("读取请求体失败: %v", err)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return "", fmt.Errorf("读取请求体失败: %v", err)
+		}
+		if len(data) > 0 {
+			lines = append(lines, "--data "+shellQuote(string(data)))
+		}
+	}
+
+	return strings.Join(lines, " \\\n  "), nil
+}
+
+// maskedMirror 返回密钥和密钥类测试请求头只显示掩码的镜像源副本.
+func maskedMirror(mirror *MirrorConfig) *MirrorConfig {
+	masked := cloneMirror(mirror)
+	switch {
+	case masked.APIKey != "":
+		masked.APIKey = MaskAPIKey(masked.APIKey)
+	case strings.TrimSpace(masked.APIKeyCommand) != "":
+		masked.APIKey = maskedKeyCommandValue
+	}
+	masked.APIKeyCommand = ""
+	for key, value := range masked.TestHeaders {
+		if IsSecretEnvKey(key) {
+			masked.TestHeaders[key] = MaskAPIKey(value)
+		}
+	}
+	return masked
+}
+
+// shellQuote 用单引号包裹参数，供 POSIX shell 原样使用.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DiscoveryImportReport 从已有工具配置（Codex config.toml、Claude settings.json）导入镜像源的结果，各列表按名称排序.
+type DiscoveryImportReport struct {
+	Imported []string // 新增的镜像源
+	Merged   []string // 补全了缺失字段的同名镜像源（仅 merge 模式）
+	Skipped  []string // 已存在（同名或相同 API 地址）而跳过的镜像源
+}
+
+// ImportCodexConfig 从 ~/.codex/config.toml 导入尚未管理的模型提供商.
+// 同名或相同 API 地址的镜像源不会被覆盖；merge 为 true 时为同名镜像源补全为空的 API 密钥和模型名称.
+func (mm *MirrorManager) ImportCodexConfig(merge bool) (*DiscoveryImportReport, error) {
+	discovered, err := mm.readCodexProviders()
+	if err != nil {
+		return nil, err
+	}
+	return mm.importDiscovered(discovered, merge)
+}
+
+// readCodexProviders 读取 Codex 配置文件中的模型提供商，API 密钥取自环境变量或 auth.json.
+func (mm *MirrorManager) readCodexProviders() (map[string]MirrorConfig, error) {
+	configPath, err := GetCodexConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var config CodexConfig
+	if _, err := toml.DecodeFile(configPath, &config); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("未找到 Codex 配置文件: %s", configPath)
+		}
+		return nil, fmt.Errorf("解析 Codex 配置文件失败: %w", err)
+	}
+
+	var auth CodexAuth
+	if authPath, err := GetCodexAuthPath(); err == nil {
+		if data, err := os.ReadFile(authPath); err == nil {
+			_ = json.Unmarshal(data, &auth)
+		}
+	}
+
+	discovered := make(map[string]MirrorConfig)
+	for name, provider := range config.ModelProviders {
+		// 跳过空配置
+		if provider.BaseURL == "" {
+			continue
+		}
+		discovered[name] = MirrorConfig{
+			Name:     name,
+			BaseURL:  provider.BaseURL,
+			APIKey:   mm.getApiKeyForProvider(auth),
+			EnvKey:   provider.EnvKey,
+			ToolType: ToolTypeCodex,
+		}
+	}
+	return discovered, nil
+}
+
+// ImportClaudeSettings 从 ~/.claude/settings.json 的 env 字段导入 Claude 镜像源.
+// 名称根据 ANTHROPIC_BASE_URL 生成；已有相同 API 地址的 Claude 镜像源时跳过，名称被其他镜像源占用时追加序号.
+func (mm *MirrorManager) ImportClaudeSettings() (*DiscoveryImportReport, error) {
+	mirror, err := readClaudeSettingsMirror()
+	if err != nil {
+		return nil, err
+	}
+	mirror.Name = mm.availableMirrorName(mirror.Name, mirror)
+	return mm.importDiscovered(map[string]MirrorConfig{mirror.Name: *mirror}, false)
+}
+
+// readClaudeSettingsMirror 读取 Claude settings.json 中的 env 字段并转换为镜像源配置.
+func readClaudeSettingsMirror() (*MirrorConfig, error) {
+	settingsPath, err := GetClaudeSettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(settingsPath); err != nil {
+		return nil, fmt.Errorf("未找到 Claude 配置文件: %s", settingsPath)
+	}
+
+	ccm := &ClaudeConfigManager{settingsPath: settingsPath}
+	settings, err := ccm.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := strings.TrimSpace(settings.Env[AnthropicBaseURLEnv])
+	if baseURL == "" {
+		return nil, fmt.Errorf("%s 的 env 中没有 %s", settingsPath, AnthropicBaseURLEnv)
+	}
+	apiKey := settings.Env[AnthropicAuthTokenEnv]
+	if apiKey == "" {
+		apiKey = settings.Env["ANTHROPIC_API_KEY"]
+	}
+
+	return &MirrorConfig{
+		Name:        extractMirrorNameFromURL(baseURL, "claude"),
+		BaseURL:     baseURL,
+		APIKey:      apiKey,
+		ToolType:    ToolTypeClaude,
+		ModelName:   settings.Env[AnthropicModelEnv],
+		HaikuModel:  settings.Env[AnthropicDefaultHaikuModelEnv],
+		SonnetModel: settings.Env[AnthropicDefaultSonnetModelEnv],
+		OpusModel:   settings.Env[AnthropicDefaultOpusModelEnv],
+	}, nil
+}
+
+// availableMirrorName 返回可用于导入镜像源的名称：名称未被占用，或被类型和 API 地址相同的镜像源占用时原样返回，
+// 否则追加序号（如 name-2）.
+func (mm *MirrorManager) availableMirrorName(name string, mirror *MirrorConfig) string {
+	candidate := name
+	for i := 2; ; i++ {
+		existing := mm.findActiveMirror(candidate)
+		if existing == nil || (existing.ToolType == mirror.ToolType && sameBaseURL(existing.BaseURL, mirror.BaseURL)) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// importDiscovered 将发现的镜像源加入配置，已存在的镜像源不会被覆盖，所有修改完成后只保存一次.
+func (mm *MirrorManager) importDiscovered(discovered map[string]MirrorConfig, merge bool) (*DiscoveryImportReport, error) {
+	names := make([]string, 0, len(discovered))
+	for name := range discovered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := &DiscoveryImportReport{}
+	for _, name := range names {
+		mirror := discovered[name]
+		if existing := mm.findActiveMirror(name); existing != nil {
+			if merge && existing.ToolType == mirror.ToolType && mergeMissingFields(existing, &mirror) {
+				report.Merged = append(report.Merged, name)
+			} else {
+				report.Skipped = append(report.Skipped, name)
+			}
+			continue
+		}
+		if mm.hasMirrorWithBaseURL(mirror.ToolType, mirror.BaseURL) {
+			report.Skipped = append(report.Skipped, name)
+			continue
+		}
+
+		if err := mm.addMirror(name, mirror.BaseURL, mirror.APIKey, mirror.ToolType, mirror.ModelName, mirror.ExtraEnv); err != nil {
+			return nil, fmt.Errorf("导入镜像源 '%s' 失败: %w", name, err)
+		}
+		created := mm.findActiveMirror(name)
+		created.HaikuModel = mirror.HaikuModel
+		created.SonnetModel = mirror.SonnetModel
+		created.OpusModel = mirror.OpusModel
+		report.Imported = append(report.Imported, name)
+	}
+
+	if len(report.Imported) > 0 || len(report.Merged) > 0 {
+		if err := mm.saveConfig(); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// hasMirrorWithBaseURL 判断是否已有相同类型和 API 地址的活跃镜像源.
+func (mm *MirrorManager) hasMirrorWithBaseURL(toolType ToolType, baseURL string) bool {
+	for _, mirror := range mm.ListActiveMirrors() {
+		if mirror.ToolType == toolType && sameBaseURL(mirror.BaseURL, baseURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeMissingFields 用发现的配置补全已有镜像源中为空的 API 密钥和模型名称，返回是否有修改.
+func mergeMissingFields(existing, discovered *MirrorConfig) bool {
+	changed := false
+	if existing.APIKey == "" && discovered.APIKey != "" {
+		existing.APIKey = discovered.APIKey
+		changed = true
+	}
+	if existing.ModelName == "" && discovered.ModelName != "" {
+		existing.ModelName = discovered.ModelName
+		changed = true
+	}
+	if changed {
+		existing.LastModified = time.Now()
+	}
+	return changed
+}
@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestImportCodexConfig 测试从 Codex 配置文件导入模型提供商.
+func TestImportCodexConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		merge        bool
+		wantImported []string
+		wantMerged   []string
+		wantSkipped  []string
+		wantKey      string
+	}{
+		{name: "只导入新的提供商", wantImported: []string{"fresh"}, wantSkipped: []string{"mine", "same-url"}},
+		{name: "merge 补全同名镜像源的密钥", merge: true, wantImported: []string{"fresh"}, wantMerged: []string{"mine"}, wantSkipped: []string{"same-url"}, wantKey: "sk-from-auth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			mm := createTestMirrorManager(t, tempDir)
+			t.Setenv(CodexSwitchAPIKeyEnv, "")
+			if err := mm.AddMirror("mine", "https://mine.example.com", ""); err != nil {
+				t.Fatalf("AddMirror() error = %v", err)
+			}
+			if err := mm.AddMirror("tracked", "https://tracked.example.com", "sk-tracked"); err != nil {
+				t.Fatalf("AddMirror() error = %v", err)
+			}
+
+			codexDir := filepath.Join(tempDir, ".codex")
+			if err := os.MkdirAll(codexDir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			config := `model_provider = "fresh"
+
+[model_providers.fresh]
+name = "fresh"
+base_url = "https://fresh.example.com/v1"
+
+[model_providers.mine]
+name = "mine"
+base_url = "https://other.example.com"
+
+[model_providers.same-url]
+name = "same-url"
+base_url = "https://tracked.example.com/"
+
+[model_providers.empty]
+name = "empty"
+`
+			if err := os.WriteFile(filepath.Join(codexDir, "config.toml"), []byte(config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(codexDir, "auth.json"), []byte(`{"OPENAI_API_KEY": "sk-from-auth"}`), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := mm.ImportCodexConfig(tt.merge)
+			if err != nil {
+				t.Fatalf("ImportCodexConfig() error = %v", err)
+			}
+			if !slices.Equal(report.Imported, tt.wantImported) || !slices.Equal(report.Merged, tt.wantMerged) || !slices.Equal(report.Skipped, tt.wantSkipped) {
+				t.Errorf("report = %+v, expected imported %v merged %v skipped %v", report, tt.wantImported, tt.wantMerged, tt.wantSkipped)
+			}
+
+			reloaded, err := NewMirrorManagerWithPath(mm.configPath)
+			if err != nil {
+				t.Fatalf("NewMirrorManagerWithPath() error = %v", err)
+			}
+			fresh, err := reloaded.GetMirrorByName("fresh")
+			if err != nil || fresh.BaseURL != "https://fresh.example.com/v1" || fresh.APIKey != "sk-from-auth" || fresh.ToolType != ToolTypeCodex {
+				t.Errorf("fresh = %+v, %v", fresh, err)
+			}
+			mine, _ := reloaded.GetMirrorByName("mine")
+			if mine.BaseURL != "https://mine.example.com" || mine.APIKey != tt.wantKey {
+				t.Errorf("mine = %+v, expected URL kept and key %q", mine, tt.wantKey)
+			}
+		})
+	}
+}
+
+// TestImportCodexConfigMissing 测试 Codex 配置文件不存在时返回错误.
+func TestImportCodexConfigMissing(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if _, err := mm.ImportCodexConfig(false); err == nil {
+		t.Error("ImportCodexConfig() should fail without ~/.codex/config.toml")
+	}
+}
+
+// TestImportClaudeSettings 测试从 Claude settings.json 导入镜像源.
+func TestImportClaudeSettings(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     *MirrorConfig
+		env          string
+		wantImported []string
+		wantSkipped  []string
+		wantErr      bool
+	}{
+		{
+			name:         "导入新的镜像源",
+			env:          `"ANTHROPIC_BASE_URL": "https://api.moonshot.cn/anthropic", "ANTHROPIC_AUTH_TOKEN": "sk-claude", "ANTHROPIC_MODEL": "kimi-k2"`,
+			wantImported: []string{"api-moonshot"},
+		},
+		{
+			name:         "使用 ANTHROPIC_API_KEY 作为密钥",
+			env:          `"ANTHROPIC_BASE_URL": "https://api.moonshot.cn/anthropic", "ANTHROPIC_API_KEY": "sk-claude", "ANTHROPIC_MODEL": "kimi-k2"`,
+			wantImported: []string{"api-moonshot"},
+		},
+		{
+			name:        "相同 API 地址的镜像源已存在时跳过",
+			existing:    &MirrorConfig{Name: "kimi", BaseURL: "https://api.moonshot.cn/anthropic/"},
+			env:         `"ANTHROPIC_BASE_URL": "https://api.moonshot.cn/anthropic", "ANTHROPIC_AUTH_TOKEN": "sk-claude"`,
+			wantSkipped: []string{"api-moonshot"},
+		},
+		{
+			name:         "名称被占用时追加序号",
+			existing:     &MirrorConfig{Name: "api-moonshot", BaseURL: "https://other.example.com"},
+			env:          `"ANTHROPIC_BASE_URL": "https://api.moonshot.cn/anthropic", "ANTHROPIC_AUTH_TOKEN": "sk-claude", "ANTHROPIC_MODEL": "kimi-k2"`,
+			wantImported: []string{"api-moonshot-2"},
+		},
+		{
+			name:    "缺少 ANTHROPIC_BASE_URL",
+			env:     `"ANTHROPIC_AUTH_TOKEN": "sk-claude"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			mm := createTestMirrorManager(t, tempDir)
+			if tt.existing != nil {
+				if err := mm.AddMirrorWithType(tt.existing.Name, tt.existing.BaseURL, "sk-existing", ToolTypeClaude); err != nil {
+					t.Fatalf("AddMirrorWithType() error = %v", err)
+				}
+			}
+
+			claudeDir := filepath.Join(tempDir, ".claude")
+			if err := os.MkdirAll(claudeDir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			settings := "{\n  // 注释\n  \"env\": {" + tt.env + "}\n}"
+			if err := os.WriteFile(filepath.Join(claudeDir, "settings.json"), []byte(settings), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := mm.ImportClaudeSettings()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ImportClaudeSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(report.Imported, tt.wantImported) || !slices.Equal(report.Skipped, tt.wantSkipped) {
+				t.Errorf("report = %+v, want imported %v skipped %v", report, tt.wantImported, tt.wantSkipped)
+			}
+
+			for _, name := range tt.wantImported {
+				mirror, err := mm.GetMirrorByName(name)
+				if err != nil {
+					t.Fatalf("GetMirrorByName(%s) error = %v", name, err)
+				}
+				if mirror.ToolType != ToolTypeClaude || mirror.APIKey != "sk-claude" || mirror.ModelName != "kimi-k2" {
+					t.Errorf("imported mirror = %+v", mirror)
+				}
+			}
+		})
+	}
+}
+
+// TestImportClaudeSettingsMissing 测试 Claude 配置文件不存在时返回错误.
+func TestImportClaudeSettingsMissing(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if _, err := mm.ImportClaudeSettings(); err == nil {
+		t.Error("ImportClaudeSettings() should fail without ~/.claude/settings.json")
+	}
+}
@@ -5,9 +5,27 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 )
 
+// shellManagedMarker 标记由本工具写入 shell 配置文件的环境变量行.
+const shellManagedMarker = "# codex-mirror managed"
+
+// legacyManagedComment 旧版本写在导出行上方的注释，紧随其后的导出行同样视为由本工具管理.
+const legacyManagedComment = "# Codex Mirror Switch - API Key"
+
+// knownShellRCFiles 本工具可能写入导出语句的所有 shell 配置文件，清除环境变量时全部检查，
+// 避免用户更换登录 shell 后旧文件中残留密钥.
+var knownShellRCFiles = []string{".zshrc", ".bashrc", ".bash_profile", ".profile", fishConfigFile}
+
+// fishConfigFile fish 的配置文件（相对用户主目录）.
+var fishConfigFile = filepath.Join(".config", "fish", "config.fish")
+
+// wslWarnOnce 保证 WSL 提示在每次运行中只输出一次.
+var wslWarnOnce sync.Once
+
 // EnvManager 环境变量管理器.
 type EnvManager struct{}
 
@@ -16,6 +34,47 @@ func NewEnvManager() *EnvManager {
 	return &EnvManager{}
 }
 
+// MirrorEnvVars 返回镜像源对应的环境变量.
+// 值为空表示该变量应被清除（如 Claude 镜像未设置模型名称时的 ANTHROPIC_MODEL）.
+// 配置了 APIKeyCommand 时执行命令获取密钥.
+func MirrorEnvVars(mirror *MirrorConfig) (map[string]string, error) {
+	mirror, err := ResolveMirrorAPIKey(mirror)
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string)
+
+	switch mirror.ToolType {
+	case ToolTypeClaude:
+		vars[AnthropicBaseURLEnv] = mirror.BaseURL
+		vars[AnthropicAuthTokenEnv] = mirror.APIKey
+		for key, value := range ClaudeModelEnvVars(mirror) {
+			vars[key] = value
+		}
+	case ToolTypeCodex:
+		// Codex 使用镜像的 EnvKey 读取 API Key
+		envKey := strings.TrimSpace(mirror.EnvKey)
+		if envKey == "" {
+			envKey = CodexSwitchAPIKeyEnv
+		}
+		vars[envKey] = mirror.APIKey
+	default:
+		return nil, fmt.Errorf("不支持的配置类型 '%s'", mirror.ToolType)
+	}
+
+	// 为遵循标准代理环境变量的工具提供代理设置
+	if proxy := strings.TrimSpace(mirror.Proxy); proxy != "" {
+		vars[HTTPSProxyEnv] = proxy
+		vars[HTTPProxyEnv] = proxy
+	}
+
+	for key, value := range mirror.ExtraEnv {
+		vars[key] = value
+	}
+
+	return vars, nil
+}
+
 // SetClaudeEnvVars 设置 Claude Code 环境变量.
 func (em *EnvManager) SetClaudeEnvVars(baseURL, authToken string) error {
 	return em.SetClaudeEnvVarsWithModel(baseURL, authToken, "")
@@ -77,12 +136,13 @@ func (em *EnvManager) setEnvironmentVariableNoRefresh(envKey, value string) erro
 	switch platform {
 	case PlatformWindows:
 		err = em.setWindowsUserEnvVarNoRefresh(envKey, value)
-	case PlatformMac:
-		shellFiles := []string{".zshrc"} // macOS 默认使用 zsh
-		err = setUnixUserEnvVar(envKey, value, shellFiles)
-	case PlatformLinux:
-		shellFiles := []string{".bashrc", ".profile"} // bash (最常见), 通用 profile
-		err = setUnixUserEnvVar(envKey, value, shellFiles)
+	case PlatformMac, PlatformLinux:
+		if IsWSL() {
+			wslWarnOnce.Do(func() {
+				LogWarnf("警告: 检测到 WSL，环境变量只写入 WSL 中的 shell 配置文件，不会传递给 Windows 端运行的 Codex 或 VS Code\n")
+			})
+		}
+		err = setUnixUserEnvVar(envKey, value, currentShellRCFiles())
 	}
 
 	if err != nil {
@@ -104,6 +164,35 @@ func (em *EnvManager) setWindowsUserEnvVarNoRefresh(envKey, value string) error
 	return nil
 }
 
+// shellRCFiles 根据登录 shell（$SHELL 的值）返回写入环境变量的 shell 配置文件，
+// fish 写入 config.fish，其余 shell 写入 POSIX 风格的配置文件；无法识别的 shell 使用平台默认值：macOS 为 .zshrc，Linux 为 .bashrc 和 .profile.
+func shellRCFiles(platform Platform, shell string) []string {
+	switch filepath.Base(strings.TrimSpace(shell)) {
+	case "zsh":
+		return []string{".zshrc"}
+	case "bash":
+		if platform == PlatformMac {
+			// macOS 的终端以登录 shell 启动 bash，只读取 .bash_profile
+			return []string{".bash_profile"}
+		}
+		return []string{".bashrc", ".profile"}
+	case "sh", "dash", "ksh":
+		return []string{".profile"}
+	case "fish":
+		return []string{fishConfigFile}
+	}
+
+	if platform == PlatformMac {
+		return []string{".zshrc"}
+	}
+	return []string{".bashrc", ".profile"}
+}
+
+// currentShellRCFiles 返回当前用户登录 shell 对应的 shell 配置文件.
+func currentShellRCFiles() []string {
+	return shellRCFiles(GetCurrentPlatform(), os.Getenv("SHELL"))
+}
+
 // setUnixUserEnvVar 在 Unix 系统（macOS 和 Linux）中设置用户级环境变量.
 func setUnixUserEnvVar(envKey, value string, shellFileNames []string) error {
 	homeDir, err := os.UserHomeDir()
@@ -117,10 +206,10 @@ func setUnixUserEnvVar(envKey, value string, shellFileNames []string) error {
 		shellFiles[i] = filepath.Join(homeDir, name)
 	}
 
-	envLine := fmt.Sprintf("export %s=%s", envKey, value)
 	updated := false
 
 	for _, shellFile := range shellFiles {
+		envLine := shellEnvPrefix(shellFile, envKey) + value + " " + shellManagedMarker
 		if err := updateShellProfile(shellFile, envKey, envLine); err != nil {
 			fmt.Printf("警告: 更新 %s 失败: %v\n", shellFile, err)
 			continue
@@ -136,10 +225,28 @@ func setUnixUserEnvVar(envKey, value string, shellFileNames []string) error {
 	return nil
 }
 
+// isFishConfig 判断 shell 配置文件是否为 fish 脚本.
+func isFishConfig(shellFile string) bool {
+	return filepath.Ext(shellFile) == ".fish"
+}
+
+// shellEnvPrefix 返回 shell 配置文件中设置环境变量的行前缀：fish 使用 set -Ux，其余 shell 使用 export.
+func shellEnvPrefix(shellFile, envKey string) string {
+	if isFishConfig(shellFile) {
+		return fmt.Sprintf("set -Ux %s ", envKey)
+	}
+	return fmt.Sprintf("export %s=", envKey)
+}
+
 // updateShellProfile 更新 shell 配置文件，添加或更新环境变量.
+// 受管理的导出行（带管理标记，或旧版本写入的紧随 legacyManagedComment 的行）会被原地替换，
+// 重复的受管理行会被删除，保证每个变量只保留一行；用户手动编写的导出行保持不变.
 func updateShellProfile(shellFile, envKey, envLine string) error {
 	var existingContent []byte
 	var err error
+	if err := EnsureDir(filepath.Dir(shellFile)); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
 	if _, err = os.Stat(shellFile); err == nil {
 		existingContent, err = os.ReadFile(shellFile)
 		if err != nil {
@@ -155,21 +262,25 @@ func updateShellProfile(shellFile, envKey, envLine string) error {
 		lines = cleanupOldCodexEnvVars(lines)
 	}
 
-	// 检查是否已存在该环境变量的设置
-	envPattern := fmt.Sprintf("export %s=", envKey)
+	// 原地替换第一处受管理的该环境变量设置，并删除其余重复的受管理设置
+	envPattern := shellEnvPrefix(shellFile, envKey)
 	found := false
+	updatedLines := make([]string, 0, len(lines)+2)
 	for i, line := range lines {
-		if strings.HasPrefix(strings.TrimSpace(line), envPattern) {
-			// 更新现有行
-			lines[i] = envLine
+		if !isManagedEnvLine(lines, i, envPattern) {
+			updatedLines = append(updatedLines, line)
+			continue
+		}
+		if !found {
+			updatedLines = append(updatedLines, envLine)
 			found = true
-			break
 		}
 	}
+	lines = updatedLines
 
 	// 如果没找到，添加新行
 	if !found {
-		lines = append(lines, "", "# Codex Mirror Switch - API Key", envLine)
+		lines = append(lines, "", envLine)
 	}
 
 	// 清理多余的空行和连续的注释
@@ -184,6 +295,25 @@ func updateShellProfile(shellFile, envKey, envLine string) error {
 	return nil
 }
 
+// isManagedEnvLine 判断第 i 行是否为本工具写入的指定环境变量设置行：
+// 以任一 envPatterns 开头，并且带有管理标记或紧跟在旧版本的注释行之后.
+func isManagedEnvLine(lines []string, i int, envPatterns ...string) bool {
+	trimmed := strings.TrimSpace(lines[i])
+	if !slices.ContainsFunc(envPatterns, func(pattern string) bool { return strings.HasPrefix(trimmed, pattern) }) {
+		return false
+	}
+	if strings.HasSuffix(trimmed, shellManagedMarker) {
+		return true
+	}
+	return i > 0 && isLegacyManagedComment(lines[i-1])
+}
+
+// isLegacyManagedComment 判断该行是否为旧版本写入的注释行（含带句号的版本）.
+func isLegacyManagedComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == legacyManagedComment || trimmed == legacyManagedComment+"."
+}
+
 // cleanupOldCodexEnvVars 清理旧的 Codex 相关环境变量.
 func cleanupOldCodexEnvVars(lines []string) []string {
 	var cleanedLines []string
@@ -191,14 +321,12 @@ func cleanupOldCodexEnvVars(lines []string) []string {
 
 	for i < len(lines) {
 		line := lines[i]
-		trimmed := strings.TrimSpace(line)
 
 		// 只清理由本工具写入的标记段落：
 		// 1. 注释行必须是精确的 "# Codex Mirror Switch - API Key" 或带句号版本；
 		// 2. 紧随其后的下一行必须是以 "export CODEX_" 开头且包含 "_API_KEY=" 的环境变量行。
 		// 满足这两个条件时，一并删除注释行和变量行；否则两行都保留。
-		if (trimmed == "# Codex Mirror Switch - API Key" || trimmed == "# Codex Mirror Switch - API Key.") &&
-			i+1 < len(lines) {
+		if isLegacyManagedComment(line) && i+1 < len(lines) {
 			nextLine := lines[i+1]
 			nextTrimmed := strings.TrimSpace(nextLine)
 
@@ -232,7 +360,7 @@ func cleanupExtraLines(lines []string) []string {
 		}
 
 		// 跳过孤立的 "# Codex Mirror Switch - API Key" 注释行
-		if trimmed == "# Codex Mirror Switch - API Key" || trimmed == "# Codex Mirror Switch - API Key." {
+		if isLegacyManagedComment(line) {
 			// 检查下一行是否是要保留的环境变量
 			continue
 		}
@@ -265,12 +393,8 @@ func (em *EnvManager) UnsetEnvVar(envKey string) error {
 	switch platform {
 	case PlatformWindows:
 		return em.unsetWindowsEnvVar(envKey)
-	case PlatformMac:
-		shellFiles := []string{".zshrc"}
-		return em.unsetUnixEnvVarWithError(envKey, shellFiles)
-	case PlatformLinux:
-		shellFiles := []string{".bashrc", ".profile"}
-		return em.unsetUnixEnvVarWithError(envKey, shellFiles)
+	case PlatformMac, PlatformLinux:
+		return em.unsetUnixEnvVarWithError(envKey, knownShellRCFiles)
 	}
 	return nil
 }
@@ -311,24 +435,14 @@ func (em *EnvManager) unsetUnixEnvVarWithError(envKey string, shellFiles []strin
 			continue
 		}
 
-		lines := strings.Split(string(content), "\n")
-		var newLines []string
-		envPattern := fmt.Sprintf("export %s=", envKey)
-		found := false
-
-		for _, line := range lines {
-			trimmedLine := strings.TrimSpace(line)
-			if strings.HasPrefix(trimmedLine, envPattern) {
-				found = true
-				continue // 跳过这一行
-			}
-			newLines = append(newLines, line)
-		}
-
+		newContent, found := removeEnvExportLines(string(content), envKey)
 		if found {
-			if err := os.WriteFile(shellFile, []byte(strings.Join(newLines, "\n")), 0o644); err != nil {
+			if err := os.WriteFile(shellFile, []byte(newContent), 0o644); err != nil {
 				continue
 			}
+			if isFishConfig(shellFile) {
+				eraseFishUniversalVar(envKey)
+			}
 			updated = true
 		}
 	}
@@ -363,12 +477,8 @@ func (em *EnvManager) unsetEnvironmentVariable(envKey string) {
 			// 通知系统环境变量已更改（可选，需要广播 WM_SETTINGCHANGE）
 		}
 		return
-	case PlatformMac:
-		shellFiles := []string{".zshrc"}
-		em.unsetUnixEnvVar(envKey, shellFiles)
-	case PlatformLinux:
-		shellFiles := []string{".bashrc", ".profile"}
-		em.unsetUnixEnvVar(envKey, shellFiles)
+	case PlatformMac, PlatformLinux:
+		em.unsetUnixEnvVar(envKey, knownShellRCFiles)
 	}
 }
 
@@ -395,24 +505,16 @@ func (em *EnvManager) unsetUnixEnvVar(envKey string, shellFiles []string) {
 			continue
 		}
 
-		// 分行处理
-		lines := strings.Split(string(content), "\n")
-		var newLines []string
-
-		envPattern := fmt.Sprintf("export %s=", envKey)
-		for _, line := range lines {
-			trimmedLine := strings.TrimSpace(line)
-			// 只删除以环境变量开头的行，避免误删
-			if !strings.HasPrefix(trimmedLine, envPattern) {
-				newLines = append(newLines, line)
-			}
-		}
+		newContent, found := removeEnvExportLines(string(content), envKey)
 
 		// 写回文件
-		if err := os.WriteFile(shellFile, []byte(strings.Join(newLines, "\n")), 0o644); err != nil {
+		if err := os.WriteFile(shellFile, []byte(newContent), 0o644); err != nil {
 			// 如果写入失败，跳过这个文件
 			continue
 		}
+		if found && isFishConfig(shellFile) {
+			eraseFishUniversalVar(envKey)
+		}
 		updated = true
 	}
 
@@ -421,6 +523,42 @@ func (em *EnvManager) unsetUnixEnvVar(envKey string, shellFiles []string) {
 	}
 }
 
+// eraseFishUniversalVar 删除 fish 的通用变量：set -Ux 会把值保存到 fish_variables，
+// 只删除 config.fish 中的设置行不会清除已保存的值。未安装 fish 时不做任何操作.
+func eraseFishUniversalVar(envKey string) {
+	fishPath, err := exec.LookPath("fish")
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(fishPath, "-c", "if set -q -U $argv[1]; set -Ue $argv[1]; end", envKey)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		fmt.Printf("警告: 清除 fish 通用变量 %s 失败: %v, 输出: %s\n", envKey, err, strings.TrimSpace(string(output)))
+	}
+}
+
+// removeEnvExportLines 删除 shell 配置内容中由本工具写入的指定环境变量设置行（export 或 fish 的 set -Ux），
+// 旧版本的注释行随之删除，用户手动编写的设置行保持不变。返回新内容以及是否有行被删除.
+func removeEnvExportLines(content, envKey string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	newLines := make([]string, 0, len(lines))
+	exportPattern := fmt.Sprintf("export %s=", envKey)
+	fishPattern := fmt.Sprintf("set -Ux %s ", envKey)
+	found := false
+
+	for i, line := range lines {
+		if !isManagedEnvLine(lines, i, exportPattern, fishPattern) {
+			newLines = append(newLines, line)
+			continue
+		}
+		found = true
+		if i > 0 && isLegacyManagedComment(lines[i-1]) && len(newLines) > 0 {
+			newLines = newLines[:len(newLines)-1]
+		}
+	}
+
+	return strings.Join(newLines, "\n"), found
+}
+
 // showRefreshInstructions 显示环境变量刷新指导.
 func (em *EnvManager) showRefreshInstructions() error {
 	platform := GetCurrentPlatform()
@@ -436,12 +574,7 @@ func (em *EnvManager) showRefreshInstructions() error {
 		return fmt.Errorf("获取用户主目录失败: %v", err)
 	}
 
-	var shellFiles []string
-	if platform == PlatformMac {
-		shellFiles = []string{".zshrc"}
-	} else {
-		shellFiles = []string{".bashrc", ".profile"}
-	}
+	shellFiles := currentShellRCFiles()
 
 	// 显示刷新提示信息
 	fmt.Println("\n📝 环境变量已写入配置文件")
@@ -0,0 +1,356 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestRemoveEnvExportLines 测试从 shell 配置内容中删除环境变量导出行.
+func TestRemoveEnvExportLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		envKey    string
+		expected  string
+		wantFound bool
+	}{
+		{
+			name:      "删除受管理的导出行",
+			content:   "alias ll='ls -l'\nexport MY_KEY=secret # codex-mirror managed\nexport PATH=$PATH:/bin\n",
+			envKey:    "MY_KEY",
+			expected:  "alias ll='ls -l'\nexport PATH=$PATH:/bin\n",
+			wantFound: true,
+		},
+		{
+			name:      "删除带缩进的重复行",
+			content:   "export MY_KEY=old # codex-mirror managed\n  export MY_KEY=new # codex-mirror managed\n",
+			envKey:    "MY_KEY",
+			expected:  "",
+			wantFound: true,
+		},
+		{
+			name:      "删除旧版本注释后的导出行及注释",
+			content:   "alias ll='ls -l'\n# Codex Mirror Switch - API Key\nexport MY_KEY=secret\n",
+			envKey:    "MY_KEY",
+			expected:  "alias ll='ls -l'\n",
+			wantFound: true,
+		},
+		{
+			name:      "不删除用户手动编写的导出行",
+			content:   "export MY_KEY=hand-written\nexport MY_KEY=secret # codex-mirror managed\n",
+			envKey:    "MY_KEY",
+			expected:  "export MY_KEY=hand-written\n",
+			wantFound: true,
+		},
+		{
+			name:      "不删除前缀相同的其他变量",
+			content:   "export MY_KEY_EXTRA=1\n",
+			envKey:    "MY_KEY",
+			expected:  "export MY_KEY_EXTRA=1\n",
+			wantFound: false,
+		},
+		{
+			name:      "删除fish的set -Ux行",
+			content:   "set -gx EDITOR vim\nset -Ux MY_KEY secret # codex-mirror managed\n",
+			envKey:    "MY_KEY",
+			expected:  "set -gx EDITOR vim\n",
+			wantFound: true,
+		},
+		{
+			name:      "不删除注释中的变量",
+			content:   "# export MY_KEY=secret\n",
+			envKey:    "MY_KEY",
+			expected:  "# export MY_KEY=secret\n",
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := removeEnvExportLines(tt.content, tt.envKey)
+			if found != tt.wantFound {
+				t.Errorf("found = %v, expected %v", found, tt.wantFound)
+			}
+			if got != tt.expected {
+				t.Errorf("content = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRemoveMirrorEnvKeys 测试删除镜像源时返回应清除的环境变量名，且 RemoveMirrorWithOptions 不直接改动 shell 配置.
+func TestRemoveMirrorEnvKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		remove   string
+		current  func(c *SystemConfig)
+		expected []string
+	}{
+		{
+			name:     "仍有其他镜像源使用同一环境变量",
+			remove:   "claude-a",
+			current:  func(c *SystemConfig) { c.CurrentClaude = "claude-b" },
+			expected: nil,
+		},
+		{
+			name:     "删除当前 Claude 镜像源",
+			remove:   "claude-a",
+			current:  func(c *SystemConfig) { c.CurrentClaude = "claude-a" },
+			expected: []string{AnthropicBaseURLEnv, AnthropicAuthTokenEnv, AnthropicModelEnv},
+		},
+		{
+			name:     "非当前 Codex 镜像源与官方镜像源共用环境变量",
+			remove:   "codex-a",
+			current:  func(c *SystemConfig) { c.CurrentCodex = DefaultMirrorName },
+			expected: nil,
+		},
+		{
+			name:     "删除当前 Codex 镜像源",
+			remove:   "codex-a",
+			current:  func(c *SystemConfig) { c.CurrentCodex = "codex-a" },
+			expected: []string{CodexSwitchAPIKeyEnv},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			mm := createTestMirrorManager(t, tempDir)
+			for _, mirror := range []struct {
+				name     string
+				toolType ToolType
+			}{
+				{"claude-a", ToolTypeClaude},
+				{"claude-b", ToolTypeClaude},
+				{"codex-a", ToolTypeCodex},
+			} {
+				if err := mm.AddMirrorWithType(mirror.name, "https://"+mirror.name+".example.com", "key", mirror.toolType); err != nil {
+					t.Fatalf("添加镜像源失败: %v", err)
+				}
+			}
+			tt.current(mm.config)
+
+			rcContent := "export " + AnthropicAuthTokenEnv + "=key " + shellManagedMarker + "\n"
+			rcPath := filepath.Join(tempDir, ".bashrc")
+			if err := os.WriteFile(rcPath, []byte(rcContent), 0o644); err != nil {
+				t.Fatalf("写入 .bashrc 失败: %v", err)
+			}
+
+			envKeys, err := mm.RemoveMirrorWithOptions(tt.remove, false)
+			if err != nil {
+				t.Fatalf("删除镜像源失败: %v", err)
+			}
+			if !slices.Equal(envKeys, tt.expected) {
+				t.Errorf("envKeys = %v, expected %v", envKeys, tt.expected)
+			}
+
+			data, err := os.ReadFile(rcPath)
+			if err != nil {
+				t.Fatalf("读取 .bashrc 失败: %v", err)
+			}
+			if string(data) != rcContent {
+				t.Errorf("RemoveMirrorWithOptions 不应修改 shell 配置，实际内容: %q", string(data))
+			}
+		})
+	}
+}
+
+// TestRemoveMirrorPurgesEnvKey 测试删除当前镜像源后 shell 配置中受管理的导出行被清除，启用 no_env 时保持不变.
+func TestRemoveMirrorPurgesEnvKey(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell 配置文件仅用于 Unix 系统")
+	}
+
+	tests := []struct {
+		name        string
+		noEnv       bool
+		wantRemoved bool
+	}{
+		{name: "清除受管理的导出行", noEnv: false, wantRemoved: true},
+		{name: "no_env 时不修改 shell 配置", noEnv: true, wantRemoved: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			mm := createTestMirrorManager(t, tempDir)
+			if err := mm.AddMirrorWithType("codex-a", "https://a.example.com", "key-a", ToolTypeCodex); err != nil {
+				t.Fatalf("添加镜像源失败: %v", err)
+			}
+			mm.config.CurrentCodex = "codex-a"
+			mm.config.NoEnv = tt.noEnv
+
+			managedLine := "export " + CodexSwitchAPIKeyEnv + "=key-a " + shellManagedMarker
+			rcPath := filepath.Join(tempDir, ".bashrc")
+			if err := os.WriteFile(rcPath, []byte("export KEEP_ME=1\n"+managedLine+"\n"), 0o644); err != nil {
+				t.Fatalf("写入 .bashrc 失败: %v", err)
+			}
+
+			if err := mm.RemoveMirror("codex-a"); err != nil {
+				t.Fatalf("删除镜像源失败: %v", err)
+			}
+
+			data, err := os.ReadFile(rcPath)
+			if err != nil {
+				t.Fatalf("读取 .bashrc 失败: %v", err)
+			}
+			content := string(data)
+			if removed := !strings.Contains(content, managedLine); removed != tt.wantRemoved {
+				t.Errorf("导出行已清除 = %v, 期望 %v，实际内容: %q", removed, tt.wantRemoved, content)
+			}
+			if !strings.Contains(content, "export KEEP_ME=1") {
+				t.Errorf("其他变量应保留，实际内容: %q", content)
+			}
+		})
+	}
+}
+
+// TestSetUnixUserEnvVarIdempotent 测试重复设置环境变量时只保留一行受管理的导出语句.
+func TestSetUnixUserEnvVarIdempotent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell 配置文件仅用于 Unix 系统")
+	}
+
+	tempDir := setupTestDir(t)
+	t.Setenv("HOME", tempDir)
+
+	// 历史版本可能遗留多行重复的受管理导出语句，用户手写的导出语句不应被改动
+	rcPath := filepath.Join(tempDir, ".bashrc")
+	handWritten := "export MY_API_KEY=hand-written"
+	legacy := "alias ll='ls -l'\n" + handWritten + "\nexport MY_API_KEY=old-1 " + shellManagedMarker +
+		"\nexport MY_API_KEY=old-2 " + shellManagedMarker + "\n"
+	if err := os.WriteFile(rcPath, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("写入 .bashrc 失败: %v", err)
+	}
+
+	for _, value := range []string{"first", "second"} {
+		if err := setUnixUserEnvVar("MY_API_KEY", value, []string{".bashrc"}); err != nil {
+			t.Fatalf("setUnixUserEnvVar 失败: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(rcPath)
+	if err != nil {
+		t.Fatalf("读取 .bashrc 失败: %v", err)
+	}
+	content := string(data)
+
+	if count := strings.Count(content, shellManagedMarker); count != 1 {
+		t.Errorf("应只有一行受管理的 MY_API_KEY 导出语句，实际 %d 行:\n%s", count, content)
+	}
+	if !strings.Contains(content, "export MY_API_KEY=second "+shellManagedMarker) {
+		t.Errorf("导出语句应为最新值并带有管理标记，实际内容:\n%s", content)
+	}
+	if !strings.HasPrefix(content, "alias ll='ls -l'\n"+handWritten+"\n") {
+		t.Errorf("其他内容应保留在原位置，实际内容:\n%s", content)
+	}
+}
+
+// TestSetUnixUserEnvVarMigratesLegacyLine 测试旧版本写入的（紧随旧注释的）导出行被原地替换为受管理的导出行.
+func TestSetUnixUserEnvVarMigratesLegacyLine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell 配置文件仅用于 Unix 系统")
+	}
+
+	tempDir := setupTestDir(t)
+	t.Setenv("HOME", tempDir)
+
+	rcPath := filepath.Join(tempDir, ".bashrc")
+	legacy := "alias ll='ls -l'\n\n# Codex Mirror Switch - API Key\nexport " + AnthropicAuthTokenEnv + "=old\nexport EDITOR=vim\n"
+	if err := os.WriteFile(rcPath, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("写入 .bashrc 失败: %v", err)
+	}
+
+	if err := setUnixUserEnvVar(AnthropicAuthTokenEnv, "new", []string{".bashrc"}); err != nil {
+		t.Fatalf("setUnixUserEnvVar 失败: %v", err)
+	}
+
+	data, err := os.ReadFile(rcPath)
+	if err != nil {
+		t.Fatalf("读取 .bashrc 失败: %v", err)
+	}
+	expected := "alias ll='ls -l'\n\nexport " + AnthropicAuthTokenEnv + "=new " + shellManagedMarker + "\nexport EDITOR=vim"
+	if got := string(data); got != expected {
+		t.Errorf("content = %q, expected %q", got, expected)
+	}
+}
+
+// TestShellRCFiles 测试根据登录 shell 选择写入环境变量的 shell 配置文件.
+func TestShellRCFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform Platform
+		shell    string
+		want     []string
+	}{
+		{name: "Linux zsh", platform: PlatformLinux, shell: "/usr/bin/zsh", want: []string{".zshrc"}},
+		{name: "Linux bash", platform: PlatformLinux, shell: "/bin/bash", want: []string{".bashrc", ".profile"}},
+		{name: "Linux dash", platform: PlatformLinux, shell: "/bin/dash", want: []string{".profile"}},
+		{name: "Linux fish", platform: PlatformLinux, shell: "/usr/bin/fish", want: []string{fishConfigFile}},
+		{name: "macOS fish", platform: PlatformMac, shell: "/opt/homebrew/bin/fish", want: []string{fishConfigFile}},
+		{name: "Linux 未设置SHELL", platform: PlatformLinux, shell: "", want: []string{".bashrc", ".profile"}},
+		{name: "Linux 未知shell", platform: PlatformLinux, shell: "/usr/bin/xonsh", want: []string{".bashrc", ".profile"}},
+		{name: "macOS zsh", platform: PlatformMac, shell: "/bin/zsh", want: []string{".zshrc"}},
+		{name: "macOS bash", platform: PlatformMac, shell: "/opt/homebrew/bin/bash", want: []string{".bash_profile"}},
+		{name: "macOS 未设置SHELL", platform: PlatformMac, shell: "", want: []string{".zshrc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shellRCFiles(tt.platform, tt.shell)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("shellRCFiles(%s, %q) = %v, want %v", tt.platform, tt.shell, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSetEnvironmentVariableHonorsShell 测试按 $SHELL 写入对应的 shell 配置文件和语法.
+func TestSetEnvironmentVariableHonorsShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell 配置文件仅用于 Unix 系统")
+	}
+
+	tests := []struct {
+		name     string
+		shell    string
+		file     string
+		wantLine string
+	}{
+		{name: "zsh", shell: "/usr/bin/zsh", file: ".zshrc", wantLine: "export CODEX_MIRROR_SHELL_TEST_KEY=value"},
+		{name: "fish", shell: "/usr/bin/fish", file: fishConfigFile, wantLine: "set -Ux CODEX_MIRROR_SHELL_TEST_KEY value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			t.Setenv("HOME", tempDir)
+			t.Setenv("SHELL", tt.shell)
+			t.Setenv("CODEX_MIRROR_SHELL_TEST_KEY", "")
+
+			if err := NewEnvManager().setEnvironmentVariableNoRefresh("CODEX_MIRROR_SHELL_TEST_KEY", "value"); err != nil {
+				t.Fatalf("setEnvironmentVariableNoRefresh() error = %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(tempDir, tt.file))
+			if err != nil || !strings.Contains(string(data), tt.wantLine) {
+				t.Errorf("%s 应包含 %q: %v\n%s", tt.file, tt.wantLine, err, data)
+			}
+			if _, err := os.Stat(filepath.Join(tempDir, ".bashrc")); !os.IsNotExist(err) {
+				t.Errorf("登录 shell 为 %s 时不应写入 .bashrc", tt.name)
+			}
+
+			if err := NewEnvManager().UnsetEnvVar("CODEX_MIRROR_SHELL_TEST_KEY"); err != nil {
+				t.Fatalf("UnsetEnvVar() error = %v", err)
+			}
+			data, _ = os.ReadFile(filepath.Join(tempDir, tt.file))
+			if strings.Contains(string(data), "CODEX_MIRROR_SHELL_TEST_KEY") {
+				t.Errorf("清除后 %s 不应包含该变量:\n%s", tt.file, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 镜像源操作的哨兵错误，调用方应使用 errors.Is 判断错误类型，而不是匹配错误文本.
+var (
+	// ErrMirrorNotFound 镜像源不存在（或已被删除）.
+	ErrMirrorNotFound = errors.New("镜像源不存在")
+	// ErrMirrorExists 同名镜像源已存在.
+	ErrMirrorExists = errors.New("镜像源已存在")
+	// ErrCannotRemoveOfficial 官方镜像源不能删除.
+	ErrCannotRemoveOfficial = errors.New("不能删除官方镜像源")
+	// ErrRemoteNotFound 云端尚无同步配置（首次推送）.
+	ErrRemoteNotFound = errors.New("云端配置不存在")
+	// ErrSyncAborted 检测到同步冲突且使用 abort 策略，未上传也未修改本地配置.
+	ErrSyncAborted = errors.New("检测到配置冲突，已按 abort 策略取消同步")
+	// ErrRemoteChanged 推送前发现云端配置在本次同步开始后已被其他设备修改，未上传.
+	ErrRemoteChanged = errors.New("云端配置在本次同步开始后已被修改")
+	// ErrToolTypeMismatch 镜像源的工具类型与要写入的配置不一致（如将 Claude 镜像源应用到 Codex 配置）.
+	ErrToolTypeMismatch = errors.New("镜像源类型与目标工具不匹配")
+)
+
+// mirrorError 带有具体描述的镜像源错误，Unwrap 返回对应的哨兵错误.
+type mirrorError struct {
+	msg string
+	err error
+}
+
+// Error 返回具体描述.
+func (e *mirrorError) Error() string {
+	return e.msg
+}
+
+// Unwrap 返回哨兵错误，供 errors.Is 使用.
+func (e *mirrorError) Unwrap() error {
+	return e.err
+}
+
+// mirrorNotFoundError 返回包装 ErrMirrorNotFound 的错误.
+func mirrorNotFoundError(format string, args ...interface{}) error {
+	return &mirrorError{msg: fmt.Sprintf(format, args...), err: ErrMirrorNotFound}
+}
+
+// checkMirrorToolType 确认镜像源可以应用到 expected 对应工具的配置，未设置类型的旧镜像源按 Codex 处理.
+func checkMirrorToolType(mirror *MirrorConfig, expected ToolType) error {
+	toolType := mirror.ToolType
+	if toolType == "" {
+		toolType = ToolTypeCodex
+	}
+	if toolType != expected {
+		return &mirrorError{
+			msg: fmt.Sprintf("镜像源 '%s' 的类型是 %s，不能应用到 %s 配置", mirror.Name, toolType, expected),
+			err: ErrToolTypeMismatch,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// jsoncMember JSONC 顶层对象中一个成员在源文本中的位置.
+type jsoncMember struct {
+	Key        string
+	Start      int // 键的起始位置（引号处）
+	ValueStart int
+	ValueEnd   int
+}
+
+// ParseJSONC 解析 JSONC 文本（允许 // 和 /* */ 注释以及尾随逗号）.
+func ParseJSONC(data []byte, v interface{}) error {
+	cleaned := RemoveTrailingCommas(RemoveJSONComments(string(data)))
+	return json.Unmarshal([]byte(cleaned), v)
+}
+
+// RemoveTrailingCommas 移除对象和数组末尾多余的逗号（输入中不应包含注释）.
+func RemoveTrailingCommas(jsonStr string) string {
+	var result strings.Builder
+	inString := false
+	escapeNext := false
+
+	for i := 0; i < len(jsonStr); i++ {
+		c := jsonStr[i]
+		switch {
+		case escapeNext:
+			escapeNext = false
+		case inString && c == '\\':
+			escapeNext = true
+		case c == '"':
+			inString = !inString
+		case !inString && c == ',':
+			j := i + 1
+			for j < len(jsonStr) && isJSONWhitespace(jsonStr[j]) {
+				j++
+			}
+			if j < len(jsonStr) && (jsonStr[j] == '}' || jsonStr[j] == ']') {
+				continue
+			}
+		}
+		result.WriteByte(c)
+	}
+
+	return result.String()
+}
+
+// EditJSONCFile 读取 JSONC 文件原文，经 edit 修改后原子写回；文件不存在时以空内容调用 edit.
+func EditJSONCFile(path string, edit func(src string) (string, error)) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("读取文件失败: %v", err)
+	}
+
+	updated, err := edit(string(data))
+	if err != nil {
+		return err
+	}
+
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	return WriteFileAtomic(path, 0o600, func(w io.Writer) error {
+		_, err := io.WriteString(w, updated)
+		return err
+	})
+}
+
+// SetJSONCMember 在 JSONC 顶层对象中设置成员的值，只修改该成员所在的文本，其余内容（包括注释）保持不变.
+func SetJSONCMember(src, key string, value interface{}) (string, error) {
+	if strings.TrimSpace(src) == "" {
+		src = "{}\n"
+	}
+
+	members, objStart, objEnd, err := scanJSONCMembers(src)
+	if err != nil {
+		return "", err
+	}
+
+	for _, m := range members {
+		if m.Key == key {
+			encoded, err := encodeJSONCValue(value, lineIndent(src, m.Start))
+			if err != nil {
+				return "", err
+			}
+			return src[:m.ValueStart] + encoded + src[m.ValueEnd:], nil
+		}
+	}
+
+	// 新增成员：沿用最后一个成员的缩进，追加到对象末尾
+	indent := "  "
+	if len(members) > 0 {
+		indent = lineIndent(src, members[len(members)-1].Start)
+	}
+	encoded, err := encodeJSONCValue(value, indent)
+	if err != nil {
+		return "", err
+	}
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	entry := "\n" + indent + string(keyJSON) + ": " + encoded
+
+	if len(members) == 0 {
+		closing := strings.TrimRight(src[objStart+1:objEnd], " \t\r\n")
+		return src[:objStart+1] + closing + entry + "\n" + src[objEnd:], nil
+	}
+
+	last := members[len(members)-1]
+	insertAt := last.ValueEnd
+	if next := skipJSONCTrivia(src, last.ValueEnd); next < len(src) && src[next] == ',' {
+		// 已有尾随逗号，插入到逗号之后
+		insertAt = next + 1
+	} else {
+		entry = "," + entry
+	}
+	return src[:insertAt] + entry + src[insertAt:], nil
+}
+
+// DeleteJSONCMember 从 JSONC 顶层对象中删除成员，其余内容（包括注释）保持不变.
+func DeleteJSONCMember(src, key string) (string, error) {
+	if strings.TrimSpace(src) == "" {
+		return src, nil
+	}
+
+	members, _, _, err := scanJSONCMembers(src)
+	if err != nil {
+		return "", err
+	}
+
+	for i, m := range members {
+		if m.Key != key {
+			continue
+		}
+
+		start := m.Start
+		wholeLine := false
+		if lineStart := strings.LastIndexByte(src[:start], '\n') + 1; strings.TrimSpace(src[lineStart:start]) == "" {
+			start = lineStart
+			wholeLine = true
+		}
+
+		end := m.ValueEnd
+		if next := skipJSONCTrivia(src, end); next < len(src) && src[next] == ',' {
+			end = next + 1
+		} else if i > 0 {
+			// 删除最后一个成员时，同时删除前一个成员后面的逗号
+			prev := members[i-1]
+			if comma := skipJSONCTrivia(src, prev.ValueEnd); comma < len(src) && src[comma] == ',' {
+				src = src[:comma] + src[comma+1:]
+				start--
+				end--
+			}
+		}
+
+		// 成员独占一行时，连同行尾换行一并删除
+		if lineEnd := strings.IndexByte(src[end:], '\n'); wholeLine && lineEnd >= 0 && strings.TrimSpace(src[end:end+lineEnd]) == "" {
+			end += lineEnd + 1
+		}
+
+		return src[:start] + src[end:], nil
+	}
+
+	return src, nil
+}
+
+// scanJSONCMembers 扫描 JSONC 文本的顶层对象，返回各成员位置以及对象起止位置.
+func scanJSONCMembers(src string) (members []jsoncMember, objStart, objEnd int, err error) {
+	objStart = skipJSONCTrivia(src, 0)
+	if objStart >= len(src) || src[objStart] != '{' {
+		return nil, 0, 0, fmt.Errorf("JSONC 顶层不是对象")
+	}
+
+	i := objStart + 1
+	for {
+		i = skipJSONCTrivia(src, i)
+		if i >= len(src) {
+			return nil, 0, 0, fmt.Errorf("JSONC 对象未闭合")
+		}
+		if src[i] == '}' {
+			return members, objStart, i, nil
+		}
+		if src[i] == ',' {
+			i++
+			continue
+		}
+		if src[i] != '"' {
+			return nil, 0, 0, fmt.Errorf("JSONC 第 %d 个字符处应为键名", i)
+		}
+
+		keyEnd, err := skipJSONCString(src, i)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		var key string
+		if err := json.Unmarshal([]byte(src[i:keyEnd]), &key); err != nil {
+			return nil, 0, 0, fmt.Errorf("解析 JSONC 键名失败: %v", err)
+		}
+
+		colon := skipJSONCTrivia(src, keyEnd)
+		if colon >= len(src) || src[colon] != ':' {
+			return nil, 0, 0, fmt.Errorf("JSONC 键 '%s' 之后缺少冒号", key)
+		}
+
+		valueStart := skipJSONCTrivia(src, colon+1)
+		valueEnd, err := skipJSONCValue(src, valueStart)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+
+		members = append(members, jsoncMember{Key: key, Start: i, ValueStart: valueStart, ValueEnd: valueEnd})
+		i = valueEnd
+	}
+}
+
+// skipJSONCTrivia 跳过空白和注释，返回下一个有效字符的位置.
+func skipJSONCTrivia(src string, i int) int {
+	for i < len(src) {
+		switch {
+		case isJSONWhitespace(src[i]):
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return len(src)
+			}
+			i += end + 1
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return len(src)
+			}
+			i += end + 4
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipJSONCString 跳过从 i 开始的字符串字面量，返回结束引号之后的位置.
+func skipJSONCString(src string, i int) (int, error) {
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("JSONC 字符串未闭合")
+}
+
+// skipJSONCValue 跳过从 i 开始的值（可包含注释），返回值结束之后的位置.
+func skipJSONCValue(src string, i int) (int, error) {
+	if i >= len(src) {
+		return 0, fmt.Errorf("JSONC 缺少值")
+	}
+
+	switch src[i] {
+	case '"':
+		return skipJSONCString(src, i)
+	case '{', '[':
+		depth := 0
+		for j := i; j < len(src); {
+			switch {
+			case src[j] == '"':
+				end, err := skipJSONCString(src, j)
+				if err != nil {
+					return 0, err
+				}
+				j = end
+				continue
+			case strings.HasPrefix(src[j:], "//") || strings.HasPrefix(src[j:], "/*"):
+				j = skipJSONCTrivia(src, j)
+				continue
+			case src[j] == '{' || src[j] == '[':
+				depth++
+			case src[j] == '}' || src[j] == ']':
+				depth--
+				if depth == 0 {
+					return j + 1, nil
+				}
+			}
+			j++
+		}
+		return 0, fmt.Errorf("JSONC 对象或数组未闭合")
+	default:
+		// 数字、true、false、null
+		j := i
+		for j < len(src) && !isJSONWhitespace(src[j]) && !strings.ContainsRune(",}]/", rune(src[j])) {
+			j++
+		}
+		if j == i {
+			return 0, fmt.Errorf("JSONC 第 %d 个字符处缺少值", i)
+		}
+		return j, nil
+	}
+}
+
+// encodeJSONCValue 将值编码为 JSON，多行输出的后续行使用 indent 作为前缀.
+func encodeJSONCValue(value interface{}, indent string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(indent, "  ")
+	if err := encoder.Encode(value); err != nil {
+		return "", fmt.Errorf("编码 JSON 值失败: %v", err)
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// lineIndent 返回 pos 所在行开头的空白缩进.
+func lineIndent(src string, pos int) string {
+	lineStart := strings.LastIndexByte(src[:pos], '\n') + 1
+	line := src[lineStart:pos]
+	if strings.TrimSpace(line) != "" {
+		return "  "
+	}
+	return line
+}
+
+// isJSONWhitespace 判断是否为 JSON 空白字符.
+func isJSONWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
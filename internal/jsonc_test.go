@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"testing"
+)
+
+// TestSetJSONCMember 测试在JSONC中设置顶层成员.
+func TestSetJSONCMember(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		key   string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "替换已有成员并保留注释",
+			src:   "{\n  // 编辑器字体\n  \"editor.fontSize\": 14,\n  \"chatgpt.apiBase\": \"https://old.com\" // 旧地址\n}\n",
+			key:   "chatgpt.apiBase",
+			value: "https://new.com",
+			want:  "{\n  // 编辑器字体\n  \"editor.fontSize\": 14,\n  \"chatgpt.apiBase\": \"https://new.com\" // 旧地址\n}\n",
+		},
+		{
+			name:  "追加新成员",
+			src:   "{\n  /* 块注释 */\n  \"a\": 1\n}\n",
+			key:   "b",
+			value: "x&y",
+			want:  "{\n  /* 块注释 */\n  \"a\": 1,\n  \"b\": \"x&y\"\n}\n",
+		},
+		{
+			name:  "已有尾随逗号",
+			src:   "{\n  \"a\": 1,\n}\n",
+			key:   "b",
+			value: 2,
+			want:  "{\n  \"a\": 1,\n  \"b\": 2\n}\n",
+		},
+		{
+			name:  "空对象",
+			src:   "{}",
+			key:   "a",
+			value: map[string]string{"k": "v"},
+			want:  "{\n  \"a\": {\n    \"k\": \"v\"\n  }\n}",
+		},
+		{
+			name:  "空文件",
+			src:   "",
+			key:   "a",
+			value: true,
+			want:  "{\n  \"a\": true\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetJSONCMember(tt.src, tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("SetJSONCMember() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SetJSONCMember() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDeleteJSONCMember 测试从JSONC中删除顶层成员.
+func TestDeleteJSONCMember(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		key  string
+		want string
+	}{
+		{
+			name: "删除中间成员",
+			src:  "{\n  // 保留\n  \"a\": 1,\n  \"b\": {\"x\": [1, 2]},\n  \"c\": 3\n}\n",
+			key:  "b",
+			want: "{\n  // 保留\n  \"a\": 1,\n  \"c\": 3\n}\n",
+		},
+		{
+			name: "删除最后一个成员",
+			src:  "{\n  \"a\": 1,\n  \"b\": 2\n}\n",
+			key:  "b",
+			want: "{\n  \"a\": 1\n}\n",
+		},
+		{
+			name: "删除唯一成员",
+			src:  "{\n  \"a\": 1\n}\n",
+			key:  "a",
+			want: "{\n}\n",
+		},
+		{
+			name: "成员不存在",
+			src:  "{\"a\": 1}",
+			key:  "b",
+			want: "{\"a\": 1}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeleteJSONCMember(tt.src, tt.key)
+			if err != nil {
+				t.Fatalf("DeleteJSONCMember() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DeleteJSONCMember() =\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseJSONC 测试解析带注释和尾随逗号的JSONC.
+func TestParseJSONC(t *testing.T) {
+	src := "{\n  // 注释\n  \"a\": [1, 2,],\n  \"b\": \"x, }\", /* 注释 */\n}\n"
+
+	var got map[string]interface{}
+	if err := ParseJSONC([]byte(src), &got); err != nil {
+		t.Fatalf("ParseJSONC() error = %v", err)
+	}
+	if got["b"] != "x, }" || len(got["a"].([]interface{})) != 2 {
+		t.Errorf("ParseJSONC() = %v", got)
+	}
+
+	if err := ParseJSONC([]byte("[1"), &got); err == nil {
+		t.Error("ParseJSONC() should fail on invalid input")
+	}
+}
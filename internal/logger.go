@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 日志输出格式.
+const (
+	// LogFormatText 默认的文本格式，原样输出带表情符号的提示信息到标准输出
+	LogFormatText = "text"
+	// LogFormatJSON 每条日志输出为一行 JSON 到标准错误，便于脚本解析
+	LogFormatJSON = "json"
+)
+
+// LogLevel 日志级别.
+type LogLevel string
+
+// 日志级别.
+const (
+	LogLevelInfo  LogLevel = "info"
+	LogLevelWarn  LogLevel = "warn"
+	LogLevelError LogLevel = "error"
+)
+
+// logEntry JSON 格式的单条日志.
+type logEntry struct {
+	Time    time.Time `json:"time"`
+	Level   LogLevel  `json:"level"`
+	Message string    `json:"msg"`
+}
+
+// logger 全局日志配置，命令行通过 --quiet 和 --log-format 设置.
+var logger = struct {
+	mu     sync.Mutex
+	quiet  bool
+	format string
+}{format: LogFormatText}
+
+// ConfigureLogger 设置日志输出：quiet 为 true 时不输出 info 级别的进度信息，format 为 text 或 json.
+func ConfigureLogger(quiet bool, format string) error {
+	if format == "" {
+		format = LogFormatText
+	}
+	if format != LogFormatText && format != LogFormatJSON {
+		return fmt.Errorf("无效的日志格式 '%s'，支持: %s, %s", format, LogFormatText, LogFormatJSON)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.quiet = quiet
+	logger.format = format
+	return nil
+}
+
+// LogInfof 输出 info 级别的进度信息，--quiet 时不输出.
+func LogInfof(format string, args ...interface{}) {
+	logf(LogLevelInfo, format, args...)
+}
+
+// LogWarnf 输出 warn 级别的警告信息.
+func LogWarnf(format string, args ...interface{}) {
+	logf(LogLevelWarn, format, args...)
+}
+
+// LogErrorf 输出 error 级别的错误说明.
+func LogErrorf(format string, args ...interface{}) {
+	logf(LogLevelError, format, args...)
+}
+
+// logf 按当前配置输出一条日志.
+// 文本格式与直接调用 fmt.Printf 完全一致；JSON 格式去掉首尾空白后输出到标准错误，空消息会被忽略.
+func logf(level LogLevel, format string, args ...interface{}) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if logger.quiet && level == LogLevelInfo {
+		return
+	}
+
+	message := fmt.Sprintf(format, args...)
+	if logger.format != LogFormatJSON {
+		fmt.Fprint(os.Stdout, message)
+		return
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return
+	}
+	data, err := json.Marshal(logEntry{Time: time.Now(), Level: level, Message: message})
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, string(data))
+}
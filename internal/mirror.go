@@ -1,46 +1,57 @@
 package internal
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
+// ErrConfigCorrupted 镜像源配置文件存在但无法解析.
+var ErrConfigCorrupted = errors.New("镜像源配置文件已损坏")
+
 // MirrorManager 镜像源管理器.
 type MirrorManager struct {
 	configPath string
 	config     *SystemConfig
+	// skipURLValidation 为 true 时添加/更新镜像源不校验 URL（用于特殊的内网地址）
+	skipURLValidation bool
 }
 
-// NewMirrorManager 创建新的镜像源管理器.
-func NewMirrorManager() (*MirrorManager, error) {
-	// 优先检查环境变量指定的配置路径
-	if configPath := os.Getenv("CODEX_MIRROR_CONFIG_PATH"); configPath != "" {
-		return NewMirrorManagerWithPath(configPath)
-	}
-
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("获取用户主目录失败: %v", err)
-	}
-
-	configPath := filepath.Join(homeDir, ".codex-mirror", "mirrors.toml")
-	return NewMirrorManagerWithPath(configPath)
+// Options 创建镜像源管理器的选项.
+type Options struct {
+	// Path 镜像源配置文件路径，为空时使用 GetMirrorConfigPath 返回的默认位置
+	Path string
+	// DiscoverFromEnv 配置文件不存在时是否从环境变量和 ~/.codex/config.toml 中发现镜像源；
+	// 为 false 时只使用内置的官方镜像源，且在首次修改前不写入配置文件
+	DiscoverFromEnv bool
 }
 
-// GetConfigPath 返回配置文件路径.
-func (mm *MirrorManager) GetConfigPath() string {
-	return mm.configPath
+// NewMirrorManager 创建新的镜像源管理器，配置文件不存在时从环境中发现镜像源.
+func NewMirrorManager() (*MirrorManager, error) {
+	return NewMirrorManagerWithOptions(Options{DiscoverFromEnv: true})
 }
 
-// NewMirrorManagerWithPath 使用指定路径创建新的镜像源管理器.
-func NewMirrorManagerWithPath(configPath string) (*MirrorManager, error) {
+// NewMirrorManagerWithOptions 按选项创建镜像源管理器，适用于将本包作为库嵌入的场景.
+func NewMirrorManagerWithOptions(opts Options) (*MirrorManager, error) {
+	configPath := opts.Path
+	if configPath == "" {
+		var err error
+		configPath, err = GetMirrorConfigPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	mm := &MirrorManager{
 		configPath: configPath,
 		config:     &SystemConfig{},
@@ -48,65 +59,105 @@ func NewMirrorManagerWithPath(configPath string) (*MirrorManager, error) {
 
 	// 尝试加载现有配置
 	if err := mm.loadConfig(); err != nil {
-		// 如果配置文件不存在，检查是否有已存在的环境变量
-		mm.discoverFromEnvironment()
+		switch {
+		case os.IsNotExist(err) && opts.DiscoverFromEnv:
+			// 如果配置文件不存在，检查是否有已存在的环境变量
+			mm.discoverFromEnvironment()
+		case os.IsNotExist(err):
+			mm.initDefaultConfig()
+		case errors.Is(err, ErrConfigCorrupted):
+			// 文件存在但无法解析时不能用发现的默认配置覆盖，保留原文件交给用户修复
+			return nil, mm.handleCorruptedConfig(err)
+		default:
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
 	}
 
 	return mm, nil
 }
 
-// loadConfig 加载配置文件.
-func (mm *MirrorManager) loadConfig() error {
-	if _, err := os.Stat(mm.configPath); os.IsNotExist(err) {
-		return err
-	}
+// 镜像源配置位置相关常量.
+const (
+	// MirrorHomeEnv 指定镜像源配置目录的环境变量
+	MirrorHomeEnv = "CODEX_MIRROR_HOME"
+	// mirrorConfigFileName 配置目录中的镜像源配置文件名
+	mirrorConfigFileName = "mirrors.toml"
+)
 
-	_, err := toml.DecodeFile(mm.configPath, mm.config)
-	return err
+// configDirOverride 通过 SetConfigDir 指定的配置目录，为空表示未指定.
+var configDirOverride string
+
+// SetConfigDir 指定镜像源配置目录（对应命令行 --config-dir），优先于环境变量；传入空字符串取消指定.
+// 备份、同步历史等文件随配置目录一起迁移，Codex、Claude 和 VS Code 的配置仍位于各自的标准位置.
+func SetConfigDir(dir string) {
+	configDirOverride = dir
 }
 
-// saveConfig 保存配置文件.
-func (mm *MirrorManager) saveConfig() error {
-	// 使用原子写入：先写入临时文件，再通过重命名替换原文件
-	dir := filepath.Dir(mm.configPath)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("创建配置目录失败: %v", err)
+// GetMirrorConfigPath 获取镜像源配置文件路径.
+// 优先级: SetConfigDir 指定的目录 > CODEX_MIRROR_CONFIG_PATH（配置文件路径）> CODEX_MIRROR_HOME > ~/.codex-mirror.
+func GetMirrorConfigPath() (string, error) {
+	if configDirOverride != "" {
+		return filepath.Join(configDirOverride, mirrorConfigFileName), nil
+	}
+	if configPath := os.Getenv("CODEX_MIRROR_CONFIG_PATH"); configPath != "" {
+		return configPath, nil
+	}
+	if dir := os.Getenv(MirrorHomeEnv); dir != "" {
+		return filepath.Join(dir, mirrorConfigFileName), nil
 	}
 
-	tmpFile, err := os.CreateTemp(dir, "mirrors-*.toml")
+	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("创建临时配置文件失败: %v", err)
+		return "", fmt.Errorf("获取用户主目录失败: %v", err)
 	}
-	tmpPath := tmpFile.Name()
 
-	// 结束时尝试删除临时文件（如果已经被重命名则会失败，忽略该错误）
-	defer func() {
-		_ = os.Remove(tmpPath)
-	}()
+	return filepath.Join(homeDir, ".codex-mirror", mirrorConfigFileName), nil
+}
 
-	// 写入配置到临时文件
-	if err := toml.NewEncoder(tmpFile).Encode(mm.config); err != nil {
-		_ = tmpFile.Close()
-		return fmt.Errorf("写入临时配置文件失败: %v", err)
-	}
+// GetConfigPath 返回配置文件路径.
+func (mm *MirrorManager) GetConfigPath() string {
+	return mm.configPath
+}
+
+// NewMirrorManagerWithPath 使用指定路径创建新的镜像源管理器，配置文件不存在时从环境中发现镜像源.
+func NewMirrorManagerWithPath(configPath string) (*MirrorManager, error) {
+	return NewMirrorManagerWithOptions(Options{Path: configPath, DiscoverFromEnv: true})
+}
 
-	// 确保内容刷入磁盘
-	if err := tmpFile.Sync(); err != nil {
-		_ = tmpFile.Close()
-		return fmt.Errorf("同步临时配置文件失败: %v", err)
+// loadConfig 加载配置文件.
+func (mm *MirrorManager) loadConfig() error {
+	data, err := os.ReadFile(mm.configPath)
+	if err != nil {
+		return err
 	}
 
-	// 关闭临时文件句柄，避免在 Windows 上影响重命名
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("关闭临时配置文件失败: %v", err)
+	if _, err := toml.Decode(string(data), mm.config); err != nil {
+		return fmt.Errorf("%w: %v", ErrConfigCorrupted, err)
 	}
+	mm.migrateSyncProfiles()
+	mm.ensureOfficialMirror()
+	return nil
+}
 
-	// 使用重命名原子替换目标文件
-	if err := os.Rename(tmpPath, mm.configPath); err != nil {
-		return fmt.Errorf("替换配置文件失败: %v", err)
+// handleCorruptedConfig 备份无法解析的配置文件，返回说明原因和备份位置的错误.
+// 原文件保持不变.
+func (mm *MirrorManager) handleCorruptedConfig(loadErr error) error {
+	backupPath, err := mm.CreateBackup("corrupted")
+	if err != nil {
+		return fmt.Errorf("%w（备份失败: %v），请手动修复 %s", loadErr, err, mm.configPath)
 	}
+	return fmt.Errorf("%w，已备份到 %s，请修复 %s 后重试", loadErr, backupPath, mm.configPath)
+}
 
-	return nil
+// saveConfig 保存配置文件.
+func (mm *MirrorManager) saveConfig() error {
+	// 使用原子写入，避免中途失败留下损坏的配置文件
+	return WriteFileAtomic(mm.configPath, 0o600, func(w io.Writer) error {
+		if err := toml.NewEncoder(w).Encode(mm.config); err != nil {
+			return fmt.Errorf("写入配置文件失败: %v", err)
+		}
+		return nil
+	})
 }
 
 // SaveConfig 保存配置文件（公开方法）.
@@ -114,25 +165,52 @@ func (mm *MirrorManager) SaveConfig() error {
 	return mm.saveConfig()
 }
 
+// SetURLValidation 设置添加/更新镜像源时是否校验 URL.
+func (mm *MirrorManager) SetURLValidation(enabled bool) {
+	mm.skipURLValidation = !enabled
+}
+
+// normalizeURL 根据校验设置规范化镜像源 URL.
+func (mm *MirrorManager) normalizeURL(baseURL string) (string, error) {
+	if mm.skipURLValidation {
+		return strings.TrimRight(baseURL, "/"), nil
+	}
+	return NormalizeBaseURL(baseURL)
+}
+
 // GetConfig 获取系统配置（公开方法）.
 func (mm *MirrorManager) GetConfig() *SystemConfig {
 	return mm.config
 }
 
-// initDefaultConfig 初始化默认配置.
+// NoEnv 返回切换时是否跳过环境变量的持久化.
+func (mm *MirrorManager) NoEnv() bool {
+	return mm.config.NoEnv
+}
+
+// SetNoEnv 设置切换时是否跳过环境变量的持久化并保存配置.
+func (mm *MirrorManager) SetNoEnv(noEnv bool) error {
+	mm.config.NoEnv = noEnv
+	return mm.saveConfig()
+}
+
+// initDefaultConfig 初始化默认配置，官方镜像源的名称和地址可通过环境变量指定.
 func (mm *MirrorManager) initDefaultConfig() {
-	mm.config = &SystemConfig{
-		CurrentMirror: DefaultMirrorName,
-		CurrentCodex:  DefaultMirrorName,
-		Mirrors: []MirrorConfig{
-			{
-				Name:     DefaultMirrorName,
-				BaseURL:  "https://api.openai.com",
-				APIKey:   "",
-				ToolType: ToolTypeCodex,
-			},
+	officialName, officialURL := officialMirrorFromEnv()
+	config := &SystemConfig{OfficialName: officialName, OfficialURL: officialURL}
+	name := config.OfficialMirror()
+
+	config.CurrentMirror = name
+	config.CurrentCodex = name
+	config.Mirrors = []MirrorConfig{
+		{
+			Name:     name,
+			BaseURL:  config.officialMirrorURL(),
+			APIKey:   "",
+			ToolType: ToolTypeCodex,
 		},
 	}
+	mm.config = config
 }
 
 // AddMirror 添加镜像源.
@@ -155,6 +233,34 @@ func (mm *MirrorManager) ClearAPIKey(name string) error {
 	return fmt.Errorf("镜像源 '%s' 不存在或没有 API Key 需要清除", name)
 }
 
+// RotateAPIKey 将所有 API Key 与 oldKey 完全相同的镜像源替换为 newKey，返回被修改的镜像源数量.
+// 仅做精确匹配，避免误替换其他镜像源的密钥.
+func (mm *MirrorManager) RotateAPIKey(oldKey, newKey string) (int, error) {
+	if oldKey == "" || strings.TrimSpace(newKey) == "" {
+		return 0, fmt.Errorf("旧密钥和新密钥都不能为空")
+	}
+	if oldKey == newKey {
+		return 0, fmt.Errorf("新密钥不能与旧密钥相同")
+	}
+
+	now := time.Now()
+	count := 0
+	for i := range mm.config.Mirrors {
+		mirror := &mm.config.Mirrors[i]
+		if mirror.Deleted || mirror.APIKey != oldKey {
+			continue
+		}
+		mirror.APIKey = newKey
+		mirror.LastModified = now
+		count++
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+	return count, mm.saveConfig()
+}
+
 // AddMirrorWithType 添加指定类型的镜像源.
 func (mm *MirrorManager) AddMirrorWithType(name, baseURL, apiKey string, toolType ToolType) error {
 	return mm.AddMirrorWithExtra(name, baseURL, apiKey, toolType, "", nil)
@@ -167,11 +273,24 @@ func (mm *MirrorManager) AddMirrorWithModel(name, baseURL, apiKey string, toolTy
 
 // AddMirrorWithExtra 添加指定类型、模型名称和额外环境变量的镜像源.
 func (mm *MirrorManager) AddMirrorWithExtra(name, baseURL, apiKey string, toolType ToolType, modelName string, extraEnv map[string]string) error {
+	if err := mm.addMirror(name, baseURL, apiKey, toolType, modelName, extraEnv); err != nil {
+		return err
+	}
+	return mm.saveConfig()
+}
+
+// addMirror 在内存中添加镜像源（不保存配置文件）.
+func (mm *MirrorManager) addMirror(name, baseURL, apiKey string, toolType ToolType, modelName string, extraEnv map[string]string) error {
+	baseURL, err := mm.normalizeURL(baseURL)
+	if err != nil {
+		return fmt.Errorf("无效的 API 地址: %v", err)
+	}
+
 	// 检查镜像源是否已存在（只检查未删除的）
 	for i := range mm.config.Mirrors {
 		mirror := &mm.config.Mirrors[i]
 		if mirror.Name == name && !mirror.Deleted {
-			return fmt.Errorf("镜像源 '%s' 已存在", name)
+			return &mirrorError{msg: fmt.Sprintf("镜像源 '%s' 已存在", name), err: ErrMirrorExists}
 		}
 		// 如果找到已删除的同名镜像源，恢复它
 		if mirror.Name == name && mirror.Deleted {
@@ -182,7 +301,10 @@ func (mm *MirrorManager) AddMirrorWithExtra(name, baseURL, apiKey string, toolTy
 			mirror.ExtraEnv = extraEnv
 			mirror.Deleted = false
 			mirror.DeletedAt = time.Time{}
-			mirror.LastModified = time.Now()
+			// 恢复已删除的镜像源视为重新添加
+			now := time.Now()
+			mirror.CreatedAt = now
+			mirror.LastModified = now
 
 			// 根据工具类型设置环境变量key
 			switch toolType {
@@ -199,7 +321,7 @@ func (mm *MirrorManager) AddMirrorWithExtra(name, baseURL, apiKey string, toolTy
 				mm.config.CurrentClaude = name
 			}
 
-			return mm.saveConfig()
+			return nil
 		}
 	}
 
@@ -235,34 +357,55 @@ func (mm *MirrorManager) AddMirrorWithExtra(name, baseURL, apiKey string, toolTy
 		mm.config.CurrentClaude = name
 	}
 
-	return mm.saveConfig()
+	return nil
 }
 
-// RemoveMirror 删除镜像源.
+// RemoveMirror 删除镜像源，并清除其残留在 shell 配置（Windows 为注册表）中的环境变量.
+// 启用 no_env 时不修改环境变量；清除失败只输出警告，不影响删除结果.
 func (mm *MirrorManager) RemoveMirror(name string) error {
-	return mm.RemoveMirrorWithOptions(name, false)
+	envKeys, err := mm.RemoveMirrorWithOptions(name, false)
+	if err != nil {
+		return err
+	}
+	if mm.config.NoEnv {
+		return nil
+	}
+
+	envManager := NewEnvManager()
+	for _, envKey := range envKeys {
+		if err := envManager.UnsetEnvVar(envKey); err != nil {
+			LogWarnf("警告: 清除环境变量 %s 失败: %v\n", envKey, err)
+		}
+	}
+	return nil
 }
 
-// RemoveMirrorWithOptions 删除镜像源（带选项）.
-func (mm *MirrorManager) RemoveMirrorWithOptions(name string, permanent bool) error {
-	if name == DefaultMirrorName {
-		return fmt.Errorf("不能删除官方镜像源")
+// RemoveMirrorWithOptions 删除镜像源（带选项），不修改环境变量.
+// 返回应清除的环境变量名：删除的是当前镜像源时为其写入的全部变量，否则为不再被其他镜像源使用的变量.
+func (mm *MirrorManager) RemoveMirrorWithOptions(name string, permanent bool) ([]string, error) {
+	if mm.IsOfficialMirror(name) {
+		return nil, ErrCannotRemoveOfficial
 	}
+	official := mm.config.OfficialMirror()
 
 	now := time.Now()
 
 	for i := range mm.config.Mirrors {
 		mirror := &mm.config.Mirrors[i]
-		if mirror.Name != name {
+		// 已软删除的镜像源视为不存在，只有永久删除时才处理
+		if mirror.Name != name || (mirror.Deleted && !permanent) {
 			continue
 		}
 
+		wasCurrent := mm.config.CurrentMirror == name || mm.config.CurrentCodex == name || mm.config.CurrentClaude == name
+		envKeys := persistedEnvKeys(mirror)
+
 		// 如果删除的是当前使用的镜像源，切换到官方镜像源
 		if mm.config.CurrentMirror == name {
-			mm.config.CurrentMirror = DefaultMirrorName
+			mm.config.CurrentMirror = official
 		}
 		if mm.config.CurrentCodex == name {
-			mm.config.CurrentCodex = DefaultMirrorName
+			mm.config.CurrentCodex = official
 		}
 		if mm.config.CurrentClaude == name {
 			mm.config.CurrentClaude = ""
@@ -278,10 +421,54 @@ func (mm *MirrorManager) RemoveMirrorWithOptions(name string, permanent bool) er
 			mirror.LastModified = now
 		}
 
-		return mm.saveConfig()
+		if err := mm.saveConfig(); err != nil {
+			return nil, err
+		}
+
+		// 当前镜像源导出的值就是被删除镜像的密钥，即使变量名与其他镜像源共用也要清除
+		if wasCurrent {
+			return envKeys, nil
+		}
+		return mm.orphanedEnvKeys(envKeys), nil
 	}
 
-	return fmt.Errorf("镜像源 '%s' 不存在", name)
+	return nil, mirrorNotFoundError("镜像源 '%s' 不存在", name)
+}
+
+// persistedEnvKeys 返回切换到该镜像源时持久化的环境变量名.
+func persistedEnvKeys(mirror *MirrorConfig) []string {
+	switch mirror.ToolType {
+	case ToolTypeClaude:
+		return []string{AnthropicBaseURLEnv, AnthropicAuthTokenEnv, AnthropicModelEnv}
+	case ToolTypeCodex:
+		if envKey := strings.TrimSpace(mirror.EnvKey); envKey != "" {
+			return []string{envKey}
+		}
+		return []string{CodexSwitchAPIKeyEnv}
+	}
+	return nil
+}
+
+// orphanedEnvKeys 返回已没有任何活跃镜像源使用的环境变量名，避免已删除镜像的密钥残留在 shell 配置中.
+func (mm *MirrorManager) orphanedEnvKeys(envKeys []string) []string {
+	inUse := make(map[string]bool)
+	for i := range mm.config.Mirrors {
+		mirror := &mm.config.Mirrors[i]
+		if mirror.Deleted {
+			continue
+		}
+		for _, envKey := range persistedEnvKeys(mirror) {
+			inUse[envKey] = true
+		}
+	}
+
+	var orphaned []string
+	for _, envKey := range envKeys {
+		if !inUse[envKey] {
+			orphaned = append(orphaned, envKey)
+		}
+	}
+	return orphaned
 }
 
 // ListMirrors 列出所有镜像源.
@@ -301,6 +488,154 @@ func (mm *MirrorManager) ListActiveMirrors() []MirrorConfig {
 	return activeMirrors
 }
 
+// SearchMirrors 按名称、API 地址或模型名称搜索活跃镜像源（不区分大小写的子串匹配）.
+// 查询为空时返回全部活跃镜像源.
+func (mm *MirrorManager) SearchMirrors(query string) []MirrorConfig {
+	mirrors := mm.ListActiveMirrors()
+	if strings.TrimSpace(query) == "" {
+		return mirrors
+	}
+
+	var matched []MirrorConfig
+	for i := range mirrors {
+		if MirrorMatches(&mirrors[i], query) {
+			matched = append(matched, mirrors[i])
+		}
+	}
+	return matched
+}
+
+// DuplicateURLGroup 使用相同 API 地址的同类型镜像源分组.
+type DuplicateURLGroup struct {
+	ToolType ToolType `json:"tool_type"`
+	BaseURL  string   `json:"base_url"`
+	Names    []string `json:"names"`
+}
+
+// sameBaseURL 判断两个 API 地址是否相同（忽略大小写和末尾的斜杠）.
+func sameBaseURL(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, "/"), strings.TrimRight(b, "/"))
+}
+
+// MirrorsSharingBaseURL 返回与指定镜像源类型相同且 API 地址相同的其他活跃镜像源名称.
+func (mm *MirrorManager) MirrorsSharingBaseURL(name string) []string {
+	target, err := mm.GetMirrorByName(name)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, mirror := range mm.ListActiveMirrors() {
+		if mirror.Name != target.Name && mirror.ToolType == target.ToolType && sameBaseURL(mirror.BaseURL, target.BaseURL) {
+			names = append(names, mirror.Name)
+		}
+	}
+	return names
+}
+
+// FindDuplicateBaseURLs 返回所有包含多个同类型镜像源的 API 地址分组.
+// 有些场景会有意复用同一地址，因此调用方应将结果视为警告而非错误.
+func (mm *MirrorManager) FindDuplicateBaseURLs() []DuplicateURLGroup {
+	var groups []DuplicateURLGroup
+	for _, mirror := range mm.ListActiveMirrors() {
+		found := false
+		for i := range groups {
+			if groups[i].ToolType == mirror.ToolType && sameBaseURL(groups[i].BaseURL, mirror.BaseURL) {
+				groups[i].Names = append(groups[i].Names, mirror.Name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, DuplicateURLGroup{ToolType: mirror.ToolType, BaseURL: mirror.BaseURL, Names: []string{mirror.Name}})
+		}
+	}
+
+	duplicates := make([]DuplicateURLGroup, 0)
+	for _, group := range groups {
+		if len(group.Names) > 1 {
+			duplicates = append(duplicates, group)
+		}
+	}
+	return duplicates
+}
+
+// MirrorMatches 判断镜像源的名称、API 地址或模型名称是否包含查询字符串（不区分大小写），空查询匹配所有镜像源.
+func MirrorMatches(mirror *MirrorConfig, query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(mirror.Name), query) ||
+		strings.Contains(strings.ToLower(mirror.BaseURL), query) ||
+		strings.Contains(strings.ToLower(mirror.ModelName), query)
+}
+
+// ListByTag 列出带有指定标签的活跃镜像源，标签为空时返回全部活跃镜像源.
+func (mm *MirrorManager) ListByTag(tag string) []MirrorConfig {
+	mirrors := mm.ListActiveMirrors()
+	if tag == "" {
+		return mirrors
+	}
+
+	var tagged []MirrorConfig
+	for i := range mirrors {
+		if slices.Contains(mirrors[i].Tags, tag) {
+			tagged = append(tagged, mirrors[i])
+		}
+	}
+	return tagged
+}
+
+// ListTags 列出活跃镜像源中使用过的所有标签（已排序）.
+func (mm *MirrorManager) ListTags() []string {
+	var tags []string
+	for _, mirror := range mm.ListActiveMirrors() {
+		tags = append(tags, mirror.Tags...)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// MoveMirror 将活跃镜像源移动到活跃列表中的 toIndex 位置（从 0 开始）.
+// 只在活跃镜像源所占的位置之间重排，已删除镜像源的位置保持不变.
+func (mm *MirrorManager) MoveMirror(name string, toIndex int) error {
+	var positions []int
+	from := -1
+	for i := range mm.config.Mirrors {
+		if mm.config.Mirrors[i].Deleted {
+			continue
+		}
+		if mm.config.Mirrors[i].Name == name {
+			from = len(positions)
+		}
+		positions = append(positions, i)
+	}
+
+	if from < 0 {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+	if toIndex < 0 || toIndex >= len(positions) {
+		return fmt.Errorf("目标位置 %d 超出范围 (1-%d)", toIndex+1, len(positions))
+	}
+	if from == toIndex {
+		return nil
+	}
+
+	active := make([]MirrorConfig, len(positions))
+	for i, pos := range positions {
+		active[i] = mm.config.Mirrors[pos]
+	}
+	moved := active[from]
+	active = slices.Delete(active, from, from+1)
+	active = slices.Insert(active, toIndex, moved)
+	for i, pos := range positions {
+		mm.config.Mirrors[pos] = active[i]
+	}
+
+	return mm.saveConfig()
+}
+
 // ListDeletedMirrors 列出已删除的镜像源.
 func (mm *MirrorManager) ListDeletedMirrors() []MirrorConfig {
 	var deletedMirrors []MirrorConfig
@@ -328,47 +663,79 @@ func (mm *MirrorManager) PurgeDeletedMirrors() error {
 }
 
 // GetCurrentMirror 获取当前镜像源.
+// 返回的是配置的独立副本，修改它不会影响已保存的配置；需要修改时使用 WithMirror.
 func (mm *MirrorManager) GetCurrentMirror() (*MirrorConfig, error) {
 	for i := range mm.config.Mirrors {
 		mirror := &mm.config.Mirrors[i]
 		if mirror.Name == mm.config.CurrentMirror {
-			return mirror, nil
+			return cloneMirror(mirror), nil
 		}
 	}
-	return nil, fmt.Errorf("当前镜像源 '%s' 不存在", mm.config.CurrentMirror)
+	return nil, mirrorNotFoundError("当前镜像源 '%s' 不存在", mm.config.CurrentMirror)
 }
 
-// GetCurrentCodexMirror 获取当前激活的 Codex 镜像源.
+// GetCurrentCodexMirror 获取当前激活的 Codex 镜像源的副本.
 func (mm *MirrorManager) GetCurrentCodexMirror() (*MirrorConfig, error) {
 	for i := range mm.config.Mirrors {
 		mirror := &mm.config.Mirrors[i]
 		if mirror.Name == mm.config.CurrentCodex && mirror.ToolType == ToolTypeCodex {
-			return mirror, nil
+			return cloneMirror(mirror), nil
 		}
 	}
-	return nil, fmt.Errorf("当前 Codex 镜像源 '%s' 不存在", mm.config.CurrentCodex)
+	return nil, mirrorNotFoundError("当前 Codex 镜像源 '%s' 不存在", mm.config.CurrentCodex)
 }
 
-// GetCurrentClaudeMirror 获取当前激活的 Claude 镜像源.
+// GetCurrentClaudeMirror 获取当前激活的 Claude 镜像源的副本.
 func (mm *MirrorManager) GetCurrentClaudeMirror() (*MirrorConfig, error) {
 	for i := range mm.config.Mirrors {
 		mirror := &mm.config.Mirrors[i]
 		if mirror.Name == mm.config.CurrentClaude && mirror.ToolType == ToolTypeClaude {
-			return mirror, nil
+			return cloneMirror(mirror), nil
 		}
 	}
-	return nil, fmt.Errorf("当前 Claude 镜像源 '%s' 不存在", mm.config.CurrentClaude)
+	return nil, mirrorNotFoundError("当前 Claude 镜像源 '%s' 不存在", mm.config.CurrentClaude)
 }
 
-// GetMirrorByName 根据名称获取镜像源配置.
+// GetMirrorByName 根据名称获取镜像源配置的副本.
 func (mm *MirrorManager) GetMirrorByName(name string) (*MirrorConfig, error) {
 	for i := range mm.config.Mirrors {
 		mirror := &mm.config.Mirrors[i]
 		if mirror.Name == name {
-			return mirror, nil
+			return cloneMirror(mirror), nil
 		}
 	}
-	return nil, fmt.Errorf("镜像源 '%s' 不存在", name)
+	return nil, mirrorNotFoundError("镜像源 '%s' 不存在", name)
+}
+
+// WithMirror 对指定的活跃镜像源执行修改并保存配置.
+// edit 作用于副本，返回错误时配置保持不变；镜像源名称不允许在 edit 中修改.
+func (mm *MirrorManager) WithMirror(name string, edit func(*MirrorConfig) error) error {
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	edited := cloneMirror(mirror)
+	if err := edit(edited); err != nil {
+		return err
+	}
+	if edited.Name != mirror.Name {
+		return fmt.Errorf("不能在修改镜像源时更改名称 '%s'", name)
+	}
+
+	edited.LastModified = time.Now()
+	*mirror = *edited
+	return mm.saveConfig()
+}
+
+// cloneMirror 深拷贝镜像源配置，副本与原配置不共享 map 和切片.
+func cloneMirror(mirror *MirrorConfig) *MirrorConfig {
+	clone := *mirror
+	clone.ExtraEnv = maps.Clone(mirror.ExtraEnv)
+	clone.TestHeaders = maps.Clone(mirror.TestHeaders)
+	clone.Tags = slices.Clone(mirror.Tags)
+	clone.Models = slices.Clone(mirror.Models)
+	return &clone
 }
 
 // SwitchMirror 切换镜像源.
@@ -391,7 +758,29 @@ func (mm *MirrorManager) SwitchMirror(name string) error {
 		}
 	}
 
-	return fmt.Errorf("镜像源 '%s' 不存在", name)
+	return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+}
+
+// ClearCurrentMirror 清除指定工具类型的当前镜像源设置（用于修复指向不存在镜像源的配置）.
+func (mm *MirrorManager) ClearCurrentMirror(toolType ToolType) error {
+	var current *string
+	switch toolType {
+	case ToolTypeCodex:
+		current = &mm.config.CurrentCodex
+	case ToolTypeClaude:
+		current = &mm.config.CurrentClaude
+	default:
+		return fmt.Errorf("无效的工具类型 '%s'，支持: codex, claude", toolType)
+	}
+
+	if *current == "" {
+		return nil
+	}
+	if mm.config.CurrentMirror == *current {
+		mm.config.CurrentMirror = ""
+	}
+	*current = ""
+	return mm.saveConfig()
 }
 
 // UpdateMirror 更新镜像源.
@@ -405,17 +794,22 @@ func (mm *MirrorManager) UpdateMirrorFull(name, baseURL, apiKey, modelName, tool
 		mirror := &mm.config.Mirrors[i]
 		if mirror.Name == name {
 			if mirror.Deleted {
-				return fmt.Errorf("镜像源 '%s' 已被删除", name)
+				return mirrorNotFoundError("镜像源 '%s' 已被删除", name)
 			}
 
 			updated := false
 
 			if baseURL != "" {
-				mirror.BaseURL = baseURL
+				normalized, err := mm.normalizeURL(baseURL)
+				if err != nil {
+					return fmt.Errorf("无效的 API 地址: %v", err)
+				}
+				mirror.BaseURL = normalized
 				updated = true
 			}
 			if apiKey != "" {
 				mirror.APIKey = apiKey
+				mirror.APIKeyCommand = ""
 				updated = true
 			}
 			if modelName != "" {
@@ -445,7 +839,211 @@ func (mm *MirrorManager) UpdateMirrorFull(name, baseURL, apiKey, modelName, tool
 		}
 	}
 
-	return fmt.Errorf("镜像源 '%s' 不存在", name)
+	return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+}
+
+// UpdateMirrorExtraEnv 替换镜像源的额外环境变量.
+func (mm *MirrorManager) UpdateMirrorExtraEnv(name string, extraEnv map[string]string) error {
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	mirror.ExtraEnv = extraEnv
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
+
+// SetMirrorProxy 设置镜像源的 HTTP 代理，空字符串表示清除代理.
+func (mm *MirrorManager) SetMirrorProxy(name, proxy string) error {
+	proxy = strings.TrimSpace(proxy)
+	if proxy != "" {
+		if err := ValidateProxyURL(proxy); err != nil {
+			return fmt.Errorf("无效的代理地址: %v", err)
+		}
+	}
+
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	mirror.Proxy = proxy
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
+
+// SetMirrorResponseStorage 设置 Codex 镜像源的 disable_response_storage，nil 表示恢复默认（禁用响应存储）.
+func (mm *MirrorManager) SetMirrorResponseStorage(name string, disable *bool) error {
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+	if mirror.ToolType != ToolTypeCodex {
+		return fmt.Errorf("disable_response_storage 仅适用于 Codex 镜像源")
+	}
+
+	mirror.DisableResponseStorage = disable
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
+
+// SetMirrorTierModel 设置 Claude 镜像源指定级别使用的模型，空字符串表示清除.
+func (mm *MirrorManager) SetMirrorTierModel(name string, tier ModelTier, model string) error {
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	field := tierModelField(mirror, tier)
+	if field == nil {
+		return fmt.Errorf("无效的模型级别 '%s'", tier)
+	}
+	model = strings.TrimSpace(model)
+	if model != "" && mirror.ToolType != ToolTypeClaude {
+		return fmt.Errorf("只有 Claude 镜像源支持按级别设置模型")
+	}
+
+	*field = model
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
+
+// TierModel 返回镜像源指定级别使用的模型，未设置时返回空字符串.
+func TierModel(mirror *MirrorConfig, tier ModelTier) string {
+	if field := tierModelField(mirror, tier); field != nil {
+		return *field
+	}
+	return ""
+}
+
+// tierModelField 返回镜像源中对应级别模型字段的指针，级别无效时返回 nil.
+func tierModelField(mirror *MirrorConfig, tier ModelTier) *string {
+	switch tier {
+	case ModelTierHaiku:
+		return &mirror.HaikuModel
+	case ModelTierSonnet:
+		return &mirror.SonnetModel
+	case ModelTierOpus:
+		return &mirror.OpusModel
+	}
+	return nil
+}
+
+// SetMirrorTags 替换镜像源的标签，传入空列表表示清除.
+func (mm *MirrorManager) SetMirrorTags(name string, tags []string) error {
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	mirror.Tags = NormalizeTags(tags)
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
+
+// NormalizeTags 去除标签两端空白、空标签和重复标签，保持原有顺序.
+func NormalizeTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// SetMirrorHealthPath 设置镜像源连通性测试使用的路径，空字符串表示恢复默认探测端点.
+func (mm *MirrorManager) SetMirrorHealthPath(name, healthPath string) error {
+	healthPath, err := NormalizeHealthPath(healthPath)
+	if err != nil {
+		return err
+	}
+
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	mirror.HealthPath = healthPath
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
+
+// SetMirrorTimeout 设置镜像源连通性测试的超时时间（秒），0 表示使用默认超时.
+func (mm *MirrorManager) SetMirrorTimeout(name string, seconds int) error {
+	if seconds < 0 {
+		return fmt.Errorf("测试超时时间不能为负数")
+	}
+
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	mirror.TimeoutSeconds = seconds
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
+
+// NormalizeHealthPath 校验测试路径并补全开头的斜杠.
+func NormalizeHealthPath(healthPath string) (string, error) {
+	healthPath = strings.TrimSpace(healthPath)
+	if healthPath == "" {
+		return "", nil
+	}
+	if strings.Contains(healthPath, "://") {
+		return "", fmt.Errorf("测试路径应为相对于 API 地址的路径，当前: %s", healthPath)
+	}
+	if !strings.HasPrefix(healthPath, "/") {
+		healthPath = "/" + healthPath
+	}
+	return healthPath, nil
+}
+
+// SetMirrorTestHeaders 替换镜像源连通性测试时附加的请求头，传入空 map 表示清除.
+func (mm *MirrorManager) SetMirrorTestHeaders(name string, headers map[string]string) error {
+	for key := range headers {
+		if err := ValidateHeaderName(key); err != nil {
+			return err
+		}
+	}
+
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	if len(headers) == 0 {
+		headers = nil
+	}
+	mirror.TestHeaders = headers
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
+
+// ValidateHeaderName 验证 HTTP 请求头名称，只允许 RFC 7230 中的 token 字符.
+func ValidateHeaderName(name string) error {
+	if name == "" {
+		return fmt.Errorf("请求头名称不能为空")
+	}
+	for _, c := range name {
+		if !isHeaderTokenChar(c) {
+			return fmt.Errorf("请求头名称 '%s' 包含无效字符 %q", name, c)
+		}
+	}
+	return nil
+}
+
+// isHeaderTokenChar 判断字符是否为合法的 HTTP token 字符.
+func isHeaderTokenChar(c rune) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	default:
+		return strings.ContainsRune("!#$%&'*+-.^_`|~", c)
+	}
 }
 
 // FixEnvKeyFormat 修复所有镜像源的env_key格式.
@@ -486,6 +1084,7 @@ func (mm *MirrorManager) FixEnvKeyFormat() error {
 func (mm *MirrorManager) discoverFromEnvironment() {
 	// 首先初始化默认配置作为基础
 	mm.initDefaultConfig()
+	official := mm.config.OfficialMirror()
 
 	discoveredMirrors := make(map[string]MirrorConfig)
 
@@ -517,7 +1116,7 @@ func (mm *MirrorManager) discoverFromEnvironment() {
 			// 设置当前激活的配置
 			switch mirror.ToolType {
 			case ToolTypeCodex:
-				if mm.config.CurrentCodex == DefaultMirrorName {
+				if mm.config.CurrentCodex == official {
 					mm.config.CurrentCodex = name
 				}
 			case ToolTypeClaude:
@@ -527,7 +1126,7 @@ func (mm *MirrorManager) discoverFromEnvironment() {
 			}
 
 			// 设置通用当前镜像源（兼容旧版本）
-			if mm.config.CurrentMirror == DefaultMirrorName && len(mm.config.Mirrors) > 1 {
+			if mm.config.CurrentMirror == official && len(mm.config.Mirrors) > 1 {
 				mm.config.CurrentMirror = name
 			}
 		}
@@ -543,65 +1142,11 @@ func (mm *MirrorManager) discoverFromEnvironment() {
 
 // discoverCodexFromConfig 从 ~/.codex/config.toml 文件中发现 Codex 配置.
 func (mm *MirrorManager) discoverCodexFromConfig(discoveredMirrors map[string]MirrorConfig) {
-	// 获取 Codex 配置文件路径
-	configPath, err := GetCodexConfigPath()
-	if err != nil {
-		return // 无法获取路径，跳过
-	}
-
-	// 检查配置文件是否存在
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return // 配置文件不存在，跳过
-	}
-
-	// 读取配置文件
-	var config CodexConfig
-	if _, err := toml.DecodeFile(configPath, &config); err != nil {
-		return // 解析失败，跳过
-	}
-
-	// 获取认证文件中的 API 密钥
-	authPath, err := GetCodexAuthPath()
+	providers, err := mm.readCodexProviders()
 	if err != nil {
-		return // 无法获取认证路径，跳过
-	}
-
-	var auth CodexAuth
-	if _, err := os.Stat(authPath); err == nil {
-		// 认证文件存在，读取 API 密钥
-		if file, err := os.Open(authPath); err == nil {
-			defer func() {
-				if closeErr := file.Close(); closeErr != nil {
-					fmt.Printf("关闭文件失败: %v\n", closeErr)
-				}
-			}()
-			_ = json.NewDecoder(file).Decode(&auth)
-		}
-	}
-
-	// 遍历所有模型提供商配置
-	if config.ModelProviders != nil {
-		for name, provider := range config.ModelProviders {
-			// 跳过空配置
-			if provider.BaseURL == "" {
-				continue
-			}
-
-			// 智能获取 API 密钥
-			apiKey := mm.getApiKeyForProvider(auth)
-
-			// 创建镜像源配置
-			mirror := MirrorConfig{
-				Name:     name,
-				BaseURL:  provider.BaseURL,
-				APIKey:   apiKey,
-				EnvKey:   provider.EnvKey,
-				ToolType: ToolTypeCodex,
-			}
-
-			discoveredMirrors[name] = mirror
-		}
+		return // 配置文件不存在或解析失败，跳过
 	}
+	maps.Copy(discoveredMirrors, providers)
 }
 
 // getApiKeyForProvider 智能获取提供商的 API 密钥.
@@ -656,6 +1201,9 @@ func (mm *MirrorManager) discoverClaudeFromEnv(discoveredMirrors map[string]Mirr
 	}
 }
 
+// DisableCodexEnvDiscoveryEnv 设为 true 或 1 时，首次运行不从 CODEX_<NAME>_API_KEY 环境变量发现镜像源.
+const DisableCodexEnvDiscoveryEnv = "CODEX_MIRROR_DISABLE_ENV_DISCOVERY"
+
 // discoverCodexFromEnv 从环境变量中发现 Codex 配置（作为补充）.
 func (mm *MirrorManager) discoverCodexFromEnv(discoveredMirrors map[string]MirrorConfig) {
 	// 扫描所有环境变量，寻找可能相关的API密钥
@@ -682,14 +1230,17 @@ func (mm *MirrorManager) discoverCodexFromEnv(discoveredMirrors map[string]Mirro
 			mirrorName = "openai"
 			baseURL = "https://api.openai.com"
 			toolType = ToolTypeCodex
-		case strings.HasPrefix(key, "CODEX_") && strings.HasSuffix(key, "_API_KEY"):
-			// 提取镜像源名称: CODEX_<NAME>_API_KEY -> <name>
+		case strings.HasPrefix(key, "CODEX_") && strings.HasSuffix(key, "_API_KEY") && !codexEnvDiscoveryDisabled():
+			// 提取镜像源名称: CODEX_<NAME>_API_KEY -> <name>，需要同时设置有效的 CODEX_<NAME>_BASE_URL，
+			// 避免无关的环境变量生成无法使用的镜像源
 			namePart := strings.TrimPrefix(key, "CODEX_")
 			namePart = strings.TrimSuffix(namePart, "_API_KEY")
 			if namePart != "" {
-				mirrorName = strings.ToLower(namePart)
-				baseURL = "https://api.example.com" // 通用URL，用户可以后续修改
-				toolType = ToolTypeCodex
+				if envURL := strings.TrimSpace(os.Getenv("CODEX_" + namePart + "_BASE_URL")); envURL != "" && ValidateBaseURL(envURL) == nil {
+					mirrorName = strings.ToLower(namePart)
+					baseURL = envURL
+					toolType = ToolTypeCodex
+				}
 			}
 		}
 
@@ -708,6 +1259,12 @@ func (mm *MirrorManager) discoverCodexFromEnv(discoveredMirrors map[string]Mirro
 	}
 }
 
+// codexEnvDiscoveryDisabled 判断是否通过 DisableCodexEnvDiscoveryEnv 关闭了 CODEX_*_API_KEY 的发现.
+func codexEnvDiscoveryDisabled() bool {
+	disabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(DisableCodexEnvDiscoveryEnv)))
+	return err == nil && disabled
+}
+
 // SanitizeEnvVarName 将镜像源名称转换为合法的环境变量名称部分.
 func SanitizeEnvVarName(name string) string {
 	// 将连字符和空格替换为下划线，保留原有下划线，移除其他特殊字符
@@ -746,12 +1303,12 @@ func ValidateBaseURL(baseURL string) error {
 	// 解析 URL
 	u, err := url.Parse(baseURL)
 	if err != nil {
-		return fmt.Errorf("URL 格式无效: %v", err)
+		return fmt.Errorf("URL 格式无效: %v", urlParseError(err))
 	}
 
 	// 检查 scheme
 	if u.Scheme == "" {
-		return fmt.Errorf("URL 缺少协议 (http:// 或 https://)，当前: %s", baseURL)
+		return fmt.Errorf("URL 缺少协议 (http:// 或 https://)，当前: %s", RedactURL(baseURL))
 	}
 	if u.Scheme != "http" && u.Scheme != "https" {
 		return fmt.Errorf("URL 协议必须是 http 或 https，当前: %s", u.Scheme)
@@ -771,6 +1328,37 @@ func ValidateBaseURL(baseURL string) error {
 	return nil
 }
 
+// ValidateProxyURL 验证代理地址格式，支持 http、https 和 socks5 协议.
+func ValidateProxyURL(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return fmt.Errorf("代理地址格式无效: %v", urlParseError(err))
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	case "":
+		return fmt.Errorf("代理地址缺少协议 (如 http://)，当前: %s", RedactURL(proxy))
+	default:
+		return fmt.Errorf("代理协议必须是 http、https 或 socks5，当前: %s", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("代理地址缺少主机名")
+	}
+
+	return nil
+}
+
+// NormalizeBaseURL 校验 API 基础 URL 并去除末尾的斜杠.
+func NormalizeBaseURL(baseURL string) (string, error) {
+	baseURL = strings.TrimSpace(baseURL)
+	if err := ValidateBaseURL(baseURL); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(baseURL, "/"), nil
+}
+
 // extractMirrorNameFromURL 从 URL 中提取镜像源名称.
 func extractMirrorNameFromURL(urlStr, defaultName string) string {
 	// 解析 URL
@@ -0,0 +1,340 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// 导出格式.
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+// mirrorExportEntry 导出文件中的单个镜像源条目，字段与导入格式一致.
+// 包含镜像源的全部用户设置，不包含创建/修改时间、删除标记和模型缓存等内部状态.
+type mirrorExportEntry struct {
+	Name         string            `json:"name"`
+	BaseURL      string            `json:"base_url"`
+	APIKey       string            `json:"api_key,omitempty"`
+	ToolType     ToolType          `json:"tool_type"`
+	ModelName    string            `json:"model_name,omitempty"`
+	HaikuModel   string            `json:"haiku_model,omitempty"`
+	SonnetModel  string            `json:"sonnet_model,omitempty"`
+	OpusModel    string            `json:"opus_model,omitempty"`
+	Proxy        string            `json:"proxy,omitempty"`
+	HealthPath   string            `json:"health_path,omitempty"`
+	ProviderKind ProviderKind      `json:"provider_kind,omitempty"`
+	APIVersion   string            `json:"api_version,omitempty"`
+	ExtraEnv     map[string]string `json:"extra_env,omitempty"`
+	TestHeaders  map[string]string `json:"test_headers,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	// 连通性测试的超时时间（秒）
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// 获取 API 密钥的命令和 Codex 响应存储设置
+	APIKeyCommand          string `json:"api_key_command,omitempty"`
+	DisableResponseStorage *bool  `json:"disable_response_storage,omitempty"`
+}
+
+// ImportMirrors 从 JSON 数组批量导入镜像源.
+// 已存在的同名镜像源默认跳过，overwrite 为 true 时用导入内容覆盖（计入 added）。
+// 默认导出中掩码的 API 密钥（如 sk-a****5678）视为未提供，覆盖时保留原有的密钥和密钥命令；
+// 掩码的额外环境变量、测试请求头和隐藏密码的代理地址覆盖时沿用原值，新增时忽略。
+// 所有条目校验通过后才会修改配置，并且只在最后保存一次.
+func (mm *MirrorManager) ImportMirrors(r io.Reader, overwrite bool) (added, skipped int, err error) {
+	var entries []MirrorConfig
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return 0, 0, fmt.Errorf("解析导入文件失败: %v", err)
+	}
+
+	// 先校验全部条目，避免导入一半后失败
+	for i := range entries {
+		restoreMaskedSecrets(&entries[i], mm.findActiveMirror(entries[i].Name))
+		if err := mm.ValidateMirrorConfig(&entries[i]); err != nil {
+			return 0, 0, fmt.Errorf("第 %d 个镜像源无效: %v", i+1, err)
+		}
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		existing := mm.findActiveMirror(entry.Name)
+		if existing == nil {
+			if err := mm.addMirror(entry.Name, entry.BaseURL, entry.APIKey, entry.ToolType, entry.ModelName, entry.ExtraEnv); err != nil {
+				return added, skipped, err
+			}
+			created := mm.findActiveMirror(entry.Name)
+			created.APIKeyCommand = entry.APIKeyCommand
+			applyMirrorSettings(created, entry)
+			added++
+			continue
+		}
+
+		if !overwrite {
+			skipped++
+			continue
+		}
+
+		existing.BaseURL = entry.BaseURL
+		if entry.APIKey != "" || entry.APIKeyCommand != "" {
+			existing.APIKey = entry.APIKey
+			existing.APIKeyCommand = entry.APIKeyCommand
+		}
+		existing.ToolType = entry.ToolType
+		existing.EnvKey = envKeyForToolType(entry.ToolType)
+		applyMirrorSettings(existing, entry)
+		existing.LastModified = time.Now()
+		added++
+	}
+
+	if added > 0 {
+		if err := mm.saveConfig(); err != nil {
+			return added, skipped, err
+		}
+	}
+
+	return added, skipped, nil
+}
+
+// restoreMaskedSecrets 处理默认导出中被掩码的值：掩码值不是真实值，有同名镜像源时沿用其原值，否则视为未提供.
+// API 密钥由导入流程单独处理（覆盖时保留原有的密钥和密钥命令）.
+func restoreMaskedSecrets(entry, existing *MirrorConfig) {
+	if IsMaskedAPIKey(entry.APIKey) {
+		entry.APIKey = ""
+	}
+
+	var existingEnv, existingHeaders map[string]string
+	existingProxy := ""
+	if existing != nil {
+		existingEnv, existingHeaders, existingProxy = existing.ExtraEnv, existing.TestHeaders, existing.Proxy
+	}
+	if IsRedactedURL(entry.Proxy) {
+		entry.Proxy = existingProxy
+	}
+	restoreMaskedValues(entry.ExtraEnv, existingEnv)
+	restoreMaskedValues(entry.TestHeaders, existingHeaders)
+}
+
+// restoreMaskedValues 将 values 中的掩码值替换为 existing 中的原值，没有原值时删除该项.
+func restoreMaskedValues(values, existing map[string]string) {
+	for key, value := range values {
+		if !IsMaskedAPIKey(value) {
+			continue
+		}
+		if original, ok := existing[key]; ok {
+			values[key] = original
+		} else {
+			delete(values, key)
+		}
+	}
+}
+
+// maskSecretValues 返回敏感键（见 IsSecretEnvKey）的值被掩码后的副本.
+func maskSecretValues(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	masked := make(map[string]string, len(values))
+	for key, value := range values {
+		if IsSecretEnvKey(key) {
+			value = MaskAPIKey(value)
+		}
+		masked[key] = value
+	}
+	return masked
+}
+
+// AddMirrorConfig 校验并添加包含全部设置的镜像源，只保存一次配置文件，校验失败时不修改配置.
+// 同名镜像源已存在时返回 ErrMirrorExists.
+func (mm *MirrorManager) AddMirrorConfig(mirror MirrorConfig) error {
+	if mm.findActiveMirror(mirror.Name) != nil {
+		return &mirrorError{msg: fmt.Sprintf("镜像源 '%s' 已存在", mirror.Name), err: ErrMirrorExists}
+	}
+	if err := mm.ValidateMirrorConfig(&mirror); err != nil {
+		return err
+	}
+	if err := mm.addMirror(mirror.Name, mirror.BaseURL, mirror.APIKey, mirror.ToolType, mirror.ModelName, mirror.ExtraEnv); err != nil {
+		return err
+	}
+	created := mm.findActiveMirror(mirror.Name)
+	created.APIKeyCommand = mirror.APIKeyCommand
+	applyMirrorSettings(created, &mirror)
+	return mm.saveConfig()
+}
+
+// applyMirrorSettings 将地址、密钥和工具类型以外的设置从 src 复制到 dst.
+func applyMirrorSettings(dst, src *MirrorConfig) {
+	dst.ModelName = src.ModelName
+	dst.HaikuModel = src.HaikuModel
+	dst.SonnetModel = src.SonnetModel
+	dst.OpusModel = src.OpusModel
+	dst.Proxy = src.Proxy
+	dst.HealthPath = src.HealthPath
+	dst.ProviderKind = src.ProviderKind
+	dst.APIVersion = src.APIVersion
+	dst.TestHeaders = src.TestHeaders
+	dst.TimeoutSeconds = src.TimeoutSeconds
+	dst.DisableResponseStorage = src.DisableResponseStorage
+	dst.Tags = NormalizeTags(src.Tags)
+	dst.ExtraEnv = src.ExtraEnv
+}
+
+// ValidateMirrorConfig 校验并补全包含全部设置的镜像源配置（导入和 add 命令共用），
+// URL 按 addMirror 相同的规则规范化，并按工具类型设置环境变量名.
+func (mm *MirrorManager) ValidateMirrorConfig(entry *MirrorConfig) error {
+	if entry.Name == "" {
+		return fmt.Errorf("名称不能为空")
+	}
+	baseURL, err := mm.normalizeURL(entry.BaseURL)
+	if err != nil {
+		return fmt.Errorf("'%s' 的 URL 无效: %v", entry.Name, err)
+	}
+	entry.BaseURL = baseURL
+
+	// 同时提供密钥和命令时以命令为准
+	entry.APIKeyCommand = strings.TrimSpace(entry.APIKeyCommand)
+	if entry.APIKeyCommand != "" {
+		entry.APIKey = ""
+	}
+
+	switch entry.ToolType {
+	case "":
+		entry.ToolType = ToolTypeCodex
+	case ToolTypeCodex, ToolTypeClaude:
+	default:
+		return fmt.Errorf("'%s' 的工具类型 '%s' 无效，支持: %s, %s", entry.Name, entry.ToolType, ToolTypeCodex, ToolTypeClaude)
+	}
+	entry.EnvKey = envKeyForToolType(entry.ToolType)
+
+	if entry.ToolType != ToolTypeClaude && (entry.HaikuModel != "" || entry.SonnetModel != "" || entry.OpusModel != "") {
+		return fmt.Errorf("'%s' 不是 Claude 镜像源，不支持按级别设置模型", entry.Name)
+	}
+
+	if entry.TimeoutSeconds < 0 {
+		return fmt.Errorf("'%s' 的测试超时时间不能为负数", entry.Name)
+	}
+
+	if entry.Proxy != "" {
+		if err := ValidateProxyURL(entry.Proxy); err != nil {
+			return fmt.Errorf("'%s' 的代理地址无效: %v", entry.Name, err)
+		}
+	}
+	healthPath, err := NormalizeHealthPath(entry.HealthPath)
+	if err != nil {
+		return fmt.Errorf("'%s' 的测试路径无效: %v", entry.Name, err)
+	}
+	entry.HealthPath = healthPath
+
+	if entry.ProviderKind == ProviderKindOpenAI {
+		entry.ProviderKind = ""
+	}
+	if err := validateProviderKind(entry.ToolType, entry.ProviderKind, entry.APIVersion); err != nil {
+		return fmt.Errorf("'%s' 的提供商配置无效: %v", entry.Name, err)
+	}
+
+	for key := range entry.TestHeaders {
+		if err := ValidateHeaderName(key); err != nil {
+			return fmt.Errorf("'%s' 的测试请求头无效: %v", entry.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// findActiveMirror 查找未删除的同名镜像源.
+func (mm *MirrorManager) findActiveMirror(name string) *MirrorConfig {
+	for i := range mm.config.Mirrors {
+		mirror := &mm.config.Mirrors[i]
+		if mirror.Name == name && !mirror.Deleted {
+			return mirror
+		}
+	}
+	return nil
+}
+
+// envKeyForToolType 返回工具类型对应的环境变量名.
+func envKeyForToolType(toolType ToolType) string {
+	if toolType == ToolTypeClaude {
+		return AnthropicAuthTokenEnv
+	}
+	return CodexSwitchAPIKeyEnv
+}
+
+// ExportMirrors 将活跃镜像源导出为 JSON 或 CSV.
+// 默认用 MaskAPIKey 掩码 API Key 以及敏感的额外环境变量和测试请求头，隐藏代理地址中的密码，不导出密钥命令；
+// includeKeys 为 true 时原样输出。CSV 只在 includeKeys 为 true 时包含 api_key 列.
+func (mm *MirrorManager) ExportMirrors(w io.Writer, format string, includeKeys bool) error {
+	mirrors := mm.ListActiveMirrors()
+
+	switch format {
+	case ExportFormatJSON, "":
+		entries := make([]mirrorExportEntry, 0, len(mirrors))
+		for i := range mirrors {
+			mirror := &mirrors[i]
+			entry := mirrorExportEntry{
+				Name:                   mirror.Name,
+				BaseURL:                mirror.BaseURL,
+				APIKey:                 mirror.APIKey,
+				ToolType:               mirror.ToolType,
+				ModelName:              mirror.ModelName,
+				HaikuModel:             mirror.HaikuModel,
+				SonnetModel:            mirror.SonnetModel,
+				OpusModel:              mirror.OpusModel,
+				Proxy:                  mirror.Proxy,
+				HealthPath:             mirror.HealthPath,
+				ProviderKind:           mirror.ProviderKind,
+				APIVersion:             mirror.APIVersion,
+				ExtraEnv:               mirror.ExtraEnv,
+				TestHeaders:            mirror.TestHeaders,
+				Tags:                   mirror.Tags,
+				TimeoutSeconds:         mirror.TimeoutSeconds,
+				APIKeyCommand:          mirror.APIKeyCommand,
+				DisableResponseStorage: mirror.DisableResponseStorage,
+			}
+			if !includeKeys {
+				entry.APIKey = MaskAPIKey(mirror.APIKey)
+				entry.Proxy = RedactURL(mirror.Proxy)
+				entry.ExtraEnv = maskSecretValues(mirror.ExtraEnv)
+				entry.TestHeaders = maskSecretValues(mirror.TestHeaders)
+				entry.APIKeyCommand = ""
+			}
+			entries = append(entries, entry)
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			return fmt.Errorf("写入 JSON 失败: %v", err)
+		}
+		return nil
+	case ExportFormatCSV:
+		writer := csv.NewWriter(w)
+		header := []string{"name", "base_url", "tool_type", "model_name"}
+		if includeKeys {
+			header = append(header, "api_key")
+		}
+		if err := writer.Write(header); err != nil {
+			return fmt.Errorf("写入 CSV 失败: %v", err)
+		}
+		for i := range mirrors {
+			mirror := &mirrors[i]
+			record := []string{mirror.Name, mirror.BaseURL, string(mirror.ToolType), mirror.ModelName}
+			if includeKeys {
+				record = append(record, mirror.APIKey)
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("写入 CSV 失败: %v", err)
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("写入 CSV 失败: %v", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("不支持的导出格式 '%s'，支持: %s, %s", format, ExportFormatJSON, ExportFormatCSV)
+	}
+}
@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 )
@@ -59,6 +61,44 @@ func TestNewMirrorManager(t *testing.T) {
 	}
 }
 
+// TestNewMirrorManagerCorruptedConfig 测试配置文件损坏时返回错误并保留原文件.
+func TestNewMirrorManagerCorruptedConfig(t *testing.T) {
+	tempDir := setupTestDir(t)
+	configPath := filepath.Join(tempDir, ".codex-mirror", "mirrors.toml")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+
+	broken := "current_codex = \"work\"\n[[mirrors]]\nname = \"work\"\nbase_url = \"https://api.work.com\n"
+	if err := os.WriteFile(configPath, []byte(broken), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	mm, err := NewMirrorManagerWithPath(configPath)
+	if !errors.Is(err, ErrConfigCorrupted) {
+		t.Fatalf("NewMirrorManagerWithPath() error = %v, expected ErrConfigCorrupted", err)
+	}
+	if mm != nil {
+		t.Error("损坏的配置不应返回镜像源管理器")
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if string(data) != broken {
+		t.Errorf("损坏的配置文件被覆盖:\n%s", data)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(tempDir, ".codex-mirror", "backup", "corrupted-*.toml"))
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected one backup of the corrupted config, got %v (err %v)", backups, err)
+	}
+	if backup, _ := os.ReadFile(backups[0]); string(backup) != broken {
+		t.Errorf("备份内容与原文件不一致:\n%s", backup)
+	}
+}
+
 // TestAddMirror 测试添加镜像源.
 func TestAddMirror(t *testing.T) {
 	tempDir := setupTestDir(t)
@@ -228,6 +268,41 @@ func TestRemoveMirror(t *testing.T) {
 	}
 }
 
+// TestMirrorSentinelErrors 测试镜像源操作返回可用 errors.Is 判断的错误.
+func TestMirrorSentinelErrors(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.AddMirror("exists", TestAPIURL, "sk-test"); err != nil {
+		t.Fatalf("AddMirror() error = %v", err)
+	}
+	if err := mm.AddMirror("gone", TestAPIURL, "sk-test"); err != nil {
+		t.Fatalf("AddMirror() error = %v", err)
+	}
+	if err := mm.RemoveMirror("gone"); err != nil {
+		t.Fatalf("RemoveMirror() error = %v", err)
+	}
+
+	_, getErr := mm.GetMirrorByName("missing")
+	tests := []struct {
+		name   string
+		err    error
+		target error
+	}{
+		{"AddMirror 重名", mm.AddMirror("exists", TestAPIURL, "sk-test"), ErrMirrorExists},
+		{"RemoveMirror 不存在", mm.RemoveMirror("missing"), ErrMirrorNotFound},
+		{"RemoveMirror 已删除", mm.RemoveMirror("gone"), ErrMirrorNotFound},
+		{"RemoveMirror 官方", mm.RemoveMirror(DefaultMirrorName), ErrCannotRemoveOfficial},
+		{"SwitchMirror 不存在", mm.SwitchMirror("missing"), ErrMirrorNotFound},
+		{"GetMirrorByName 不存在", getErr, ErrMirrorNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.target) {
+				t.Errorf("error = %v, expected errors.Is(%v)", tt.err, tt.target)
+			}
+		})
+	}
+}
+
 // TestSwitchMirror 测试切换镜像源.
 func TestSwitchMirror(t *testing.T) {
 	tempDir := setupTestDir(t)
@@ -273,6 +348,159 @@ func TestSwitchMirror(t *testing.T) {
 	}
 }
 
+// TestClearCurrentMirror 测试清除当前镜像源设置.
+func TestClearCurrentMirror(t *testing.T) {
+	tests := []struct {
+		name        string
+		toolType    ToolType
+		wantCodex   string
+		wantClaude  string
+		expectError bool
+	}{
+		{name: "清除Codex", toolType: ToolTypeCodex, wantCodex: "", wantClaude: "test-claude"},
+		{name: "清除Claude", toolType: ToolTypeClaude, wantCodex: "dangling", wantClaude: ""},
+		{name: "无效类型", toolType: "x", wantCodex: "dangling", wantClaude: "test-claude", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := createTestMirrorManager(t, setupTestDir(t))
+			if err := mm.AddMirrorWithType("test-claude", TestAPIURL, "test-key", ToolTypeClaude); err != nil {
+				t.Fatalf("添加测试镜像源失败: %v", err)
+			}
+			if err := mm.SwitchMirror("test-claude"); err != nil {
+				t.Fatalf("切换镜像源失败: %v", err)
+			}
+			mm.config.CurrentCodex = "dangling"
+
+			err := mm.ClearCurrentMirror(tt.toolType)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ClearCurrentMirror() error = %v, expectError %v", err, tt.expectError)
+			}
+			if mm.config.CurrentCodex != tt.wantCodex || mm.config.CurrentClaude != tt.wantClaude {
+				t.Errorf("CurrentCodex/CurrentClaude = %q/%q, 期望 %q/%q",
+					mm.config.CurrentCodex, mm.config.CurrentClaude, tt.wantCodex, tt.wantClaude)
+			}
+		})
+	}
+}
+
+// TestOfficialMirrorOverride 测试通过环境变量和配置文件指定官方镜像源.
+func TestOfficialMirrorOverride(t *testing.T) {
+	tempDir := setupTestDir(t)
+	t.Setenv("HOME", tempDir)
+	t.Setenv("USERPROFILE", tempDir)
+
+	t.Run("首次运行使用环境变量", func(t *testing.T) {
+		t.Setenv(OfficialMirrorNameEnv, "corp")
+		t.Setenv(OfficialMirrorURLEnv, "https://llm.corp.example/")
+
+		mm, err := NewMirrorManagerWithPath(filepath.Join(tempDir, "env", "mirrors.toml"))
+		if err != nil {
+			t.Fatalf("NewMirrorManagerWithPath() error = %v", err)
+		}
+		official, err := mm.GetMirrorByName("corp")
+		if err != nil || official.BaseURL != "https://llm.corp.example" {
+			t.Fatalf("official mirror = %+v, %v", official, err)
+		}
+		if mm.OfficialMirror() != "corp" {
+			t.Errorf("OfficialMirror() = %q, expected corp", mm.OfficialMirror())
+		}
+		if _, err := mm.GetMirrorByName(DefaultMirrorName); !errors.Is(err, ErrMirrorNotFound) {
+			t.Errorf("%s should not be seeded, error = %v", DefaultMirrorName, err)
+		}
+
+		if err := mm.AddMirror("other", TestAPIURL, "sk-test"); err != nil {
+			t.Fatalf("AddMirror() error = %v", err)
+		}
+		if err := mm.SwitchMirror("other"); err != nil {
+			t.Fatalf("SwitchMirror() error = %v", err)
+		}
+		if err := mm.RemoveMirror("corp"); !errors.Is(err, ErrCannotRemoveOfficial) {
+			t.Errorf("RemoveMirror(corp) error = %v, expected ErrCannotRemoveOfficial", err)
+		}
+		if err := mm.RemoveMirror("other"); err != nil {
+			t.Fatalf("RemoveMirror(other) error = %v", err)
+		}
+		if mm.config.CurrentCodex != "corp" {
+			t.Errorf("CurrentCodex after removal = %q, expected corp", mm.config.CurrentCodex)
+		}
+	})
+
+	t.Run("配置文件指定的官方镜像源不存在时补充", func(t *testing.T) {
+		configPath := filepath.Join(tempDir, "file", "mirrors.toml")
+		if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := "official_name = \"intranet\"\nofficial_url = \"https://gw.internal\"\n"
+		if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		mm, err := NewMirrorManagerWithPath(configPath)
+		if err != nil {
+			t.Fatalf("NewMirrorManagerWithPath() error = %v", err)
+		}
+		official, err := mm.GetMirrorByName("intranet")
+		if err != nil || official.BaseURL != "https://gw.internal" || official.ToolType != ToolTypeCodex {
+			t.Fatalf("official mirror = %+v, %v", official, err)
+		}
+		if !mm.IsOfficialMirror("intranet") || mm.IsOfficialMirror(DefaultMirrorName) {
+			t.Errorf("IsOfficialMirror() should only protect intranet")
+		}
+	})
+}
+
+// TestRotateAPIKey 测试批量替换共用的 API 密钥.
+func TestRotateAPIKey(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	for name, key := range map[string]string{"a": "sk-shared", "b": "sk-shared", "c": "sk-shared-2", "gone": "sk-shared"} {
+		if err := mm.AddMirror(name, TestAPIURL, key); err != nil {
+			t.Fatalf("AddMirror(%s) error = %v", name, err)
+		}
+	}
+	if err := mm.RemoveMirror("gone"); err != nil {
+		t.Fatalf("RemoveMirror() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		oldKey    string
+		newKey    string
+		wantCount int
+		wantErr   bool
+	}{
+		{"空的旧密钥", "", "sk-new", 0, true},
+		{"空的新密钥", "sk-shared", " ", 0, true},
+		{"新旧相同", "sk-shared", "sk-shared", 0, true},
+		{"没有匹配", "sk-unknown", "sk-new", 0, false},
+		{"精确匹配替换", "sk-shared", "sk-new", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := mm.RotateAPIKey(tt.oldKey, tt.newKey)
+			if (err != nil) != tt.wantErr || count != tt.wantCount {
+				t.Errorf("RotateAPIKey() = %d, %v, expected %d, error %v", count, err, tt.wantCount, tt.wantErr)
+			}
+		})
+	}
+
+	reloaded, err := NewMirrorManagerWithPath(mm.configPath)
+	if err != nil {
+		t.Fatalf("NewMirrorManagerWithPath() error = %v", err)
+	}
+	for name, want := range map[string]string{"a": "sk-new", "b": "sk-new", "c": "sk-shared-2"} {
+		if mirror, _ := reloaded.GetMirrorByName(name); mirror == nil || mirror.APIKey != want {
+			t.Errorf("mirror %s APIKey = %+v, expected %s", name, mirror, want)
+		}
+	}
+	for i := range reloaded.config.Mirrors {
+		if mirror := reloaded.config.Mirrors[i]; mirror.Name == "gone" && mirror.APIKey != "sk-shared" {
+			t.Errorf("deleted mirror should keep its key, got %s", mirror.APIKey)
+		}
+	}
+}
+
 // TestGetCurrentMirror 测试获取当前镜像源.
 func TestGetCurrentMirror(t *testing.T) {
 	tempDir := setupTestDir(t)
@@ -365,6 +593,114 @@ func TestUpdateMirror(t *testing.T) {
 	}
 }
 
+// TestMirrorTimestamps 测试修改镜像源时更新时间戳.
+func TestMirrorTimestamps(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+
+	before := time.Now()
+	if err := mm.AddMirror("ts-mirror", TestAPIURL, "sk-test"); err != nil {
+		t.Fatalf("添加镜像源失败: %v", err)
+	}
+	mirror, _ := mm.GetMirrorByName("ts-mirror")
+	if mirror.CreatedAt.Before(before) || mirror.LastModified.Before(before) {
+		t.Fatalf("添加时应设置时间戳，CreatedAt=%v LastModified=%v", mirror.CreatedAt, mirror.LastModified)
+	}
+
+	tests := []struct {
+		name          string
+		mutate        func() error
+		expectCreated bool // 是否同时更新 CreatedAt
+	}{
+		{name: "UpdateMirror", mutate: func() error { return mm.UpdateMirror("ts-mirror", "https://api.new.com", "") }},
+		{name: "UpdateMirrorFull", mutate: func() error { return mm.UpdateMirrorFull("ts-mirror", "", "", "model-x", "") }},
+		{name: "UpdateMirrorExtraEnv", mutate: func() error {
+			return mm.UpdateMirrorExtraEnv("ts-mirror", map[string]string{"KEY": "VALUE"})
+		}},
+		{name: "ClearAPIKey", mutate: func() error { return mm.ClearAPIKey("ts-mirror") }},
+		{name: "恢复已删除的镜像源", mutate: func() error {
+			if err := mm.RemoveMirror("ts-mirror"); err != nil {
+				return err
+			}
+			return mm.AddMirror("ts-mirror", TestAPIURL, "sk-test")
+		}, expectCreated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 将时间戳回拨，便于判断是否前进
+			past := time.Now().Add(-time.Hour)
+			mirror := mm.findActiveMirror("ts-mirror")
+			mirror.CreatedAt = past
+			mirror.LastModified = past
+
+			if err := tt.mutate(); err != nil {
+				t.Fatalf("操作失败: %v", err)
+			}
+
+			mirror, _ = mm.GetMirrorByName("ts-mirror")
+			if !mirror.LastModified.After(past) {
+				t.Errorf("LastModified 未更新: %v", mirror.LastModified)
+			}
+			if mirror.CreatedAt.After(past) != tt.expectCreated {
+				t.Errorf("CreatedAt = %v, 期望更新: %v", mirror.CreatedAt, tt.expectCreated)
+			}
+		})
+	}
+}
+
+// TestWithMirror 测试查询返回副本，以及通过 WithMirror 修改并保存镜像源.
+func TestWithMirror(t *testing.T) {
+	tempDir := setupTestDir(t)
+	mm := createTestMirrorManager(t, tempDir)
+	if err := mm.AddMirrorWithExtra("edit-me", TestAPIURL, "sk-test", ToolTypeCodex, "", map[string]string{"KEY": "old"}); err != nil {
+		t.Fatalf("添加镜像源失败: %v", err)
+	}
+
+	// 修改查询结果不影响配置
+	copied, _ := mm.GetMirrorByName("edit-me")
+	copied.BaseURL = "https://changed.example.com"
+	copied.ExtraEnv["KEY"] = "changed"
+	if mirror, _ := mm.GetMirrorByName("edit-me"); mirror.BaseURL != TestAPIURL || mirror.ExtraEnv["KEY"] != "old" {
+		t.Fatalf("修改副本不应影响配置: %+v", mirror)
+	}
+
+	if err := mm.WithMirror("edit-me", func(m *MirrorConfig) error {
+		m.Proxy = "http://127.0.0.1:7890"
+		return nil
+	}); err != nil {
+		t.Fatalf("WithMirror() error = %v", err)
+	}
+
+	// 返回错误或修改名称时配置保持不变
+	if err := mm.WithMirror("edit-me", func(m *MirrorConfig) error {
+		m.Proxy = ""
+		return errors.New("abort")
+	}); err == nil {
+		t.Error("edit 返回错误时 WithMirror 应返回错误")
+	}
+	if err := mm.WithMirror("edit-me", func(m *MirrorConfig) error {
+		m.Name = "renamed"
+		return nil
+	}); err == nil {
+		t.Error("修改名称时 WithMirror 应返回错误")
+	}
+	if err := mm.WithMirror("missing", func(*MirrorConfig) error { return nil }); err == nil {
+		t.Error("不存在的镜像源应返回错误")
+	}
+
+	reloaded, err := NewMirrorManagerWithPath(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("重新加载配置失败: %v", err)
+	}
+	mirror, err := reloaded.GetMirrorByName("edit-me")
+	if err != nil {
+		t.Fatalf("GetMirrorByName() error = %v", err)
+	}
+	if mirror.Proxy != "http://127.0.0.1:7890" {
+		t.Errorf("Proxy = %q, 期望修改已保存", mirror.Proxy)
+	}
+}
+
 // TestSanitizeEnvVarName 测试环境变量名称清理函数.
 func TestSanitizeEnvVarName(t *testing.T) {
 	tests := []struct {
@@ -417,6 +753,415 @@ func TestExtractMirrorNameFromURL(t *testing.T) {
 	}
 }
 
+// TestNormalizeBaseURL 测试 URL 校验与规范化.
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{"https://api.test.com", "https://api.test.com", false},
+		{"https://api.test.com/", "https://api.test.com", false},
+		{"http://localhost:8080/v1//", "http://localhost:8080/v1", false},
+		{" https://api.test.com ", "https://api.test.com", false},
+		{"api.test.com", "", true},
+		{"htps://api.test.com", "", true},
+		{"https://", "", true},
+		{"https://api.test.com?key=1", "", true},
+		{"://bad", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := NormalizeBaseURL(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("NormalizeBaseURL(%q) error = %v, expectError %v", tt.input, err, tt.expectError)
+			}
+			if result != tt.expected {
+				t.Errorf("NormalizeBaseURL(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestMirrorURLValidation 测试添加/更新镜像源时的 URL 校验开关.
+func TestMirrorURLValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseURL     string
+		validate    bool
+		expectURL   string
+		expectError bool
+	}{
+		{"有效URL去除末尾斜杠", "https://api.test.com/", true, "https://api.test.com", false},
+		{"协议拼写错误", "htps://api.test.com", true, "", true},
+		{"缺少协议", "api.test.com", true, "", true},
+		{"关闭校验", "intranet-host:9000/", false, "intranet-host:9000", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := createTestMirrorManager(t, setupTestDir(t))
+			mm.SetURLValidation(tt.validate)
+
+			err := mm.AddMirror("url-test", tt.baseURL, "sk-test")
+			if (err != nil) != tt.expectError {
+				t.Fatalf("AddMirror() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				// 更新时同样应拒绝无效 URL
+				if err := mm.UpdateMirror(DefaultMirrorName, tt.baseURL, ""); err == nil {
+					t.Errorf("UpdateMirror() 应拒绝无效 URL %q", tt.baseURL)
+				}
+				return
+			}
+
+			mirror, _ := mm.GetMirrorByName("url-test")
+			if mirror.BaseURL != tt.expectURL {
+				t.Errorf("BaseURL = %q, expected %q", mirror.BaseURL, tt.expectURL)
+			}
+		})
+	}
+}
+
+// TestSetMirrorProxy 测试设置和清除镜像源代理.
+func TestSetMirrorProxy(t *testing.T) {
+	tests := []struct {
+		name        string
+		proxy       string
+		expectProxy string
+		expectError bool
+	}{
+		{"HTTP代理", "http://127.0.0.1:7890", "http://127.0.0.1:7890", false},
+		{"SOCKS5代理", " socks5://proxy.local:1080 ", "socks5://proxy.local:1080", false},
+		{"清除代理", "", "", false},
+		{"缺少协议", "127.0.0.1:7890", "", true},
+		{"不支持的协议", "ftp://proxy.local", "", true},
+		{"缺少主机名", "http://", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := createTestMirrorManager(t, setupTestDir(t))
+			if err := mm.AddMirror("proxy-test", "https://api.test.com", "sk-test"); err != nil {
+				t.Fatalf("AddMirror() error = %v", err)
+			}
+
+			err := mm.SetMirrorProxy("proxy-test", tt.proxy)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("SetMirrorProxy(%q) error = %v, expectError %v", tt.proxy, err, tt.expectError)
+			}
+
+			mirror, _ := mm.GetMirrorByName("proxy-test")
+			if mirror.Proxy != tt.expectProxy {
+				t.Errorf("Proxy = %q, expected %q", mirror.Proxy, tt.expectProxy)
+			}
+
+			vars, err := MirrorEnvVars(mirror)
+			if err != nil {
+				t.Fatalf("MirrorEnvVars() error = %v", err)
+			}
+			_, hasProxyEnv := vars[HTTPSProxyEnv]
+			if hasProxyEnv != (tt.expectProxy != "") || vars[HTTPSProxyEnv] != tt.expectProxy || vars[HTTPProxyEnv] != tt.expectProxy {
+				t.Errorf("代理环境变量 = %q/%q, expected %q", vars[HTTPSProxyEnv], vars[HTTPProxyEnv], tt.expectProxy)
+			}
+		})
+	}
+
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.SetMirrorProxy("missing", "http://127.0.0.1:7890"); err == nil {
+		t.Error("SetMirrorProxy() 对不存在的镜像源应返回错误")
+	}
+}
+
+// TestSearchMirrors 测试按名称、URL 和模型名称搜索镜像源.
+func TestSearchMirrors(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.AddMirrorWithModel("Work-API", "https://gateway.corp.com", "sk-work", ToolTypeCodex, ""); err != nil {
+		t.Fatalf("AddMirrorWithModel() error = %v", err)
+	}
+	if err := mm.AddMirrorWithModel("my-claude", "https://claude.proxy.io", "sk-claude", ToolTypeClaude, "claude-Sonnet-4"); err != nil {
+		t.Fatalf("AddMirrorWithModel() error = %v", err)
+	}
+	if err := mm.AddMirror("removed-api", "https://gateway.removed.com", "sk-removed"); err != nil {
+		t.Fatalf("AddMirror() error = %v", err)
+	}
+	if err := mm.RemoveMirror("removed-api"); err != nil {
+		t.Fatalf("RemoveMirror() error = %v", err)
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"work", []string{"Work-API"}},
+		{"CORP.COM", []string{"Work-API"}},
+		{"sonnet", []string{"my-claude"}},
+		{"gateway", []string{"Work-API"}}, // 已删除的镜像源不参与搜索
+		{"nothing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var names []string
+			for _, mirror := range mm.SearchMirrors(tt.query) {
+				names = append(names, mirror.Name)
+			}
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("SearchMirrors(%q) = %v, expected %v", tt.query, names, tt.expected)
+			}
+		})
+	}
+
+	if got := len(mm.SearchMirrors(" ")); got != len(mm.ListActiveMirrors()) {
+		t.Errorf("空查询返回 %d 个镜像源，期望全部 %d 个", got, len(mm.ListActiveMirrors()))
+	}
+}
+
+// TestSetMirrorTierModel 测试按级别设置 Claude 模型.
+func TestFindDuplicateBaseURLs(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	mirrors := []struct {
+		name     string
+		url      string
+		toolType ToolType
+	}{
+		{"dup-a", "https://shared.example.com", ToolTypeCodex},
+		{"dup-b", "https://SHARED.example.com/", ToolTypeCodex},
+		{"dup-claude", "https://shared.example.com", ToolTypeClaude},
+		{"unique", "https://unique.example.com", ToolTypeCodex},
+	}
+	for _, m := range mirrors {
+		if err := mm.AddMirrorWithModel(m.name, m.url, "sk-"+m.name, m.toolType, ""); err != nil {
+			t.Fatalf("AddMirrorWithModel(%s) error = %v", m.name, err)
+		}
+	}
+
+	if got := mm.MirrorsSharingBaseURL("dup-a"); !slices.Equal(got, []string{"dup-b"}) {
+		t.Errorf("MirrorsSharingBaseURL(dup-a) = %v, expected [dup-b]", got)
+	}
+	// 不同工具类型的相同地址不算重复
+	if got := mm.MirrorsSharingBaseURL("dup-claude"); len(got) != 0 {
+		t.Errorf("MirrorsSharingBaseURL(dup-claude) = %v, expected none", got)
+	}
+
+	groups := mm.FindDuplicateBaseURLs()
+	if len(groups) != 1 || groups[0].ToolType != ToolTypeCodex || !slices.Equal(groups[0].Names, []string{"dup-a", "dup-b"}) {
+		t.Fatalf("FindDuplicateBaseURLs() = %+v", groups)
+	}
+
+	// 删除后的镜像源不再参与检测
+	if err := mm.RemoveMirror("dup-b"); err != nil {
+		t.Fatalf("RemoveMirror() error = %v", err)
+	}
+	if groups := mm.FindDuplicateBaseURLs(); len(groups) != 0 {
+		t.Errorf("FindDuplicateBaseURLs() after removal = %+v, expected none", groups)
+	}
+}
+
+func TestSetMirrorTierModel(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.AddMirrorWithModel("tiered", "https://tiered.test.com", "sk-tier", ToolTypeClaude, ""); err != nil {
+		t.Fatalf("AddMirrorWithModel() error = %v", err)
+	}
+	if err := mm.AddMirror("codex-api", "https://codex.test.com", "sk-codex"); err != nil {
+		t.Fatalf("AddMirror() error = %v", err)
+	}
+
+	if err := mm.SetMirrorTierModel("tiered", ModelTierHaiku, " fast-model "); err != nil {
+		t.Fatalf("SetMirrorTierModel() error = %v", err)
+	}
+	mirror, _ := mm.GetMirrorByName("tiered")
+	if got := TierModel(mirror, ModelTierHaiku); got != "fast-model" {
+		t.Errorf("TierModel(haiku) = %q, expected %q", got, "fast-model")
+	}
+
+	if err := mm.SetMirrorTierModel("tiered", ModelTierHaiku, ""); err != nil {
+		t.Fatalf("SetMirrorTierModel() clear error = %v", err)
+	}
+	mirror, _ = mm.GetMirrorByName("tiered")
+	if mirror.HaikuModel != "" {
+		t.Errorf("HaikuModel = %q, expected cleared", mirror.HaikuModel)
+	}
+
+	if err := mm.SetMirrorTierModel("codex-api", ModelTierOpus, "smart-model"); err == nil {
+		t.Error("Codex 镜像源设置级别模型应返回错误")
+	}
+	if err := mm.SetMirrorTierModel("tiered", ModelTier("mini"), "x"); err == nil {
+		t.Error("无效的模型级别应返回错误")
+	}
+}
+
+// TestListByTag 测试按标签过滤镜像源.
+func TestListByTag(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.AddMirror("work-api", "https://work.test.com", "sk-work"); err != nil {
+		t.Fatalf("AddMirror() error = %v", err)
+	}
+	if err := mm.AddMirror("cheap-api", "https://cheap.test.com", "sk-cheap"); err != nil {
+		t.Fatalf("AddMirror() error = %v", err)
+	}
+	if err := mm.SetMirrorTags("work-api", []string{" work ", "work", ""}); err != nil {
+		t.Fatalf("SetMirrorTags() error = %v", err)
+	}
+	if err := mm.SetMirrorTags("cheap-api", []string{"cheap", "personal"}); err != nil {
+		t.Fatalf("SetMirrorTags() error = %v", err)
+	}
+
+	tests := []struct {
+		tag      string
+		expected []string
+	}{
+		{"work", []string{"work-api"}},
+		{"personal", []string{"cheap-api"}},
+		{"unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			var names []string
+			for _, mirror := range mm.ListByTag(tt.tag) {
+				names = append(names, mirror.Name)
+			}
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("ListByTag(%q) = %v, expected %v", tt.tag, names, tt.expected)
+			}
+		})
+	}
+
+	if got := len(mm.ListByTag("")); got != len(mm.ListActiveMirrors()) {
+		t.Errorf("ListByTag(\"\") 返回 %d 个镜像源，期望全部 %d 个", got, len(mm.ListActiveMirrors()))
+	}
+	if got := mm.ListTags(); !slices.Equal(got, []string{"cheap", "personal", "work"}) {
+		t.Errorf("ListTags() = %v", got)
+	}
+	mirror, _ := mm.GetMirrorByName("work-api")
+	if !slices.Equal(mirror.Tags, []string{"work"}) {
+		t.Errorf("标签未规范化: %v", mirror.Tags)
+	}
+}
+
+// TestMoveMirror 测试调整镜像源顺序且不影响已删除镜像源的位置.
+func TestMoveMirror(t *testing.T) {
+	tests := []struct {
+		name        string
+		mirror      string
+		toIndex     int
+		expected    []string
+		expectError bool
+	}{
+		{"移动到最前", "c", 0, []string{"c", DefaultMirrorName, "a", "d"}, false},
+		{"移动到最后", "a", 3, []string{DefaultMirrorName, "c", "d", "a"}, false},
+		{"位置不变", "a", 1, []string{DefaultMirrorName, "a", "c", "d"}, false},
+		{"位置超出范围", "a", 4, nil, true},
+		{"负数位置", "a", -1, nil, true},
+		{"已删除的镜像源", "b", 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := createTestMirrorManager(t, setupTestDir(t))
+			for _, name := range []string{"a", "b", "c", "d"} {
+				if err := mm.AddMirror(name, "https://"+name+".test.com", "sk-"+name); err != nil {
+					t.Fatalf("AddMirror(%s) error = %v", name, err)
+				}
+			}
+			if err := mm.RemoveMirror("b"); err != nil {
+				t.Fatalf("RemoveMirror() error = %v", err)
+			}
+
+			err := mm.MoveMirror(tt.mirror, tt.toIndex)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("MoveMirror(%s, %d) error = %v, expectError %v", tt.mirror, tt.toIndex, err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+
+			var names []string
+			for _, mirror := range mm.ListActiveMirrors() {
+				names = append(names, mirror.Name)
+			}
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("活跃镜像源顺序 = %v, expected %v", names, tt.expected)
+			}
+
+			// 已删除的镜像源保持在原来的位置（第 3 个）
+			if all := mm.GetConfig().Mirrors; all[2].Name != "b" || !all[2].Deleted {
+				t.Errorf("已删除镜像源的位置被改变: %+v", all[2])
+			}
+		})
+	}
+}
+
+// TestNormalizeHealthPath 测试测试路径的校验与规范化.
+func TestNormalizeHealthPath(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{"/healthz", "/healthz", false},
+		{"healthz", "/healthz", false},
+		{" /api/health ", "/api/health", false},
+		{"", "", false},
+		{"https://api.test.com/healthz", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := NormalizeHealthPath(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("NormalizeHealthPath(%q) error = %v, expectError %v", tt.input, err, tt.expectError)
+			}
+			if result != tt.expected {
+				t.Errorf("NormalizeHealthPath(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSetMirrorTestHeaders 测试设置和清除连通性测试请求头.
+func TestSetMirrorTestHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		expectError bool
+	}{
+		{"设置请求头", map[string]string{"X-Org-Id": "org-123"}, false},
+		{"清除请求头", map[string]string{}, false},
+		{"请求头名称包含空格", map[string]string{"X Org": "1"}, true},
+		{"请求头名称包含冒号", map[string]string{"X-Org:": "1"}, true},
+		{"空请求头名称", map[string]string{"": "1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := createTestMirrorManager(t, setupTestDir(t))
+			if err := mm.AddMirror("header-test", "https://api.test.com", "sk-test"); err != nil {
+				t.Fatalf("AddMirror() error = %v", err)
+			}
+
+			err := mm.SetMirrorTestHeaders("header-test", tt.headers)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("SetMirrorTestHeaders() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+
+			mirror, _ := mm.GetMirrorByName("header-test")
+			if len(mirror.TestHeaders) != len(tt.headers) {
+				t.Errorf("TestHeaders = %v, expected %v", mirror.TestHeaders, tt.headers)
+			}
+			for key, value := range tt.headers {
+				if mirror.TestHeaders[key] != value {
+					t.Errorf("TestHeaders[%s] = %q, expected %q", key, mirror.TestHeaders[key], value)
+				}
+			}
+		})
+	}
+}
+
 // TestMirrorFixEnvKeyFormat 测试修复环境变量key格式.
 func TestMirrorFixEnvKeyFormat(t *testing.T) {
 	tempDir := setupTestDir(t)
@@ -518,6 +1263,103 @@ func createTestMirrorManager(t *testing.T, tempDir string) *MirrorManager {
 	return mm
 }
 
+// TestNewMirrorManagerWithOptions 测试按选项控制配置文件不存在时是否从环境中发现镜像源.
+func TestNewMirrorManagerWithOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		discover    bool
+		wantClaude  bool
+		wantCreated bool
+	}{
+		{name: "discovery on", discover: true, wantClaude: true, wantCreated: true},
+		{name: "discovery off", discover: false, wantClaude: false, wantCreated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := setupTestDir(t)
+			t.Setenv("HOME", tempDir)
+			t.Setenv("USERPROFILE", tempDir)
+			t.Setenv("ANTHROPIC_BASE_URL", "https://claude.example.com")
+			t.Setenv("ANTHROPIC_AUTH_TOKEN", "sk-claude")
+
+			configPath := filepath.Join(tempDir, "custom", "mirrors.toml")
+			mm, err := NewMirrorManagerWithOptions(Options{Path: configPath, DiscoverFromEnv: tt.discover})
+			if err != nil {
+				t.Fatalf("NewMirrorManagerWithOptions() error = %v", err)
+			}
+			if mm.GetConfigPath() != configPath {
+				t.Errorf("GetConfigPath() = %s, want %s", mm.GetConfigPath(), configPath)
+			}
+
+			if hasClaude := mm.GetConfig().CurrentClaude != ""; hasClaude != tt.wantClaude {
+				t.Errorf("发现 Claude 镜像源 = %v, want %v", hasClaude, tt.wantClaude)
+			}
+			if _, err := mm.GetMirrorByName(mm.GetConfig().OfficialMirror()); err != nil {
+				t.Errorf("应包含官方镜像源: %v", err)
+			}
+			if _, err := os.Stat(configPath); (err == nil) != tt.wantCreated {
+				t.Errorf("配置文件已创建 = %v, want %v", err == nil, tt.wantCreated)
+			}
+		})
+	}
+}
+
+// TestDiscoverCodexFromEnv 测试只有同时设置了有效地址的 CODEX_<NAME>_API_KEY 才会被发现，且可以整体关闭.
+func TestDiscoverCodexFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		wantURL  string
+		wantSeen bool
+	}{
+		{
+			name:     "key with base URL",
+			env:      map[string]string{"CODEX_TESTMIRROR_API_KEY": "sk-test", "CODEX_TESTMIRROR_BASE_URL": "https://test.example.com"},
+			wantURL:  "https://test.example.com",
+			wantSeen: true,
+		},
+		{
+			name:     "key without base URL",
+			env:      map[string]string{"CODEX_TESTMIRROR_API_KEY": "sk-test"},
+			wantSeen: false,
+		},
+		{
+			name:     "invalid base URL",
+			env:      map[string]string{"CODEX_TESTMIRROR_API_KEY": "sk-test", "CODEX_TESTMIRROR_BASE_URL": "not-a-url"},
+			wantSeen: false,
+		},
+		{
+			name: "discovery disabled",
+			env: map[string]string{
+				"CODEX_TESTMIRROR_API_KEY":  "sk-test",
+				"CODEX_TESTMIRROR_BASE_URL": "https://test.example.com",
+				DisableCodexEnvDiscoveryEnv: "true",
+			},
+			wantSeen: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			discovered := make(map[string]MirrorConfig)
+			(&MirrorManager{config: &SystemConfig{}}).discoverCodexFromEnv(discovered)
+
+			mirror, seen := discovered["testmirror"]
+			if seen != tt.wantSeen {
+				t.Fatalf("发现 testmirror = %v, want %v", seen, tt.wantSeen)
+			}
+			if seen && (mirror.BaseURL != tt.wantURL || mirror.APIKey != "sk-test" || mirror.EnvKey != "CODEX_TESTMIRROR_API_KEY") {
+				t.Errorf("发现的镜像源不正确: %+v", mirror)
+			}
+		})
+	}
+}
+
 // TestSystemConfigPersistence 测试系统配置持久化.
 func TestSystemConfigPersistence(t *testing.T) {
 	tempDir := setupTestDir(t)
@@ -582,15 +1424,54 @@ func TestSystemConfigPersistence(t *testing.T) {
 		t.Errorf("配置持久化失败，期望 BaseURL 为 'https://api.test.com'，实际为 %s", mirror.BaseURL)
 	}
 
-	// 验证sync配置
-	if mm2.config.Sync == nil {
+	// 验证旧版sync配置被迁移为默认同步配置
+	if mm2.config.Sync != nil {
+		t.Error("旧版Sync配置应在加载时迁移")
+	}
+	if syncConfig := mm2.GetSyncProfile(DefaultSyncProfile); syncConfig == nil {
 		t.Error("Sync配置未正确持久化")
 	} else {
-		if mm2.config.Sync.Provider != "gist" {
-			t.Errorf("Sync配置持久化失败，期望 Provider 为 'gist'，实际为 %s", mm2.config.Sync.Provider)
+		if syncConfig.Provider != "gist" {
+			t.Errorf("Sync配置持久化失败，期望 Provider 为 'gist'，实际为 %s", syncConfig.Provider)
 		}
-		if mm2.config.Sync.DeviceID != "test-device-id" {
-			t.Errorf("Sync配置持久化失败，期望 DeviceID 为 'test-device-id'，实际为 %s", mm2.config.Sync.DeviceID)
+		if syncConfig.DeviceID != "test-device-id" {
+			t.Errorf("Sync配置持久化失败，期望 DeviceID 为 'test-device-id'，实际为 %s", syncConfig.DeviceID)
 		}
 	}
 }
+
+// TestGetMirrorConfigPath 测试配置目录的优先级: --config-dir > CODEX_MIRROR_CONFIG_PATH > CODEX_MIRROR_HOME > 默认.
+func TestGetMirrorConfigPath(t *testing.T) {
+	home := t.TempDir()
+	tests := []struct {
+		name       string
+		override   string
+		configPath string
+		mirrorHome string
+		want       string
+	}{
+		{name: "默认目录", want: filepath.Join(home, ".codex-mirror", "mirrors.toml")},
+		{name: "CODEX_MIRROR_HOME", mirrorHome: "/env/home", want: filepath.Join("/env/home", "mirrors.toml")},
+		{name: "CODEX_MIRROR_CONFIG_PATH 优先于 CODEX_MIRROR_HOME", configPath: "/env/file.toml", mirrorHome: "/env/home", want: "/env/file.toml"},
+		{name: "--config-dir 优先于环境变量", override: "/flag/dir", configPath: "/env/file.toml", mirrorHome: "/env/home", want: filepath.Join("/flag/dir", "mirrors.toml")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", home)
+			t.Setenv("USERPROFILE", home)
+			t.Setenv("CODEX_MIRROR_CONFIG_PATH", tt.configPath)
+			t.Setenv(MirrorHomeEnv, tt.mirrorHome)
+			SetConfigDir(tt.override)
+			defer SetConfigDir("")
+
+			got, err := GetMirrorConfigPath()
+			if err != nil {
+				t.Fatalf("GetMirrorConfigPath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetMirrorConfigPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
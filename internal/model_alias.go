@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// ModelAliases 返回模型别名（别名 -> 实际模型名称）的副本.
+func (mm *MirrorManager) ModelAliases() map[string]string {
+	return maps.Clone(mm.config.ModelAliases)
+}
+
+// SetModelAlias 设置模型别名并保存配置，model 为空时删除该别名.
+// 镜像源的模型名称为别名时，应用配置前替换为实际模型名称；别名不支持嵌套.
+func (mm *MirrorManager) SetModelAlias(alias, model string) error {
+	alias = strings.TrimSpace(alias)
+	model = strings.TrimSpace(model)
+	if alias == "" || strings.ContainsAny(alias, " \t") {
+		return fmt.Errorf("无效的模型别名 '%s'", alias)
+	}
+
+	if model == "" {
+		if _, ok := mm.config.ModelAliases[alias]; !ok {
+			return fmt.Errorf("模型别名 '%s' 不存在", alias)
+		}
+		delete(mm.config.ModelAliases, alias)
+		return mm.saveConfig()
+	}
+
+	if mm.config.ModelAliases == nil {
+		mm.config.ModelAliases = make(map[string]string)
+	}
+	mm.config.ModelAliases[alias] = model
+	return mm.saveConfig()
+}
+
+// ResolveModelAlias 返回模型名称已按别名替换的镜像源副本，用于写入 Codex、VS Code 和 Claude 配置.
+// 模型名称不是别名时直接返回原镜像源；副本不能写回 mirrors.toml，以便修改别名后所有镜像源随之生效.
+func (mm *MirrorManager) ResolveModelAlias(mirror *MirrorConfig) (*MirrorConfig, error) {
+	model, ok := mm.config.ModelAliases[strings.TrimSpace(mirror.ModelName)]
+	if !ok {
+		return mirror, nil
+	}
+	if strings.TrimSpace(model) == "" {
+		return nil, fmt.Errorf("镜像源 '%s' 的模型别名 '%s' 解析结果为空", mirror.Name, mirror.ModelName)
+	}
+
+	resolved := cloneMirror(mirror)
+	resolved.ModelName = strings.TrimSpace(model)
+	return resolved, nil
+}
+
+// SetCurrentModel 修改指定工具类型当前激活镜像源的模型名称（可以是模型别名）并保存，返回该镜像源.
+func (mm *MirrorManager) SetCurrentModel(toolType ToolType, model string) (*MirrorConfig, error) {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return nil, fmt.Errorf("模型名称不能为空")
+	}
+
+	var mirror *MirrorConfig
+	var err error
+	switch toolType {
+	case ToolTypeCodex:
+		mirror, err = mm.GetCurrentCodexMirror()
+	case ToolTypeClaude:
+		mirror, err = mm.GetCurrentClaudeMirror()
+	default:
+		return nil, fmt.Errorf("无效的工具类型 '%s'，支持: %s, %s", toolType, ToolTypeCodex, ToolTypeClaude)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mm.UpdateMirrorFull(mirror.Name, "", "", model, ""); err != nil {
+		return nil, err
+	}
+	return mm.GetMirrorByName(mirror.Name)
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"os"
+	"testing"
+)
+
+// TestResolveModelAlias 测试模型别名在应用时解析，未匹配别名时返回原镜像源.
+func TestResolveModelAlias(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.SetModelAlias("fast", "gpt-5-mini"); err != nil {
+		t.Fatalf("SetModelAlias() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		modelName string
+		want      string
+	}{
+		{name: "alias", modelName: "fast", want: "gpt-5-mini"},
+		{name: "plain model", modelName: "gpt-5", want: "gpt-5"},
+		{name: "empty model", modelName: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mirror := &MirrorConfig{Name: "m", ModelName: tt.modelName, ToolType: ToolTypeCodex}
+			resolved, err := mm.ResolveModelAlias(mirror)
+			if err != nil {
+				t.Fatalf("ResolveModelAlias() error = %v", err)
+			}
+			if resolved.ModelName != tt.want {
+				t.Errorf("ModelName = %q, want %q", resolved.ModelName, tt.want)
+			}
+			if mirror.ModelName != tt.modelName {
+				t.Errorf("原镜像源不应被修改，实际: %q", mirror.ModelName)
+			}
+		})
+	}
+
+	// 手动编辑出的空别名应报错
+	mm.config.ModelAliases["broken"] = " "
+	if _, err := mm.ResolveModelAlias(&MirrorConfig{Name: "m", ModelName: "broken"}); err == nil {
+		t.Error("ResolveModelAlias() 空别名应失败")
+	}
+
+	if err := mm.SetModelAlias("fast", ""); err != nil {
+		t.Fatalf("删除别名 error = %v", err)
+	}
+	if _, ok := mm.ModelAliases()["fast"]; ok {
+		t.Error("别名应已删除")
+	}
+	if err := mm.SetModelAlias("fast", ""); err == nil {
+		t.Error("删除不存在的别名应失败")
+	}
+	if err := mm.SetModelAlias("bad alias", "gpt-5"); err == nil {
+		t.Error("包含空格的别名应失败")
+	}
+}
+
+// TestSetCurrentModel 测试修改当前激活镜像源的模型.
+func TestSetCurrentModel(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.AddMirrorWithModel("work", "https://work.example.com", "sk-work", ToolTypeCodex, "gpt-5"); err != nil {
+		t.Fatalf("AddMirrorWithModel() error = %v", err)
+	}
+	if err := mm.SwitchMirror("work"); err != nil {
+		t.Fatalf("SwitchMirror() error = %v", err)
+	}
+
+	mirror, err := mm.SetCurrentModel(ToolTypeCodex, "fast")
+	if err != nil {
+		t.Fatalf("SetCurrentModel() error = %v", err)
+	}
+	if mirror.Name != "work" || mirror.ModelName != "fast" {
+		t.Errorf("SetCurrentModel() = %s/%s, want work/fast", mirror.Name, mirror.ModelName)
+	}
+
+	if _, err := mm.SetCurrentModel(ToolTypeCodex, " "); err == nil {
+		t.Error("空模型名称应失败")
+	}
+	if _, err := mm.SetCurrentModel(ToolTypeClaude, "claude-sonnet"); err == nil {
+		t.Error("没有激活的 Claude 镜像源时应失败")
+	}
+}
+
+// TestCodexApplyRejectsEmptyModel 测试模型名称为空白时不写入 config.toml.
+func TestCodexApplyRejectsEmptyModel(t *testing.T) {
+	tempDir := setupTestDir(t)
+	ccm := createTestCodexConfigManager(t, tempDir)
+	mirror := &MirrorConfig{Name: "blank", BaseURL: "https://blank.example.com", APIKey: "sk-blank", ToolType: ToolTypeCodex, ModelName: "  "}
+
+	if _, err := ccm.PlanMirror(mirror); err == nil {
+		t.Error("PlanMirror() 空白模型名称应失败")
+	}
+	if err := ccm.ApplyMirror(mirror); err == nil {
+		t.Fatal("ApplyMirror() 空白模型名称应失败")
+	}
+	if _, err := os.Stat(ccm.configPath); !os.IsNotExist(err) {
+		t.Errorf("不应写入 config.toml: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxModelsResponseSize 读取模型列表响应的大小上限.
+const maxModelsResponseSize = 4 << 20
+
+// OpenAIModelsResponse OpenAI 兼容的 models API 响应（Anthropic 的 models API 格式相同）.
+type OpenAIModelsResponse struct {
+	Data   []OpenAIModel `json:"data"`
+	Object string        `json:"object"`
+}
+
+// OpenAIModel models API 响应中的单个模型.
+type OpenAIModel struct {
+	ID string `json:"id"`
+}
+
+// parseModelIDs 解析 models API 响应中的模型 ID，返回排序去重后的列表.
+// 响应不是模型列表（缺少 data 数组）时 ok 为 false.
+func parseModelIDs(body []byte) (models []string, ok bool) {
+	var resp struct {
+		Data *json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Data == nil {
+		return nil, false
+	}
+
+	var list OpenAIModelsResponse
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, false
+	}
+	models = make([]string, 0, len(list.Data))
+	for _, model := range list.Data {
+		if id := strings.TrimSpace(model.ID); id != "" {
+			models = append(models, id)
+		}
+	}
+	slices.Sort(models)
+	return slices.Compact(models), true
+}
+
+// ListModels 请求镜像源的 /v1/models 接口并返回可用的模型 ID.
+func (ct *ConnectivityTester) ListModels(mirror *MirrorConfig, timeout int) ([]string, error) {
+	mirror, err := ResolveMirrorAPIKey(mirror)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := ct.transportFor(mirror)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Timeout:   time.Duration(timeout) * time.Second,
+		Transport: transport,
+	}
+
+	req, err := newModelsRequest(mirror)
+	if err != nil {
+		return nil, err
+	}
+	statusCode, body, err := doProbeRequest(client, req)
+	if err != nil {
+		return nil, fmt.Errorf("连接失败: %w", err)
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取模型列表失败: HTTP %d", statusCode)
+	}
+
+	models, ok := parseModelIDs(body)
+	if !ok {
+		return nil, fmt.Errorf("响应不是有效的模型列表")
+	}
+	return models, nil
+}
+
+// newModelsRequest 构造获取模型列表的请求，认证方式与连通性测试一致.
+func newModelsRequest(mirror *MirrorConfig) (*http.Request, error) {
+	if IsAzureMirror(mirror) {
+		probe := *mirror
+		probe.HealthPath = ""
+		return newTestRequest(&probe)
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimSuffix(mirror.BaseURL, "/")+"/v1/models", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if mirror.APIKey != "" {
+		if mirror.ToolType == ToolTypeClaude {
+			req.Header.Set("x-api-key", mirror.APIKey)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+mirror.APIKey)
+		}
+	}
+	if mirror.ToolType == ToolTypeClaude {
+		req.Header.Set("anthropic-version", "2023-06-01")
+	}
+	for key, value := range mirror.TestHeaders {
+		req.Header.Set(key, value)
+	}
+	return req, nil
+}
+
+// SupportsModel 判断模型是否在镜像源的已知模型列表中；模型为空或尚未获取模型列表时视为支持.
+func (m *MirrorConfig) SupportsModel(model string) bool {
+	return model == "" || len(m.Models) == 0 || slices.Contains(m.Models, model)
+}
+
+// SetMirrorModels 缓存镜像源的可用模型列表.
+// 模型列表是从镜像源探测得到的缓存，不更新 LastModified，也不参与同步冲突判断.
+func (mm *MirrorManager) SetMirrorModels(name string, models []string) error {
+	return mm.CacheMirrorModels(map[string][]string{name: models})
+}
+
+// CacheMirrorModels 批量缓存多个镜像源的可用模型列表，只在有变化时保存一次.
+// 不存在的镜像源会被忽略.
+func (mm *MirrorManager) CacheMirrorModels(models map[string][]string) error {
+	changed := false
+	for name, list := range models {
+		mirror := mm.findActiveMirror(name)
+		if mirror == nil || slices.Equal(mirror.Models, list) {
+			continue
+		}
+		mirror.Models = slices.Clone(list)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return mm.saveConfig()
+}
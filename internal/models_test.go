@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// TestListModels 测试获取镜像源的模型列表.
+func TestListModels(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "排序去重", status: http.StatusOK, body: `{"object":"list","data":[{"id":"gpt-5"},{"id":"gpt-4o"},{"id":"gpt-5"}]}`, want: []string{"gpt-4o", "gpt-5"}},
+		{name: "空列表", status: http.StatusOK, body: `{"data":[]}`, want: []string{}},
+		{name: "不是模型列表", status: http.StatusOK, body: `<html>login</html>`, wantErr: true},
+		{name: "认证失败", status: http.StatusUnauthorized, body: `{}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/models" || r.Header.Get("Authorization") != "Bearer sk-test" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			mirror := &MirrorConfig{Name: "models", BaseURL: server.URL, APIKey: "sk-test", ToolType: ToolTypeCodex}
+			got, err := (&ConnectivityTester{}).ListModels(mirror, 5)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListModels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("ListModels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConnectivityModels 测试连通性测试从模型列表响应中解析可用模型.
+func TestConnectivityModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"id":"b"},{"id":"a"}]}`))
+	}))
+	defer server.Close()
+
+	mirror := &MirrorConfig{Name: "models", BaseURL: server.URL, APIKey: "sk-test", ToolType: ToolTypeCodex}
+	result := (&ConnectivityTester{}).TestOnce(mirror, 5)
+	if !result.Success || !slices.Equal(result.Models, []string{"a", "b"}) || result.ModelCount != 2 {
+		t.Errorf("TestOnce() = %+v, want success with 2 models [a b]", result)
+	}
+}
+
+// TestCacheMirrorModels 测试缓存模型列表并校验模型名称.
+func TestCacheMirrorModels(t *testing.T) {
+	mm := createTestMirrorManager(t, setupTestDir(t))
+	if err := mm.AddMirror("cached", "https://cached.example.com", "sk-cached"); err != nil {
+		t.Fatalf("AddMirror() error = %v", err)
+	}
+
+	mirror, _ := mm.GetMirrorByName("cached")
+	if !mirror.SupportsModel("anything") {
+		t.Error("未缓存模型列表时应视为支持任意模型")
+	}
+
+	if err := mm.CacheMirrorModels(map[string][]string{"cached": {"gpt-5"}, "missing": {"x"}}); err != nil {
+		t.Fatalf("CacheMirrorModels() error = %v", err)
+	}
+
+	reloaded, err := NewMirrorManagerWithPath(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("NewMirrorManagerWithPath() error = %v", err)
+	}
+	mirror, _ = reloaded.GetMirrorByName("cached")
+	if !slices.Equal(mirror.Models, []string{"gpt-5"}) {
+		t.Fatalf("Models = %v, want [gpt-5]", mirror.Models)
+	}
+	if !mirror.SupportsModel("gpt-5") || !mirror.SupportsModel("") || mirror.SupportsModel("gpt-4o") {
+		t.Errorf("SupportsModel() 结果与缓存的模型列表不一致: %v", mirror.Models)
+	}
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"os"
+	"strings"
+)
+
+// 首次运行生成默认配置时用于指定官方镜像源的环境变量，适用于无法访问 api.openai.com 的内网环境.
+const (
+	OfficialMirrorNameEnv = "CODEX_MIRROR_OFFICIAL_NAME"
+	OfficialMirrorURLEnv  = "CODEX_MIRROR_OFFICIAL_URL"
+)
+
+// DefaultOfficialURL 未配置时官方镜像源使用的地址.
+const DefaultOfficialURL = "https://api.openai.com"
+
+// OfficialMirror 返回受保护的官方镜像源名称，未配置时为 DefaultMirrorName.
+func (c *SystemConfig) OfficialMirror() string {
+	if c.OfficialName != "" {
+		return c.OfficialName
+	}
+	return DefaultMirrorName
+}
+
+// officialMirrorURL 返回官方镜像源的地址，未配置时为 DefaultOfficialURL.
+func (c *SystemConfig) officialMirrorURL() string {
+	if c.OfficialURL != "" {
+		return c.OfficialURL
+	}
+	return DefaultOfficialURL
+}
+
+// OfficialMirror 返回受保护的官方镜像源名称.
+func (mm *MirrorManager) OfficialMirror() string {
+	return mm.config.OfficialMirror()
+}
+
+// IsOfficialMirror 判断镜像源是否为受保护的官方镜像源（不能删除或修改）.
+func (mm *MirrorManager) IsOfficialMirror(name string) bool {
+	return name == mm.config.OfficialMirror()
+}
+
+// officialMirrorFromEnv 从环境变量读取官方镜像源的名称和地址，未设置时返回空字符串.
+func officialMirrorFromEnv() (name, baseURL string) {
+	name = strings.TrimSpace(os.Getenv(OfficialMirrorNameEnv))
+	baseURL = strings.TrimRight(strings.TrimSpace(os.Getenv(OfficialMirrorURLEnv)), "/")
+	return name, baseURL
+}
+
+// ensureOfficialMirror 配置文件中指定了官方镜像源但镜像源列表中不存在时补充该镜像源，
+// 便于通过手动编辑配置文件切换到内网的官方地址.
+func (mm *MirrorManager) ensureOfficialMirror() {
+	if mm.config.OfficialName == "" && mm.config.OfficialURL == "" {
+		return
+	}
+	name := mm.config.OfficialMirror()
+	for i := range mm.config.Mirrors {
+		if mm.config.Mirrors[i].Name == name && !mm.config.Mirrors[i].Deleted {
+			return
+		}
+	}
+	mm.config.Mirrors = append([]MirrorConfig{{
+		Name:     name,
+		BaseURL:  mm.config.officialMirrorURL(),
+		ToolType: ToolTypeCodex,
+	}}, mm.config.Mirrors...)
+	if mm.config.CurrentCodex == "" {
+		mm.config.CurrentCodex = name
+	}
+}
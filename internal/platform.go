@@ -1,11 +1,17 @@
 package internal
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 )
 
+// wslVersionFile 用于检测 WSL 的内核版本文件.
+const wslVersionFile = "/proc/version"
+
 // GetCurrentPlatform 获取当前运行平台.
 func GetCurrentPlatform() Platform {
 	switch runtime.GOOS {
@@ -20,6 +26,23 @@ func GetCurrentPlatform() Platform {
 	}
 }
 
+// IsWSL 检测是否运行在 Windows Subsystem for Linux 中.
+func IsWSL() bool {
+	if runtime.GOOS != LinuxOS {
+		return false
+	}
+	data, err := os.ReadFile(wslVersionFile)
+	if err != nil {
+		return false
+	}
+	return isWSLKernel(string(data))
+}
+
+// isWSLKernel 判断内核版本信息是否来自 WSL（WSL 的内核版本包含 "microsoft"）.
+func isWSLKernel(version string) bool {
+	return strings.Contains(strings.ToLower(version), "microsoft")
+}
+
 // GetPathConfig 根据平台获取路径配置.
 func GetPathConfig() (*PathConfig, error) {
 	homeDir, err := os.UserHomeDir()
@@ -32,24 +55,38 @@ func GetPathConfig() (*PathConfig, error) {
 		HomeDir: homeDir,
 	}
 
+	config.CodexConfigDir = filepath.Join(homeDir, ".codex")
+	config.VSCodeConfigDir = vscodeUserDir(homeDir, platform, detectVSCodeVariant(homeDir, platform))
+
+	return config, nil
+}
+
+// vscodeUserDir 返回指定平台和发行版本的 VS Code 用户配置目录.
+func vscodeUserDir(homeDir string, platform Platform, variant VSCodeVariant) string {
+	appDir := "Code"
+	if variant == VSCodeInsiders {
+		appDir = "Code - Insiders"
+	}
+
 	switch platform {
 	case PlatformWindows:
-		// Windows路径配置.
-		config.CodexConfigDir = filepath.Join(homeDir, ".codex")
-		config.VSCodeConfigDir = filepath.Join(homeDir, "AppData", "Roaming", "Code", "User")
-
+		return filepath.Join(homeDir, "AppData", "Roaming", appDir, "User")
 	case PlatformMac:
-		// Mac路径配置.
-		config.CodexConfigDir = filepath.Join(homeDir, ".codex")
-		config.VSCodeConfigDir = filepath.Join(homeDir, "Library", "Application Support", "Code", "User")
-
-	case PlatformLinux:
-		// Linux路径配置.
-		config.CodexConfigDir = filepath.Join(homeDir, ".codex")
-		config.VSCodeConfigDir = filepath.Join(homeDir, ".config", "Code", "User")
+		return filepath.Join(homeDir, "Library", "Application Support", appDir, "User")
+	default:
+		return filepath.Join(homeDir, ".config", appDir, "User")
 	}
+}
 
-	return config, nil
+// detectVSCodeVariant 检测使用的 VS Code 发行版本：仅安装了 Insiders 时使用 Insiders，否则使用稳定版.
+func detectVSCodeVariant(homeDir string, platform Platform) VSCodeVariant {
+	if _, err := os.Stat(vscodeUserDir(homeDir, platform, VSCodeStable)); err == nil {
+		return VSCodeStable
+	}
+	if _, err := os.Stat(vscodeUserDir(homeDir, platform, VSCodeInsiders)); err == nil {
+		return VSCodeInsiders
+	}
+	return VSCodeStable
 }
 
 // EnsureDir 确保目录存在，如果不存在则创建.
@@ -78,7 +115,7 @@ func GetCodexAuthPath() (string, error) {
 	return filepath.Join(pathConfig.CodexConfigDir, "auth.json"), nil
 }
 
-// GetVSCodeSettingsPath 获取VS Code设置文件路径.
+// GetVSCodeSettingsPath 获取VS Code设置文件路径（自动检测发行版本）.
 func GetVSCodeSettingsPath() (string, error) {
 	pathConfig, err := GetPathConfig()
 	if err != nil {
@@ -86,3 +123,73 @@ func GetVSCodeSettingsPath() (string, error) {
 	}
 	return filepath.Join(pathConfig.VSCodeConfigDir, "settings.json"), nil
 }
+
+// GetVSCodeSettingsPathForVariant 获取指定发行版本的VS Code设置文件路径.
+func GetVSCodeSettingsPathForVariant(variant VSCodeVariant) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(vscodeUserDir(homeDir, GetCurrentPlatform(), variant), "settings.json"), nil
+}
+
+// ManagedPath 由本工具管理的配置文件路径.
+type ManagedPath struct {
+	Name   string `json:"-"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+}
+
+// GetManagedPaths 返回所有受管理的配置文件路径及其是否存在.
+func GetManagedPaths() ([]ManagedPath, error) {
+	resolvers := []struct {
+		name    string
+		resolve func() (string, error)
+	}{
+		{"mirrors", GetMirrorConfigPath},
+		{"codex_config", GetCodexConfigPath},
+		{"codex_auth", GetCodexAuthPath},
+		{"claude_settings", GetClaudeSettingsPath},
+		{"vscode_settings", GetVSCodeSettingsPath},
+	}
+
+	paths := make([]ManagedPath, 0, len(resolvers))
+	for _, r := range resolvers {
+		path, err := r.resolve()
+		if err != nil {
+			return nil, fmt.Errorf("获取 %s 路径失败: %v", r.name, err)
+		}
+		_, statErr := os.Stat(path)
+		paths = append(paths, ManagedPath{Name: r.name, Path: path, Exists: statErr == nil})
+	}
+
+	return paths, nil
+}
+
+// fileManagerCommand 返回指定平台上用于在文件管理器中打开路径的命令.
+func fileManagerCommand(platform Platform) string {
+	switch platform {
+	case PlatformWindows:
+		return "explorer"
+	case PlatformMac:
+		return "open"
+	default:
+		return "xdg-open"
+	}
+}
+
+// OpenInFileManager 在系统文件管理器中打开指定目录，找不到启动程序时返回错误.
+func OpenInFileManager(dir string) error {
+	launcher := fileManagerCommand(GetCurrentPlatform())
+	if _, err := exec.LookPath(launcher); err != nil {
+		return fmt.Errorf("未找到文件管理器启动程序 %s: %v", launcher, err)
+	}
+
+	cmd := exec.Command(launcher, dir)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("启动文件管理器失败: %v", err)
+	}
+	// explorer 即使成功也可能返回非零退出码，只回收进程不检查结果
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
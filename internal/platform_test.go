@@ -40,6 +40,28 @@ func TestGetCurrentPlatform(t *testing.T) {
 	}
 }
 
+// TestIsWSLKernel 测试根据内核版本信息识别 WSL.
+func TestIsWSLKernel(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    bool
+	}{
+		{name: "WSL2", version: "Linux version 5.15.153.1-microsoft-standard-WSL2 (root@65c757a075e2) (gcc (GCC) 11.2.0)", want: true},
+		{name: "WSL1", version: "Linux version 4.4.0-19041-Microsoft (Microsoft@Microsoft.com) (gcc version 5.4.0)", want: true},
+		{name: "普通Linux", version: "Linux version 6.8.0-45-generic (buildd@lcy02-amd64-075) (gcc 13.2.0)", want: false},
+		{name: "空内容", version: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isWSLKernel(tt.version); got != tt.want {
+				t.Errorf("isWSLKernel(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
 // TestGetPathConfig 测试获取路径配置.
 func TestGetPathConfig(t *testing.T) {
 	// 设置临时home目录
@@ -371,6 +393,52 @@ func TestEnvironmentConstants(t *testing.T) {
 
 // Helper functions for testing
 
+// TestGetManagedPaths 测试获取受管理的配置文件路径.
+func TestGetManagedPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	oldHome := setTempHome(t, tempDir)
+	defer restoreHome(oldHome)
+	t.Setenv("CODEX_MIRROR_CONFIG_PATH", "")
+
+	// 只创建 Codex 配置文件
+	codexConfig := filepath.Join(tempDir, ".codex", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(codexConfig), 0o755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(codexConfig, []byte(""), 0o644); err != nil {
+		t.Fatalf("创建文件失败: %v", err)
+	}
+
+	paths, err := GetManagedPaths()
+	if err != nil {
+		t.Fatalf("GetManagedPaths() error = %v", err)
+	}
+
+	expected := map[string]struct {
+		path   string
+		exists bool
+	}{
+		"mirrors":         {filepath.Join(tempDir, ".codex-mirror", "mirrors.toml"), false},
+		"codex_config":    {codexConfig, true},
+		"codex_auth":      {filepath.Join(tempDir, ".codex", "auth.json"), false},
+		"claude_settings": {filepath.Join(tempDir, ".claude", "settings.json"), false},
+	}
+
+	for _, p := range paths {
+		want, ok := expected[p.Name]
+		if !ok {
+			continue
+		}
+		if p.Path != want.path || p.Exists != want.exists {
+			t.Errorf("%s = {%s, %v}, expected {%s, %v}", p.Name, p.Path, p.Exists, want.path, want.exists)
+		}
+		delete(expected, p.Name)
+	}
+	if len(expected) > 0 {
+		t.Errorf("缺少路径: %v", expected)
+	}
+}
+
 // setTempHome 设置临时home目录并返回原始值.
 func setTempHome(_ *testing.T, tempDir string) map[string]string {
 	oldValues := make(map[string]string)
@@ -448,3 +516,66 @@ func TestFilepathIntegration(t *testing.T) {
 		t.Errorf("Codex config and auth files should be in the same directory: %v vs %v", codexDir, authDir)
 	}
 }
+
+// TestVSCodeVariantDetection 测试VS Code稳定版和Insiders的路径检测.
+func TestVSCodeVariantDetection(t *testing.T) {
+	tests := []struct {
+		name        string
+		createDirs  []VSCodeVariant
+		wantVariant VSCodeVariant
+	}{
+		{name: "都未安装时使用稳定版", wantVariant: VSCodeStable},
+		{name: "仅安装Insiders", createDirs: []VSCodeVariant{VSCodeInsiders}, wantVariant: VSCodeInsiders},
+		{name: "都安装时使用稳定版", createDirs: []VSCodeVariant{VSCodeStable, VSCodeInsiders}, wantVariant: VSCodeStable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			oldHome := setTempHome(t, tempDir)
+			defer restoreHome(oldHome)
+
+			platform := GetCurrentPlatform()
+			for _, variant := range tt.createDirs {
+				if err := os.MkdirAll(vscodeUserDir(tempDir, platform, variant), 0o755); err != nil {
+					t.Fatalf("创建目录失败: %v", err)
+				}
+			}
+
+			path, err := GetVSCodeSettingsPath()
+			if err != nil {
+				t.Fatalf("GetVSCodeSettingsPath() error = %v", err)
+			}
+			want := filepath.Join(vscodeUserDir(tempDir, platform, tt.wantVariant), "settings.json")
+			if path != want {
+				t.Errorf("GetVSCodeSettingsPath() = %v, expected %v", path, want)
+			}
+
+			insidersPath, err := GetVSCodeSettingsPathForVariant(VSCodeInsiders)
+			if err != nil {
+				t.Fatalf("GetVSCodeSettingsPathForVariant() error = %v", err)
+			}
+			if want := filepath.Join(vscodeUserDir(tempDir, platform, VSCodeInsiders), "settings.json"); insidersPath != want {
+				t.Errorf("GetVSCodeSettingsPathForVariant() = %v, expected %v", insidersPath, want)
+			}
+		})
+	}
+}
+
+// TestFileManagerCommand 测试各平台的文件管理器启动命令.
+func TestFileManagerCommand(t *testing.T) {
+	tests := []struct {
+		platform Platform
+		expected string
+	}{
+		{PlatformWindows, "explorer"},
+		{PlatformMac, "open"},
+		{PlatformLinux, "xdg-open"},
+	}
+
+	for _, tt := range tests {
+		if got := fileManagerCommand(tt.platform); got != tt.expected {
+			t.Errorf("fileManagerCommand(%v) = %q, expected %q", tt.platform, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvChangeTarget 表示变化发生在用户环境变量（而非配置文件）中.
+const EnvChangeTarget = "环境变量"
+
+// ConfigChange 应用镜像源时某个配置项的预期变化，用于在写入前预览.
+type ConfigChange struct {
+	File   string `json:"file"`
+	Key    string `json:"key"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+	Secret bool   `json:"secret"` // 值为密钥，展示时应掩码
+}
+
+// Changed 返回该配置项的值是否会改变.
+func (c ConfigChange) Changed() bool {
+	return c.Old != c.New
+}
+
+// IsSecretEnvKey 判断环境变量名（或请求头名）是否表示密钥类的值.
+func IsSecretEnvKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "AUTHORIZATION", "COOKIE"} {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanEnvVars 比较当前进程环境与即将设置的环境变量，值为空表示该变量将被清除.
+func PlanEnvVars(vars map[string]string) []ConfigChange {
+	current := make(map[string]string, len(vars))
+	for key := range vars {
+		current[key] = os.Getenv(key)
+	}
+	return diffEnvMaps(EnvChangeTarget, "", current, vars)
+}
+
+// diffEnvMaps 比较两组环境变量，按变量名排序返回所有涉及的配置项（包括未变化的项）.
+func diffEnvMaps(file, prefix string, oldEnv, newEnv map[string]string) []ConfigChange {
+	keys := make([]string, 0, len(oldEnv)+len(newEnv))
+	seen := make(map[string]bool, len(oldEnv)+len(newEnv))
+	for _, env := range []map[string]string{oldEnv, newEnv} {
+		for key := range env {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]ConfigChange, 0, len(keys))
+	for _, key := range keys {
+		changes = append(changes, ConfigChange{
+			File:   file,
+			Key:    prefix + key,
+			Old:    oldEnv[key],
+			New:    newEnv[key],
+			Secret: IsSecretEnvKey(key),
+		})
+	}
+	return changes
+}
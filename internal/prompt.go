@@ -13,14 +13,9 @@ func PromptFieldChoice(conflict FieldConflict, currentIndex, totalCount int) (st
 	fmt.Printf("\n冲突 %d/%d: %s\n", currentIndex, totalCount, conflict.FieldName)
 	fmt.Printf("────────────────────────────────────────\n")
 
-	localDisplay := conflict.LocalValue
-	remoteDisplay := conflict.RemoteValue
-
-	// 如果是 APIKey 字段，遮蔽显示
-	if conflict.FieldName == FieldNameAPIKey {
-		localDisplay = maskAPIKeyDisplay(conflict.LocalValue)
-		remoteDisplay = maskAPIKeyDisplay(conflict.RemoteValue)
-	}
+	// 密钥类字段只显示掩码，选择结果仍返回原始值
+	localDisplay := DisplayFieldValue(conflict.FieldName, conflict.LocalValue)
+	remoteDisplay := DisplayFieldValue(conflict.FieldName, conflict.RemoteValue)
 
 	fmt.Printf("  本地值:  %s\n", localDisplay)
 	if !conflict.LocalTime.IsZero() {
@@ -77,6 +72,51 @@ func PromptFieldChoice(conflict FieldConflict, currentIndex, totalCount int) (st
 	}
 }
 
+// PromptCurrentMirrorChoice 当前激活镜像源冲突时，询问用户保留哪台设备的选择.
+// 返回用户选择的镜像源名称；无效输入返回空字符串，由调用方回退到默认镜像源.
+func PromptCurrentMirrorChoice(toolType ToolType, localName, remoteName, remoteDevice string) string {
+	fmt.Printf("\n⚠️  当前 %s 镜像源冲突\n", toolType)
+	fmt.Printf("────────────────────────────────────────\n")
+	fmt.Printf("  本地:  %s (本设备)\n", localName)
+	if remoteDevice != "" {
+		fmt.Printf("  远程:  %s (设备: %s)\n", remoteName, remoteDevice)
+	} else {
+		fmt.Printf("  远程:  %s\n", remoteName)
+	}
+	fmt.Println()
+
+	fmt.Printf("选择要使用的镜像源:\n")
+	fmt.Printf("  [1] 本地 (%s)\n", localName)
+	fmt.Printf("  [2] 远程 (%s)\n", remoteName)
+	fmt.Printf("  [3] 输入其他镜像源名称\n")
+	fmt.Printf("  [s] 跳过（保持本地）\n")
+	fmt.Printf("\n您的选择: ")
+
+	// 同一个 reader 读取选择和后续输入，避免缓冲吞掉下一行
+	reader := bufio.NewReader(os.Stdin)
+	choice, err := reader.ReadString('\n')
+	if err != nil && choice == "" {
+		return localName
+	}
+
+	switch strings.ToLower(strings.TrimSpace(choice)) {
+	case "1", "s", "":
+		return localName
+	case "2":
+		return remoteName
+	case "3":
+		fmt.Printf("请输入镜像源名称: ")
+		name, err := reader.ReadString('\n')
+		if err != nil && name == "" {
+			return ""
+		}
+		return strings.TrimSpace(name)
+	default:
+		fmt.Printf("⚠️  无效选择\n")
+		return ""
+	}
+}
+
 // promptManualInput 提示用户手动输入值.
 func promptManualInput(fieldName string) (string, error) {
 	fmt.Printf("请输入新的 %s 值: ", fieldName)
@@ -106,10 +146,7 @@ func ShowMergeResult(mirrorName string, resolutions []FieldResolution) {
 	fmt.Printf("────────────────────────────────────────\n")
 
 	for _, res := range resolutions {
-		displayValue := res.ResolvedValue
-		if res.FieldName == FieldNameAPIKey {
-			displayValue = maskAPIKeyDisplay(res.ResolvedValue)
-		}
+		displayValue := DisplayFieldValue(res.FieldName, res.ResolvedValue)
 
 		choiceLabel := ""
 		switch res.Choice {
@@ -158,10 +195,24 @@ func maskAPIKeyDisplay(apiKey string) string {
 	if apiKey == "" {
 		return "(空)"
 	}
-	if len(apiKey) <= 8 {
-		return "****"
+	return MaskAPIKey(apiKey)
+}
+
+// DisplayFieldValue 返回冲突字段用于展示的值：API 密钥和密钥类的额外环境变量掩码，地址中的密码隐藏.
+// 只用于输出，冲突解决始终使用原始值.
+func DisplayFieldValue(fieldName, value string) string {
+	switch {
+	case fieldName == FieldNameAPIKey:
+		return maskAPIKeyDisplay(value)
+	case strings.HasPrefix(fieldName, FieldNameExtraEnvPrefix) && IsSecretEnvKey(strings.TrimPrefix(fieldName, FieldNameExtraEnvPrefix)):
+		return maskAPIKeyDisplay(value)
+	case strings.HasPrefix(fieldName, FieldNameTestHeadersPrefix) && IsSecretEnvKey(strings.TrimPrefix(fieldName, FieldNameTestHeadersPrefix)):
+		return maskAPIKeyDisplay(value)
+	case fieldName == FieldNameBaseURL || fieldName == FieldNameProxy:
+		return RedactURL(value)
+	default:
+		return value
 	}
-	return apiKey[:4] + "****" + apiKey[len(apiKey)-4:]
 }
 
 // PrintConflictHeader 打印冲突解决的标题.
@@ -173,9 +224,5 @@ func PrintConflictHeader(mirrorName string, conflictCount int) {
 
 // PrintAutoMergeInfo 打印自动合并信息.
 func PrintAutoMergeInfo(fieldName, value, reason string) {
-	displayValue := value
-	if fieldName == FieldNameAPIKey {
-		displayValue = maskAPIKeyDisplay(value)
-	}
-	fmt.Printf("🔄 自动合并 %s: %s (%s)\n", fieldName, displayValue, reason)
+	fmt.Printf("🔄 自动合并 %s: %s (%s)\n", fieldName, DisplayFieldValue(fieldName, value), reason)
 }
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// apiKeyCommandTimeout 执行 API 密钥命令的超时时间，密码管理器可能需要等待用户解锁.
+const apiKeyCommandTimeout = 30 * time.Second
+
+// HasAPIKey 判断镜像源是否配置了 API 密钥（直接保存的密钥或获取密钥的命令）.
+func (m *MirrorConfig) HasAPIKey() bool {
+	return m.APIKey != "" || strings.TrimSpace(m.APIKeyCommand) != ""
+}
+
+// ResolveMirrorAPIKey 返回填入实际 API 密钥的镜像源副本，用于应用配置和连通性测试.
+// 配置了 APIKeyCommand 时执行该命令，以标准输出（去掉首尾空白）作为密钥，副本的 APIKeyCommand 被清空，
+// 重复调用不会再次执行命令；未配置时直接返回原镜像源.
+// 副本包含明文密钥，不能写回 mirrors.toml 或同步数据.
+func ResolveMirrorAPIKey(mirror *MirrorConfig) (*MirrorConfig, error) {
+	command := strings.TrimSpace(mirror.APIKeyCommand)
+	if command == "" {
+		return mirror, nil
+	}
+
+	key, err := runAPIKeyCommand(command)
+	if err != nil {
+		return nil, fmt.Errorf("获取镜像源 '%s' 的 API 密钥失败: %w", mirror.Name, err)
+	}
+
+	resolved := cloneMirror(mirror)
+	resolved.APIKey = key
+	resolved.APIKeyCommand = ""
+	return resolved, nil
+}
+
+// runAPIKeyCommand 通过系统 shell 执行命令并返回标准输出中的密钥.
+func runAPIKeyCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("密钥命令执行超时 (%s)", apiKeyCommandTimeout)
+		}
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return "", fmt.Errorf("密钥命令执行失败: %v: %s", err, detail)
+		}
+		return "", fmt.Errorf("密钥命令执行失败: %v", err)
+	}
+
+	key := strings.TrimSpace(stdout.String())
+	if key == "" {
+		return "", fmt.Errorf("密钥命令没有输出任何内容")
+	}
+	return key, nil
+}
+
+// SetMirrorAPIKeyCommand 设置获取镜像源 API 密钥的命令，空字符串表示清除.
+// 设置命令后清空保存的 APIKey，密钥只在应用和测试时通过命令获取.
+func (mm *MirrorManager) SetMirrorAPIKeyCommand(name, command string) error {
+	mirror := mm.findActiveMirror(name)
+	if mirror == nil {
+		return mirrorNotFoundError("镜像源 '%s' 不存在", name)
+	}
+
+	mirror.APIKeyCommand = strings.TrimSpace(command)
+	if mirror.APIKeyCommand != "" {
+		mirror.APIKey = ""
+	}
+	mirror.LastModified = time.Now()
+	return mm.saveConfig()
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestResolveMirrorAPIKey 测试通过命令获取 API 密钥.
+func TestResolveMirrorAPIKey(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("测试命令依赖 sh")
+	}
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		command string
+		want    string
+		wantErr string
+	}{
+		{name: "未设置命令", apiKey: "sk-stored", want: "sk-stored"},
+		{name: "读取标准输出", command: "echo '  sk-from-command  '", want: "sk-from-command"},
+		{name: "命令失败", command: "echo vault locked >&2; exit 3", wantErr: "vault locked"},
+		{name: "没有输出", command: "printf ''", wantErr: "没有输出"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mirror := &MirrorConfig{Name: "secret", APIKey: tt.apiKey, APIKeyCommand: tt.command}
+			got, err := ResolveMirrorAPIKey(mirror)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveMirrorAPIKey() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveMirrorAPIKey() error = %v", err)
+			}
+			if got.APIKey != tt.want {
+				t.Errorf("APIKey = %q, want %q", got.APIKey, tt.want)
+			}
+			if got.APIKeyCommand != "" {
+				t.Errorf("解析后的副本不应保留密钥命令")
+			}
+			if mirror.APIKey != tt.apiKey || mirror.APIKeyCommand != tt.command {
+				t.Errorf("不应修改原镜像源配置")
+			}
+		})
+	}
+}
+
+// TestAPIKeyCommandNotPersisted 测试通过命令获取的密钥写入工具配置，但不保存到 mirrors.toml.
+func TestAPIKeyCommandNotPersisted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("测试命令依赖 sh")
+	}
+
+	tempDir := setupTestDir(t)
+	mm := createTestMirrorManager(t, tempDir)
+
+	if err := mm.AddMirrorWithType("vault-claude", "https://claude.test.com", "sk-stored", ToolTypeClaude); err != nil {
+		t.Fatalf("添加测试镜像源失败: %v", err)
+	}
+	if err := mm.SetMirrorAPIKeyCommand("vault-claude", "printf sk-%s from-vault"); err != nil {
+		t.Fatalf("设置密钥命令失败: %v", err)
+	}
+	if err := mm.SwitchMirror("vault-claude"); err != nil {
+		t.Fatalf("切换镜像源失败: %v", err)
+	}
+	if err := mm.ReapplyCurrentMirrors(); err != nil {
+		t.Fatalf("ReapplyCurrentMirrors() error = %v", err)
+	}
+
+	settings, err := os.ReadFile(filepath.Join(tempDir, ".claude", "settings.json"))
+	if err != nil {
+		t.Fatalf("读取 Claude 配置失败: %v", err)
+	}
+	if !strings.Contains(string(settings), "sk-from-vault") {
+		t.Errorf("Claude 配置未包含命令获取的密钥:\n%s", settings)
+	}
+
+	config, err := os.ReadFile(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("读取镜像源配置失败: %v", err)
+	}
+	if strings.Contains(string(config), "sk-from-vault") || strings.Contains(string(config), "sk-stored") {
+		t.Errorf("mirrors.toml 不应包含密钥:\n%s", config)
+	}
+	if !strings.Contains(string(config), `api_key_command = "printf sk-%s from-vault"`) {
+		t.Errorf("mirrors.toml 应保存密钥命令:\n%s", config)
+	}
+}
+
+// TestConnectivityAPIKeyCommand 测试连通性测试使用命令获取的密钥.
+func TestConnectivityAPIKeyCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("测试命令依赖 sh")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-from-vault" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		command      string
+		wantSuccess  bool
+		wantCategory TestCategory
+	}{
+		{name: "命令成功", command: "echo sk-from-vault", wantSuccess: true, wantCategory: TestCategoryOK},
+		{name: "命令失败", command: "exit 1", wantCategory: TestCategoryKeyCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mirror := &MirrorConfig{Name: "vault", BaseURL: server.URL, ToolType: ToolTypeCodex, APIKeyCommand: tt.command}
+			result := (&ConnectivityTester{}).Test(mirror, 5, 0)
+			if result.Success != tt.wantSuccess || result.Category != tt.wantCategory {
+				t.Errorf("Test() = success %v, category %q (%s), want %v, %q", result.Success, result.Category, result.Error, tt.wantSuccess, tt.wantCategory)
+			}
+			if !result.HasAPIKey {
+				t.Errorf("配置了密钥命令时 HasAPIKey 应为 true")
+			}
+		})
+	}
+}
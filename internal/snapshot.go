@@ -0,0 +1,203 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// switchSnapshotPrefix 切换前快照目录的名称前缀.
+const switchSnapshotPrefix = "switch"
+
+// snapshotManifestName 快照目录中记录原始文件路径的清单文件名.
+const snapshotManifestName = "manifest.json"
+
+// SnapshotInfo 切换前快照的信息.
+type SnapshotInfo struct {
+	Name      string            `json:"name"`
+	Path      string            `json:"path"`
+	CreatedAt time.Time         `json:"created_at"`
+	Files     map[string]string `json:"files"` // 快照内文件名 -> 原始文件路径
+}
+
+// snapshotManifest 快照清单文件结构.
+type snapshotManifest struct {
+	CreatedAt time.Time         `json:"created_at"`
+	Files     map[string]string `json:"files"`
+}
+
+// CreateSwitchSnapshot 将切换会修改的目标文件一并复制到带时间戳的快照目录，返回快照目录路径.
+// 不存在的目标文件会被跳过；最多保留 defaultBackupKeepCount 个快照.
+func (mm *MirrorManager) CreateSwitchSnapshot(targets []ManagedPath) (string, error) {
+	now := time.Now()
+	snapshotDir, err := createUniqueDir(mm.GetBackupDir(), fmt.Sprintf("%s-%s", switchSnapshotPrefix, now.Format(backupTimestampFormat)))
+	if err != nil {
+		return "", fmt.Errorf("创建快照目录失败: %w", err)
+	}
+
+	manifest := snapshotManifest{CreatedAt: now, Files: make(map[string]string)}
+	for _, target := range targets {
+		if _, err := os.Stat(target.Path); err != nil {
+			continue
+		}
+		fileName := target.Name + filepath.Ext(target.Path)
+		if err := copyFile(target.Path, filepath.Join(snapshotDir, fileName)); err != nil {
+			_ = os.RemoveAll(snapshotDir)
+			return "", fmt.Errorf("备份 %s 失败: %w", target.Path, err)
+		}
+		manifest.Files[fileName] = target.Path
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		_ = os.RemoveAll(snapshotDir)
+		return "", fmt.Errorf("序列化快照清单失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(snapshotDir, snapshotManifestName), data, 0o600); err != nil {
+		_ = os.RemoveAll(snapshotDir)
+		return "", fmt.Errorf("写入快照清单失败: %w", err)
+	}
+
+	pruneSnapshots(mm.GetBackupDir(), defaultBackupKeepCount)
+	return snapshotDir, nil
+}
+
+// ListSwitchSnapshots 列出所有切换前快照，按时间从新到旧排序.
+func (mm *MirrorManager) ListSwitchSnapshots() ([]SnapshotInfo, error) {
+	backupDir := mm.GetBackupDir()
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	var snapshots []SnapshotInfo
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), switchSnapshotPrefix+"-") {
+			continue
+		}
+		info, err := readSnapshot(filepath.Join(backupDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, *info)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].Name > snapshots[j].Name
+		}
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// RestoreSwitchSnapshot 将快照中的所有文件恢复到原始位置；name 可以是快照目录名或完整路径.
+// 恢复的文件包含 mirrors.toml 时会同时重新加载镜像源配置.
+func (mm *MirrorManager) RestoreSwitchSnapshot(name string) error {
+	snapshotDir := name
+	if !filepath.IsAbs(snapshotDir) {
+		snapshotDir = filepath.Join(mm.GetBackupDir(), name)
+	}
+
+	info, err := readSnapshot(snapshotDir)
+	if err != nil {
+		return err
+	}
+
+	for fileName, target := range info.Files {
+		data, err := os.ReadFile(filepath.Join(snapshotDir, fileName))
+		if err != nil {
+			return fmt.Errorf("读取快照文件 %s 失败: %w", fileName, err)
+		}
+		if err := WriteFileAtomic(target, 0o600, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		}); err != nil {
+			return fmt.Errorf("恢复 %s 失败: %w", target, err)
+		}
+
+		if target == mm.configPath {
+			restored := &SystemConfig{}
+			if _, err := toml.Decode(string(data), restored); err != nil {
+				return fmt.Errorf("快照中的镜像源配置无效: %w", err)
+			}
+			mm.config = restored
+			mm.migrateSyncProfiles()
+		}
+	}
+
+	return nil
+}
+
+// readSnapshot 读取快照目录的清单.
+func readSnapshot(snapshotDir string) (*SnapshotInfo, error) {
+	data, err := os.ReadFile(filepath.Join(snapshotDir, snapshotManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("读取快照清单失败: %w", err)
+	}
+
+	var manifest snapshotManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("解析快照清单失败: %w", err)
+	}
+
+	return &SnapshotInfo{
+		Name:      filepath.Base(snapshotDir),
+		Path:      snapshotDir,
+		CreatedAt: manifest.CreatedAt,
+		Files:     manifest.Files,
+	}, nil
+}
+
+// createUniqueDir 在 parent 下创建名为 name 的目录，同名目录已存在时追加序号.
+func createUniqueDir(parent, name string) (string, error) {
+	if err := EnsureDir(parent); err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(parent, name)
+	for i := 2; ; i++ {
+		err := os.Mkdir(dir, 0o700)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+		dir = filepath.Join(parent, fmt.Sprintf("%s-%d", name, i))
+	}
+}
+
+// pruneSnapshots 清理旧的切换快照，只保留最新的 keepCount 个.
+func pruneSnapshots(backupDir string, keepCount int) {
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		return
+	}
+
+	var snapshotDirs []string
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), switchSnapshotPrefix+"-") {
+			snapshotDirs = append(snapshotDirs, entry.Name())
+		}
+	}
+	if len(snapshotDirs) <= keepCount {
+		return
+	}
+
+	// 时间戳格式保证字典序等于时间序
+	sort.Strings(snapshotDirs)
+	for _, name := range snapshotDirs[:len(snapshotDirs)-keepCount] {
+		_ = os.RemoveAll(filepath.Join(backupDir, name))
+	}
+}
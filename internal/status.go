@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"os"
+)
+
+// ConfigStatus 单个目标配置文件的状态.
+type ConfigStatus struct {
+	Exists bool   `json:"exists"`
+	Path   string `json:"path"`
+	Error  string `json:"error,omitempty"`
+}
+
+// StatusDTO 当前配置状态的完整快照，CLI 的 status --json 和 GUI 共用.
+type StatusDTO struct {
+	CurrentCodex  string       `json:"current_codex"`
+	CurrentClaude string       `json:"current_claude"`
+	CodexStatus   ConfigStatus `json:"codex_status"`
+	ClaudeStatus  ConfigStatus `json:"claude_status"`
+	VSCodeStatus  ConfigStatus `json:"vscode_status"`
+	ConfigPath    string       `json:"config_path"`
+	// 默认同步配置的状态，获取失败时为空
+	Sync *SyncStatus `json:"sync,omitempty"`
+}
+
+// Status 汇总当前激活的镜像源、各目标配置文件是否存在以及云同步状态.
+func (mm *MirrorManager) Status() StatusDTO {
+	status := StatusDTO{
+		CurrentCodex:  mm.config.CurrentCodex,
+		CurrentClaude: mm.config.CurrentClaude,
+		CodexStatus:   configFileStatus(GetCodexConfigPath),
+		ClaudeStatus:  configFileStatus(GetClaudeSettingsPath),
+		VSCodeStatus:  configFileStatus(GetVSCodeSettingsPath),
+		ConfigPath:    mm.GetConfigPath(),
+	}
+
+	if syncStatus, err := NewSyncManager(mm).GetStatus(); err == nil {
+		status.Sync = syncStatus
+	}
+
+	return status
+}
+
+// configFileStatus 检查配置文件是否存在，无法确定路径时记录错误.
+func configFileStatus(resolve func() (string, error)) ConfigStatus {
+	path, err := resolve()
+	if err != nil {
+		return ConfigStatus{Error: err.Error()}
+	}
+	_, statErr := os.Stat(path)
+	return ConfigStatus{Exists: statErr == nil, Path: path}
+}
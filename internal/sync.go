@@ -4,21 +4,54 @@ import (
 	"bufio"
 	"crypto/md5"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 )
 
+// syncDataVersion 同步数据格式版本（3.2 起使用覆盖完整数据的 SHA-256 完整性校验）.
+const syncDataVersion = "3.2"
+
+// integrityAlgorithmSHA256 完整性校验值使用的算法标识.
+const integrityAlgorithmSHA256 = "sha256"
+
+// 同步进度阶段.
+const (
+	SyncStageBackup   = "backup"   // 备份本地配置
+	SyncStageDownload = "download" // 下载云端配置
+	SyncStageConflict = "conflict" // 检测冲突
+	SyncStageUpload   = "upload"   // 上传配置
+	SyncStageApply    = "apply"    // 应用配置
+	SyncStageDone     = "done"     // 同步完成
+)
+
+// SyncProgress 同步进度.
+type SyncProgress struct {
+	Stage   string `json:"stage"`
+	Message string `json:"message"`
+	Percent int    `json:"percent"`
+}
+
 // SyncManager 云同步管理器.
 type SyncManager struct {
 	mirrorManager *MirrorManager
 	provider      SyncProvider
 	config        *SyncConfig
 	crypto        *CryptoManager // 加密管理器
+	// profile 使用的同步配置名，空值表示默认配置
+	profile string
+	// nonInteractive 非交互模式：不读取标准输入，字段冲突按修改时间自动选择
+	nonInteractive bool
+	// onProgress 同步进度回调，onConflicts 检测到冲突时的回调，均可为空
+	onProgress  func(SyncProgress)
+	onConflicts func(*ConflictResolution)
 }
 
 // NewSyncManager 创建新的同步管理器.
@@ -28,6 +61,45 @@ func NewSyncManager(mirrorManager *MirrorManager) *SyncManager {
 	}
 }
 
+// SetProfile 设置使用的同步配置名，空名称表示默认配置.
+func (sm *SyncManager) SetProfile(name string) {
+	sm.profile = name
+}
+
+// Profile 返回使用的同步配置名.
+func (sm *SyncManager) Profile() string {
+	return normalizeSyncProfile(sm.profile)
+}
+
+// SetInteractive 设置冲突解决时是否允许从标准输入询问用户（GUI 等场景应关闭）.
+func (sm *SyncManager) SetInteractive(interactive bool) {
+	sm.nonInteractive = !interactive
+}
+
+// SetProgressHandler 设置同步进度回调（如 GUI 展示进度条）.
+func (sm *SyncManager) SetProgressHandler(handler func(SyncProgress)) {
+	sm.onProgress = handler
+}
+
+// SetConflictHandler 设置检测到冲突时的回调.
+func (sm *SyncManager) SetConflictHandler(handler func(*ConflictResolution)) {
+	sm.onConflicts = handler
+}
+
+// reportProgress 报告同步进度，未设置回调时不做任何操作.
+func (sm *SyncManager) reportProgress(stage, message string, percent int) {
+	if sm.onProgress != nil {
+		sm.onProgress(SyncProgress{Stage: stage, Message: message, Percent: percent})
+	}
+}
+
+// reportConflicts 报告检测到的冲突，未设置回调时不做任何操作.
+func (sm *SyncManager) reportConflicts(conflicts *ConflictResolution) {
+	if sm.onConflicts != nil {
+		sm.onConflicts(conflicts)
+	}
+}
+
 // InitSync 初始化云同步.
 func (sm *SyncManager) InitSync(providerType, endpoint, token string) error {
 	return sm.InitSyncWithOptions(providerType, endpoint, token, false)
@@ -41,7 +113,11 @@ func (sm *SyncManager) InitSyncWithPassword(providerType, endpoint, token, passw
 // InitSyncWithPasswordAndGist 使用密码和可选的Gist ID初始化云同步.
 func (sm *SyncManager) InitSyncWithPasswordAndGist(providerType, endpoint, token, password, gistID string) error {
 	// 生成设备ID
-	deviceID := generateDeviceID()
+	deviceUUID, err := newDeviceUUID()
+	if err != nil {
+		return fmt.Errorf("生成设备标识失败: %w", err)
+	}
+	deviceID := generateDeviceID(deviceUUID)
 
 	// 创建同步配置
 	syncConfig := &SyncConfig{
@@ -53,6 +129,7 @@ func (sm *SyncManager) InitSyncWithPasswordAndGist(providerType, endpoint, token
 		AutoSync:      false,
 		SyncInterval:  30,
 		DeviceID:      deviceID,
+		DeviceUUID:    deviceUUID,
 		LastSync:      time.Time{},
 		SyncAPIKeys:   true,     // 默认总是同步API密钥
 		EncryptionPwd: password, // 使用用户提供的密码
@@ -69,36 +146,36 @@ func (sm *SyncManager) InitSyncWithPasswordAndGist(providerType, endpoint, token
 	sm.provider = provider
 
 	// 如果提供商自动发现了Gist ID，更新配置
-	if gistProvider, ok := provider.(*GistProvider); ok {
+	if gistProvider, ok := provider.(gistIDProvider); ok {
 		if discoveredID := gistProvider.GetGistID(); discoveredID != "" && syncConfig.GistID == "" {
 			syncConfig.GistID = discoveredID
-			fmt.Printf("🔍 自动发现现有配置 Gist: %s\n", discoveredID)
+			LogInfof("🔍 自动发现现有配置 Gist: %s\n", discoveredID)
 
 			// 验证密码是否正确
 			if err := sm.validatePassword(); err != nil {
 				return fmt.Errorf("密码验证失败: %w\n\n💡 可能原因:\n   - 密码输入错误\n   - 此Gist使用了不同的密码\n\n🔧 解决方法:\n   - 检查密码是否正确\n   - 或使用 --gist-id 参数指定新的Gist", err)
 			}
-			fmt.Printf("✅ 密码验证成功，可以正常同步现有配置\n")
+			LogInfof("✅ 密码验证成功，可以正常同步现有配置\n")
 		}
 	}
 
 	// 保存同步配置到系统配置
-	sm.mirrorManager.config.Sync = syncConfig
+	sm.mirrorManager.setSyncProfile(sm.profile, syncConfig)
 	if err := sm.mirrorManager.saveConfig(); err != nil {
 		return fmt.Errorf("保存同步配置失败: %w", err)
 	}
 
-	fmt.Printf("✅ 云同步初始化成功\n")
-	fmt.Printf("   提供商: %s\n", providerType)
-	fmt.Printf("   设备ID: %s\n", deviceID)
-	fmt.Printf("   端点: %s\n", endpoint)
-	fmt.Printf("   全量同步: 启用\n")
+	LogInfof("✅ 云同步初始化成功\n")
+	LogInfof("   提供商: %s\n", providerType)
+	LogInfof("   设备ID: %s\n", deviceID)
+	LogInfof("   端点: %s\n", endpoint)
+	LogInfof("   全量同步: 启用\n")
 
 	if syncConfig.GistID != "" {
-		fmt.Printf("   Gist ID: %s\n", syncConfig.GistID)
-		fmt.Printf("   💡 可以直接使用 'codex-mirror sync pull' 拉取现有配置\n")
+		LogInfof("   Gist ID: %s\n", syncConfig.GistID)
+		LogInfof("   💡 可以直接使用 'codex-mirror sync pull' 拉取现有配置\n")
 	} else {
-		fmt.Printf("   💡 使用 'codex-mirror sync push' 创建新的云端配置\n")
+		LogInfof("   💡 使用 'codex-mirror sync push' 创建新的云端配置\n")
 	}
 
 	return nil
@@ -107,7 +184,11 @@ func (sm *SyncManager) InitSyncWithPasswordAndGist(providerType, endpoint, token
 // InitSyncWithOptions 初始化云同步（带选项）- 保持向后兼容.
 func (sm *SyncManager) InitSyncWithOptions(providerType, endpoint, token string, syncAPIKeys bool) error {
 	// 生成设备ID
-	deviceID := generateDeviceID()
+	deviceUUID, err := newDeviceUUID()
+	if err != nil {
+		return fmt.Errorf("生成设备标识失败: %w", err)
+	}
+	deviceID := generateDeviceID(deviceUUID)
 
 	// 生成加密密钥
 	encryptKey, err := generateEncryptKey()
@@ -125,6 +206,7 @@ func (sm *SyncManager) InitSyncWithOptions(providerType, endpoint, token string,
 		AutoSync:     false,
 		SyncInterval: 30,
 		DeviceID:     deviceID,
+		DeviceUUID:   deviceUUID,
 		LastSync:     time.Time{},
 		SyncAPIKeys:  syncAPIKeys,
 	}
@@ -139,19 +221,19 @@ func (sm *SyncManager) InitSyncWithOptions(providerType, endpoint, token string,
 	sm.provider = provider
 
 	// 保存同步配置到系统配置
-	sm.mirrorManager.config.Sync = syncConfig
+	sm.mirrorManager.setSyncProfile(sm.profile, syncConfig)
 	if err := sm.mirrorManager.saveConfig(); err != nil {
 		return fmt.Errorf("保存同步配置失败: %w", err)
 	}
 
-	fmt.Printf("✅ 云同步初始化成功\n")
-	fmt.Printf("   提供商: %s\n", providerType)
-	fmt.Printf("   设备ID: %s\n", deviceID)
-	fmt.Printf("   端点: %s\n", endpoint)
+	LogInfof("✅ 云同步初始化成功\n")
+	LogInfof("   提供商: %s\n", providerType)
+	LogInfof("   设备ID: %s\n", deviceID)
+	LogInfof("   端点: %s\n", endpoint)
 	if syncAPIKeys {
-		fmt.Printf("   API密钥同步: 是\n")
+		LogInfof("   API密钥同步: 是\n")
 	} else {
-		fmt.Printf("   API密钥同步: 否\n")
+		LogInfof("   API密钥同步: 否\n")
 	}
 
 	return nil
@@ -159,11 +241,17 @@ func (sm *SyncManager) InitSyncWithOptions(providerType, endpoint, token string,
 
 // LoadSync 加载同步配置.
 func (sm *SyncManager) LoadSync() error {
-	if sm.mirrorManager.config.Sync == nil {
-		return fmt.Errorf("未配置云同步")
+	config := sm.mirrorManager.GetSyncProfile(sm.profile)
+	if config == nil {
+		return fmt.Errorf("未配置云同步 (配置: %s)", sm.Profile())
 	}
 
-	sm.config = sm.mirrorManager.config.Sync
+	sm.config = config
+
+	// 迁移旧版仅基于主机名的设备ID
+	if err := sm.ensureDeviceID(); err != nil {
+		return err
+	}
 
 	// 创建加密管理器
 	if sm.config.EncryptionPwd != "" {
@@ -185,58 +273,133 @@ func (sm *SyncManager) Push() error {
 	return sm.PushWithStrategy("auto")
 }
 
-// PushWithStrategy 使用指定策略推送配置到云端.
+// PushWithStrategy 使用指定策略推送配置到云端，并记录本次同步的结果.
 func (sm *SyncManager) PushWithStrategy(strategy string) error {
+	return sm.recordSyncResult(SyncDirectionPush, strategy, sm.pushWithStrategy(strategy))
+}
+
+// pushWithStrategy 执行推送.
+func (sm *SyncManager) pushWithStrategy(strategy string) error {
 	if err := sm.LoadSync(); err != nil {
 		return err
 	}
 
 	// 推送前自动备份
+	sm.reportProgress(SyncStageBackup, "正在备份本地配置", 10)
 	if err := sm.createBackupWithPrefix("pre-push"); err != nil {
-		fmt.Printf("⚠️  创建备份失败: %v（继续推送）\n", err)
+		LogWarnf("⚠️  创建备份失败: %v（继续推送）\n", err)
 	}
 
-	fmt.Printf("📤 正在推送配置到云端...\n")
+	LogInfof("📤 正在推送配置到云端...\n")
 
 	// 首先检查是否存在云端配置，如果存在则进行冲突检查
 	filename := ConfigFileName
-	if encryptedRemoteData, err := sm.provider.Download(filename); err == nil {
-		fmt.Printf("🔍 检查云端配置冲突...\n")
+	sm.reportProgress(SyncStageDownload, "正在检查云端配置", 30)
+	encryptedRemoteData, baseRevision, err := sm.downloadWithRevision(filename)
+	if err == nil {
+		LogInfof("🔍 检查云端配置冲突...\n")
 		// 解密远程数据
 		if remoteData, err := sm.decryptData(encryptedRemoteData); err == nil {
 			var remoteSyncData SyncData
 			if err := json.Unmarshal(remoteData, &remoteSyncData); err == nil {
+				sm.recordRemoteWriter(&remoteSyncData)
+
 				// 解密所有远程镜像源的 APIKey（在冲突检测之前）
 				if err := sm.decryptSyncDataAPIKeys(&remoteSyncData); err != nil {
-					fmt.Printf("⚠️  解密远程 API 密钥失败: %v（继续推送）\n", err)
+					LogWarnf("⚠️  解密远程 API 密钥失败: %v（继续推送）\n", err)
 				}
 
 				// 检测冲突
-				resolver := NewConflictResolver(sm.mirrorManager.config, &remoteSyncData)
-				resolver.SetCryptoManager(sm.crypto) // 设置加密管理器，用于解密可能遗漏的 APIKey
+				sm.reportProgress(SyncStageConflict, "正在检测配置冲突", 50)
+				resolver := sm.newConflictResolver(&remoteSyncData)
 				conflicts := resolver.DetectConflicts()
 
-				if len(conflicts.Conflicts) > 0 {
+				switch {
+				case len(conflicts.Conflicts) == 0:
+					LogInfof("✅ 无配置冲突，直接推送\n")
+				case strategy == "force":
+					LogWarnf("⚠️  检测到 %d 个冲突，强制推送将覆盖云端配置\n", len(conflicts.Conflicts))
+				default:
 					// 有冲突，根据策略处理
+					sm.reportConflicts(conflicts)
 					return sm.handlePushConflicts(resolver, conflicts, strategy, &remoteSyncData)
-				} else {
-					fmt.Printf("✅ 无配置冲突，直接推送\n")
 				}
 			}
 		}
 	} else {
-		fmt.Printf("💡 云端暂无配置，首次推送\n")
+		LogInfof("💡 云端暂无配置，首次推送\n")
+	}
+
+	// 没有冲突、强制推送或首次推送，直接上传
+	return sm.performPush(filename, baseRevision)
+}
+
+// performPush 执行实际的推送操作，baseRevision 为下载云端配置时记录的修订标识.
+func (sm *SyncManager) performPush(filename, baseRevision string) error {
+	return sm.uploadSyncData(filename, sm.exportSyncData(), baseRevision)
+}
+
+// remoteRevisionProvider 能提供云端文件修订标识的同步提供商（如 Gist 的 updated_at）.
+type remoteRevisionProvider interface {
+	Revision(filename string) (string, error)
+}
+
+// downloadWithRevision 下载云端文件并返回下载时的修订标识，云端不存在时修订标识为空.
+// 提供商支持修订标识时先读取修订标识再下载，否则使用下载内容的 SHA-256.
+func (sm *SyncManager) downloadWithRevision(filename string) ([]byte, string, error) {
+	if revisioner, ok := sm.provider.(remoteRevisionProvider); ok {
+		revision, err := revisioner.Revision(filename)
+		if err != nil {
+			return nil, "", err
+		}
+		data, err := sm.provider.Download(filename)
+		return data, revision, err
 	}
 
-	// 没有冲突或首次推送，直接上传
-	return sm.performPush(filename)
+	data, err := sm.provider.Download(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentRevision(data), nil
+}
+
+// remoteRevision 返回云端文件当前的修订标识，云端不存在时返回空字符串.
+func (sm *SyncManager) remoteRevision(filename string) (string, error) {
+	if revisioner, ok := sm.provider.(remoteRevisionProvider); ok {
+		return revisioner.Revision(filename)
+	}
+
+	data, err := sm.provider.Download(filename)
+	if errors.Is(err, ErrRemoteNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return contentRevision(data), nil
+}
+
+// ensureRemoteUnchanged 上传前重新读取云端修订标识，与下载时记录的不一致说明其他设备已推送，取消上传以免覆盖其修改.
+func (sm *SyncManager) ensureRemoteUnchanged(filename, baseRevision string) error {
+	current, err := sm.remoteRevision(filename)
+	if err != nil {
+		return fmt.Errorf("检查云端配置是否变化失败: %w", err)
+	}
+	if current != baseRevision {
+		return fmt.Errorf("%w，已取消推送以免覆盖其他设备的修改，请重新推送", ErrRemoteChanged)
+	}
+	return nil
 }
 
-// performPush 执行实际的推送操作.
-func (sm *SyncManager) performPush(filename string) error {
-	// 导出同步数据
-	syncData := sm.exportSyncData()
+// contentRevision 以内容的 SHA-256 作为修订标识.
+func contentRevision(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
 
+// uploadSyncData 加密并上传同步数据，成功后更新最后同步时间.
+// 上传前检查云端修订标识是否仍为 baseRevision，不一致时返回 ErrRemoteChanged.
+func (sm *SyncManager) uploadSyncData(filename string, syncData *SyncData, baseRevision string) error {
 	// 序列化数据
 	data, err := json.MarshalIndent(syncData, "", "  ")
 	if err != nil {
@@ -250,30 +413,35 @@ func (sm *SyncManager) performPush(filename string) error {
 	}
 
 	// 上传到云端
+	sm.reportProgress(SyncStageUpload, "正在上传配置", 70)
+	if err := sm.ensureRemoteUnchanged(filename, baseRevision); err != nil {
+		return err
+	}
 	if err := sm.provider.Upload(encryptedData, filename); err != nil {
 		return fmt.Errorf("上传配置失败: %w", err)
 	}
 
 	// 保存 Gist ID（如果是新创建的）
-	if gistProvider, ok := sm.provider.(*GistProvider); ok {
+	if gistProvider, ok := sm.provider.(gistIDProvider); ok {
 		if gistID := gistProvider.GetGistID(); gistID != "" && sm.config.GistID == "" {
 			sm.config.GistID = gistID
-			sm.mirrorManager.config.Sync.GistID = gistID
 		}
 	}
 
-	// 更新最后同步时间
+	// 更新最后同步时间，云端配置的最后写入者变为本设备
 	sm.config.LastSync = time.Now()
-	sm.mirrorManager.config.Sync = sm.config
+	sm.recordRemoteWriter(syncData)
+	sm.mirrorManager.setSyncProfile(sm.profile, sm.config)
 	if err := sm.mirrorManager.saveConfig(); err != nil {
 		return fmt.Errorf("保存同步时间失败: %w", err)
 	}
 
-	fmt.Printf("✅ 配置已推送到云端\n")
-	fmt.Printf("   文件: %s\n", filename)
-	fmt.Printf("   时间: %s\n", sm.config.LastSync.Format("2006-01-02 15:04:05"))
-	fmt.Printf("   镜像源数量: %d\n", len(sm.mirrorManager.config.Mirrors))
-	fmt.Printf("   数据已加密: 是\n")
+	sm.reportProgress(SyncStageDone, "配置已推送到云端", 100)
+	LogInfof("✅ 配置已推送到云端\n")
+	LogInfof("   文件: %s\n", filename)
+	LogInfof("   时间: %s\n", sm.config.LastSync.Format("2006-01-02 15:04:05"))
+	LogInfof("   镜像源数量: %d\n", len(syncData.Mirrors))
+	LogInfof("   数据已加密: 是\n")
 
 	return nil
 }
@@ -283,20 +451,27 @@ func (sm *SyncManager) Pull() error {
 	return sm.PullWithStrategy("auto")
 }
 
-// PullWithStrategy 使用指定策略从云端拉取配置.
+// PullWithStrategy 使用指定策略从云端拉取配置，并记录本次同步的结果.
 func (sm *SyncManager) PullWithStrategy(strategy string) error {
+	return sm.recordSyncResult(SyncDirectionPull, strategy, sm.pullWithStrategy(strategy))
+}
+
+// pullWithStrategy 执行拉取.
+func (sm *SyncManager) pullWithStrategy(strategy string) error {
 	if err := sm.LoadSync(); err != nil {
 		return err
 	}
 
 	// 拉取前自动备份
+	sm.reportProgress(SyncStageBackup, "正在备份本地配置", 10)
 	if err := sm.createBackupWithPrefix("pre-pull"); err != nil {
-		fmt.Printf("⚠️  创建备份失败: %v（继续拉取）\n", err)
+		LogWarnf("⚠️  创建备份失败: %v（继续拉取）\n", err)
 	}
 
 	// 直接使用标准配置文件名
 	filename := ConfigFileName
-	fmt.Printf("📥 正在从云端拉取配置...\n")
+	LogInfof("📥 正在从云端拉取配置...\n")
+	sm.reportProgress(SyncStageDownload, "正在下载云端配置", 30)
 
 	// 下载数据
 	encryptedData, err := sm.provider.Download(filename)
@@ -315,55 +490,90 @@ func (sm *SyncManager) PullWithStrategy(strategy string) error {
 	if err := json.Unmarshal(data, &syncData); err != nil {
 		return fmt.Errorf("解析同步数据失败: %w", err)
 	}
+	sm.recordRemoteWriter(&syncData)
 
-	// 在解密 APIKey 之前先校验校验和（校验和基于加密前的镜像数据）
-	if !syncData.ValidatedChecksum {
-		rawMirrorsData, _ := json.Marshal(syncData.Mirrors)
-		if calculateChecksum(rawMirrorsData) != syncData.Checksum {
-			return fmt.Errorf("数据校验和不匹配，可能数据已损坏")
-		}
-		syncData.ValidatedChecksum = true
-	}
-
-	// 解密所有远程镜像源的 APIKey（在冲突检测之前）
-	if err := sm.decryptSyncDataAPIKeys(&syncData); err != nil {
-		return fmt.Errorf("解密远程 API 密钥失败: %w", err)
+	if err := sm.verifyRemoteSyncData(&syncData); err != nil {
+		return err
 	}
 
 	// 检测冲突
-	fmt.Printf("🔍 检查配置冲突...\n")
-	resolver := NewConflictResolver(sm.mirrorManager.config, &syncData)
-	resolver.SetCryptoManager(sm.crypto) // 设置加密管理器，用于解密可能遗漏的 APIKey
+	LogInfof("🔍 检查配置冲突...\n")
+	sm.reportProgress(SyncStageConflict, "正在检测配置冲突", 50)
+	resolver := sm.newConflictResolver(&syncData)
 	conflicts := resolver.DetectConflicts()
 
 	if len(conflicts.Conflicts) > 0 {
 		// 有冲突，根据策略处理
+		sm.reportConflicts(conflicts)
 		return sm.handleConflicts(resolver, conflicts, strategy, &syncData)
 	} else {
-		fmt.Printf("✅ 无配置冲突，直接应用\n")
+		LogInfof("✅ 无配置冲突，直接应用\n")
 	}
 
 	// 没有冲突，直接应用
+	sm.reportProgress(SyncStageApply, "正在应用云端配置", 80)
 	if err := sm.applySyncData(&syncData); err != nil {
 		return fmt.Errorf("应用同步数据失败: %w", err)
 	}
 
 	// 更新最后同步时间
 	sm.config.LastSync = time.Now()
-	sm.mirrorManager.config.Sync = sm.config
+	sm.mirrorManager.setSyncProfile(sm.profile, sm.config)
 	if err := sm.mirrorManager.saveConfig(); err != nil {
 		return fmt.Errorf("保存同步时间失败: %w", err)
 	}
 
-	fmt.Printf("✅ 配置已从云端拉取并应用\n")
-	fmt.Printf("   来源设备: %s\n", syncData.DeviceID)
-	fmt.Printf("   配置时间: %s\n", syncData.Timestamp.Format("2006-01-02 15:04:05"))
-	fmt.Printf("   镜像源数量: %d\n", len(syncData.Mirrors))
-	fmt.Printf("   数据已解密: 是\n")
+	sm.reportProgress(SyncStageDone, "配置已从云端拉取并应用", 100)
+	LogInfof("✅ 配置已从云端拉取并应用\n")
+	LogInfof("   来源设备: %s\n", syncData.DeviceID)
+	LogInfof("   配置时间: %s\n", syncData.Timestamp.Format("2006-01-02 15:04:05"))
+	LogInfof("   镜像源数量: %d\n", len(syncData.Mirrors))
+	LogInfof("   数据已解密: 是\n")
 
 	return nil
 }
 
+// recordRemoteWriter 记录云端配置的最后写入设备和时间（随同步结果一起保存）.
+func (sm *SyncManager) recordRemoteWriter(syncData *SyncData) {
+	if syncData.DeviceID == "" {
+		return
+	}
+	sm.config.LastRemoteDevice = syncData.DeviceID
+	sm.config.LastRemoteTimestamp = syncData.Timestamp
+}
+
+// recordSyncResult 将推送/拉取的结果写入同步配置和同步历史，返回原错误.
+// 未配置云同步时不记录.
+func (sm *SyncManager) recordSyncResult(direction, strategy string, syncErr error) error {
+	config := sm.mirrorManager.GetSyncProfile(sm.profile)
+	if config == nil {
+		return syncErr
+	}
+
+	config.LastSyncSuccess = syncErr == nil
+	config.LastSyncError = ""
+	if syncErr != nil {
+		config.LastSyncError = syncErr.Error()
+	}
+	if err := sm.mirrorManager.saveConfig(); err != nil {
+		LogWarnf("⚠️  保存同步结果失败: %v\n", err)
+	}
+
+	entry := SyncHistoryEntry{
+		Timestamp:   time.Now(),
+		Direction:   direction,
+		Strategy:    strategy,
+		DeviceID:    config.DeviceID,
+		MirrorCount: len(sm.mirrorManager.ListActiveMirrors()),
+		Success:     config.LastSyncSuccess,
+		Error:       config.LastSyncError,
+	}
+	if err := sm.AppendHistory(entry); err != nil {
+		LogWarnf("⚠️  记录同步历史失败: %v\n", err)
+	}
+	return syncErr
+}
+
 // FetchRemoteSyncData 仅获取云端同步数据（不应用到本地）。
 func (sm *SyncManager) FetchRemoteSyncData() (*SyncData, error) {
 	// 确保提供商已初始化
@@ -379,7 +589,95 @@ func (sm *SyncManager) FetchRemoteSyncData() (*SyncData, error) {
 		return nil, fmt.Errorf("下载配置失败: %w", err)
 	}
 
-	// 解密
+	return sm.decodeRemoteSyncData(encryptedData)
+}
+
+// ListRemoteFiles 列出云端存储中的文件（按名称排序）.
+func (sm *SyncManager) ListRemoteFiles() ([]string, error) {
+	if err := sm.LoadSync(); err != nil {
+		return nil, err
+	}
+	return sm.listRemoteFiles()
+}
+
+// listRemoteFiles 通过当前提供商列出云端文件.
+func (sm *SyncManager) listRemoteFiles() ([]string, error) {
+	files, err := sm.provider.List()
+	if err != nil {
+		return nil, fmt.Errorf("列出云端文件失败: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// DeleteRemoteFile 删除云端存储中的文件，文件不存在时返回包装 ErrRemoteNotFound 的错误.
+func (sm *SyncManager) DeleteRemoteFile(filename string) error {
+	if err := sm.LoadSync(); err != nil {
+		return err
+	}
+	return sm.deleteRemoteFile(filename)
+}
+
+// deleteRemoteFile 确认文件存在后通过当前提供商删除.
+func (sm *SyncManager) deleteRemoteFile(filename string) error {
+	files, err := sm.listRemoteFiles()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(files, filename) {
+		return &mirrorError{msg: fmt.Sprintf("云端文件 '%s' 不存在", filename), err: ErrRemoteNotFound}
+	}
+	if err := sm.provider.Delete(filename); err != nil {
+		return fmt.Errorf("删除云端文件失败: %w", err)
+	}
+	return nil
+}
+
+// Unlink 停用云同步并清除本地保存的令牌、加密密码和 Gist ID.
+// keepRemote 为 false 时先删除云端主配置文件，云端文件不存在时忽略.
+func (sm *SyncManager) Unlink(keepRemote bool) error {
+	config := sm.mirrorManager.GetSyncProfile(sm.profile)
+	if config == nil {
+		return fmt.Errorf("未配置云同步 (配置: %s)", sm.Profile())
+	}
+
+	if !keepRemote {
+		if err := sm.LoadSync(); err != nil {
+			return err
+		}
+	}
+	return sm.unlink(keepRemote)
+}
+
+// unlink 使用已加载的提供商删除云端配置（可选）并清除本地凭据.
+func (sm *SyncManager) unlink(keepRemote bool) error {
+	if !keepRemote {
+		err := sm.deleteRemoteFile(ConfigFileName)
+		switch {
+		case err == nil:
+			LogInfof("✅ 已删除云端配置文件: %s\n", ConfigFileName)
+		case errors.Is(err, ErrRemoteNotFound):
+			LogInfof("💡 云端配置文件不存在，无需删除\n")
+		default:
+			return fmt.Errorf("%w（可使用 --keep-remote 仅清除本地凭据）", err)
+		}
+	}
+
+	config := sm.mirrorManager.GetSyncProfile(sm.profile)
+	config.Enabled = false
+	config.AutoSync = false
+	config.Token = ""
+	config.EncryptionPwd = ""
+	config.EncryptKey = ""
+	config.GistID = ""
+	if err := sm.mirrorManager.saveConfig(); err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+	return nil
+}
+
+// decodeRemoteSyncData 解密并解析云端同步数据（不校验、不解密 APIKey）.
+func (sm *SyncManager) decodeRemoteSyncData(encryptedData []byte) (*SyncData, error) {
 	data, err := sm.decryptData(encryptedData)
 	if err != nil {
 		return nil, fmt.Errorf("解密数据失败: %w", err)
@@ -394,6 +692,41 @@ func (sm *SyncManager) FetchRemoteSyncData() (*SyncData, error) {
 	return &syncData, nil
 }
 
+// DetectRemoteConflicts 获取云端配置并检测与本地配置的冲突，不修改本地配置也不读取标准输入.
+func (sm *SyncManager) DetectRemoteConflicts() (*ConflictResolution, error) {
+	syncData, err := sm.FetchRemoteSyncData()
+	if err != nil {
+		return nil, err
+	}
+	if err := sm.verifyRemoteSyncData(syncData); err != nil {
+		return nil, err
+	}
+
+	return sm.newConflictResolver(syncData).DetectConflicts(), nil
+}
+
+// verifyRemoteSyncData 校验云端数据的校验和并解密其中的 APIKey.
+func (sm *SyncManager) verifyRemoteSyncData(syncData *SyncData) error {
+	// 在解密 APIKey 之前先校验（校验值基于加密后的镜像数据）
+	if err := verifySyncDataIntegrity(syncData); err != nil {
+		return err
+	}
+
+	// 解密所有远程镜像源的 APIKey（在冲突检测之前）
+	if err := sm.decryptSyncDataAPIKeys(syncData); err != nil {
+		return fmt.Errorf("解密远程 API 密钥失败: %w", err)
+	}
+	return nil
+}
+
+// newConflictResolver 基于本地配置和云端数据创建冲突解决器，沿用同步管理器的交互模式.
+func (sm *SyncManager) newConflictResolver(syncData *SyncData) *ConflictResolver {
+	resolver := NewConflictResolver(sm.mirrorManager.config, syncData)
+	resolver.SetCryptoManager(sm.crypto) // 设置加密管理器，用于解密可能遗漏的 APIKey
+	resolver.SetInteractive(!sm.nonInteractive)
+	return resolver
+}
+
 // handleConflicts 处理配置冲突.
 func (sm *SyncManager) handleConflicts(resolver *ConflictResolver, conflicts *ConflictResolution, strategy string, syncData *SyncData) error {
 	return sm.executeConflictResolution(resolver, conflicts, strategy, syncData, false)
@@ -406,51 +739,60 @@ func (sm *SyncManager) handlePushConflicts(resolver *ConflictResolver, conflicts
 
 // executeConflictResolution 执行冲突解决（公共方法，处理推送和拉取的冲突）.
 func (sm *SyncManager) executeConflictResolution(resolver *ConflictResolver, conflicts *ConflictResolution, strategy string, syncData *SyncData, isPush bool) error {
-	fmt.Printf("⚠️  检测到配置冲突\n\n")
-	fmt.Printf("%s", resolver.FormatConflicts(conflicts))
+	LogWarnf("⚠️  检测到配置冲突\n\n")
+	LogInfof("%s", resolver.FormatConflicts(conflicts))
 
 	var resolvedConfig *SystemConfig
 	var err error
 
 	switch strategy {
 	case "auto", StrategyMerge:
-		fmt.Printf("🔄 使用智能合并策略解决冲突...\n")
+		LogInfof("🔄 使用智能合并策略解决冲突...\n")
 		resolvedConfig, err = resolver.ResolveConflicts(conflicts, StrategyMerge)
 		if err != nil {
 			return fmt.Errorf("自动解决冲突失败: %w", err)
 		}
 
-		fmt.Printf("✅ 冲突已自动解决（智能合并）\n")
+		LogInfof("✅ 冲突已自动解决（智能合并）\n")
 		if isPush {
-			fmt.Printf("   - 优先保留本地配置修改（URL、模型名等）\n")
+			LogInfof("   - 优先保留本地配置修改（URL、模型名等）\n")
 		}
-		fmt.Printf("   - 保留了本地API密钥\n")
-		fmt.Printf("   - 合并了镜像源配置\n")
-		fmt.Printf("   - 新增镜像源需要手动配置API密钥\n")
+		LogInfof("   - 保留了本地API密钥\n")
+		LogInfof("   - 合并了镜像源配置\n")
+		LogInfof("   - 新增镜像源需要手动配置API密钥\n")
 
 	case "local":
-		fmt.Printf("🏠 使用本地优先策略解决冲突...\n")
+		LogInfof("🏠 使用本地优先策略解决冲突...\n")
 		resolvedConfig, err = resolver.ResolveConflicts(conflicts, "local")
 		if err != nil {
 			return fmt.Errorf("本地优先解决冲突失败: %w", err)
 		}
 
-		fmt.Printf("✅ 冲突已解决（本地优先）\n")
-		fmt.Printf("   - 保持本地配置不变\n")
-		fmt.Printf("   - 添加了云端新增的镜像源\n")
+		LogInfof("✅ 冲突已解决（本地优先）\n")
+		LogInfof("   - 保持本地配置不变\n")
+		LogInfof("   - 添加了云端新增的镜像源\n")
 
 	case "remote":
-		fmt.Printf("☁️  使用远程优先策略解决冲突...\n")
+		LogInfof("☁️  使用远程优先策略解决冲突...\n")
 		resolvedConfig, err = resolver.ResolveConflicts(conflicts, "remote")
 		if err != nil {
 			return fmt.Errorf("远程优先解决冲突失败: %w", err)
 		}
 
-		fmt.Printf("✅ 冲突已解决（远程优先）\n")
-		fmt.Printf("   - 使用云端配置\n")
-		fmt.Printf("   - 保留了本地API密钥\n")
+		LogInfof("✅ 冲突已解决（远程优先）\n")
+		LogInfof("   - 使用云端配置\n")
+		LogInfof("   - 保留了本地API密钥\n")
+
+	case StrategyAbort:
+		// 不上传、不修改本地配置，供脚本根据错误类型判断存在冲突
+		sm.reportProgress(SyncStageDone, "检测到冲突，已取消同步", 100)
+		LogInfof("🛑 检测到 %d 个冲突，已取消同步，本地和云端配置均未修改\n", len(conflicts.Conflicts))
+		return fmt.Errorf("%w（%d 个冲突）", ErrSyncAborted, len(conflicts.Conflicts))
 
 	case "manual":
+		if sm.nonInteractive {
+			return fmt.Errorf("非交互模式不支持手动解决冲突，请使用 merge、local 或 remote 策略")
+		}
 		return sm.handleManualConflictResolution(resolver, conflicts, syncData)
 
 	default:
@@ -459,10 +801,11 @@ func (sm *SyncManager) executeConflictResolution(resolver *ConflictResolver, con
 
 	// 创建备份
 	if err := sm.createBackup(); err != nil {
-		fmt.Printf("警告: 创建备份失败: %v\n", err)
+		LogWarnf("警告: 创建备份失败: %v\n", err)
 	}
 
 	// 应用解决后的配置
+	sm.reportProgress(SyncStageApply, "正在应用解决后的配置", 80)
 	sm.mirrorManager.config = resolvedConfig
 	if err := sm.mirrorManager.saveConfig(); err != nil {
 		return fmt.Errorf("保存解决后的配置失败: %w", err)
@@ -470,16 +813,17 @@ func (sm *SyncManager) executeConflictResolution(resolver *ConflictResolver, con
 
 	// 更新最后同步时间
 	sm.config.LastSync = time.Now()
-	sm.mirrorManager.config.Sync = sm.config
+	sm.mirrorManager.setSyncProfile(sm.profile, sm.config)
 	if err := sm.mirrorManager.saveConfig(); err != nil {
 		return fmt.Errorf("保存同步时间失败: %w", err)
 	}
 
-	fmt.Printf("\n📊 同步完成统计:\n")
-	fmt.Printf("   来源设备: %s\n", syncData.DeviceID)
-	fmt.Printf("   配置时间: %s\n", syncData.Timestamp.Format("2006-01-02 15:04:05"))
-	fmt.Printf("   镜像源数量: %d\n", len(resolvedConfig.Mirrors))
-	fmt.Printf("   解决冲突: %d个\n", len(conflicts.Conflicts))
+	sm.reportProgress(SyncStageDone, fmt.Sprintf("已解决 %d 个冲突", len(conflicts.Conflicts)), 100)
+	LogInfof("\n📊 同步完成统计:\n")
+	LogInfof("   来源设备: %s\n", syncData.DeviceID)
+	LogInfof("   配置时间: %s\n", syncData.Timestamp.Format("2006-01-02 15:04:05"))
+	LogInfof("   镜像源数量: %d\n", len(resolvedConfig.Mirrors))
+	LogInfof("   解决冲突: %d个\n", len(conflicts.Conflicts))
 
 	return nil
 }
@@ -491,77 +835,44 @@ func (sm *SyncManager) createBackup() error {
 
 // createBackupWithPrefix 使用指定前缀创建配置备份.
 func (sm *SyncManager) createBackupWithPrefix(prefix string) error {
-	// 使用 MirrorManager 的配置路径，而不是硬编码的系统路径
-	configPath := sm.mirrorManager.GetConfigPath()
-	configDir := filepath.Dir(configPath)
-	backupDir := filepath.Join(configDir, "backup")
-
-	if err := EnsureDir(backupDir); err != nil {
-		return fmt.Errorf("创建备份目录失败: %w", err)
-	}
-
-	// 生成备份文件名
-	timestamp := time.Now().Format("20060102-150405")
-	backupFileName := fmt.Sprintf("%s-%s.toml", prefix, timestamp)
-	backupPath := filepath.Join(backupDir, backupFileName)
-
-	// 复制当前配置文件
-	if err := copyFile(configPath, backupPath); err != nil {
-		return fmt.Errorf("创建备份失败: %w", err)
-	}
-
-	fmt.Printf("💾 已备份配置: %s\n", backupPath)
-
-	// 清理旧备份（保留最近10个）
-	sm.cleanOldBackups(backupDir, prefix, 10)
-
-	return nil
-}
-
-// cleanOldBackups 清理旧备份文件，保留指定数量的最新备份.
-func (sm *SyncManager) cleanOldBackups(backupDir, prefix string, keepCount int) {
-	entries, err := os.ReadDir(backupDir)
+	backupPath, err := sm.mirrorManager.CreateBackup(prefix)
 	if err != nil {
-		return
-	}
-
-	// 筛选匹配前缀的备份文件
-	var backupFiles []os.DirEntry
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix+"-") && strings.HasSuffix(entry.Name(), ".toml") {
-			backupFiles = append(backupFiles, entry)
-		}
+		return err
 	}
 
-	// 如果备份数量超过限制，删除最旧的
-	if len(backupFiles) > keepCount {
-		// 按名称排序（时间戳格式保证字典序等于时间序）
-		// 删除最旧的
-		for i := 0; i < len(backupFiles)-keepCount; i++ {
-			oldFile := backupDir + "/" + backupFiles[i].Name()
-			_ = os.Remove(oldFile)
-		}
-	}
+	LogInfof("💾 已备份配置: %s\n", backupPath)
+	return nil
 }
 
 // GetStatus 获取同步状态.
 func (sm *SyncManager) GetStatus() (*SyncStatus, error) {
-	if sm.mirrorManager.config.Sync == nil {
+	config := sm.mirrorManager.GetSyncProfile(sm.profile)
+	if config == nil {
 		return &SyncStatus{
 			Enabled: false,
 			Message: "未配置云同步",
+			Profile: sm.Profile(),
 		}, nil
 	}
 
-	config := sm.mirrorManager.config.Sync
+	sm.config = config
+	if err := sm.ensureDeviceID(); err != nil {
+		return nil, err
+	}
+
 	status := &SyncStatus{
-		Enabled:      config.Enabled,
-		Provider:     config.Provider,
-		Endpoint:     config.Endpoint,
-		DeviceID:     config.DeviceID,
-		AutoSync:     config.AutoSync,
-		SyncInterval: config.SyncInterval,
-		LastSync:     config.LastSync,
+		Enabled:             config.Enabled,
+		Provider:            config.Provider,
+		Endpoint:            config.Endpoint,
+		DeviceID:            config.DeviceID,
+		AutoSync:            config.AutoSync,
+		SyncInterval:        config.SyncInterval,
+		LastSync:            config.LastSync,
+		LastSyncSuccess:     config.LastSyncSuccess,
+		LastSyncError:       config.LastSyncError,
+		Profile:             sm.Profile(),
+		LastRemoteDevice:    config.LastRemoteDevice,
+		LastRemoteTimestamp: config.LastRemoteTimestamp,
 	}
 
 	if config.LastSync.IsZero() {
@@ -570,25 +881,38 @@ func (sm *SyncManager) GetStatus() (*SyncStatus, error) {
 		duration := time.Since(config.LastSync)
 		status.Message = fmt.Sprintf("上次同步: %s 前", formatDuration(duration))
 	}
+	if config.LastSyncError != "" {
+		status.Message += "（最近一次同步失败）"
+	}
 
 	return status, nil
 }
 
 // exportSyncData 导出同步数据.
 func (sm *SyncManager) exportSyncData() *SyncData {
+	return sm.exportSyncDataForTool("")
+}
+
+// exportSyncDataForTool 导出指定工具类型的同步数据，toolType 为空时导出全部镜像源.
+// 活跃镜像源保持配置中的顺序（即 reorder 设置的顺序），已删除镜像源按名称排序；
+// 除记录写入时间的 Timestamp 外，相同配置的导出内容逐字节相同.
+func (sm *SyncManager) exportSyncDataForTool(toolType ToolType) *SyncData {
 	var mirrors []MirrorConfig
 	var deletedMirrors []MirrorConfig
 
 	// 总是包含API密钥（加密后）
 	for i := range sm.mirrorManager.config.Mirrors {
 		mirror := &sm.mirrorManager.config.Mirrors[i]
+		if toolType != "" && mirror.ToolType != toolType {
+			continue
+		}
 		exportMirror := *mirror
 
 		// 如果有API密钥，进行加密
 		if mirror.APIKey != "" {
 			encryptedKey, err := sm.encryptAPIKey(mirror.APIKey)
 			if err != nil {
-				fmt.Printf("警告: 加密API密钥失败 (%s): %v\n", mirror.Name, err)
+				LogWarnf("警告: 加密API密钥失败 (%s): %v\n", mirror.Name, err)
 				// 如果加密失败，不包含API密钥
 				exportMirror.APIKey = ""
 			} else {
@@ -613,33 +937,39 @@ func (sm *SyncManager) exportSyncData() *SyncData {
 			mirrors = append(mirrors, exportMirror)
 		}
 	}
+	sortMirrorsByName(deletedMirrors)
 
-	// 计算数据校验和
+	// 旧版校验和仅覆盖镜像源列表，继续写入以兼容旧客户端
 	data, _ := json.Marshal(mirrors)
 	checksum := calculateChecksum(data)
 
-	return &SyncData{
+	syncData := &SyncData{
 		Mirrors:        mirrors,
 		CurrentCodex:   sm.mirrorManager.config.CurrentCodex,
 		CurrentClaude:  sm.mirrorManager.config.CurrentClaude,
 		Timestamp:      time.Now(),
 		DeviceID:       sm.config.DeviceID,
-		Version:        "3.1", // 支持删除追踪的新版本
+		Version:        syncDataVersion,
 		Checksum:       checksum,
 		HasAPIKeys:     true,           // 总是为true
 		DeletedMirrors: deletedMirrors, // 包含已删除的镜像源信息
 	}
+	syncData.Integrity = calculateSyncDataIntegrity(syncData)
+	return syncData
+}
+
+// sortMirrorsByName 按名称排序镜像源，使已删除镜像源的导出顺序与其存储顺序无关.
+func sortMirrorsByName(mirrors []MirrorConfig) {
+	sort.SliceStable(mirrors, func(i, j int) bool {
+		return mirrors[i].Name < mirrors[j].Name
+	})
 }
 
 // applySyncData 应用同步数据.
 func (sm *SyncManager) applySyncData(syncData *SyncData) error {
-	// 校验和仅在未验证时计算（避免解密后因明文APIKey导致不一致）
-	if !syncData.ValidatedChecksum {
-		data, _ := json.Marshal(syncData.Mirrors)
-		if calculateChecksum(data) != syncData.Checksum {
-			return fmt.Errorf("数据校验和不匹配，可能数据已损坏")
-		}
-		syncData.ValidatedChecksum = true
+	// 校验仅在未验证时进行（避免解密后因明文APIKey导致不一致）
+	if err := verifySyncDataIntegrity(syncData); err != nil {
+		return err
 	}
 
 	// 备份当前配置
@@ -683,13 +1013,13 @@ func (sm *SyncManager) applySyncData(syncData *SyncData) error {
 			newMirrors[i].Deleted = true
 			newMirrors[i].DeletedAt = deletedMirror.DeletedAt
 			found = true
-			fmt.Printf("🗑️  同步删除镜像源: %s\n", deletedMirror.Name)
+			LogInfof("🗑️  同步删除镜像源: %s\n", deletedMirror.Name)
 			break
 		}
 		if !found {
 			deletedMirror.Deleted = true
 			newMirrors = append(newMirrors, *deletedMirror)
-			fmt.Printf("🗑️  同步删除镜像源: %s\n", deletedMirror.Name)
+			LogInfof("🗑️  同步删除镜像源: %s\n", deletedMirror.Name)
 		}
 	}
 
@@ -717,16 +1047,39 @@ func (sm *SyncManager) applySyncData(syncData *SyncData) error {
 	return nil
 }
 
+// gistIDProvider 基于 Gist 或代码片段的提供商（GitHub Gist、Gitee、GitLab），可获取自动发现或新建的 ID.
+type gistIDProvider interface {
+	GetGistID() string
+}
+
 // createProvider 创建同步提供商.
 func (sm *SyncManager) createProvider(config *SyncConfig) (SyncProvider, error) {
 	switch config.Provider {
 	case "gist":
 		return NewGistProvider(config.Token, config.GistID)
+	case "gitee":
+		return NewGiteeProvider(config.Token, config.GistID)
+	case "gitlab":
+		return NewGitLabSnippetProvider(config.Endpoint, config.Token, config.GistID)
 	default:
 		return nil, fmt.Errorf("不支持的同步提供商: %s", config.Provider)
 	}
 }
 
+// SyncProviderInfo 返回指定类型同步提供商的信息，无需创建连接.
+func SyncProviderInfo(providerType string) (ProviderInfo, error) {
+	switch providerType {
+	case "gist":
+		return (&GistProvider{}).GetInfo(), nil
+	case "gitee":
+		return (&GiteeProvider{}).GetInfo(), nil
+	case "gitlab":
+		return (&GitLabSnippetProvider{apiBase: gitlabDefaultAPIBase}).GetInfo(), nil
+	default:
+		return ProviderInfo{}, fmt.Errorf("不支持的同步提供商: %s，支持: gist, gitee, gitlab", providerType)
+	}
+}
+
 // encryptData 加密数据.
 func (sm *SyncManager) encryptData(data []byte) ([]byte, error) {
 	// 优先使用用户设置的密码，否则使用随机密钥
@@ -813,7 +1166,7 @@ func (sm *SyncManager) decryptSyncDataAPIKeys(syncData *SyncData) error {
 			decryptedKey, err := sm.decryptAPIKey(mirror.APIKey)
 			if err != nil {
 				// 已删除的镜像源解密失败不影响主流程
-				fmt.Printf("⚠️  解密已删除镜像源 '%s' 的 API 密钥失败: %v\n", mirror.Name, err)
+				LogWarnf("⚠️  解密已删除镜像源 '%s' 的 API 密钥失败: %v\n", mirror.Name, err)
 				mirror.APIKey = ""
 			} else {
 				mirror.APIKey = decryptedKey
@@ -857,23 +1210,66 @@ type SyncStatus struct {
 	SyncInterval int       `json:"sync_interval"`
 	LastSync     time.Time `json:"last_sync"`
 	Message      string    `json:"message"`
+	// 最近一次同步的结果
+	LastSyncSuccess bool   `json:"last_sync_success"`
+	LastSyncError   string `json:"last_sync_error,omitempty"`
+	// 云端配置的最后写入者（最近一次推送/拉取时记录）
+	LastRemoteDevice    string    `json:"last_remote_device,omitempty"`
+	LastRemoteTimestamp time.Time `json:"last_remote_timestamp,omitempty"`
+	// Profile 同步配置名
+	Profile string `json:"profile"`
 }
 
 // 辅助函数
 
-// generateDeviceID 生成设备ID.
-func generateDeviceID() string {
+// ensureDeviceID 确保设备拥有持久化的唯一标识.
+// 旧版本的设备ID只基于主机名，同名主机会冲突，首次加载时迁移为主机名+随机标识.
+func (sm *SyncManager) ensureDeviceID() error {
+	if sm.config.DeviceUUID != "" && sm.config.DeviceID != "" {
+		return nil
+	}
+
+	deviceUUID, err := newDeviceUUID()
+	if err != nil {
+		return fmt.Errorf("生成设备标识失败: %w", err)
+	}
+
+	sm.config.DeviceUUID = deviceUUID
+	sm.config.DeviceID = generateDeviceID(deviceUUID)
+	if err := sm.mirrorManager.saveConfig(); err != nil {
+		return fmt.Errorf("保存设备ID失败: %w", err)
+	}
+	return nil
+}
+
+// generateDeviceID 根据主机名和设备唯一标识生成可读的设备ID.
+func generateDeviceID(deviceUUID string) string {
 	hostname, err := os.Hostname()
 	if err != nil {
 		hostname = "unknown"
 	}
 
-	hash := md5.Sum([]byte(hostname + "codex-mirror-device-v1"))
-	suffix := hex.EncodeToString(hash[:4]) // 使用前4字节作为8位十六进制后缀
+	// 使用唯一标识的前8位十六进制作为后缀
+	suffix := strings.ReplaceAll(deviceUUID, "-", "")
+	if len(suffix) > 8 {
+		suffix = suffix[:8]
+	}
 
 	return fmt.Sprintf("%s-%s", hostname, suffix)
 }
 
+// newDeviceUUID 生成随机的 UUID v4.
+func newDeviceUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // 版本 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 变体
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
 // generateEncryptKey 生成加密密钥.
 func generateEncryptKey() (string, error) {
 	key := make([]byte, 32)
@@ -883,12 +1279,48 @@ func generateEncryptKey() (string, error) {
 	return hex.EncodeToString(key), nil
 }
 
-// calculateChecksum 计算校验和.
+// calculateChecksum 计算旧版 MD5 校验和.
 func calculateChecksum(data []byte) string {
 	hash := md5.Sum(data)
 	return hex.EncodeToString(hash[:])
 }
 
+// calculateSyncDataIntegrity 计算完整同步数据（不含校验字段）的 SHA-256 完整性校验值.
+func calculateSyncDataIntegrity(syncData *SyncData) string {
+	canonical := *syncData
+	canonical.Checksum = ""
+	canonical.Integrity = ""
+	data, _ := json.Marshal(canonical)
+	hash := sha256.Sum256(data)
+	return integrityAlgorithmSHA256 + ":" + hex.EncodeToString(hash[:])
+}
+
+// verifySyncDataIntegrity 校验同步数据的完整性，已校验过的数据直接通过.
+// 新数据校验 Integrity 字段，未携带该字段的旧数据回退到仅覆盖 Mirrors 的 MD5 校验和.
+func verifySyncDataIntegrity(syncData *SyncData) error {
+	if syncData.ValidatedChecksum {
+		return nil
+	}
+
+	if syncData.Integrity != "" {
+		algorithm, _, _ := strings.Cut(syncData.Integrity, ":")
+		if algorithm != integrityAlgorithmSHA256 {
+			return fmt.Errorf("不支持的数据校验算法 '%s'，请升级 codex-mirror", algorithm)
+		}
+		if calculateSyncDataIntegrity(syncData) != syncData.Integrity {
+			return fmt.Errorf("数据校验和不匹配，可能数据已损坏或被篡改")
+		}
+	} else {
+		data, _ := json.Marshal(syncData.Mirrors)
+		if calculateChecksum(data) != syncData.Checksum {
+			return fmt.Errorf("数据校验和不匹配，可能数据已损坏")
+		}
+	}
+
+	syncData.ValidatedChecksum = true
+	return nil
+}
+
 // formatDuration 格式化时间间隔.
 func formatDuration(d time.Duration) string {
 	switch {
@@ -940,7 +1372,7 @@ func (sm *SyncManager) handleManualConflictResolution(resolver *ConflictResolver
 
 	// 创建备份
 	if err := sm.createBackup(); err != nil {
-		fmt.Printf("警告: 创建备份失败: %v\n", err)
+		LogWarnf("警告: 创建备份失败: %v\n", err)
 	}
 
 	// 应用解决后的配置
@@ -951,15 +1383,15 @@ func (sm *SyncManager) handleManualConflictResolution(resolver *ConflictResolver
 
 	// 更新最后同步时间
 	sm.config.LastSync = time.Now()
-	sm.mirrorManager.config.Sync = sm.config
+	sm.mirrorManager.setSyncProfile(sm.profile, sm.config)
 	if err := sm.mirrorManager.saveConfig(); err != nil {
 		return fmt.Errorf("保存同步时间失败: %w", err)
 	}
 
-	fmt.Printf("\n✅ 冲突已解决并应用\n")
-	fmt.Printf("   来源设备: %s\n", syncData.DeviceID)
-	fmt.Printf("   配置时间: %s\n", syncData.Timestamp.Format("2006-01-02 15:04:05"))
-	fmt.Printf("   解决策略: %s\n", strategy)
+	LogInfof("\n✅ 冲突已解决并应用\n")
+	LogInfof("   来源设备: %s\n", syncData.DeviceID)
+	LogInfof("   配置时间: %s\n", syncData.Timestamp.Format("2006-01-02 15:04:05"))
+	LogInfof("   解决策略: %s\n", strategy)
 
 	return nil
 }
@@ -1084,7 +1516,7 @@ func (sm *SyncManager) switchToDefaultIfDeleted() {
 		for i := range sm.mirrorManager.config.Mirrors {
 			mirror := &sm.mirrorManager.config.Mirrors[i]
 			if mirror.Name == sm.mirrorManager.config.CurrentCodex && mirror.Deleted {
-				fmt.Printf("⚠️  当前Codex镜像源 '%s' 已被删除，切换到默认\n", sm.mirrorManager.config.CurrentCodex)
+				LogWarnf("⚠️  当前Codex镜像源 '%s' 已被删除，切换到默认\n", sm.mirrorManager.config.CurrentCodex)
 				sm.mirrorManager.config.CurrentCodex = ""
 				break
 			}
@@ -1096,7 +1528,7 @@ func (sm *SyncManager) switchToDefaultIfDeleted() {
 		for i := range sm.mirrorManager.config.Mirrors {
 			mirror := &sm.mirrorManager.config.Mirrors[i]
 			if mirror.Name == sm.mirrorManager.config.CurrentClaude && mirror.Deleted {
-				fmt.Printf("⚠️  当前Claude镜像源 '%s' 已被删除，切换到默认\n", sm.mirrorManager.config.CurrentClaude)
+				LogWarnf("⚠️  当前Claude镜像源 '%s' 已被删除，切换到默认\n", sm.mirrorManager.config.CurrentClaude)
 				sm.mirrorManager.config.CurrentClaude = ""
 				break
 			}
@@ -11,17 +11,30 @@ import (
 	"time"
 )
 
-// GistProvider GitHub Gist 同步提供商.
+// GitHub Gist API 地址.
+const githubAPIBase = "https://api.github.com"
+
+// GistProvider GitHub Gist 同步提供商，也用作 API 兼容的代码片段服务（如 Gitee）的基础实现.
 type GistProvider struct {
 	token  string
 	gistID string
 	client *http.Client
+	// apiBase API 根地址，apiName 用于错误信息的服务名称
+	apiBase string
+	apiName string
+	// queryAuth 为 true 时通过 access_token 查询参数认证，否则使用 Authorization 请求头
+	queryAuth bool
 }
 
 // NewGistProvider 创建新的 GitHub Gist 提供商.
 func NewGistProvider(token, gistID string) (*GistProvider, error) {
+	return newGistAPIProvider(token, gistID, githubAPIBase, "GitHub", false)
+}
+
+// newGistAPIProvider 创建使用 Gist 兼容 API 的提供商，未提供 Gist ID 时按描述自动发现现有配置.
+func newGistAPIProvider(token, gistID, apiBase, apiName string, queryAuth bool) (*GistProvider, error) {
 	if token == "" {
-		return nil, fmt.Errorf("GitHub token 不能为空")
+		return nil, fmt.Errorf("%s token 不能为空", apiName)
 	}
 
 	provider := &GistProvider{
@@ -30,13 +43,16 @@ func NewGistProvider(token, gistID string) (*GistProvider, error) {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		apiBase:   apiBase,
+		apiName:   apiName,
+		queryAuth: queryAuth,
 	}
 
 	// 如果没有提供 Gist ID，尝试自动发现
 	if gistID == "" {
 		if discoveredID, err := provider.discoverExistingGist(); err == nil && discoveredID != "" {
 			provider.gistID = discoveredID
-			fmt.Printf("🔍 自动发现现有配置 Gist: %s\n", discoveredID)
+			LogInfof("🔍 自动发现现有配置 Gist: %s\n", discoveredID)
 		}
 	}
 
@@ -65,29 +81,21 @@ func (g *GistProvider) Upload(data []byte, filename string) error {
 		return fmt.Errorf("序列化请求数据失败: %w", err)
 	}
 
-	var url string
-	var method string
+	path := "/gists"
+	method := "POST" // 创建新的 Gist
 
-	if g.gistID == "" {
-		// 创建新的 Gist
-		url = "https://api.github.com/gists"
-		method = "POST"
-	} else {
+	if g.gistID != "" {
 		// 更新现有的 Gist
-		url = fmt.Sprintf("https://api.github.com/gists/%s", g.gistID)
+		path = "/gists/" + g.gistID
 		method = "PATCH"
 	}
 
 	// 创建 HTTP 请求
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(requestData))
+	req, err := g.newRequest(method, path, bytes.NewBuffer(requestData))
 	if err != nil {
 		return fmt.Errorf("创建请求失败: %w", err)
 	}
-
-	// 设置请求头
-	req.Header.Set("Authorization", "token "+g.token)
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
 	// 发送请求
 	resp, err := g.client.Do(req)
@@ -96,7 +104,7 @@ func (g *GistProvider) Upload(data []byte, filename string) error {
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Warning: failed to close response body: %v\n", err)
+			LogWarnf("Warning: failed to close response body: %v\n", err)
 		}
 	}()
 
@@ -108,7 +116,7 @@ func (g *GistProvider) Upload(data []byte, filename string) error {
 
 	// 检查响应状态
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("GitHub API 错误 (%d): %s", resp.StatusCode, string(respBody))
+		return fmt.Errorf("%s API 错误 (%d): %s", g.apiName, resp.StatusCode, string(respBody))
 	}
 
 	// 解析响应获取 Gist ID（如果是新创建的）
@@ -127,7 +135,7 @@ func (g *GistProvider) Upload(data []byte, filename string) error {
 // Download 从 GitHub Gist 下载数据.
 func (g *GistProvider) Download(filename string) ([]byte, error) {
 	if g.gistID == "" {
-		return nil, fmt.Errorf("Gist ID 未设置")
+		return nil, &mirrorError{msg: "Gist ID 未设置", err: ErrRemoteNotFound}
 	}
 
 	respBody, err := g.fetchGistData()
@@ -143,16 +151,31 @@ func (g *GistProvider) Download(filename string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(fileContent)
 }
 
-func (g *GistProvider) fetchGistData() ([]byte, error) {
-	url := fmt.Sprintf("https://api.github.com/gists/%s", g.gistID)
+// Revision 返回 Gist 的最后更新时间（updated_at），用于推送前检查云端是否被修改；Gist 尚未创建时返回空字符串.
+func (g *GistProvider) Revision(_ string) (string, error) {
+	if g.gistID == "" {
+		return "", nil
+	}
 
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	respBody, err := g.fetchGistData()
 	if err != nil {
-		return nil, fmt.Errorf("创建请求失败: %w", err)
+		return "", err
 	}
 
-	req.Header.Set("Authorization", "token "+g.token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	var gistResp struct {
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := json.Unmarshal(respBody, &gistResp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	return gistResp.UpdatedAt, nil
+}
+
+func (g *GistProvider) fetchGistData() ([]byte, error) {
+	req, err := g.newRequest("GET", "/gists/"+g.gistID, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
 
 	resp, err := g.client.Do(req)
 	if err != nil {
@@ -160,7 +183,7 @@ func (g *GistProvider) fetchGistData() ([]byte, error) {
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Warning: failed to close response body: %v\n", err)
+			LogWarnf("Warning: failed to close response body: %v\n", err)
 		}
 	}()
 
@@ -170,7 +193,7 @@ func (g *GistProvider) fetchGistData() ([]byte, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API 错误 (%d): %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("%s API 错误 (%d): %s", g.apiName, resp.StatusCode, string(respBody))
 	}
 
 	return respBody, nil
@@ -196,7 +219,7 @@ func (g *GistProvider) extractFileContent(respBody []byte, filename string) (str
 func (g *GistProvider) getSpecificFile(files map[string]interface{}, filename string) (string, error) {
 	file, exists := files[filename]
 	if !exists {
-		return "", fmt.Errorf("未找到文件: %s", filename)
+		return "", &mirrorError{msg: "未找到文件: " + filename, err: ErrRemoteNotFound}
 	}
 
 	return extractContentFromFile(file)
@@ -232,19 +255,12 @@ func (g *GistProvider) List() ([]string, error) {
 		return []string{}, nil // 如果没有 Gist ID，返回空列表
 	}
 
-	// 构建请求 URL
-	url := fmt.Sprintf("https://api.github.com/gists/%s", g.gistID)
-
 	// 创建 HTTP 请求
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := g.newRequest("GET", "/gists/"+g.gistID, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
 
-	// 设置请求头
-	req.Header.Set("Authorization", "token "+g.token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
 	// 发送请求
 	resp, err := g.client.Do(req)
 	if err != nil {
@@ -252,7 +268,7 @@ func (g *GistProvider) List() ([]string, error) {
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Warning: failed to close response body: %v\n", err)
+			LogWarnf("Warning: failed to close response body: %v\n", err)
 		}
 	}()
 
@@ -264,7 +280,7 @@ func (g *GistProvider) List() ([]string, error) {
 
 	// 检查响应状态
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API 错误 (%d): %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("%s API 错误 (%d): %s", g.apiName, resp.StatusCode, string(respBody))
 	}
 
 	// 解析响应
@@ -310,19 +326,12 @@ func (g *GistProvider) Delete(filename string) error {
 		return fmt.Errorf("序列化请求数据失败: %w", err)
 	}
 
-	// 构建请求 URL
-	url := fmt.Sprintf("https://api.github.com/gists/%s", g.gistID)
-
 	// 创建 HTTP 请求
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(requestData))
+	req, err := g.newRequest("PATCH", "/gists/"+g.gistID, bytes.NewBuffer(requestData))
 	if err != nil {
 		return fmt.Errorf("创建请求失败: %w", err)
 	}
-
-	// 设置请求头
-	req.Header.Set("Authorization", "token "+g.token)
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
 	// 发送请求
 	resp, err := g.client.Do(req)
@@ -331,25 +340,42 @@ func (g *GistProvider) Delete(filename string) error {
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Warning: failed to close response body: %v\n", err)
+			LogWarnf("Warning: failed to close response body: %v\n", err)
 		}
 	}()
 
 	// 检查响应状态
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("GitHub API 错误 (%d): %s", resp.StatusCode, string(respBody))
+		return fmt.Errorf("%s API 错误 (%d): %s", g.apiName, resp.StatusCode, string(respBody))
 	}
 
 	return nil
 }
 
+// newRequest 创建带认证信息的 API 请求，path 为 API 根地址之后的路径.
+func (g *GistProvider) newRequest(method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, g.apiBase+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if g.queryAuth {
+		query := req.URL.Query()
+		query.Set("access_token", g.token)
+		req.URL.RawQuery = query.Encode()
+	} else {
+		req.Header.Set("Authorization", "token "+g.token)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	return req, nil
+}
+
 // GetInfo 获取提供商信息.
 func (g *GistProvider) GetInfo() ProviderInfo {
 	return ProviderInfo{
 		Name:        "GitHub Gist",
 		Type:        "gist",
-		Endpoint:    "https://api.github.com",
+		Endpoint:    githubAPIBase,
 		MaxFileSize: 10 * 1024 * 1024, // 10MB
 		Description: "使用 GitHub Gist 存储配置文件",
 	}
@@ -375,19 +401,12 @@ type GistCandidate struct {
 
 // discoverExistingGist 自动发现现有的配置 Gist.
 func (g *GistProvider) discoverExistingGist() (string, error) {
-	// 构建请求 URL - 获取用户的所有 Gist
-	url := "https://api.github.com/gists"
-
-	// 创建 HTTP 请求
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	// 创建 HTTP 请求 - 获取用户的所有 Gist
+	req, err := g.newRequest("GET", "/gists", http.NoBody)
 	if err != nil {
 		return "", fmt.Errorf("创建请求失败: %w", err)
 	}
 
-	// 设置请求头
-	req.Header.Set("Authorization", "token "+g.token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
 	// 发送请求
 	resp, err := g.client.Do(req)
 	if err != nil {
@@ -395,7 +414,7 @@ func (g *GistProvider) discoverExistingGist() (string, error) {
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Warning: failed to close response body: %v\n", err)
+			LogWarnf("Warning: failed to close response body: %v\n", err)
 		}
 	}()
 
@@ -407,7 +426,7 @@ func (g *GistProvider) discoverExistingGist() (string, error) {
 
 	// 检查响应状态
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GitHub API 错误 (%d): %s", resp.StatusCode, string(respBody))
+		return "", fmt.Errorf("%s API 错误 (%d): %s", g.apiName, resp.StatusCode, string(respBody))
 	}
 
 	// 解析响应
@@ -430,7 +449,7 @@ func (g *GistProvider) discoverExistingGist() (string, error) {
 	}
 
 	// 多个候选项：选择最新更新的
-	fmt.Printf("🔍 发现 %d 个配置 Gist，选择最新的...\n", len(candidates))
+	LogInfof("🔍 发现 %d 个配置 Gist，选择最新的...\n", len(candidates))
 
 	latestCandidate := candidates[0]
 	latestTime, err := time.Parse(time.RFC3339, candidates[0].UpdatedAt)
@@ -451,7 +470,7 @@ func (g *GistProvider) discoverExistingGist() (string, error) {
 		}
 	}
 
-	fmt.Printf("   选中最新的 Gist (更新于: %s): %s\n",
+	LogInfof("   选中最新的 Gist (更新于: %s): %s\n",
 		latestTime.Format("2006-01-02 15:04:05"), latestCandidate.ID)
 
 	return latestCandidate.ID, nil
@@ -459,19 +478,12 @@ func (g *GistProvider) discoverExistingGist() (string, error) {
 
 // validateGistContent 验证 Gist 是否包含有效的配置文件.
 func (g *GistProvider) validateGistContent(gistID string) bool {
-	// 构建请求 URL
-	url := fmt.Sprintf("https://api.github.com/gists/%s", gistID)
-
 	// 创建 HTTP 请求
-	req, err := http.NewRequest("GET", url, http.NoBody)
+	req, err := g.newRequest("GET", "/gists/"+gistID, http.NoBody)
 	if err != nil {
 		return false
 	}
 
-	// 设置请求头
-	req.Header.Set("Authorization", "token "+g.token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
 	// 发送请求
 	resp, err := g.client.Do(req)
 	if err != nil {
@@ -479,7 +491,7 @@ func (g *GistProvider) validateGistContent(gistID string) bool {
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Warning: failed to close response body: %v\n", err)
+			LogWarnf("Warning: failed to close response body: %v\n", err)
 		}
 	}()
 
@@ -0,0 +1,29 @@
+package internal
+
+// Gitee 代码片段 API 地址.
+const giteeAPIBase = "https://gitee.com/api/v5"
+
+// GiteeProvider Gitee 代码片段同步提供商，API 与 GitHub Gist 兼容，适合中国大陆用户.
+type GiteeProvider struct {
+	*GistProvider
+}
+
+// NewGiteeProvider 创建新的 Gitee 代码片段提供商，未提供 Gist ID 时按描述自动发现现有配置.
+func NewGiteeProvider(token, gistID string) (*GiteeProvider, error) {
+	provider, err := newGistAPIProvider(token, gistID, giteeAPIBase, "Gitee", true)
+	if err != nil {
+		return nil, err
+	}
+	return &GiteeProvider{GistProvider: provider}, nil
+}
+
+// GetInfo 获取提供商信息.
+func (g *GiteeProvider) GetInfo() ProviderInfo {
+	return ProviderInfo{
+		Name:        "Gitee 代码片段",
+		Type:        "gitee",
+		Endpoint:    giteeAPIBase,
+		MaxFileSize: 10 * 1024 * 1024, // 10MB
+		Description: "使用 Gitee 代码片段存储配置文件",
+	}
+}
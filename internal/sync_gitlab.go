@@ -0,0 +1,294 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitLab 代码片段相关常量.
+const (
+	// gitlabDefaultAPIBase 未指定端点时使用的 GitLab API 地址
+	gitlabDefaultAPIBase = "https://gitlab.com/api/v4"
+	// gitlabSnippetTitle 配置代码片段的标题，用于自动发现现有配置
+	gitlabSnippetTitle = "Codex Mirror Switch Configuration - codex-mirror-sync"
+	// gitlabSnippetRef 读取代码片段文件时使用的版本（默认分支的最新提交）
+	gitlabSnippetRef = "HEAD"
+)
+
+// GitLabSnippetProvider GitLab 个人代码片段同步提供商，支持自建 GitLab 实例.
+type GitLabSnippetProvider struct {
+	apiBase   string
+	token     string
+	snippetID string
+	client    *http.Client
+}
+
+// gitlabSnippet GitLab 代码片段 API 响应.
+type gitlabSnippet struct {
+	ID        int64               `json:"id"`
+	Title     string              `json:"title"`
+	UpdatedAt string              `json:"updated_at"`
+	FileName  string              `json:"file_name"`
+	Files     []gitlabSnippetFile `json:"files"`
+}
+
+// gitlabSnippetFile GitLab 代码片段中的文件.
+type gitlabSnippetFile struct {
+	Path string `json:"path"`
+}
+
+// gitlabSnippetFileAction 更新代码片段时对单个文件的操作.
+type gitlabSnippetFileAction struct {
+	Action   string `json:"action,omitempty"`
+	FilePath string `json:"file_path"`
+	Content  string `json:"content,omitempty"`
+}
+
+// NewGitLabSnippetProvider 创建新的 GitLab 代码片段提供商.
+// endpoint 为 GitLab 实例的 API 地址（如 https://gitlab.example.com/api/v4），为空时使用 gitlab.com；
+// 未提供代码片段 ID 时按标题自动发现现有配置.
+func NewGitLabSnippetProvider(endpoint, token, snippetID string) (*GitLabSnippetProvider, error) {
+	if token == "" {
+		return nil, fmt.Errorf("GitLab token 不能为空")
+	}
+	if endpoint == "" {
+		endpoint = gitlabDefaultAPIBase
+	}
+
+	provider := &GitLabSnippetProvider{
+		apiBase:   strings.TrimSuffix(endpoint, "/"),
+		token:     token,
+		snippetID: snippetID,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+
+	if snippetID == "" {
+		if discoveredID, err := provider.discoverExistingSnippet(); err == nil && discoveredID != "" {
+			provider.snippetID = discoveredID
+			LogInfof("🔍 自动发现现有配置代码片段: %s\n", discoveredID)
+		}
+	}
+
+	return provider, nil
+}
+
+// Upload 上传数据到 GitLab 代码片段，未设置代码片段 ID 时创建新的私有代码片段.
+func (g *GitLabSnippetProvider) Upload(data []byte, filename string) error {
+	encodedData := base64.StdEncoding.EncodeToString(data)
+
+	if g.snippetID == "" {
+		respBody, err := g.do("POST", "/snippets", map[string]interface{}{
+			"title":       gitlabSnippetTitle,
+			"description": gitlabSnippetTitle,
+			"visibility":  "private",
+			"files":       []gitlabSnippetFileAction{{FilePath: filename, Content: encodedData}},
+		})
+		if err != nil {
+			return err
+		}
+
+		var snippet gitlabSnippet
+		if err := json.Unmarshal(respBody, &snippet); err != nil {
+			return fmt.Errorf("解析响应失败: %w", err)
+		}
+		g.snippetID = strconv.FormatInt(snippet.ID, 10)
+		return nil
+	}
+
+	snippet, err := g.getSnippet(g.snippetID)
+	if err != nil {
+		return err
+	}
+	action := "create"
+	if snippet.hasFile(filename) {
+		action = "update"
+	}
+
+	_, err = g.do("PUT", "/snippets/"+g.snippetID, map[string]interface{}{
+		"files": []gitlabSnippetFileAction{{Action: action, FilePath: filename, Content: encodedData}},
+	})
+	return err
+}
+
+// Download 从 GitLab 代码片段下载数据.
+func (g *GitLabSnippetProvider) Download(filename string) ([]byte, error) {
+	if g.snippetID == "" {
+		return nil, &mirrorError{msg: "代码片段 ID 未设置", err: ErrRemoteNotFound}
+	}
+
+	snippet, err := g.getSnippet(g.snippetID)
+	if err != nil {
+		return nil, err
+	}
+	if !snippet.hasFile(filename) {
+		return nil, &mirrorError{msg: "未找到文件: " + filename, err: ErrRemoteNotFound}
+	}
+
+	path := fmt.Sprintf("/snippets/%s/files/%s/%s/raw", g.snippetID, gitlabSnippetRef, url.PathEscape(filename))
+	content, err := g.do("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+}
+
+// List 列出代码片段中的配置文件.
+func (g *GitLabSnippetProvider) List() ([]string, error) {
+	if g.snippetID == "" {
+		return []string{}, nil // 如果没有代码片段 ID，返回空列表
+	}
+
+	snippet, err := g.getSnippet(g.snippetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileList []string
+	for _, file := range snippet.filePaths() {
+		if file == ConfigFileName ||
+			(strings.HasPrefix(file, "codex-mirror-config-") && strings.HasSuffix(file, ".json")) {
+			fileList = append(fileList, file)
+		}
+	}
+	return fileList, nil
+}
+
+// Delete 删除代码片段中的文件.
+func (g *GitLabSnippetProvider) Delete(filename string) error {
+	if g.snippetID == "" {
+		return fmt.Errorf("代码片段 ID 未设置")
+	}
+
+	_, err := g.do("PUT", "/snippets/"+g.snippetID, map[string]interface{}{
+		"files": []gitlabSnippetFileAction{{Action: "delete", FilePath: filename}},
+	})
+	return err
+}
+
+// GetInfo 获取提供商信息.
+func (g *GitLabSnippetProvider) GetInfo() ProviderInfo {
+	return ProviderInfo{
+		Name:        "GitLab 代码片段",
+		Type:        "gitlab",
+		Endpoint:    g.apiBase,
+		MaxFileSize: 10 * 1024 * 1024, // 10MB
+		Description: "使用 GitLab 个人代码片段存储配置文件",
+	}
+}
+
+// GetGistID 获取代码片段 ID（保存在同步配置的 gist_id 字段中）.
+func (g *GitLabSnippetProvider) GetGistID() string {
+	return g.snippetID
+}
+
+// discoverExistingSnippet 按标题自动发现现有的配置代码片段，存在多个时选择最近更新的.
+func (g *GitLabSnippetProvider) discoverExistingSnippet() (string, error) {
+	respBody, err := g.do("GET", "/snippets?per_page=100", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var snippets []gitlabSnippet
+	if err := json.Unmarshal(respBody, &snippets); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	var latest *gitlabSnippet
+	for i := range snippets {
+		snippet := &snippets[i]
+		if snippet.Title != gitlabSnippetTitle || !snippet.hasFile(ConfigFileName) {
+			continue
+		}
+		// updated_at 均为 RFC3339 格式，可直接按字符串比较
+		if latest == nil || snippet.UpdatedAt > latest.UpdatedAt {
+			latest = snippet
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return strconv.FormatInt(latest.ID, 10), nil
+}
+
+// getSnippet 获取代码片段详情.
+func (g *GitLabSnippetProvider) getSnippet(id string) (*gitlabSnippet, error) {
+	respBody, err := g.do("GET", "/snippets/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var snippet gitlabSnippet
+	if err := json.Unmarshal(respBody, &snippet); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &snippet, nil
+}
+
+// do 发送 API 请求并返回响应内容，payload 非空时以 JSON 形式发送.
+func (g *GitLabSnippetProvider) do(method, path string, payload interface{}) ([]byte, error) {
+	var body io.Reader = http.NoBody
+	if payload != nil {
+		requestData, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求数据失败: %w", err)
+		}
+		body = bytes.NewReader(requestData)
+	}
+
+	req, err := http.NewRequest(method, g.apiBase+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			LogWarnf("Warning: failed to close response body: %v\n", err)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("GitLab API 错误 (%d): %s", resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
+
+// filePaths 返回代码片段中的文件路径，兼容只返回 file_name 的旧版本 GitLab.
+func (s *gitlabSnippet) filePaths() []string {
+	if len(s.Files) == 0 && s.FileName != "" {
+		return []string{s.FileName}
+	}
+	paths := make([]string, 0, len(s.Files))
+	for _, file := range s.Files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+// hasFile 判断代码片段中是否包含指定文件.
+func (s *gitlabSnippet) hasFile(filename string) bool {
+	return slices.Contains(s.filePaths(), filename)
+}
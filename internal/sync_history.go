@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// syncHistoryFileName 同步历史日志文件名（与 mirrors.toml 位于同一目录）.
+const syncHistoryFileName = "sync-history.log"
+
+// syncHistoryMaxSize 同步历史日志的大小上限，超过后轮转为 .1 文件.
+const syncHistoryMaxSize = 1 << 20
+
+// 同步方向.
+const (
+	SyncDirectionPush = "push"
+	SyncDirectionPull = "pull"
+)
+
+// SyncHistoryEntry 同步历史中的一条记录.
+type SyncHistoryEntry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Direction   string    `json:"direction"`
+	Strategy    string    `json:"strategy"`
+	DeviceID    string    `json:"device_id"`
+	MirrorCount int       `json:"mirror_count"`
+	Success     bool      `json:"success"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// SyncHistoryPath 返回同步历史日志的路径.
+func (sm *SyncManager) SyncHistoryPath() string {
+	return filepath.Join(filepath.Dir(sm.mirrorManager.configPath), syncHistoryFileName)
+}
+
+// AppendHistory 以 JSON 行的形式追加一条同步历史，文件超过大小上限时先轮转.
+func (sm *SyncManager) AppendHistory(entry SyncHistoryEntry) error {
+	path := sm.SyncHistoryPath()
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Size() >= syncHistoryMaxSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return fmt.Errorf("轮转同步历史失败: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化同步历史失败: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("打开同步历史失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入同步历史失败: %w", err)
+	}
+	return nil
+}
+
+// ReadHistory 读取同步历史（包括轮转的旧文件），按时间从旧到新返回最近的 limit 条；limit <= 0 表示全部.
+// 无法解析的行会被跳过.
+func (sm *SyncManager) ReadHistory(limit int) ([]SyncHistoryEntry, error) {
+	path := sm.SyncHistoryPath()
+
+	var entries []SyncHistoryEntry
+	for _, file := range []string{path + ".1", path} {
+		fileEntries, err := readHistoryFile(file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// readHistoryFile 读取单个同步历史文件，文件不存在时返回空结果.
+func readHistoryFile(path string) ([]SyncHistoryEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取同步历史失败: %w", err)
+	}
+	defer file.Close()
+
+	var entries []SyncHistoryEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry SyncHistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取同步历史失败: %w", err)
+	}
+	return entries, nil
+}
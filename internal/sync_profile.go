@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// DefaultSyncProfile 默认同步配置名，旧版单一的 [sync] 配置会迁移到该名称下.
+const DefaultSyncProfile = "default"
+
+// syncProfileNamePattern 同步配置名允许的字符.
+var syncProfileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateSyncProfileName 校验同步配置名，只允许字母、数字、下划线和短横线.
+func ValidateSyncProfileName(name string) error {
+	if !syncProfileNamePattern.MatchString(name) {
+		return fmt.Errorf("无效的同步配置名 '%s'，只允许字母、数字、下划线和短横线", name)
+	}
+	return nil
+}
+
+// normalizeSyncProfile 空配置名视为默认配置.
+func normalizeSyncProfile(name string) string {
+	if name == "" {
+		return DefaultSyncProfile
+	}
+	return name
+}
+
+// migrateSyncProfiles 将旧版单一的同步配置迁移为默认同步配置.
+// 已存在默认同步配置时保留现有配置，丢弃旧字段.
+func (mm *MirrorManager) migrateSyncProfiles() {
+	if mm.config.Sync == nil {
+		return
+	}
+	if mm.config.SyncProfiles == nil {
+		mm.config.SyncProfiles = make(map[string]*SyncConfig)
+	}
+	if _, exists := mm.config.SyncProfiles[DefaultSyncProfile]; !exists {
+		mm.config.SyncProfiles[DefaultSyncProfile] = mm.config.Sync
+	}
+	mm.config.Sync = nil
+}
+
+// GetSyncProfile 获取指定名称的同步配置，空名称表示默认配置，不存在时返回 nil.
+// 返回的是配置本身，修改后需调用 SaveConfig 保存.
+func (mm *MirrorManager) GetSyncProfile(name string) *SyncConfig {
+	return mm.config.SyncProfiles[normalizeSyncProfile(name)]
+}
+
+// setSyncProfile 设置指定名称的同步配置（不保存）.
+func (mm *MirrorManager) setSyncProfile(name string, config *SyncConfig) {
+	if mm.config.SyncProfiles == nil {
+		mm.config.SyncProfiles = make(map[string]*SyncConfig)
+	}
+	mm.config.SyncProfiles[normalizeSyncProfile(name)] = config
+}
+
+// SyncProfileNames 返回所有同步配置名（按字母排序）.
+func (mm *MirrorManager) SyncProfileNames() []string {
+	names := make([]string, 0, len(mm.config.SyncProfiles))
+	for name := range mm.config.SyncProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PushToolWithStrategy 仅推送指定工具类型的镜像源，云端其他类型的镜像源保持不变，并记录本次同步的结果.
+// force 策略用本地该类型的镜像源整体替换云端同类型镜像源；auto/merge 策略保留云端独有的同类型镜像源.
+func (sm *SyncManager) PushToolWithStrategy(toolType ToolType, strategy string) error {
+	return sm.recordSyncResult(SyncDirectionPush, strategy, sm.pushToolWithStrategy(toolType, strategy))
+}
+
+// pushToolWithStrategy 执行按工具类型推送.
+func (sm *SyncManager) pushToolWithStrategy(toolType ToolType, strategy string) error {
+	if toolType != ToolTypeCodex && toolType != ToolTypeClaude {
+		return fmt.Errorf("无效的工具类型 '%s'，支持: %s, %s", toolType, ToolTypeCodex, ToolTypeClaude)
+	}
+	switch strategy {
+	case StrategyAuto, StrategyMerge, "force":
+	default:
+		return fmt.Errorf("按工具类型推送仅支持 auto、merge、force 策略，不支持: %s", strategy)
+	}
+
+	if err := sm.LoadSync(); err != nil {
+		return err
+	}
+
+	// 推送前自动备份
+	sm.reportProgress(SyncStageBackup, "正在备份本地配置", 10)
+	if err := sm.createBackupWithPrefix("pre-push"); err != nil {
+		LogWarnf("⚠️  创建备份失败: %v（继续推送）\n", err)
+	}
+
+	LogInfof("📤 正在推送 %s 镜像源到云端...\n", toolType)
+	return sm.pushToolSubset(ConfigFileName, toolType, strategy == "force")
+}
+
+// pushToolSubset 下载云端数据，合并本地指定类型的镜像源后重新上传.
+// 云端数据无法读取或校验失败时取消推送，避免覆盖其他类型的镜像源.
+func (sm *SyncManager) pushToolSubset(filename string, toolType ToolType, replace bool) error {
+	syncData := sm.exportSyncDataForTool(toolType)
+
+	sm.reportProgress(SyncStageDownload, "正在下载云端配置", 30)
+	encryptedRemoteData, baseRevision, err := sm.downloadWithRevision(filename)
+	switch {
+	case err == nil:
+		remoteSyncData, err := sm.decodeRemoteSyncData(encryptedRemoteData)
+		if err == nil {
+			// 其他类型的镜像源原样保留，其 APIKey 保持加密状态，只校验不解密
+			err = verifySyncDataIntegrity(remoteSyncData)
+		}
+		if err != nil {
+			return fmt.Errorf("读取云端配置失败，已取消推送以免覆盖其他类型的镜像源: %w", err)
+		}
+		sm.reportProgress(SyncStageConflict, "正在合并云端配置", 50)
+		syncData = mergeToolSyncData(remoteSyncData, syncData, toolType, replace)
+	case errors.Is(err, ErrRemoteNotFound):
+		LogInfof("💡 云端暂无配置，首次推送\n")
+	default:
+		return fmt.Errorf("下载云端配置失败: %w", err)
+	}
+
+	return sm.uploadSyncData(filename, syncData, baseRevision)
+}
+
+// mergeToolSyncData 将本地指定类型的同步数据合并到云端数据中，返回新的同步数据.
+// 云端其他类型的镜像源和当前镜像源保持不变；replace 为 false 时保留本地没有的云端同类型镜像源.
+func mergeToolSyncData(remote, local *SyncData, toolType ToolType, replace bool) *SyncData {
+	localNames := make(map[string]bool)
+	for _, mirrors := range [][]MirrorConfig{local.Mirrors, local.DeletedMirrors} {
+		for i := range mirrors {
+			localNames[mirrors[i].Name] = true
+		}
+	}
+	keepRemote := func(mirror *MirrorConfig) bool {
+		return mirror.ToolType != toolType || (!replace && !localNames[mirror.Name])
+	}
+
+	merged := &SyncData{
+		CurrentCodex:  remote.CurrentCodex,
+		CurrentClaude: remote.CurrentClaude,
+		Timestamp:     local.Timestamp,
+		DeviceID:      local.DeviceID,
+		Version:       local.Version,
+		HasAPIKeys:    local.HasAPIKeys,
+	}
+	if toolType == ToolTypeCodex {
+		merged.CurrentCodex = local.CurrentCodex
+	} else {
+		merged.CurrentClaude = local.CurrentClaude
+	}
+
+	for i := range remote.Mirrors {
+		if keepRemote(&remote.Mirrors[i]) {
+			merged.Mirrors = append(merged.Mirrors, remote.Mirrors[i])
+		}
+	}
+	merged.Mirrors = append(merged.Mirrors, local.Mirrors...)
+	for i := range remote.DeletedMirrors {
+		if keepRemote(&remote.DeletedMirrors[i]) {
+			merged.DeletedMirrors = append(merged.DeletedMirrors, remote.DeletedMirrors[i])
+		}
+	}
+	merged.DeletedMirrors = append(merged.DeletedMirrors, local.DeletedMirrors...)
+	sortMirrorsByName(merged.DeletedMirrors)
+
+	data, _ := json.Marshal(merged.Mirrors)
+	merged.Checksum = calculateChecksum(data)
+	merged.Integrity = calculateSyncDataIntegrity(merged)
+	return merged
+}
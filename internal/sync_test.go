@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -44,7 +49,7 @@ func (m *MockSyncProvider) Upload(data []byte, filename string) error {
 func (m *MockSyncProvider) Download(filename string) ([]byte, error) {
 	data, exists := m.files[filename]
 	if !exists {
-		return nil, fmt.Errorf("文件 %s 不存在", filename)
+		return nil, fmt.Errorf("文件 %s 不存在: %w", filename, ErrRemoteNotFound)
 	}
 	result := make([]byte, len(data))
 	copy(result, data)
@@ -95,6 +100,83 @@ func TestNewSyncManager(t *testing.T) {
 	}
 }
 
+// TestSyncManagerNonInteractive 测试非交互模式下不会读取标准输入.
+func TestSyncManagerNonInteractive(t *testing.T) {
+	tempDir := setupTestDirWithCleanup(t)
+	mm := createTestMirrorManagerForSync(t, tempDir)
+
+	sm := NewSyncManager(mm)
+	if !sm.newConflictResolver(&SyncData{}).Interactive {
+		t.Error("默认应启用交互模式")
+	}
+
+	sm.SetInteractive(false)
+	resolver := sm.newConflictResolver(&SyncData{})
+	if resolver.Interactive {
+		t.Error("非交互模式下冲突解决器不应启用交互模式")
+	}
+
+	err := sm.executeConflictResolution(resolver, &ConflictResolution{}, StrategyManual, &SyncData{}, false)
+	if err == nil {
+		t.Error("非交互模式下 manual 策略应返回错误")
+	}
+}
+
+// TestPullMergeNonInteractive 测试非交互模式下拉取合并字段冲突时按修改时间保留较新的值，不读取标准输入.
+func TestPullMergeNonInteractive(t *testing.T) {
+	tests := []struct {
+		name         string
+		remoteOffset time.Duration
+		want         string
+	}{
+		{name: "remote newer", remoteOffset: time.Hour, want: "https://remote.example.com"},
+		{name: "local newer", remoteOffset: -time.Hour, want: "https://local.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t))
+			if err := mm.AddMirrorWithModel("shared", "https://local.example.com", "sk-local", ToolTypeCodex, ""); err != nil {
+				t.Fatalf("AddMirrorWithModel() error = %v", err)
+			}
+
+			sm := NewSyncManager(mm)
+			sm.config = &SyncConfig{DeviceID: "test-device"}
+			sm.provider = NewMockSyncProvider()
+			sm.SetInteractive(false)
+
+			local := mm.findActiveMirror("shared")
+			remoteMirror := *local
+			remoteMirror.BaseURL = "https://remote.example.com"
+			remoteMirror.LastModified = local.LastModified.Add(tt.remoteOffset)
+			remote := &SyncData{Mirrors: []MirrorConfig{remoteMirror}, DeviceID: "other-device", Timestamp: time.Now(), ValidatedChecksum: true}
+
+			// 询问时读取到 EOF 会保留本地值，因此远程值胜出说明没有询问
+			reader, writer, err := os.Pipe()
+			if err != nil {
+				t.Fatalf("os.Pipe() error = %v", err)
+			}
+			writer.Close()
+			oldStdin := os.Stdin
+			os.Stdin = reader
+			defer func() { os.Stdin = oldStdin }()
+
+			resolver := sm.newConflictResolver(remote)
+			conflicts := resolver.DetectConflicts()
+			if len(conflicts.Conflicts) == 0 {
+				t.Fatal("应检测到冲突")
+			}
+			if err := sm.handleConflicts(resolver, conflicts, StrategyMerge, remote); err != nil {
+				t.Fatalf("handleConflicts() error = %v", err)
+			}
+
+			if got := mm.findActiveMirror("shared").BaseURL; got != tt.want {
+				t.Errorf("BaseURL = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestInitSyncWithPassword 测试使用密码初始化同步.
 func TestInitSyncWithPassword(t *testing.T) {
 	tempDir := setupTestDirWithCleanup(t)
@@ -176,7 +258,7 @@ func TestLoadSync(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.setupSync {
 				// 设置同步配置
-				mm.config.Sync = &SyncConfig{
+				mm.setSyncProfile(DefaultSyncProfile, &SyncConfig{
 					Enabled:       true,
 					Provider:      "gist",
 					Endpoint:      "https://api.github.com",
@@ -184,9 +266,9 @@ func TestLoadSync(t *testing.T) {
 					DeviceID:      "test-device",
 					SyncAPIKeys:   true,
 					EncryptionPwd: "test-password",
-				}
+				})
 			} else {
-				mm.config.Sync = nil
+				mm.config.SyncProfiles = nil
 			}
 
 			err := sm.LoadSync()
@@ -197,7 +279,7 @@ func TestLoadSync(t *testing.T) {
 				if sm.config == nil {
 					t.Error("Config should be loaded when sync is configured")
 				}
-				if sm.config != mm.config.Sync {
+				if sm.config != mm.GetSyncProfile(DefaultSyncProfile) {
 					t.Error("Config should match MirrorManager's sync config")
 				}
 				// 注意：provider可能为nil，因为网络连接会失败，这是预期的
@@ -437,32 +519,456 @@ func TestEncryptDataWithEmptyPassword(t *testing.T) {
 
 // TestGenerateDeviceID 测试生成设备ID.
 func TestGenerateDeviceID(t *testing.T) {
-	deviceID := generateDeviceID()
+	uuid1, err := newDeviceUUID()
+	if err != nil {
+		t.Fatalf("newDeviceUUID() error = %v", err)
+	}
+	uuid2, _ := newDeviceUUID()
+	if uuid1 == uuid2 || len(uuid1) != 36 {
+		t.Fatalf("UUID 应唯一且为标准格式: %s, %s", uuid1, uuid2)
+	}
 
+	deviceID := generateDeviceID(uuid1)
 	if deviceID == "" {
 		t.Error("Device ID should not be empty")
 	}
 
-	// 生成两个ID应该相同（因为基于主机名）
-	deviceID2 := generateDeviceID()
+	// 同一个唯一标识生成的ID应该相同
+	if deviceID != generateDeviceID(uuid1) {
+		t.Error("Same UUID should produce the same device ID")
+	}
 
-	if deviceID != deviceID2 {
-		t.Error("Two device IDs on same machine should be the same")
+	// 同名主机使用不同唯一标识时ID应不同
+	if deviceID == generateDeviceID(uuid2) {
+		t.Error("Different UUIDs should produce different device IDs")
 	}
 
 	// 验证ID格式（应该包含主机名和十六进制后缀）
-	parts := strings.Split(deviceID, "-")
-	if len(parts) < 2 {
-		t.Errorf("Device ID should have format 'hostname-suffix', got: %s", deviceID)
+	hostname, _ := os.Hostname()
+	if !strings.HasPrefix(deviceID, hostname+"-") {
+		t.Errorf("Device ID should start with hostname, got: %s", deviceID)
+	}
+	suffix := deviceID[strings.LastIndex(deviceID, "-")+1:]
+	if _, err := hex.DecodeString(suffix); err != nil || len(suffix) != 8 {
+		t.Errorf("Device ID suffix should be 8 hex chars, got: %s", suffix)
+	}
+}
+
+// TestEnsureDeviceIDMigration 测试迁移旧版设备ID.
+func TestEnsureDeviceIDMigration(t *testing.T) {
+	tempDir := setupTestDirWithCleanup(t)
+	mm := createTestMirrorManagerForSync(t, tempDir)
+	mm.setSyncProfile(DefaultSyncProfile, &SyncConfig{
+		Enabled:  true,
+		Provider: "gist",
+		DeviceID: "macbook-1a2b3c4d", // 旧版仅基于主机名的ID
+	})
+	sm := NewSyncManager(mm)
+
+	status, err := sm.GetStatus()
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
 	}
+	if mm.GetSyncProfile(DefaultSyncProfile).DeviceUUID == "" {
+		t.Fatal("应生成并保存设备唯一标识")
+	}
+	if status.DeviceID == "macbook-1a2b3c4d" || status.DeviceID != mm.GetSyncProfile(DefaultSyncProfile).DeviceID {
+		t.Errorf("状态应显示迁移后的设备ID，实际: %s", status.DeviceID)
+	}
+
+	// 迁移结果应持久化，且再次加载保持不变
+	reloaded, err := NewMirrorManagerWithPath(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("重新加载配置失败: %v", err)
+	}
+	if reloaded.GetSyncProfile(DefaultSyncProfile).DeviceID != status.DeviceID {
+		t.Errorf("设备ID未持久化: %s != %s", reloaded.GetSyncProfile(DefaultSyncProfile).DeviceID, status.DeviceID)
+	}
+	status2, _ := NewSyncManager(reloaded).GetStatus()
+	if status2.DeviceID != status.DeviceID {
+		t.Errorf("设备ID应保持稳定: %s != %s", status2.DeviceID, status.DeviceID)
+	}
+}
+
+// TestLastSyncResult 测试推送/拉取失败时记录错误，并在状态中展示.
+func TestLastSyncResult(t *testing.T) {
+	tempDir := setupTestDirWithCleanup(t)
+	mm := createTestMirrorManagerForSync(t, tempDir)
+	mm.setSyncProfile(DefaultSyncProfile, &SyncConfig{Enabled: true, Provider: "unsupported", DeviceID: "dev-1", DeviceUUID: "uuid-1"})
+	sm := NewSyncManager(mm)
+
+	if err := sm.Pull(); err == nil {
+		t.Fatal("Pull() 使用不支持的提供商应失败")
+	}
+
+	reloaded, err := NewMirrorManagerWithPath(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("重新加载配置失败: %v", err)
+	}
+	status, err := NewSyncManager(reloaded).GetStatus()
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status.LastSyncSuccess || !strings.Contains(status.LastSyncError, "不支持的同步提供商") {
+		t.Errorf("状态应记录同步失败，实际: success=%v error=%q", status.LastSyncSuccess, status.LastSyncError)
+	}
+
+	// 成功后清除之前的错误
+	if err := sm.recordSyncResult(SyncDirectionPush, "auto", nil); err != nil {
+		t.Fatalf("recordSyncResult() error = %v", err)
+	}
+	status, _ = sm.GetStatus()
+	if !status.LastSyncSuccess || status.LastSyncError != "" {
+		t.Errorf("成功后应清除错误，实际: success=%v error=%q", status.LastSyncSuccess, status.LastSyncError)
+	}
+}
+
+// TestLastRemoteWriter 测试记录云端配置的最后写入者，推送后写入者变为本设备.
+func TestLastRemoteWriter(t *testing.T) {
+	tempDir := setupTestDirWithCleanup(t)
+	mm := createTestMirrorManagerForSync(t, tempDir)
+	mm.setSyncProfile(DefaultSyncProfile, &SyncConfig{Enabled: true, Provider: "gist", DeviceID: "dev-1", DeviceUUID: "uuid-1", EncryptionPwd: "test-password"})
+	sm := NewSyncManager(mm)
+	sm.config = mm.GetSyncProfile(DefaultSyncProfile)
+	sm.provider = NewMockSyncProvider()
+	sm.crypto = NewCryptoManager("test-password")
+
+	remoteTime := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	sm.recordRemoteWriter(&SyncData{DeviceID: "other-device", Timestamp: remoteTime})
+	if err := sm.recordSyncResult(SyncDirectionPull, "merge", nil); err != nil {
+		t.Fatalf("recordSyncResult() error = %v", err)
+	}
+
+	reloaded, err := NewMirrorManagerWithPath(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("重新加载配置失败: %v", err)
+	}
+	status, err := NewSyncManager(reloaded).GetStatus()
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status.LastRemoteDevice != "other-device" || !status.LastRemoteTimestamp.Equal(remoteTime) {
+		t.Errorf("状态应显示云端写入者，实际: %s %v", status.LastRemoteDevice, status.LastRemoteTimestamp)
+	}
+
+	// 推送成功后云端写入者为本设备
+	syncData := sm.exportSyncData()
+	if err := sm.uploadSyncData(ConfigFileName, syncData, ""); err != nil {
+		t.Fatalf("uploadSyncData() error = %v", err)
+	}
+	status, _ = sm.GetStatus()
+	if status.LastRemoteDevice != "dev-1" || !status.LastRemoteTimestamp.Equal(syncData.Timestamp) {
+		t.Errorf("推送后写入者应为本设备，实际: %s %v", status.LastRemoteDevice, status.LastRemoteTimestamp)
+	}
+}
+
+// TestPushToolSubset 测试按工具类型推送时只合并该类型的镜像源，云端其他类型的镜像源保持不变.
+func TestPushToolSubset(t *testing.T) {
+	tests := []struct {
+		name           string
+		replace        bool
+		wantRemoteOnly bool
+	}{
+		{"merge keeps remote-only codex mirrors", false, true},
+		{"force replaces remote codex mirrors", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t))
+			sm := NewSyncManager(mm)
+			sm.config = &SyncConfig{DeviceID: "test-device", SyncAPIKeys: true, EncryptionPwd: "test-password"}
+			sm.crypto = NewCryptoManager("test-password")
+			provider := NewMockSyncProvider()
+			sm.provider = provider
+
+			// 云端已有其他设备推送的配置
+			now := time.Now()
+			mm.config.CurrentClaude = "remote-claude"
+			mm.config.Mirrors = []MirrorConfig{
+				{Name: "remote-codex", BaseURL: "https://remote-codex.com", APIKey: "rc-key", ToolType: ToolTypeCodex, CreatedAt: now, LastModified: now},
+				{Name: "remote-claude", BaseURL: "https://remote-claude.com", APIKey: "rl-key", ToolType: ToolTypeClaude, CreatedAt: now, LastModified: now},
+			}
+			if err := sm.performPush(ConfigFileName, ""); err != nil {
+				t.Fatalf("performPush() error = %v", err)
+			}
+
+			// 本地只修改了 Codex 镜像源
+			mm.config.CurrentCodex = "local-codex"
+			mm.config.CurrentClaude = "local-claude"
+			mm.config.Mirrors = []MirrorConfig{
+				{Name: "local-codex", BaseURL: "https://local-codex.com", APIKey: "lc-key", ToolType: ToolTypeCodex, CreatedAt: now, LastModified: now},
+				{Name: "local-claude", BaseURL: "https://local-claude.com", ToolType: ToolTypeClaude, CreatedAt: now, LastModified: now},
+			}
+			if err := sm.pushToolSubset(ConfigFileName, ToolTypeCodex, tt.replace); err != nil {
+				t.Fatalf("pushToolSubset() error = %v", err)
+			}
+
+			encrypted, err := provider.Download(ConfigFileName)
+			if err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+			remote, err := sm.decodeRemoteSyncData(encrypted)
+			if err != nil {
+				t.Fatalf("decodeRemoteSyncData() error = %v", err)
+			}
+			if err := sm.verifyRemoteSyncData(remote); err != nil {
+				t.Fatalf("verifyRemoteSyncData() error = %v", err)
+			}
+
+			names := make(map[string]MirrorConfig)
+			for _, mirror := range remote.Mirrors {
+				names[mirror.Name] = mirror
+			}
+			if claude, ok := names["remote-claude"]; !ok || claude.APIKey != "rl-key" {
+				t.Errorf("云端 Claude 镜像源应保持不变: %+v", claude)
+			}
+			if _, ok := names["local-claude"]; ok {
+				t.Error("本地 Claude 镜像源不应被推送")
+			}
+			if codex, ok := names["local-codex"]; !ok || codex.APIKey != "lc-key" {
+				t.Errorf("本地 Codex 镜像源应被推送: %+v", codex)
+			}
+			if _, ok := names["remote-codex"]; ok != tt.wantRemoteOnly {
+				t.Errorf("云端独有的 Codex 镜像源保留 = %v, want %v", ok, tt.wantRemoteOnly)
+			}
+			if remote.CurrentCodex != "local-codex" || remote.CurrentClaude != "remote-claude" {
+				t.Errorf("当前镜像源 = %s/%s, want local-codex/remote-claude", remote.CurrentCodex, remote.CurrentClaude)
+			}
+		})
+	}
+}
+
+// TestPushAbortStrategy 测试推送冲突使用 abort 策略时返回哨兵错误，且不上传、不修改本地配置.
+func TestPushAbortStrategy(t *testing.T) {
+	mm := createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t))
+	if err := mm.AddMirrorWithModel("shared", "https://local.example.com", "sk-local", ToolTypeCodex, ""); err != nil {
+		t.Fatalf("AddMirrorWithModel() error = %v", err)
+	}
+	before, err := os.ReadFile(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("读取配置失败: %v", err)
+	}
+
+	sm := NewSyncManager(mm)
+	sm.config = &SyncConfig{DeviceID: "test-device"}
+	provider := NewMockSyncProvider()
+	sm.provider = provider
 
-	// 验证后缀是十六进制
-	if len(parts) >= 2 {
-		suffix := parts[len(parts)-1]
-		if _, err := hex.DecodeString(suffix); err != nil {
-			t.Errorf("Device ID suffix should be valid hex string: %v", err)
+	local := mm.findActiveMirror("shared")
+	remoteMirror := *local
+	remoteMirror.BaseURL = "https://remote.example.com"
+	remoteMirror.LastModified = local.LastModified.Add(time.Hour)
+	remote := &SyncData{Mirrors: []MirrorConfig{remoteMirror}, DeviceID: "other-device", Timestamp: time.Now(), ValidatedChecksum: true}
+
+	resolver := sm.newConflictResolver(remote)
+	conflicts := resolver.DetectConflicts()
+	if len(conflicts.Conflicts) == 0 {
+		t.Fatal("应检测到冲突")
+	}
+
+	err = sm.handlePushConflicts(resolver, conflicts, StrategyAbort, remote)
+	if !errors.Is(err, ErrSyncAborted) {
+		t.Fatalf("handlePushConflicts() error = %v, want ErrSyncAborted", err)
+	}
+	if files, _ := provider.List(); len(files) != 0 {
+		t.Errorf("abort 不应上传，实际: %v", files)
+	}
+	after, err := os.ReadFile(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("读取配置失败: %v", err)
+	}
+	if !bytes.Equal(before, after) || mm.findActiveMirror("shared").BaseURL != "https://local.example.com" {
+		t.Error("abort 不应修改本地配置")
+	}
+}
+
+// TestResolveCurrentConflict 测试两台设备激活源不同时的交互式和基于修改时间的选择.
+func TestResolveCurrentConflict(t *testing.T) {
+	now := time.Now()
+	localConfig := &SystemConfig{
+		CurrentCodex: "local-codex",
+		Mirrors: []MirrorConfig{
+			{Name: DefaultMirrorName, ToolType: ToolTypeCodex, LastModified: now.Add(-time.Hour)},
+			{Name: "local-codex", ToolType: ToolTypeCodex, LastModified: now.Add(-time.Minute)},
+			{Name: "local-claude", ToolType: ToolTypeClaude, LastModified: now},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		interactive bool
+		input       string
+		remoteTime  time.Time
+		want        string
+	}{
+		{"interactive local", true, "1\n", now, "local-codex"},
+		{"interactive remote", true, "2\n", now, "remote-codex"},
+		{"interactive other", true, "3\nlocal-codex\n", now, "local-codex"},
+		{"interactive unknown name", true, "3\nmissing\n", now, DefaultMirrorName},
+		{"interactive wrong tool type", true, "3\nlocal-claude\n", now, DefaultMirrorName},
+		{"interactive invalid choice", true, "9\n", now, DefaultMirrorName},
+		{"non-interactive remote newer", false, "", now, "remote-codex"},
+		{"non-interactive local newer", false, "", now.Add(-2 * time.Minute), "local-codex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remoteData := &SyncData{
+				CurrentCodex: "remote-codex",
+				DeviceID:     "other-device",
+				Mirrors:      []MirrorConfig{{Name: "remote-codex", ToolType: ToolTypeCodex, LastModified: tt.remoteTime}},
+			}
+			resolver := NewConflictResolver(localConfig, remoteData)
+			resolver.SetInteractive(tt.interactive)
+
+			if tt.interactive {
+				reader, writer, err := os.Pipe()
+				if err != nil {
+					t.Fatalf("os.Pipe() error = %v", err)
+				}
+				oldStdin := os.Stdin
+				os.Stdin = reader
+				defer func() { os.Stdin = oldStdin }()
+				_, _ = writer.WriteString(tt.input)
+				writer.Close()
+			}
+
+			resolved, err := resolver.ResolveConflicts(resolver.DetectConflicts(), StrategyMerge)
+			if err != nil {
+				t.Fatalf("ResolveConflicts() error = %v", err)
+			}
+			if resolved.CurrentCodex != tt.want {
+				t.Errorf("CurrentCodex = %s, want %s", resolved.CurrentCodex, tt.want)
+			}
+		})
+	}
+}
+
+// TestRemoteFiles 测试列出和删除云端文件.
+func TestRemoteFiles(t *testing.T) {
+	sm := NewSyncManager(createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t)))
+	provider := NewMockSyncProvider()
+	sm.provider = provider
+	for _, name := range []string{ConfigFileName, "junk.json"} {
+		if err := provider.Upload([]byte("data"), name); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+	}
+
+	files, err := sm.listRemoteFiles()
+	if err != nil || !slices.Equal(files, []string{ConfigFileName, "junk.json"}) {
+		t.Fatalf("listRemoteFiles() = %v, %v", files, err)
+	}
+
+	if err := sm.deleteRemoteFile("missing.json"); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("deleteRemoteFile(missing) error = %v, want ErrRemoteNotFound", err)
+	}
+	if err := sm.deleteRemoteFile("junk.json"); err != nil {
+		t.Fatalf("deleteRemoteFile() error = %v", err)
+	}
+	if files, _ := sm.listRemoteFiles(); !slices.Equal(files, []string{ConfigFileName}) {
+		t.Errorf("删除后文件列表 = %v", files)
+	}
+}
+
+// TestSyncProfiles 测试多个同步配置互不影响，以及旧版配置迁移.
+func TestSyncProfiles(t *testing.T) {
+	tempDir := setupTestDirWithCleanup(t)
+	mm := createTestMirrorManagerForSync(t, tempDir)
+	mm.setSyncProfile(DefaultSyncProfile, &SyncConfig{Enabled: true, Provider: "gist", DeviceID: "dev-1", DeviceUUID: "uuid-1", GistID: "personal-gist"})
+	mm.setSyncProfile("work", &SyncConfig{Enabled: true, Provider: "unsupported", DeviceID: "dev-1", DeviceUUID: "uuid-1"})
+
+	sm := NewSyncManager(mm)
+	sm.SetProfile("work")
+	if err := sm.Push(); err == nil {
+		t.Fatal("Push() 使用不支持的提供商应失败")
+	}
+
+	reloaded, err := NewMirrorManagerWithPath(mm.GetConfigPath())
+	if err != nil {
+		t.Fatalf("重新加载配置失败: %v", err)
+	}
+	if names := reloaded.SyncProfileNames(); len(names) != 2 || names[0] != DefaultSyncProfile || names[1] != "work" {
+		t.Errorf("SyncProfileNames() = %v, want [default work]", names)
+	}
+	if reloaded.GetSyncProfile("work").LastSyncError == "" {
+		t.Error("work 同步配置应记录失败")
+	}
+	if def := reloaded.GetSyncProfile(""); def.LastSyncError != "" || def.GistID != "personal-gist" {
+		t.Errorf("默认同步配置不应受影响: %+v", def)
+	}
+
+	missing := NewSyncManager(reloaded)
+	missing.SetProfile("missing")
+	status, err := missing.GetStatus()
+	if err != nil || status.Enabled || status.Profile != "missing" {
+		t.Errorf("未配置的同步配置状态 = %+v, err = %v", status, err)
+	}
+
+	// 已存在 default 时，旧版配置不覆盖它
+	reloaded.config.Sync = &SyncConfig{Provider: "legacy"}
+	reloaded.migrateSyncProfiles()
+	if reloaded.config.Sync != nil || reloaded.GetSyncProfile(DefaultSyncProfile).Provider != "gist" {
+		t.Errorf("迁移不应覆盖已有的默认同步配置: %+v", reloaded.GetSyncProfile(DefaultSyncProfile))
+	}
+
+	for _, name := range []string{"work", "my_profile-2"} {
+		if err := ValidateSyncProfileName(name); err != nil {
+			t.Errorf("ValidateSyncProfileName(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "a b", "a.b"} {
+		if err := ValidateSyncProfileName(name); err == nil {
+			t.Errorf("ValidateSyncProfileName(%q) should fail", name)
+		}
+	}
+}
+
+// TestSyncHistory 测试同步历史的写入、读取和轮转.
+func TestSyncHistory(t *testing.T) {
+	tempDir := setupTestDirWithCleanup(t)
+	mm := createTestMirrorManagerForSync(t, tempDir)
+	mm.setSyncProfile(DefaultSyncProfile, &SyncConfig{Enabled: true, Provider: "unsupported", DeviceID: "dev-1", DeviceUUID: "uuid-1"})
+	sm := NewSyncManager(mm)
+
+	// 推送失败也会写入历史
+	if err := sm.PushWithStrategy("merge"); err == nil {
+		t.Fatal("PushWithStrategy() 使用不支持的提供商应失败")
+	}
+	entries, err := sm.ReadHistory(0)
+	if err != nil {
+		t.Fatalf("ReadHistory() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("期望 1 条历史，实际 %d 条", len(entries))
+	}
+	if e := entries[0]; e.Direction != SyncDirectionPush || e.Strategy != "merge" || e.DeviceID != "dev-1" || e.Success || e.Error == "" {
+		t.Errorf("历史记录不正确: %+v", e)
+	}
+
+	// 超过大小上限时轮转，旧记录仍可读取
+	if err := os.WriteFile(sm.SyncHistoryPath(), []byte(strings.Repeat("invalid\n", syncHistoryMaxSize/8+1)), 0o600); err != nil {
+		t.Fatalf("写入历史失败: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := sm.AppendHistory(SyncHistoryEntry{Direction: SyncDirectionPull, MirrorCount: i, Success: true}); err != nil {
+			t.Fatalf("AppendHistory() error = %v", err)
 		}
 	}
+	if info, err := os.Stat(sm.SyncHistoryPath()); err != nil || info.Size() >= syncHistoryMaxSize {
+		t.Fatalf("历史文件应已轮转: %v", err)
+	}
+	if _, err := os.Stat(sm.SyncHistoryPath() + ".1"); err != nil {
+		t.Fatalf("轮转后的文件不存在: %v", err)
+	}
+
+	entries, err = sm.ReadHistory(2)
+	if err != nil {
+		t.Fatalf("ReadHistory() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].MirrorCount != 1 || entries[1].MirrorCount != 2 {
+		t.Errorf("应返回最近的 2 条记录，实际: %+v", entries)
+	}
 }
 
 // TestGenerateEncryptKey 测试生成加密密钥.
@@ -860,7 +1366,7 @@ func TestMirrorManagerSoftDelete(t *testing.T) {
 	}
 
 	// 软删除镜像源
-	err = mm.RemoveMirrorWithOptions("test-mirror", false)
+	_, err = mm.RemoveMirrorWithOptions("test-mirror", false)
 	if err != nil {
 		t.Fatalf("RemoveMirrorWithOptions error = %v", err)
 	}
@@ -945,8 +1451,203 @@ func TestExportSyncDataWithDeletedMirrors(t *testing.T) {
 	}
 
 	// 验证版本号
-	if syncData.Version != "3.1" {
-		t.Errorf("Expected version 3.1, got: %v", syncData.Version)
+	if syncData.Version != syncDataVersion {
+		t.Errorf("Expected version %s, got: %v", syncDataVersion, syncData.Version)
+	}
+}
+
+// TestExportSyncDataDeterministic 测试已删除镜像源存储顺序不同但内容相同时导出的数据逐字节相同（不含写入时间）.
+func TestExportSyncDataDeterministic(t *testing.T) {
+	mm := createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t))
+	sm := NewSyncManager(mm)
+	sm.config = &SyncConfig{DeviceID: "test-device"}
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mirrors := []MirrorConfig{
+		{Name: "zeta", BaseURL: "https://zeta.example.com", ToolType: ToolTypeCodex, CreatedAt: created, LastModified: created},
+		{Name: "alpha", BaseURL: "https://alpha.example.com", ToolType: ToolTypeClaude, CreatedAt: created, LastModified: created},
+		{Name: "gone-b", BaseURL: "https://b.example.com", ToolType: ToolTypeCodex, CreatedAt: created, LastModified: created, Deleted: true, DeletedAt: created},
+		{Name: "gone-a", BaseURL: "https://a.example.com", ToolType: ToolTypeCodex, CreatedAt: created, LastModified: created, Deleted: true, DeletedAt: created},
+	}
+
+	export := func(order []int) []byte {
+		mm.config.Mirrors = nil
+		for _, i := range order {
+			mm.config.Mirrors = append(mm.config.Mirrors, mirrors[i])
+		}
+		syncData := sm.exportSyncData()
+		// Timestamp 记录写入时间，每次导出都不同，不在稳定性保证范围内
+		if syncData.Timestamp.IsZero() {
+			t.Fatal("导出数据应记录写入时间")
+		}
+		syncData.Timestamp = created
+		syncData.Integrity = calculateSyncDataIntegrity(syncData)
+		data, err := json.Marshal(syncData)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		return data
+	}
+
+	first := export([]int{0, 1, 2, 3})
+	second := export([]int{3, 0, 2, 1})
+	if !bytes.Equal(first, second) {
+		t.Errorf("相同配置的两次导出应逐字节相同:\n%s\n%s", first, second)
+	}
+
+	// 导出保持配置中的顺序，不打乱用户通过 reorder 设置的顺序
+	syncData := sm.exportSyncData()
+	var names []string
+	for _, mirror := range syncData.Mirrors {
+		names = append(names, mirror.Name)
+	}
+	if expected := []string{"zeta", "alpha"}; !slices.Equal(names, expected) {
+		t.Errorf("导出顺序 = %v, 期望 %v", names, expected)
+	}
+}
+
+// TestSelectDefaultMirrorDeterministic 测试当前激活源被删除时按固定规则选择默认镜像源.
+func TestSelectDefaultMirrorDeterministic(t *testing.T) {
+	resolver := NewConflictResolver(&SystemConfig{}, &SyncData{})
+	tests := []struct {
+		name     string
+		mirrors  []MirrorConfig
+		toolType ToolType
+		expected string
+	}{
+		{
+			name: "优先选择官方镜像源",
+			mirrors: []MirrorConfig{
+				{Name: "aaa", ToolType: ToolTypeCodex},
+				{Name: DefaultMirrorName, ToolType: ToolTypeCodex},
+			},
+			toolType: ToolTypeCodex,
+			expected: DefaultMirrorName,
+		},
+		{
+			name: "按名称选择同类型镜像源",
+			mirrors: []MirrorConfig{
+				{Name: "zeta", ToolType: ToolTypeClaude},
+				{Name: "beta", ToolType: ToolTypeClaude},
+				{Name: "alpha", ToolType: ToolTypeCodex},
+				{Name: "gamma", ToolType: ToolTypeClaude},
+			},
+			toolType: ToolTypeClaude,
+			expected: "beta",
+		},
+		{
+			name:     "没有同类型镜像源",
+			mirrors:  []MirrorConfig{{Name: "alpha", ToolType: ToolTypeCodex}},
+			toolType: ToolTypeClaude,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			available := make(map[string]MirrorConfig)
+			for _, mirror := range tt.mirrors {
+				available[mirror.Name] = mirror
+			}
+			for range 5 {
+				if got := resolver.selectDefaultMirror(available, tt.toolType); got != tt.expected {
+					t.Fatalf("selectDefaultMirror() = %q, 期望 %q", got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+// TestMergePreservesLocalOrder 测试合并后本地镜像源保持原有顺序，仅存在于云端的镜像源按名称追加.
+func TestMergePreservesLocalOrder(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newMirror := func(name string) MirrorConfig {
+		return MirrorConfig{Name: name, BaseURL: "https://" + name + ".example.com", ToolType: ToolTypeCodex, CreatedAt: created, LastModified: created}
+	}
+	local := &SystemConfig{Mirrors: []MirrorConfig{newMirror("zeta"), newMirror("alpha"), newMirror("mid")}}
+	remote := &SyncData{
+		DeviceID: "remote-device",
+		Mirrors:  []MirrorConfig{newMirror("new-b"), newMirror("alpha"), newMirror("zeta"), newMirror("new-a")},
+	}
+
+	for range 3 {
+		resolver := NewConflictResolver(local, remote)
+		resolver.SetInteractive(false)
+		resolution := resolver.DetectConflicts()
+
+		merged, err := resolver.resolveWithMerge(&SystemConfig{}, resolution)
+		if err != nil {
+			t.Fatalf("resolveWithMerge() error = %v", err)
+		}
+
+		var names []string
+		for _, mirror := range merged.Mirrors {
+			names = append(names, mirror.Name)
+		}
+		if expected := []string{"zeta", "alpha", "mid", "new-a", "new-b"}; !slices.Equal(names, expected) {
+			t.Fatalf("合并顺序 = %v, 期望 %v", names, expected)
+		}
+	}
+}
+
+// TestSyncDataIntegrity 测试完整性校验覆盖整个同步数据，并兼容只有 MD5 校验和的旧数据.
+func TestSyncDataIntegrity(t *testing.T) {
+	tempDir := setupTestDirWithCleanup(t)
+	mm := createTestMirrorManagerForSync(t, tempDir)
+	sm := NewSyncManager(mm)
+	sm.config = &SyncConfig{DeviceID: "test-device", SyncAPIKeys: true}
+
+	now := time.Now()
+	mm.config.CurrentCodex = "active-mirror"
+	mm.config.Mirrors = []MirrorConfig{
+		{Name: "active-mirror", BaseURL: "https://active.com", APIKey: "active-key", ToolType: ToolTypeCodex, CreatedAt: now, LastModified: now},
+		{Name: "deleted-mirror", BaseURL: "https://deleted.com", ToolType: ToolTypeClaude, CreatedAt: now, LastModified: now, Deleted: true, DeletedAt: now},
+	}
+
+	// 模拟上传后再下载的数据
+	roundTrip := func(t *testing.T) *SyncData {
+		t.Helper()
+		raw, err := json.Marshal(sm.exportSyncData())
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		var syncData SyncData
+		if err := json.Unmarshal(raw, &syncData); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		return &syncData
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*SyncData)
+		wantErr bool
+	}{
+		{"round trip", func(*SyncData) {}, false},
+		{"tampered current codex", func(d *SyncData) { d.CurrentCodex = "other" }, true},
+		{"tampered deleted mirrors", func(d *SyncData) { d.DeletedMirrors = nil }, true},
+		{"tampered device", func(d *SyncData) { d.DeviceID = "attacker" }, true},
+		{"tampered mirror", func(d *SyncData) { d.Mirrors[0].BaseURL = "https://evil.com" }, true},
+		{"unknown algorithm", func(d *SyncData) { d.Integrity = "sha1:abc" }, true},
+		{"legacy md5 only", func(d *SyncData) { d.Integrity = "" }, false},
+		{"legacy md5 tampered mirror", func(d *SyncData) { d.Integrity = ""; d.Mirrors[0].BaseURL = "https://evil.com" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncData := roundTrip(t)
+			if !strings.HasPrefix(syncData.Integrity, integrityAlgorithmSHA256+":") {
+				t.Fatalf("Integrity = %q, want sha256 prefix", syncData.Integrity)
+			}
+			tt.modify(syncData)
+
+			err := verifySyncDataIntegrity(syncData)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifySyncDataIntegrity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if syncData.ValidatedChecksum == tt.wantErr {
+				t.Errorf("ValidatedChecksum = %v, want %v", syncData.ValidatedChecksum, !tt.wantErr)
+			}
+		})
 	}
 }
 
@@ -1543,6 +2244,315 @@ func TestProviderInfo(t *testing.T) {
 	}
 }
 
+// TestGistAPIProviderAuth 测试 Gist 兼容提供商的请求地址与认证方式.
+func TestGistAPIProviderAuth(t *testing.T) {
+	tests := []struct {
+		name      string
+		queryAuth bool
+	}{
+		{name: "GitHub 使用请求头认证", queryAuth: false},
+		{name: "Gitee 使用查询参数认证", queryAuth: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotHeader, gotQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotHeader = r.Header.Get("Authorization")
+				gotQuery = r.URL.Query().Get("access_token")
+				_, _ = w.Write([]byte(`{"files":{"codex-mirror-config.json":{"content":"x"}}}`))
+			}))
+			defer server.Close()
+
+			provider, err := newGistAPIProvider("secret", "abc", server.URL+"/api/v5", "Test", tt.queryAuth)
+			if err != nil {
+				t.Fatalf("newGistAPIProvider() error = %v", err)
+			}
+			if _, err := provider.List(); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			if gotPath != "/api/v5/gists/abc" {
+				t.Errorf("path = %q, expected /api/v5/gists/abc", gotPath)
+			}
+			if tt.queryAuth {
+				if gotQuery != "secret" || gotHeader != "" {
+					t.Errorf("access_token = %q, Authorization = %q, expected query auth only", gotQuery, gotHeader)
+				}
+			} else if gotHeader != "token secret" || gotQuery != "" {
+				t.Errorf("Authorization = %q, access_token = %q, expected header auth only", gotHeader, gotQuery)
+			}
+		})
+	}
+
+	sm := NewSyncManager(createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t)))
+	provider, err := sm.createProvider(&SyncConfig{Provider: "gitee", Token: "secret", GistID: "abc"})
+	if err != nil {
+		t.Fatalf("createProvider(gitee) error = %v", err)
+	}
+	if info := provider.GetInfo(); info.Type != "gitee" || info.Endpoint != giteeAPIBase {
+		t.Errorf("GetInfo() = %+v, expected gitee provider", info)
+	}
+	if gistProvider, ok := provider.(gistIDProvider); !ok || gistProvider.GetGistID() != "abc" {
+		t.Errorf("gitee provider should expose Gist ID abc")
+	}
+}
+
+// TestPushRejectsConcurrentRemoteChange 测试下载后云端被其他设备修改时取消推送，不覆盖其修改.
+func TestPushRejectsConcurrentRemoteChange(t *testing.T) {
+	mm := createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t))
+	sm := NewSyncManager(mm)
+	sm.config = &SyncConfig{DeviceID: "test-device", EncryptionPwd: "test-password"}
+	sm.crypto = NewCryptoManager("test-password")
+	provider := NewMockSyncProvider()
+	sm.provider = provider
+
+	if err := sm.performPush(ConfigFileName, ""); err != nil {
+		t.Fatalf("首次推送 error = %v", err)
+	}
+	_, baseRevision, err := sm.downloadWithRevision(ConfigFileName)
+	if err != nil {
+		t.Fatalf("downloadWithRevision() error = %v", err)
+	}
+
+	// 其他设备在下载之后推送了新配置
+	if err := provider.Upload([]byte("other-device"), ConfigFileName); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if err := sm.performPush(ConfigFileName, baseRevision); !errors.Is(err, ErrRemoteChanged) {
+		t.Fatalf("performPush() error = %v, want ErrRemoteChanged", err)
+	}
+	if data, _ := provider.Download(ConfigFileName); string(data) != "other-device" {
+		t.Errorf("云端配置不应被覆盖，实际: %q", data)
+	}
+
+	// 重新下载后可以推送
+	_, baseRevision, _ = sm.downloadWithRevision(ConfigFileName)
+	if err := sm.performPush(ConfigFileName, baseRevision); err != nil {
+		t.Errorf("重新下载后推送 error = %v", err)
+	}
+
+	// 云端原本不存在时，推送前被其他设备创建也应取消
+	if err := provider.Delete(ConfigFileName); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	_, baseRevision, err = sm.downloadWithRevision(ConfigFileName)
+	if !errors.Is(err, ErrRemoteNotFound) || baseRevision != "" {
+		t.Fatalf("downloadWithRevision() = %q, %v, want ErrRemoteNotFound", baseRevision, err)
+	}
+	if err := provider.Upload([]byte("other-device"), ConfigFileName); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if err := sm.performPush(ConfigFileName, baseRevision); !errors.Is(err, ErrRemoteChanged) {
+		t.Errorf("performPush() error = %v, want ErrRemoteChanged", err)
+	}
+}
+
+// TestUnlink 测试停用云同步时清除本地凭据，并按需删除云端主配置文件.
+func TestUnlink(t *testing.T) {
+	tests := []struct {
+		name       string
+		keepRemote bool
+	}{
+		{name: "delete remote", keepRemote: false},
+		{name: "keep remote", keepRemote: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t))
+			mm.setSyncProfile(DefaultSyncProfile, &SyncConfig{
+				Enabled: true, AutoSync: true, Provider: "gist", Token: "ghp-secret",
+				EncryptionPwd: "test-password", EncryptKey: "random-key", GistID: "abc",
+				DeviceID: "dev-1", DeviceUUID: "uuid-1",
+			})
+			sm := NewSyncManager(mm)
+			sm.config = mm.GetSyncProfile(DefaultSyncProfile)
+			provider := NewMockSyncProvider()
+			sm.provider = provider
+			if err := provider.Upload([]byte("data"), ConfigFileName); err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+
+			if err := sm.unlink(tt.keepRemote); err != nil {
+				t.Fatalf("unlink() error = %v", err)
+			}
+
+			_, err := provider.Download(ConfigFileName)
+			if remoteExists := err == nil; remoteExists != tt.keepRemote {
+				t.Errorf("云端配置存在 = %v, want %v", remoteExists, tt.keepRemote)
+			}
+
+			reloaded, err := NewMirrorManagerWithPath(mm.GetConfigPath())
+			if err != nil {
+				t.Fatalf("重新加载配置失败: %v", err)
+			}
+			config := reloaded.GetSyncProfile(DefaultSyncProfile)
+			if config.Enabled || config.AutoSync || config.Token != "" || config.EncryptionPwd != "" || config.EncryptKey != "" || config.GistID != "" {
+				t.Errorf("应停用同步并清除凭据，实际: %+v", config)
+			}
+			if config.DeviceID != "dev-1" {
+				t.Errorf("设备ID应保留，实际: %s", config.DeviceID)
+			}
+		})
+	}
+
+	// 云端配置已不存在时仍可停用
+	mm := createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t))
+	mm.setSyncProfile(DefaultSyncProfile, &SyncConfig{Enabled: true, Provider: "gist", Token: "ghp-secret", DeviceID: "dev-1", DeviceUUID: "uuid-1"})
+	sm := NewSyncManager(mm)
+	sm.config = mm.GetSyncProfile(DefaultSyncProfile)
+	sm.provider = NewMockSyncProvider()
+	if err := sm.unlink(false); err != nil {
+		t.Errorf("unlink() without remote config error = %v", err)
+	}
+
+	// 未配置云同步时报错
+	if err := NewSyncManager(createTestMirrorManagerForSync(t, setupTestDirWithCleanup(t))).Unlink(true); err == nil {
+		t.Error("Unlink() 未配置云同步时应失败")
+	}
+}
+
+// TestGistRevision 测试 Gist 提供商以 updated_at 作为修订标识.
+func TestGistRevision(t *testing.T) {
+	updatedAt := "2024-05-01T12:00:00Z"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"updated_at":%q,"files":{}}`, updatedAt)
+	}))
+	defer server.Close()
+
+	provider, err := newGistAPIProvider("secret", "abc", server.URL, "Test", false)
+	if err != nil {
+		t.Fatalf("newGistAPIProvider() error = %v", err)
+	}
+	if revision, err := provider.Revision(ConfigFileName); err != nil || revision != updatedAt {
+		t.Errorf("Revision() = %q, %v, want %q", revision, err, updatedAt)
+	}
+
+	// 尚未创建 Gist 时修订标识为空
+	empty := &GistProvider{}
+	if revision, err := empty.Revision(ConfigFileName); err != nil || revision != "" {
+		t.Errorf("Revision() without Gist ID = %q, %v, want empty", revision, err)
+	}
+}
+
+// fakeGitLabSnippets 模拟 GitLab 代码片段 API，只保存一个代码片段.
+type fakeGitLabSnippets struct {
+	created bool
+	files   map[string]string
+}
+
+func (f *fakeGitLabSnippets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	snippet := func() map[string]interface{} {
+		var files []map[string]string
+		for path := range f.files {
+			files = append(files, map[string]string{"path": path})
+		}
+		return map[string]interface{}{"id": 7, "title": gitlabSnippetTitle, "updated_at": "2026-01-01T00:00:00Z", "files": files}
+	}
+	var payload struct {
+		Files []gitlabSnippetFileAction `json:"files"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&payload)
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/api/v4/snippets":
+		list := []map[string]interface{}{}
+		if f.created {
+			list = append(list, snippet())
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.Method == "POST" && r.URL.Path == "/api/v4/snippets":
+		f.created = true
+		for _, file := range payload.Files {
+			f.files[file.FilePath] = file.Content
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(snippet())
+	case r.Method == "GET" && r.URL.Path == "/api/v4/snippets/7":
+		_ = json.NewEncoder(w).Encode(snippet())
+	case r.Method == "PUT" && r.URL.Path == "/api/v4/snippets/7":
+		for _, file := range payload.Files {
+			if file.Action == "delete" {
+				delete(f.files, file.FilePath)
+			} else {
+				f.files[file.FilePath] = file.Content
+			}
+		}
+		_ = json.NewEncoder(w).Encode(snippet())
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/api/v4/snippets/7/files/HEAD/"):
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v4/snippets/7/files/HEAD/"), "/raw")
+		_, _ = w.Write([]byte(f.files[name]))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// TestGitLabSnippetProvider 测试 GitLab 代码片段的创建、读写、删除与按标题自动发现.
+func TestGitLabSnippetProvider(t *testing.T) {
+	server := httptest.NewServer(&fakeGitLabSnippets{files: map[string]string{}})
+	defer server.Close()
+	endpoint := server.URL + "/api/v4/"
+
+	provider, err := NewGitLabSnippetProvider(endpoint, "secret", "")
+	if err != nil {
+		t.Fatalf("NewGitLabSnippetProvider() error = %v", err)
+	}
+	if _, err := provider.Download(ConfigFileName); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("Download() before upload error = %v, want ErrRemoteNotFound", err)
+	}
+
+	if err := provider.Upload([]byte("v1"), ConfigFileName); err != nil {
+		t.Fatalf("Upload() create error = %v", err)
+	}
+	if provider.GetGistID() != "7" {
+		t.Errorf("snippet ID = %q, expected 7", provider.GetGistID())
+	}
+	if err := provider.Upload([]byte("v2"), ConfigFileName); err != nil {
+		t.Fatalf("Upload() update error = %v", err)
+	}
+	if err := provider.Upload([]byte("old"), "codex-mirror-config-old.json"); err != nil {
+		t.Fatalf("Upload() second file error = %v", err)
+	}
+
+	// 新设备按标题发现已有代码片段
+	discovered, err := NewGitLabSnippetProvider(endpoint, "secret", "")
+	if err != nil {
+		t.Fatalf("NewGitLabSnippetProvider() error = %v", err)
+	}
+	if discovered.GetGistID() != "7" {
+		t.Fatalf("discovered snippet ID = %q, expected 7", discovered.GetGistID())
+	}
+	data, err := discovered.Download(ConfigFileName)
+	if err != nil || string(data) != "v2" {
+		t.Errorf("Download() = %q, %v, expected v2", data, err)
+	}
+
+	if err := discovered.Delete("codex-mirror-config-old.json"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	files, err := discovered.List()
+	if err != nil || !slices.Equal(files, []string{ConfigFileName}) {
+		t.Errorf("List() = %v, %v, expected [%s]", files, err, ConfigFileName)
+	}
+	if info := discovered.GetInfo(); info.Type != "gitlab" || info.Endpoint != server.URL+"/api/v4" {
+		t.Errorf("GetInfo() = %+v", info)
+	}
+
+	unauthorized, err := NewGitLabSnippetProvider(endpoint, "wrong", "7")
+	if err != nil {
+		t.Fatalf("NewGitLabSnippetProvider() error = %v", err)
+	}
+	if _, err := unauthorized.List(); err == nil || !strings.Contains(err.Error(), "GitLab API 错误 (401)") {
+		t.Errorf("List() with wrong token error = %v", err)
+	}
+}
+
 // setupTestDirWithCleanup 创建测试目录.
 func setupTestDirWithCleanup(t *testing.T) string {
 	tempDir, err := os.MkdirTemp("", "codex-mirror-test-*")
@@ -1582,3 +2592,221 @@ current_claude = ""
 
 	return mm
 }
+
+// TestMergeExtraEnvConflicts 测试合并时处理分歧的额外环境变量.
+func TestMergeExtraEnvConflicts(t *testing.T) {
+	now := time.Now()
+	local := MirrorConfig{
+		Name:         "extra-env-mirror",
+		BaseURL:      TestAPIURL,
+		ToolType:     ToolTypeClaude,
+		LastModified: now.Add(-time.Hour),
+		ExtraEnv: map[string]string{
+			"LOCAL_ONLY":     "local",
+			"API_TIMEOUT_MS": "1000",
+			"SAME":           "same",
+		},
+	}
+	remote := MirrorConfig{
+		Name:         "extra-env-mirror",
+		BaseURL:      TestAPIURL,
+		ToolType:     ToolTypeClaude,
+		LastModified: now,
+		ExtraEnv: map[string]string{
+			"REMOTE_ONLY":    "remote",
+			"API_TIMEOUT_MS": "2000",
+			"SAME":           "same",
+		},
+	}
+
+	resolver := NewConflictResolver(&SystemConfig{Mirrors: []MirrorConfig{local}}, &SyncData{DeviceID: "remote-device"})
+	resolver.SetInteractive(false)
+
+	if !resolver.isMirrorModified(&local, &remote) {
+		t.Error("ExtraEnv 不同时应视为已修改")
+	}
+
+	conflicts := resolver.DetectFieldConflicts(&local, &remote)
+	if len(conflicts) != 1 || conflicts[0].FieldName != "ExtraEnv.API_TIMEOUT_MS" {
+		t.Fatalf("期望只有 ExtraEnv.API_TIMEOUT_MS 冲突，实际: %+v", conflicts)
+	}
+
+	merged := make(map[string]MirrorConfig)
+	resolver.mergeExistingMirror(merged, &remote, local)
+
+	expected := map[string]string{
+		"LOCAL_ONLY":     "local",
+		"REMOTE_ONLY":    "remote",
+		"API_TIMEOUT_MS": "2000", // 远程更新，按时间戳选择远程
+		"SAME":           "same",
+	}
+	got := merged["extra-env-mirror"].ExtraEnv
+	if len(got) != len(expected) {
+		t.Fatalf("合并后 ExtraEnv = %v, 期望 %v", got, expected)
+	}
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("ExtraEnv[%s] = %q, 期望 %q", key, got[key], value)
+		}
+	}
+
+	// 合并不应修改本地原始数据
+	if _, exists := local.ExtraEnv["REMOTE_ONLY"]; exists || local.ExtraEnv["API_TIMEOUT_MS"] != "1000" {
+		t.Errorf("本地 ExtraEnv 被意外修改: %v", local.ExtraEnv)
+	}
+}
+
+// TestMergeRemoteNewerFields 测试非交互合并时各字段以最新修改的一方为准，远程较新时不丢弃远程的值.
+func TestMergeRemoteNewerFields(t *testing.T) {
+	enabled, disabled := false, true
+	tests := []struct {
+		name   string
+		local  func(m *MirrorConfig)
+		remote func(m *MirrorConfig)
+		check  func(t *testing.T, m MirrorConfig)
+	}{
+		{
+			name:   "HealthPath",
+			local:  func(m *MirrorConfig) { m.HealthPath = "/healthz" },
+			remote: func(m *MirrorConfig) { m.HealthPath = "/v1/ping" },
+			check: func(t *testing.T, m MirrorConfig) {
+				if m.HealthPath != "/v1/ping" {
+					t.Errorf("HealthPath = %q, 期望 /v1/ping", m.HealthPath)
+				}
+			},
+		},
+		{
+			name:   "TimeoutSeconds",
+			local:  func(m *MirrorConfig) { m.TimeoutSeconds = 10 },
+			remote: func(m *MirrorConfig) { m.TimeoutSeconds = 30 },
+			check: func(t *testing.T, m MirrorConfig) {
+				if m.TimeoutSeconds != 30 {
+					t.Errorf("TimeoutSeconds = %d, 期望 30", m.TimeoutSeconds)
+				}
+			},
+		},
+		{
+			name:   "TimeoutSeconds cleared remotely",
+			local:  func(m *MirrorConfig) { m.TimeoutSeconds = 10 },
+			remote: func(_ *MirrorConfig) {},
+			check: func(t *testing.T, m MirrorConfig) {
+				if m.TimeoutSeconds != 0 {
+					t.Errorf("TimeoutSeconds = %d, 期望 0", m.TimeoutSeconds)
+				}
+			},
+		},
+		{
+			name:   "DisableResponseStorage",
+			local:  func(m *MirrorConfig) { m.DisableResponseStorage = &disabled },
+			remote: func(m *MirrorConfig) { m.DisableResponseStorage = &enabled },
+			check: func(t *testing.T, m MirrorConfig) {
+				if m.DisableResponseStorage == nil || *m.DisableResponseStorage {
+					t.Errorf("DisableResponseStorage = %v, 期望 false", m.DisableResponseStorage)
+				}
+			},
+		},
+		{
+			name:   "DisableResponseStorage set only remotely",
+			local:  func(_ *MirrorConfig) {},
+			remote: func(m *MirrorConfig) { m.DisableResponseStorage = &enabled },
+			check: func(t *testing.T, m MirrorConfig) {
+				if m.DisableResponseStorage == nil || *m.DisableResponseStorage {
+					t.Errorf("DisableResponseStorage = %v, 期望 false", m.DisableResponseStorage)
+				}
+			},
+		},
+		{
+			name: "TestHeaders",
+			local: func(m *MirrorConfig) {
+				m.TestHeaders = map[string]string{"X-Org-Id": "org-old", "X-Local": "local"}
+			},
+			remote: func(m *MirrorConfig) {
+				m.TestHeaders = map[string]string{"X-Org-Id": "org-new", "X-Remote": "remote"}
+			},
+			check: func(t *testing.T, m MirrorConfig) {
+				expected := map[string]string{"X-Org-Id": "org-new", "X-Local": "local", "X-Remote": "remote"}
+				if !maps.Equal(m.TestHeaders, expected) {
+					t.Errorf("TestHeaders = %v, 期望 %v", m.TestHeaders, expected)
+				}
+			},
+		},
+		{
+			name:   "APIKeyCommand",
+			local:  func(m *MirrorConfig) { m.APIKey = "sk-local-12345678" },
+			remote: func(m *MirrorConfig) { m.APIKeyCommand = "pass show ai/remote" },
+			check: func(t *testing.T, m MirrorConfig) {
+				if m.APIKeyCommand != "pass show ai/remote" || m.APIKey != "" {
+					t.Errorf("APIKeyCommand/APIKey = %q/%q, 期望使用远程命令并清空密钥", m.APIKeyCommand, m.APIKey)
+				}
+			},
+		},
+		{
+			name:   "ProviderKind and APIVersion",
+			local:  func(_ *MirrorConfig) {},
+			remote: func(m *MirrorConfig) { m.ProviderKind, m.APIVersion = ProviderKindAzure, "2025-04-01-preview" },
+			check: func(t *testing.T, m MirrorConfig) {
+				if m.ProviderKind != ProviderKindAzure || m.APIVersion != "2025-04-01-preview" {
+					t.Errorf("ProviderKind/APIVersion = %q/%q, 期望 azure/2025-04-01-preview", m.ProviderKind, m.APIVersion)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			local := MirrorConfig{Name: "fields-mirror", BaseURL: TestAPIURL, ToolType: ToolTypeCodex, LastModified: now.Add(-time.Hour)}
+			remote := MirrorConfig{Name: "fields-mirror", BaseURL: TestAPIURL, ToolType: ToolTypeCodex, LastModified: now}
+			tt.local(&local)
+			tt.remote(&remote)
+
+			resolver := NewConflictResolver(&SystemConfig{Mirrors: []MirrorConfig{local}}, &SyncData{DeviceID: "remote-device"})
+			resolver.SetInteractive(false)
+
+			if !resolver.isMirrorModified(&local, &remote) {
+				t.Fatal("字段不同时应视为已修改")
+			}
+
+			merged := make(map[string]MirrorConfig)
+			resolver.mergeExistingMirror(merged, &remote, local)
+			tt.check(t, merged["fields-mirror"])
+		})
+	}
+}
+
+// TestMergeTagsUnion 测试合并时标签取本地和远程的并集.
+func TestMergeTagsUnion(t *testing.T) {
+	local := MirrorConfig{
+		Name:     "tagged-mirror",
+		BaseURL:  TestAPIURL,
+		ToolType: ToolTypeCodex,
+		Tags:     []string{"work", "cheap"},
+	}
+	remote := MirrorConfig{
+		Name:     "tagged-mirror",
+		BaseURL:  TestAPIURL,
+		ToolType: ToolTypeCodex,
+		Tags:     []string{"cheap", "personal"},
+	}
+
+	resolver := NewConflictResolver(&SystemConfig{Mirrors: []MirrorConfig{local}}, &SyncData{DeviceID: "remote-device"})
+	resolver.SetInteractive(false)
+
+	if !resolver.isMirrorModified(&local, &remote) {
+		t.Error("Tags 不同时应视为已修改")
+	}
+	if conflicts := resolver.DetectFieldConflicts(&local, &remote); len(conflicts) != 0 {
+		t.Errorf("Tags 不应产生需要用户选择的冲突，实际: %+v", conflicts)
+	}
+
+	merged := make(map[string]MirrorConfig)
+	resolver.mergeExistingMirror(merged, &remote, local)
+
+	expected := []string{"work", "cheap", "personal"}
+	if got := merged["tagged-mirror"].Tags; !slices.Equal(got, expected) {
+		t.Errorf("合并后 Tags = %v, 期望 %v", got, expected)
+	}
+	if !slices.Equal(local.Tags, []string{"work", "cheap"}) {
+		t.Errorf("本地 Tags 被意外修改: %v", local.Tags)
+	}
+}
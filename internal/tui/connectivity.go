@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"codex-mirror/internal"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// spinnerInterval 测试进行中加载动画的刷新间隔.
+const spinnerInterval = 100 * time.Millisecond
+
+// spinnerFrames 加载动画的帧.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// testResultMsg 连通性测试完成后返回的消息.
+type testResultMsg struct {
+	result *internal.TestResult
+}
+
+// spinnerTickMsg 加载动画刷新消息.
+type spinnerTickMsg struct{}
+
+// runTestCmd 在后台执行连通性测试，完成后返回 testResultMsg，避免阻塞 Update.
+func runTestCmd(mirror internal.MirrorConfig) tea.Cmd {
+	return func() tea.Msg {
+		tester := &internal.ConnectivityTester{}
+		return testResultMsg{result: tester.Test(&mirror, internal.DefaultTestTimeout, 0)}
+	}
+}
+
+// spinnerTick 定时触发加载动画刷新.
+func spinnerTick() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	})
+}
+
+// startTest 开始测试光标所在的镜像源，测试进行中时忽略.
+func (m model) startTest() (tea.Model, tea.Cmd) {
+	if m.testing || len(m.mirrors) == 0 {
+		return m, nil
+	}
+
+	mirror := m.mirrors[m.cursor]
+	m.testing = true
+	m.testingName = mirror.Name
+	m.testResult = nil
+	m.spinnerFrame = 0
+	return m, tea.Batch(runTestCmd(mirror), spinnerTick())
+}
+
+// updateTest 处理测试结果和加载动画消息.
+func (m model) updateTest(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case testResultMsg:
+		m.testing = false
+		m.testResult = msg.result
+	case spinnerTickMsg:
+		if m.testing {
+			m.spinnerFrame = (m.spinnerFrame + 1) % len(spinnerFrames)
+			return m, spinnerTick()
+		}
+	}
+	return m, nil
+}
+
+// viewTestStatus 渲染测试进度或最近一次测试结果.
+func (m model) viewTestStatus() string {
+	if m.testing {
+		return fmt.Sprintf("%s 正在测试 %s ...\n", spinnerFrames[m.spinnerFrame], m.testingName)
+	}
+	if m.testResult == nil {
+		return ""
+	}
+
+	r := m.testResult
+	status := "✓ 可用"
+	if !r.Success {
+		status = "✗ " + r.Error
+	}
+	s := fmt.Sprintf("测试 %s: %s\n", r.Name, status)
+	s += fmt.Sprintf("  延迟: %dms", r.Latency)
+	if r.StatusCode > 0 {
+		s += fmt.Sprintf("  状态码: %d", r.StatusCode)
+	}
+	return s + "\n"
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+
+	"codex-mirror/internal"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// visibleItems 列表屏幕一次显示的镜像源数量.
+const visibleItems = 5
+
+// updateFilter 处理过滤模式下的输入：字符实时缩小列表，Enter 确认，Esc 清除过滤条件.
+func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m.leaveFilteredScreen(), nil
+	case tea.KeyEsc:
+		return m.clearFilter(), nil
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyUp:
+		m = m.moveCursor(-1)
+	case tea.KeyDown:
+		m = m.moveCursor(1)
+	case tea.KeyBackspace:
+		if runes := []rune(m.filterQuery); len(runes) > 0 {
+			m.filterQuery = string(runes[:len(runes)-1])
+			m = m.applyFilter()
+		}
+	case tea.KeySpace:
+		m.filterQuery += " "
+		m = m.applyFilter()
+	case tea.KeyRunes:
+		m.filterQuery += string(msg.Runes)
+		m = m.applyFilter()
+	}
+
+	return m, nil
+}
+
+// applyFilter 按过滤条件重新筛选镜像源，并将光标和滚动偏移重置到筛选结果的开头.
+func (m model) applyFilter() model {
+	m.cursor = 0
+	m.scrollOffset = 0
+	m.keyReveal = false
+	if m.mm == nil {
+		return m
+	}
+
+	m.mirrors = nil
+	for _, mirror := range m.mm.ListActiveMirrors() {
+		if internal.MirrorMatches(&mirror, m.filterQuery) {
+			m.mirrors = append(m.mirrors, mirror)
+		}
+	}
+	return m
+}
+
+// clearFilter 退出过滤模式并恢复完整的镜像源列表.
+func (m model) clearFilter() model {
+	m.filtering = false
+	m.filterQuery = ""
+	return m.applyFilter()
+}
+
+// leaveFilteredScreen 清除过滤条件并返回主菜单.
+func (m model) leaveFilteredScreen() model {
+	m = m.clearFilter()
+	m.screen = screenMainMenu
+	return m
+}
+
+// moveCursor 在当前（可能已过滤的）列表中移动光标，保持光标处于可见范围内，并重新隐藏完整密钥.
+func (m model) moveCursor(delta int) model {
+	cursor := m.cursor + delta
+	if cursor < 0 || cursor >= len(m.mirrors) {
+		return m
+	}
+
+	m.cursor = cursor
+	m.keyReveal = false
+	if m.cursor < m.scrollOffset {
+		m.scrollOffset = m.cursor
+	}
+	if m.cursor >= m.scrollOffset+visibleItems {
+		m.scrollOffset = m.cursor - visibleItems + 1
+	}
+	return m
+}
+
+// viewFilter 渲染过滤输入框或当前生效的过滤条件.
+func (m model) viewFilter() string {
+	switch {
+	case m.filtering:
+		return fmt.Sprintf("过滤: %s█  (Enter 确认, Esc 清除)\n", m.filterQuery)
+	case m.filterQuery != "":
+		return fmt.Sprintf("过滤: %s  (共 %d 个匹配, Esc 清除)\n", m.filterQuery, len(m.mirrors))
+	}
+	return ""
+}
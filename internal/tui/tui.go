@@ -3,7 +3,6 @@ package tui
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"codex-mirror/internal"
 
@@ -20,6 +19,8 @@ const (
 	screenAddMirror                  // 添加镜像源屏幕
 	screenRemoveMirror               // 删除镜像源屏幕
 	screenViewStatus                 // 查看状态屏幕
+	screenSelectEdit                 // 选择要编辑的镜像源屏幕
+	screenEditMirror                 // 编辑镜像源屏幕
 
 	// 按键常量.
 	keyCtrlC = "ctrl+c"
@@ -55,6 +56,17 @@ type model struct {
 	inputURL      string // 输入的URL
 	inputAPIKey   string // 输入的API Key
 	inputToolType string // 输入的工具类型
+	inputModel    string // 输入的模型名称（仅编辑）
+	keyMasked     bool   // 编辑时 API Key 是否仍以掩码显示（用户开始编辑前）
+	keyReveal     bool   // 列表中是否显示选中镜像源的完整 API Key（仅用于显示）
+	// 用于连通性测试的字段
+	testing      bool                 // 是否有测试正在进行
+	testingName  string               // 正在测试的镜像源名称
+	testResult   *internal.TestResult // 最近一次测试结果
+	spinnerFrame int                  // 加载动画当前帧
+	// 用于列表过滤的字段
+	filtering   bool   // 是否正在输入过滤条件
+	filterQuery string // 过滤条件
 	// 用于显示状态的字段
 	quitting bool // 是否正在退出
 }
@@ -69,7 +81,7 @@ func initialModel() model {
 
 	return model{
 		screen:   screenMainMenu,
-		choices:  []string{"列出镜像源", "切换镜像源", "添加镜像源", "删除镜像源", "查看状态", "退出"},
+		choices:  []string{"列出镜像源", "切换镜像源", "添加镜像源", "编辑镜像源", "删除镜像源", "查看状态", "退出"},
 		selected: make(map[int]struct{}),
 		mm:       mm,
 		mirrors:  mirrors,
@@ -83,11 +95,21 @@ func (m model) Init() tea.Cmd {
 
 // Update 处理消息和更新状态.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// 测试结果和加载动画消息不清除界面上的消息
+	switch msg.(type) {
+	case testResultMsg, spinnerTickMsg:
+		return m.updateTest(msg)
+	}
+
 	// 清除之前的消息和错误
 	m.error = ""
 	m.message = ""
 
 	if msg, ok := msg.(tea.KeyMsg); ok {
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+
 		switch m.screen {
 		case screenMainMenu:
 			return m.updateMainMenu(msg)
@@ -101,6 +123,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateRemoveMirror(msg)
 		case screenViewStatus:
 			return m.updateViewStatus(msg)
+		case screenSelectEdit:
+			return m.updateSelectEdit(msg)
+		case screenEditMirror:
+			return m.updateEditMirror(msg)
 		}
 	}
 
@@ -154,6 +180,12 @@ func (m model) handleMainMenuChoice() (tea.Model, tea.Cmd) {
 		m.inputAPIKey = ""
 		m.inputToolType = toolTypeCodex
 		m.cursor = 0
+	case "编辑镜像源":
+		if m.mm != nil {
+			m.mirrors = m.mm.ListActiveMirrors()
+		}
+		m.screen = screenSelectEdit
+		m.cursor = 0
 	case "删除镜像源":
 		if m.mm != nil {
 			m.mirrors = m.mm.ListActiveMirrors()
@@ -171,27 +203,31 @@ func (m model) handleMainMenuChoice() (tea.Model, tea.Cmd) {
 // updateListMirrors 处理列出镜像源屏幕更新.
 func (m model) updateListMirrors(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case keyCtrlC, "q", keyEsc, "b":
-		m.screen = screenMainMenu
-		m.cursor = 0
-		m.scrollOffset = 0
+	case keyEsc:
+		if m.keyReveal {
+			m.keyReveal = false
+			return m, nil
+		}
+		if m.filterQuery != "" {
+			return m.clearFilter(), nil
+		}
+		return m.leaveFilteredScreen(), nil
+	case keyCtrlC, "q", "b":
+		return m.leaveFilteredScreen(), nil
+	case "/":
+		m.filtering = true
 	case "up", "k":
-		if m.cursor > 0 {
-			m.cursor--
-			// 调整滚动偏移
-			if m.cursor < m.scrollOffset {
-				m.scrollOffset = m.cursor
-			}
-		}
+		m = m.moveCursor(-1)
 	case keyDown, "j":
-		if m.cursor < len(m.mirrors)-1 {
-			m.cursor++
-			// 调整滚动偏移
-			visibleItems := 5 // 假设一次显示5个镜像源
-			if m.cursor >= m.scrollOffset+visibleItems {
-				m.scrollOffset = m.cursor - visibleItems + 1
-			}
-		}
+		m = m.moveCursor(1)
+	case "e", keyEnter:
+		if len(m.mirrors) > 0 {
+			return m.startEditMirror(&m.mirrors[m.cursor])
+		}
+	case "t":
+		return m.startTest()
+	case "s":
+		m.keyReveal = !m.keyReveal
 	}
 
 	return m, nil
@@ -200,21 +236,23 @@ func (m model) updateListMirrors(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // updateSwitchMirror 处理切换镜像源屏幕更新.
 func (m model) updateSwitchMirror(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case keyCtrlC, "q", keyEsc, "b":
-		m.screen = screenMainMenu
-		m.cursor = 0
+	case keyEsc:
+		if m.filterQuery != "" {
+			return m.clearFilter(), nil
+		}
+		return m.leaveFilteredScreen(), nil
+	case keyCtrlC, "q", "b":
+		return m.leaveFilteredScreen(), nil
+	case "/":
+		m.filtering = true
 	case "up", "k":
-		if m.cursor > 0 {
-			m.cursor--
-		}
+		m = m.moveCursor(-1)
 	case keyDown, "j":
-		if m.cursor < len(m.mirrors)-1 {
-			m.cursor++
-		}
+		m = m.moveCursor(1)
 	case keyEnter:
 		if m.mm != nil && len(m.mirrors) > 0 {
 			mirror := m.mirrors[m.cursor]
-			if mirror.Name != internal.DefaultMirrorName || m.canDeleteOfficial() {
+			if !m.isOfficialMirror(mirror.Name) || m.canDeleteOfficial() {
 				err := m.mm.SwitchMirror(mirror.Name)
 				if err != nil {
 					m.error = fmt.Sprintf("切换失败: %v", err)
@@ -223,6 +261,8 @@ func (m model) updateSwitchMirror(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 		}
+	case "t":
+		return m.startTest()
 	}
 
 	return m, nil
@@ -274,6 +314,10 @@ func (m model) handleAddMirrorInput() (tea.Model, tea.Cmd) {
 	case 2: // 输入API Key
 		m.inputStep++
 	case 3: // 选择工具类型
+		if m.screen == screenEditMirror {
+			m.inputStep++
+			return m, nil
+		}
 		if m.mm != nil {
 			var toolType internal.ToolType
 			if m.inputToolType == toolTypeCodex {
@@ -292,6 +336,8 @@ func (m model) handleAddMirrorInput() (tea.Model, tea.Cmd) {
 				m.cursor = 0
 			}
 		}
+	case 4: // 输入模型名称（仅编辑）
+		return m.saveEditedMirror()
 	}
 
 	return m, nil
@@ -307,17 +353,28 @@ func (m model) handleAddMirrorBackspace() (tea.Model, tea.Cmd) {
 	case 1: // 输入URL
 		if m.inputURL != "" {
 			m.inputURL = m.inputURL[:len(m.inputURL)-1]
-		} else {
+		} else if m.screen != screenEditMirror { // 编辑时名称不可修改
 			m.inputStep--
 		}
 	case 2: // 输入API Key
-		if m.inputAPIKey != "" {
+		switch {
+		case m.keyMasked:
+			// 开始编辑已有的 API Key 时清空原值
+			m.inputAPIKey = ""
+			m.keyMasked = false
+		case m.inputAPIKey != "":
 			m.inputAPIKey = m.inputAPIKey[:len(m.inputAPIKey)-1]
-		} else {
+		default:
 			m.inputStep--
 		}
 	case 3: // 选择工具类型
 		m.inputStep--
+	case 4: // 输入模型名称（仅编辑）
+		if m.inputModel != "" {
+			m.inputModel = m.inputModel[:len(m.inputModel)-1]
+		} else {
+			m.inputStep--
+		}
 	}
 
 	return m, nil
@@ -331,12 +388,126 @@ func (m model) handleAddMirrorChar(char string) (tea.Model, tea.Cmd) {
 	case 1: // 输入URL
 		m.inputURL += char
 	case 2: // 输入API Key
+		if m.keyMasked {
+			// 开始编辑已有的 API Key 时用新输入替换原值
+			m.inputAPIKey = ""
+			m.keyMasked = false
+		}
 		m.inputAPIKey += char
+	case 4: // 输入模型名称（仅编辑）
+		m.inputModel += char
+	}
+
+	return m, nil
+}
+
+// updateSelectEdit 处理选择要编辑的镜像源屏幕更新.
+func (m model) updateSelectEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case keyCtrlC, "q", keyEsc, "b":
+		m.screen = screenMainMenu
+		m.cursor = 0
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case keyDown, "j":
+		if m.cursor < len(m.mirrors)-1 {
+			m.cursor++
+		}
+	case keyEnter:
+		if m.mm != nil && len(m.mirrors) > 0 {
+			return m.startEditMirror(&m.mirrors[m.cursor])
+		}
 	}
 
 	return m, nil
 }
 
+// startEditMirror 进入编辑屏幕，并用所选镜像源的当前配置预填输入字段.
+func (m model) startEditMirror(mirror *internal.MirrorConfig) (tea.Model, tea.Cmd) {
+	if m.isOfficialMirror(mirror.Name) {
+		m.error = "不能编辑官方镜像源"
+		return m, nil
+	}
+
+	m.screen = screenEditMirror
+	m.inputStep = 1 // 名称不可修改，从 URL 开始
+	m.inputName = mirror.Name
+	m.inputURL = mirror.BaseURL
+	m.inputAPIKey = mirror.APIKey
+	m.keyMasked = mirror.APIKey != ""
+	m.inputToolType = string(mirror.ToolType)
+	m.inputModel = mirror.ModelName
+	return m, nil
+}
+
+// updateEditMirror 处理编辑镜像源屏幕更新.
+// 编辑时字段中经常包含字母，因此只用 Esc 返回.
+func (m model) updateEditMirror(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case keyCtrlC, keyEsc:
+		m.screen = screenMainMenu
+		m.cursor = 0
+	case keyEnter:
+		return m.handleAddMirrorInput()
+	case "backspace":
+		return m.handleAddMirrorBackspace()
+	case "up", keyDown:
+		if m.inputStep == 3 { // 工具类型选择
+			if m.inputToolType == toolTypeCodex {
+				m.inputToolType = toolTypeClaude
+			} else {
+				m.inputToolType = toolTypeCodex
+			}
+		}
+	default:
+		if len(msg.String()) == 1 {
+			return m.handleAddMirrorChar(msg.String())
+		}
+	}
+
+	return m, nil
+}
+
+// saveEditedMirror 将编辑后的字段通过 UpdateMirrorFull 保存，只提交有变化的字段.
+func (m model) saveEditedMirror() (tea.Model, tea.Cmd) {
+	if m.mm == nil {
+		return m, nil
+	}
+
+	original, err := m.mm.GetMirrorByName(m.inputName)
+	if err != nil {
+		m.error = fmt.Sprintf("镜像源 '%s' 不存在", m.inputName)
+		return m, nil
+	}
+
+	var baseURL, apiKey, modelName, toolType string
+	if m.inputURL != original.BaseURL {
+		baseURL = m.inputURL
+	}
+	if m.inputAPIKey != original.APIKey {
+		apiKey = m.inputAPIKey
+	}
+	if m.inputModel != original.ModelName {
+		modelName = m.inputModel
+	}
+	if m.inputToolType != string(original.ToolType) {
+		toolType = m.inputToolType
+	}
+
+	if err := m.mm.UpdateMirrorFull(m.inputName, baseURL, apiKey, modelName, toolType); err != nil {
+		m.error = fmt.Sprintf("更新失败: %v", err)
+		return m, nil
+	}
+
+	m.message = fmt.Sprintf("成功更新镜像源: %s", m.inputName)
+	m.mirrors = m.mm.ListActiveMirrors()
+	m.screen = screenMainMenu
+	m.cursor = 0
+	return m, nil
+}
+
 // updateRemoveMirror 处理删除镜像源屏幕更新.
 func (m model) updateRemoveMirror(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -354,7 +525,7 @@ func (m model) updateRemoveMirror(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case keyEnter:
 		if m.mm != nil && len(m.mirrors) > 0 {
 			mirror := m.mirrors[m.cursor]
-			if mirror.Name != internal.DefaultMirrorName {
+			if !m.isOfficialMirror(mirror.Name) {
 				err := m.mm.RemoveMirror(mirror.Name)
 				if err != nil {
 					m.error = fmt.Sprintf("删除失败: %v", err)
@@ -385,6 +556,14 @@ func (m model) updateViewStatus(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// isOfficialMirror 判断镜像源是否为受保护的官方镜像源.
+func (m model) isOfficialMirror(name string) bool {
+	if m.mm == nil {
+		return name == internal.DefaultMirrorName
+	}
+	return m.mm.IsOfficialMirror(name)
+}
+
 // canDeleteOfficial 判断是否可以删除官方镜像源（始终返回false）.
 func (m model) canDeleteOfficial() bool {
 	return false
@@ -411,6 +590,10 @@ func (m model) View() string {
 		s = m.viewRemoveMirror()
 	case screenViewStatus:
 		s = m.viewViewStatus()
+	case screenSelectEdit:
+		s = m.viewSelectEdit()
+	case screenEditMirror:
+		s = m.viewEditMirror()
 	}
 
 	// 显示消息和错误
@@ -427,7 +610,7 @@ func (m model) View() string {
 // viewMainMenu 渲染主菜单.
 func (m model) viewMainMenu() string {
 	s := uiBorderTop
-	s += "║   Codex Mirror Switch TUI            ║\n"
+	s += fmt.Sprintf("║   %-35s║\n", "Codex Mirror Switch TUI "+internal.Version)
 	s += uiBorderBottom
 
 	for i, choice := range m.choices {
@@ -451,10 +634,11 @@ func (m model) viewListMirrors() string {
 	switch {
 	case m.mm == nil:
 		s += errMirrorManagerInit
+	case len(m.mirrors) == 0 && m.filterQuery != "":
+		s += fmt.Sprintf("没有匹配 '%s' 的镜像源\n", m.filterQuery)
 	case len(m.mirrors) == 0:
 		s += "没有配置的镜像源\n"
 	default:
-		visibleItems := 5 // 假设一次显示5个镜像源
 		start := m.scrollOffset
 		end := start + visibleItems
 		if end > len(m.mirrors) {
@@ -476,9 +660,12 @@ func (m model) viewListMirrors() string {
 			s += fmt.Sprintf("  类型: %s\n", mirror.ToolType)
 			s += fmt.Sprintf("  URL: %s\n", mirror.BaseURL)
 			if mirror.APIKey != "" {
-				// 只显示API Key的前4位和后4位
-				maskedKey := maskAPIKey(mirror.APIKey)
-				s += fmt.Sprintf("  API Key: %s\n", maskedKey)
+				if m.keyReveal && m.cursor == i {
+					s += fmt.Sprintf("  API Key: %s\n", mirror.APIKey)
+				} else {
+					// 只显示API Key的前4位和后4位
+					s += fmt.Sprintf("  API Key: %s\n", internal.MaskAPIKey(mirror.APIKey))
+				}
 			}
 			if mirror.ModelName != "" {
 				s += fmt.Sprintf("  模型: %s\n", mirror.ModelName)
@@ -492,7 +679,14 @@ func (m model) viewListMirrors() string {
 		}
 	}
 
-	s += "按 q 或 b 返回主菜单, 按 j/k 或 方向键滚动."
+	s += m.viewTestStatus()
+	s += m.viewFilter()
+	if m.keyReveal {
+		s += "⚠ 正在显示选中镜像源的完整 API Key (按 s 或 Esc 隐藏)\n"
+		s += "按 e 或 Enter 编辑, 按 t 测试, 按 / 过滤, 按 q 或 b 返回主菜单, 按 j/k 或 方向键滚动."
+	} else {
+		s += "按 e 或 Enter 编辑, 按 t 测试, 按 s 显示密钥, 按 / 过滤, 按 q 或 b 返回主菜单, 按 j/k 或 方向键滚动."
+	}
 	return s
 }
 
@@ -505,6 +699,8 @@ func (m model) viewSwitchMirror() string {
 	switch {
 	case m.mm == nil:
 		s += errMirrorManagerInit
+	case len(m.mirrors) == 0 && m.filterQuery != "":
+		s += fmt.Sprintf("没有匹配 '%s' 的镜像源\n", m.filterQuery)
 	case len(m.mirrors) == 0:
 		s += "没有可切换的镜像源\n"
 	default:
@@ -523,7 +719,9 @@ func (m model) viewSwitchMirror() string {
 		}
 	}
 
-	s += "\n按 Enter 切换, 按 q 或 b 返回主菜单."
+	s += "\n" + m.viewTestStatus()
+	s += m.viewFilter()
+	s += "按 Enter 切换, 按 t 测试, 按 / 过滤, 按 q 或 b 返回主菜单."
 	return s
 }
 
@@ -550,7 +748,7 @@ func (m model) viewAddMirror() string {
 		s += fmt.Sprintf("镜像源名称: %s\n", m.inputName)
 		s += fmt.Sprintf("API 基础 URL: %s\n", m.inputURL)
 		if m.inputAPIKey != "" {
-			s += fmt.Sprintf("API Key: %s\n", maskAPIKey(m.inputAPIKey))
+			s += fmt.Sprintf("API Key: %s\n", internal.MaskAPIKey(m.inputAPIKey))
 		}
 		s += "\n选择工具类型:\n"
 		cursorCodex := "  "
@@ -569,6 +767,80 @@ func (m model) viewAddMirror() string {
 	return s
 }
 
+// viewSelectEdit 渲染选择要编辑的镜像源屏幕.
+func (m model) viewSelectEdit() string {
+	s := uiBorderTop
+	s += "║         选择要编辑的镜像源           ║\n"
+	s += uiBorderBottom
+
+	switch {
+	case m.mm == nil:
+		s += errMirrorManagerInit
+	case len(m.mirrors) == 0:
+		s += "没有可编辑的镜像源\n"
+	default:
+		for i := range m.mirrors {
+			mirror := &m.mirrors[i]
+			cursor := "  "
+			if m.cursor == i {
+				cursor = uiCursor
+			}
+			locked := "  "
+			if m.isOfficialMirror(mirror.Name) {
+				locked = "🔒 "
+			}
+
+			s += fmt.Sprintf("%s%s%s [%s]\n", cursor, locked, mirror.Name, mirror.ToolType)
+		}
+		s += "\n🔒 表示不能编辑的官方镜像源."
+	}
+
+	s += "\n按 Enter 编辑, 按 q 或 b 返回主菜单."
+	return s
+}
+
+// viewEditMirror 渲染编辑镜像源屏幕.
+func (m model) viewEditMirror() string {
+	s := uiBorderTop
+	s += "║           编辑镜像源                 ║\n"
+	s += uiBorderBottom
+
+	// field 渲染单个字段，当前输入步骤的字段后显示光标
+	field := func(step int, label, value string) string {
+		if m.inputStep == step {
+			return fmt.Sprintf("%s: %s█\n", label, value)
+		}
+		return fmt.Sprintf("%s: %s\n", label, value)
+	}
+
+	apiKey := m.inputAPIKey
+	if m.keyMasked || m.inputStep != 2 {
+		apiKey = internal.MaskAPIKey(apiKey)
+	}
+
+	s += fmt.Sprintf("镜像源名称: %s\n", m.inputName)
+	s += field(1, "API 基础 URL", m.inputURL)
+	s += field(2, "API Key", apiKey)
+	s += fmt.Sprintf("工具类型: %s\n", m.inputToolType)
+	if m.inputStep >= 4 {
+		s += field(4, "模型名称 (可选)", m.inputModel)
+	}
+
+	switch m.inputStep {
+	case 1:
+		s += "\n修改 API 基础 URL，然后按 Enter 继续."
+	case 2:
+		s += "\n输入新的 API Key 替换原值 (直接按 Enter 保持不变)."
+	case 3:
+		s += "\n使用 ↑/↓ 切换工具类型，按 Enter 继续."
+	case 4:
+		s += "\n修改模型名称，按 Enter 保存."
+	}
+
+	s += "\n按 Esc 取消编辑."
+	return s
+}
+
 // viewRemoveMirror 渲染删除镜像源屏幕.
 func (m model) viewRemoveMirror() string {
 	s := uiBorderTop
@@ -588,7 +860,7 @@ func (m model) viewRemoveMirror() string {
 				cursor = uiCursor
 			}
 			locked := "  "
-			if mirror.Name == internal.DefaultMirrorName {
+			if m.isOfficialMirror(mirror.Name) {
 				locked = "🔒 "
 			}
 
@@ -646,14 +918,6 @@ func (m model) viewViewStatus() string {
 	return s
 }
 
-// maskAPIKey 掩码显示 API Key.
-func maskAPIKey(key string) string {
-	if len(key) <= 8 {
-		return strings.Repeat("*", len(key))
-	}
-	return key[:4] + strings.Repeat("*", len(key)-8) + key[len(key)-4:]
-}
-
 // Start 启动 TUI 应用.
 func Start() error {
 	p := tea.NewProgram(initialModel())
@@ -10,6 +10,26 @@ const (
 	ToolTypeClaude ToolType = "claude"
 )
 
+// ProviderKind Codex 镜像源的 API 形式.
+type ProviderKind string
+
+const (
+	ProviderKindOpenAI ProviderKind = "openai"
+	ProviderKindAzure  ProviderKind = "azure"
+)
+
+// ModelTier Claude Code 的模型级别.
+type ModelTier string
+
+const (
+	ModelTierHaiku  ModelTier = "haiku"
+	ModelTierSonnet ModelTier = "sonnet"
+	ModelTierOpus   ModelTier = "opus"
+)
+
+// ModelTiers 所有模型级别.
+var ModelTiers = []ModelTier{ModelTierHaiku, ModelTierSonnet, ModelTierOpus}
+
 // MirrorConfig 镜像源配置结构.
 type MirrorConfig struct {
 	Name         string    `json:"name" toml:"name"`                                       // 镜像源名称
@@ -18,12 +38,31 @@ type MirrorConfig struct {
 	EnvKey       string    `json:"env_key" toml:"env_key"`                                 // 环境变量key
 	ToolType     ToolType  `json:"tool_type" toml:"tool_type"`                             // 工具类型
 	ModelName    string    `json:"model_name,omitempty" toml:"model_name,omitempty"`       // 模型名称 (可选，主要用于Claude)
+	HaikuModel   string    `json:"haiku_model,omitempty" toml:"haiku_model,omitempty"`     // Claude haiku 级别模型 (可选)
+	SonnetModel  string    `json:"sonnet_model,omitempty" toml:"sonnet_model,omitempty"`   // Claude sonnet 级别模型 (可选)
+	OpusModel    string    `json:"opus_model,omitempty" toml:"opus_model,omitempty"`       // Claude opus 级别模型 (可选)
+	Proxy        string    `json:"proxy,omitempty" toml:"proxy,omitempty"`                 // HTTP 代理地址 (可选)
+	HealthPath   string    `json:"health_path,omitempty" toml:"health_path,omitempty"`     // 连通性测试路径 (可选，如 /healthz)
+	Tags         []string  `json:"tags,omitempty" toml:"tags,omitempty"`                   // 分组标签 (可选，如 work、personal)
 	CreatedAt    time.Time `json:"created_at,omitempty" toml:"created_at,omitempty"`       // 创建时间
 	LastModified time.Time `json:"last_modified,omitempty" toml:"last_modified,omitempty"` // 最后修改时间
 	Deleted      bool      `json:"deleted,omitempty" toml:"deleted,omitempty"`             // 删除标记
 	DeletedAt    time.Time `json:"deleted_at,omitempty" toml:"deleted_at,omitempty"`       // 删除时间
 	// Claude Code 额外环境变量配置
 	ExtraEnv map[string]string `json:"extra_env,omitempty" toml:"extra_env,omitempty"` // 额外环境变量 (如 ANTHROPIC_DEFAULT_HAIKU_MODEL 等)
+	// 连通性测试时附加的请求头，不会写入 Codex/Claude 配置文件
+	TestHeaders map[string]string `json:"test_headers,omitempty" toml:"test_headers,omitempty"` // 测试请求头 (如 X-Org-Id 等)
+	// Codex 提供商形式，空值等同于 openai；azure 使用 api-key 请求头和 api-version 查询参数
+	ProviderKind ProviderKind `json:"provider_kind,omitempty" toml:"provider_kind,omitempty"` // 提供商形式 (可选)
+	APIVersion   string       `json:"api_version,omitempty" toml:"api_version,omitempty"`     // Azure API 版本 (provider_kind 为 azure 时必需)
+	// 写入 Codex 配置的 disable_response_storage，未设置时默认为 true
+	DisableResponseStorage *bool `json:"disable_response_storage,omitempty" toml:"disable_response_storage,omitempty"`
+	// 从 /v1/models 获取并缓存的可用模型列表，切换时用于校验 ModelName
+	Models []string `json:"models,omitempty" toml:"models,omitempty"`
+	// 获取 API 密钥的命令 (如 op read、pass show)，设置后 APIKey 为空，应用和测试时执行命令读取密钥
+	APIKeyCommand string `json:"api_key_command,omitempty" toml:"api_key_command,omitempty"`
+	// 连通性测试的超时时间（秒），用于响应较慢的镜像源，为 0 时使用 test/doctor 的默认超时
+	TimeoutSeconds int `json:"timeout_seconds,omitempty" toml:"timeout_seconds,omitempty"`
 }
 
 // SystemConfig 系统配置结构.
@@ -32,7 +71,16 @@ type SystemConfig struct {
 	CurrentCodex  string         `json:"current_codex" toml:"current_codex"`   // 当前使用的 Codex 镜像源
 	CurrentClaude string         `json:"current_claude" toml:"current_claude"` // 当前使用的 Claude 镜像源
 	Mirrors       []MirrorConfig `json:"mirrors" toml:"mirrors"`               // 可用镜像源列表
-	Sync          *SyncConfig    `json:"sync,omitempty" toml:"sync,omitempty"` // 云同步配置
+	Sync          *SyncConfig    `json:"sync,omitempty" toml:"sync,omitempty"` // 旧版云同步配置（加载时迁移到 default 同步配置）
+	// 按名称区分的云同步配置，例如 "work" 和 "personal" 分别同步到不同的 Gist
+	SyncProfiles map[string]*SyncConfig `json:"sync_profiles,omitempty" toml:"sync_profiles,omitempty"`
+	// 受保护的官方镜像源名称和地址，为空时分别为 "official" 和 https://api.openai.com
+	OfficialName string `json:"official_name,omitempty" toml:"official_name,omitempty"`
+	OfficialURL  string `json:"official_url,omitempty" toml:"official_url,omitempty"`
+	// 切换时只更新配置文件，不把密钥写入 shell 配置文件或 Windows 用户环境变量
+	NoEnv bool `json:"no_env,omitempty" toml:"no_env,omitempty"`
+	// 模型别名（别名 -> 实际模型名称），镜像源的模型名称为别名时在应用配置时替换
+	ModelAliases map[string]string `json:"model_aliases,omitempty" toml:"model_aliases,omitempty"`
 }
 
 // CodexConfig Codex CLI配置文件结构.
@@ -53,6 +101,10 @@ type ModelProviderConfig struct {
 	WireAPI            string `toml:"wire_api,omitempty"`
 	EnvKey             string `toml:"env_key,omitempty"`
 	RequiresOpenAIAuth bool   `toml:"requires_openai_auth,omitempty"`
+	// QueryParams 附加到每个请求的查询参数（如 Azure 的 api-version）.
+	QueryParams map[string]string `toml:"query_params,omitempty"`
+	// EnvHTTPHeaders 从环境变量读取值的请求头（请求头名 -> 环境变量名）.
+	EnvHTTPHeaders map[string]string `toml:"env_http_headers,omitempty"`
 }
 
 // CodexAuth Codex CLI认证文件结构.
@@ -93,6 +145,12 @@ const (
 	AnthropicBaseURLEnv   = "ANTHROPIC_BASE_URL"
 	AnthropicAuthTokenEnv = "ANTHROPIC_AUTH_TOKEN"
 	AnthropicModelEnv     = "ANTHROPIC_MODEL"
+	HTTPSProxyEnv         = "HTTPS_PROXY"
+	HTTPProxyEnv          = "HTTP_PROXY"
+
+	AnthropicDefaultHaikuModelEnv  = "ANTHROPIC_DEFAULT_HAIKU_MODEL"
+	AnthropicDefaultSonnetModelEnv = "ANTHROPIC_DEFAULT_SONNET_MODEL"
+	AnthropicDefaultOpusModelEnv   = "ANTHROPIC_DEFAULT_OPUS_MODEL"
 
 	// 默认镜像源名称.
 	DefaultMirrorName = "official"
@@ -107,6 +165,16 @@ const (
 	BatShell        = "bat"
 )
 
+// VSCodeVariant VS Code 发行版本.
+type VSCodeVariant string
+
+const (
+	// VSCodeStable 稳定版 (Code).
+	VSCodeStable VSCodeVariant = "stable"
+	// VSCodeInsiders 预览版 (Code - Insiders).
+	VSCodeInsiders VSCodeVariant = "insiders"
+)
+
 // PathConfig 路径配置结构.
 type PathConfig struct {
 	CodexConfigDir  string // Codex配置目录.
@@ -117,7 +185,7 @@ type PathConfig struct {
 // SyncConfig 云同步配置结构.
 type SyncConfig struct {
 	Enabled       bool      `json:"enabled" toml:"enabled"`                                   // 是否启用同步
-	Provider      string    `json:"provider" toml:"provider"`                                 // 同步提供商 (gist, webdav, custom)
+	Provider      string    `json:"provider" toml:"provider"`                                 // 同步提供商 (gist, gitee, gitlab)
 	Endpoint      string    `json:"endpoint" toml:"endpoint"`                                 // API端点
 	Token         string    `json:"token" toml:"token"`                                       // 访问令牌
 	EncryptKey    string    `json:"encrypt_key" toml:"encrypt_key"`                           // 加密密钥
@@ -125,9 +193,16 @@ type SyncConfig struct {
 	SyncInterval  int       `json:"sync_interval" toml:"sync_interval"`                       // 同步间隔(分钟)
 	LastSync      time.Time `json:"last_sync" toml:"last_sync"`                               // 最后同步时间
 	DeviceID      string    `json:"device_id" toml:"device_id"`                               // 设备ID
-	GistID        string    `json:"gist_id,omitempty" toml:"gist_id,omitempty"`               // GitHub Gist ID
+	DeviceUUID    string    `json:"device_uuid,omitempty" toml:"device_uuid,omitempty"`       // 设备唯一标识（首次使用时随机生成）
+	GistID        string    `json:"gist_id,omitempty" toml:"gist_id,omitempty"`               // Gist ID（GitLab 为代码片段 ID）
 	SyncAPIKeys   bool      `json:"sync_api_keys" toml:"sync_api_keys"`                       // 是否同步API密钥
 	EncryptionPwd string    `json:"encryption_pwd,omitempty" toml:"encryption_pwd,omitempty"` // 加密密码（可选，用于额外安全层）
+	// 最近一次推送/拉取的结果，用于发现静默失败的自动同步
+	LastSyncSuccess bool   `json:"last_sync_success" toml:"last_sync_success"`                 // 最近一次同步是否成功
+	LastSyncError   string `json:"last_sync_error,omitempty" toml:"last_sync_error,omitempty"` // 最近一次同步失败的原因
+	// 最近一次推送/拉取时看到的云端写入者，用于排查来自其他设备的意外修改
+	LastRemoteDevice    string    `json:"last_remote_device,omitempty" toml:"last_remote_device,omitempty"`       // 云端配置最后写入的设备ID
+	LastRemoteTimestamp time.Time `json:"last_remote_timestamp,omitempty" toml:"last_remote_timestamp,omitempty"` // 云端配置最后写入的时间
 }
 
 // SyncData 同步数据结构.
@@ -138,7 +213,8 @@ type SyncData struct {
 	Timestamp         time.Time      `json:"timestamp"`                 // 时间戳
 	DeviceID          string         `json:"device_id"`                 // 设备ID
 	Version           string         `json:"version"`                   // 配置版本
-	Checksum          string         `json:"checksum,omitempty"`        // 数据校验和
+	Checksum          string         `json:"checksum,omitempty"`        // 旧版数据校验和（仅覆盖 Mirrors 的 MD5，保留以兼容旧客户端）
+	Integrity         string         `json:"integrity,omitempty"`       // 完整性校验值，格式为 "<算法>:<十六进制摘要>"
 	HasAPIKeys        bool           `json:"has_api_keys"`              // 是否包含API密钥
 	DeletedMirrors    []MirrorConfig `json:"deleted_mirrors,omitempty"` // 已删除的镜像源（用于追踪删除操作）
 	ValidatedChecksum bool           `json:"-"`                         // 本地校验标记（不参与序列化）
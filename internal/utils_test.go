@@ -1,6 +1,11 @@
 package internal
 
 import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -51,3 +56,68 @@ func TestMaskAPIKey(t *testing.T) {
 		})
 	}
 }
+
+// TestWriteFileAtomic 测试原子写入.
+func TestWriteFileAtomic(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    bool
+		writeErr    bool
+		expected    string
+		expectError bool
+	}{
+		{name: "写入新文件", expected: "new content"},
+		{name: "替换已有文件", existing: true, expected: "new content"},
+		{name: "写入中途失败保留原文件", existing: true, writeErr: true, expected: "original", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.toml")
+			if tt.existing {
+				if err := os.WriteFile(path, []byte("original"), 0o644); err != nil {
+					t.Fatalf("创建原文件失败: %v", err)
+				}
+			}
+
+			err := WriteFileAtomic(path, 0o600, func(w io.Writer) error {
+				if tt.writeErr {
+					_, _ = io.WriteString(w, "partial")
+					return errors.New("模拟写入失败")
+				}
+				_, err := io.WriteString(w, "new content")
+				return err
+			})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("WriteFileAtomic() error = %v, expectError %v", err, tt.expectError)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("读取文件失败: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("文件内容 = %q, 期望 %q", data, tt.expected)
+			}
+
+			// 不应残留临时文件
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 1 {
+				t.Errorf("目录中应只有目标文件，实际 %d 个文件", len(entries))
+			}
+
+			// 保留原文件权限
+			if runtime.GOOS != WindowsOS {
+				info, _ := os.Stat(path)
+				wantMode := os.FileMode(0o600)
+				if tt.existing {
+					wantMode = 0o644
+				}
+				if info.Mode().Perm() != wantMode {
+					t.Errorf("文件权限 = %v, 期望 %v", info.Mode().Perm(), wantMode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 版本信息变量，通过 ldflags 在构建时注入.
+// 构建命令示例:
+// go build -ldflags "-X codex-mirror/internal.Version=1.2.0 -X codex-mirror/internal.GitCommit=abc123 -X codex-mirror/internal.BuildTime=2024-01-01T00:00:00Z" .
+var (
+	// Version 版本号.
+	Version = "dev"
+	// GitCommit Git 提交 hash.
+	GitCommit = "unknown"
+	// BuildTime 构建时间.
+	BuildTime = "unknown"
+	// ReleaseRepo 用于检查更新的 GitHub 仓库（owner/name）.
+	ReleaseRepo = "meimingqi222/codex-mirror-switch"
+)
+
+// DefaultUpdateCheckTimeout 检查更新的默认超时时间.
+const DefaultUpdateCheckTimeout = 5 * time.Second
+
+// BuildInfo 构建信息.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// GetBuildInfo 返回当前程序的构建信息.
+func GetBuildInfo() BuildInfo {
+	return BuildInfo{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
+
+// UpdateInfo 更新检查结果.
+type UpdateInfo struct {
+	CurrentVersion  string `json:"current_version"`
+	LatestVersion   string `json:"latest_version"`
+	ReleaseURL      string `json:"release_url"`
+	UpdateAvailable bool   `json:"update_available"`
+}
+
+// UpdateChecker 通过 GitHub Releases API 检查新版本.
+type UpdateChecker struct {
+	// APIURL 为空时使用 GitHub 官方 API 地址，测试时可替换.
+	APIURL string
+	// Timeout 为 0 时使用 DefaultUpdateCheckTimeout.
+	Timeout time.Duration
+}
+
+// Check 查询最新发布版本，并与当前版本比较.
+func (uc *UpdateChecker) Check(ctx context.Context) (*UpdateInfo, error) {
+	apiURL := uc.APIURL
+	if apiURL == "" {
+		apiURL = fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", ReleaseRepo)
+	}
+	timeout := uc.Timeout
+	if timeout <= 0 {
+		timeout = DefaultUpdateCheckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求发布信息失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取发布信息失败: HTTP %d", resp.StatusCode)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("解析发布信息失败: %v", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("发布信息缺少版本号")
+	}
+
+	return &UpdateInfo{
+		CurrentVersion:  Version,
+		LatestVersion:   release.TagName,
+		ReleaseURL:      release.HTMLURL,
+		UpdateAvailable: IsNewerVersion(Version, release.TagName),
+	}, nil
+}
+
+// IsNewerVersion 判断 latest 是否比 current 更新；current 不是有效版本号（如 dev）时视为有更新.
+func IsNewerVersion(current, latest string) bool {
+	latestParts, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	currentParts, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+
+	for i := range latestParts {
+		if latestParts[i] != currentParts[i] {
+			return latestParts[i] > currentParts[i]
+		}
+	}
+	return false
+}
+
+// parseVersion 将 "v1.2.3" 形式的版本号解析为主、次、修订号，忽略预发布和构建后缀.
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return parts, false
+	}
+
+	fields := strings.Split(version, ".")
+	if len(fields) > len(parts) {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestIsNewerVersion 测试版本号比较.
+func TestIsNewerVersion(t *testing.T) {
+	tests := []struct {
+		current  string
+		latest   string
+		expected bool
+	}{
+		{"v1.2.0", "v1.3.0", true},
+		{"1.2.0", "v1.2.1", true},
+		{"v1.10.0", "v1.9.9", false},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2", "v1.2.0", false},
+		{"v1.2.0-rc1", "v1.2.0", false},
+		{"dev", "v1.0.0", true},
+		{"v1.0.0", "nightly", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsNewerVersion(tt.current, tt.latest); got != tt.expected {
+			t.Errorf("IsNewerVersion(%q, %q) = %v, expected %v", tt.current, tt.latest, got, tt.expected)
+		}
+	}
+}
+
+// TestUpdateCheckerCheck 测试从发布 API 获取最新版本.
+func TestUpdateCheckerCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name": "v99.0.0", "html_url": "https://example.com/releases/v99.0.0"}`))
+	}))
+	defer server.Close()
+
+	checker := &UpdateChecker{APIURL: server.URL}
+	info, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !info.UpdateAvailable || info.LatestVersion != "v99.0.0" || info.ReleaseURL == "" {
+		t.Errorf("unexpected update info: %+v", info)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+
+	checker = &UpdateChecker{APIURL: failing.URL}
+	if _, err := checker.Check(context.Background()); err == nil {
+		t.Error("Check() should fail on non-200 responses")
+	}
+}
@@ -14,12 +14,26 @@ type VSCodeConfigManager struct {
 	settingsPath string
 }
 
-// NewVSCodeConfigManager 创建新的VS Code配置管理器.
+// NewVSCodeConfigManager 创建新的VS Code配置管理器（自动检测稳定版或 Insiders）.
 func NewVSCodeConfigManager() (*VSCodeConfigManager, error) {
 	settingsPath, err := GetVSCodeSettingsPath()
 	if err != nil {
 		return nil, fmt.Errorf("获取VS Code设置路径失败: %v", err)
 	}
+	return newVSCodeConfigManager(settingsPath)
+}
+
+// NewVSCodeConfigManagerForVariant 创建指定发行版本的VS Code配置管理器.
+func NewVSCodeConfigManagerForVariant(variant VSCodeVariant) (*VSCodeConfigManager, error) {
+	settingsPath, err := GetVSCodeSettingsPathForVariant(variant)
+	if err != nil {
+		return nil, fmt.Errorf("获取VS Code设置路径失败: %v", err)
+	}
+	return newVSCodeConfigManager(settingsPath)
+}
+
+// newVSCodeConfigManager 使用指定设置文件路径创建VS Code配置管理器.
+func newVSCodeConfigManager(settingsPath string) (*VSCodeConfigManager, error) {
 
 	// 确保VS Code配置目录存在
 	configDir := filepath.Dir(settingsPath)
@@ -57,11 +71,8 @@ func (vcm *VSCodeConfigManager) LoadSettings() (map[string]interface{}, error) {
 		return nil, fmt.Errorf("读取VS Code设置文件失败: %v", err)
 	}
 
-	// 移除JSONC注释（支持 // 和 /* */）
-	cleanedJSON := RemoveJSONComments(buf.String())
-
-	// 解析JSON
-	if err := json.Unmarshal([]byte(cleanedJSON), &settings); err != nil {
+	// 解析JSONC（支持 // 和 /* */ 注释以及尾随逗号）
+	if err := ParseJSONC(buf.Bytes(), &settings); err != nil {
 		return nil, fmt.Errorf("解析VS Code设置文件失败: %v", err)
 	}
 
@@ -203,15 +214,16 @@ func (vcm *VSCodeConfigManager) SaveSettings(settings map[string]interface{}) er
 
 // ApplyMirror 应用镜像源配置到VS Code.
 func (vcm *VSCodeConfigManager) ApplyMirror(mirror *MirrorConfig) error {
+	if err := checkMirrorToolType(mirror, ToolTypeCodex); err != nil {
+		return err
+	}
+
 	// 加载现有设置
 	settings, err := vcm.LoadSettings()
 	if err != nil {
 		return fmt.Errorf("加载VS Code设置失败: %v", err)
 	}
 
-	// 更新chatgpt.apiBase
-	settings["chatgpt.apiBase"] = mirror.BaseURL
-
 	// 更新chatgpt.config，只保留基本配置，不设置baseurl和key
 	chatgptConfig := make(map[string]interface{})
 	if existingConfig, exists := settings["chatgpt.config"]; exists {
@@ -222,11 +234,7 @@ func (vcm *VSCodeConfigManager) ApplyMirror(mirror *MirrorConfig) error {
 
 	// 设置基本配置项
 	chatgptConfig["preferred_auth_method"] = "apikey"
-	if mirror.ModelName != "" {
-		chatgptConfig["model"] = mirror.ModelName
-	} else {
-		chatgptConfig["model"] = DefaultModelGPT4
-	}
+	chatgptConfig["model"] = codexModelName(mirror)
 	chatgptConfig["model_reasoning_effort"] = "medium"
 	chatgptConfig["wire_api"] = "messages"
 
@@ -234,16 +242,43 @@ func (vcm *VSCodeConfigManager) ApplyMirror(mirror *MirrorConfig) error {
 	delete(chatgptConfig, "apiKey")
 	delete(chatgptConfig, "apiBaseUrl")
 
-	settings["chatgpt.config"] = chatgptConfig
-
-	// 保存设置
-	if err := vcm.SaveSettings(settings); err != nil {
+	// 只修改 chatgpt.* 配置项，保留文件中的注释和其他内容
+	if err := EditJSONCFile(vcm.settingsPath, func(src string) (string, error) {
+		src, err := SetJSONCMember(src, "chatgpt.apiBase", mirror.BaseURL)
+		if err != nil {
+			return "", err
+		}
+		return SetJSONCMember(src, "chatgpt.config", chatgptConfig)
+	}); err != nil {
 		return fmt.Errorf("保存VS Code设置失败: %v", err)
 	}
 
 	return nil
 }
 
+// PlanMirror 计算应用镜像源后 VS Code 设置的变化，不写入任何文件.
+func (vcm *VSCodeConfigManager) PlanMirror(mirror *MirrorConfig) ([]ConfigChange, error) {
+	if err := checkMirrorToolType(mirror, ToolTypeCodex); err != nil {
+		return nil, err
+	}
+
+	settings, err := vcm.LoadSettings()
+	if err != nil {
+		return nil, fmt.Errorf("加载VS Code设置失败: %v", err)
+	}
+
+	oldBase, _ := settings["chatgpt.apiBase"].(string)
+	oldModel := ""
+	if configMap, ok := settings["chatgpt.config"].(map[string]interface{}); ok {
+		oldModel, _ = configMap["model"].(string)
+	}
+
+	return []ConfigChange{
+		{File: vcm.settingsPath, Key: "chatgpt.apiBase", Old: oldBase, New: mirror.BaseURL},
+		{File: vcm.settingsPath, Key: "chatgpt.config.model", Old: oldModel, New: codexModelName(mirror)},
+	}, nil
+}
+
 // GetCurrentConfig 获取当前VS Code中的ChatGPT配置.
 func (vcm *VSCodeConfigManager) GetCurrentConfig() (map[string]interface{}, error) {
 	settings, err := vcm.LoadSettings()
@@ -289,17 +324,18 @@ func (vcm *VSCodeConfigManager) BackupSettings() error {
 
 // RemoveChatGPTConfig 移除ChatGPT相关配置.
 func (vcm *VSCodeConfigManager) RemoveChatGPTConfig() error {
-	settings, err := vcm.LoadSettings()
-	if err != nil {
+	if _, err := vcm.LoadSettings(); err != nil {
 		return fmt.Errorf("加载VS Code设置失败: %v", err)
 	}
 
-	// 删除chatgpt相关配置
-	delete(settings, "chatgpt.apiBase")
-	delete(settings, "chatgpt.config")
-
-	// 保存设置
-	if err := vcm.SaveSettings(settings); err != nil {
+	// 只删除chatgpt相关配置，保留文件中的注释和其他内容
+	if err := EditJSONCFile(vcm.settingsPath, func(src string) (string, error) {
+		src, err := DeleteJSONCMember(src, "chatgpt.apiBase")
+		if err != nil {
+			return "", err
+		}
+		return DeleteJSONCMember(src, "chatgpt.config")
+	}); err != nil {
 		return fmt.Errorf("保存VS Code设置失败: %v", err)
 	}
 
@@ -740,3 +740,55 @@ line2 */`,
 		})
 	}
 }
+
+// TestApplyMirrorPreservesComments 测试应用镜像源后保留设置文件中的注释.
+func TestApplyMirrorPreservesComments(t *testing.T) {
+	tempDir := setupTestDir(t)
+	vcm := createTestVSCodeConfigManager(t, tempDir)
+
+	original := `{
+  // 字体设置
+  "editor.fontSize": 14,
+  /* 旧的 ChatGPT 地址 */
+  "chatgpt.apiBase": "https://old.example.com",
+  "files.exclude": {
+    "**/.git": true, // 隐藏 git 目录
+  },
+}
+`
+	if err := os.WriteFile(vcm.settingsPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("写入设置文件失败: %v", err)
+	}
+
+	mirror := &MirrorConfig{Name: "jsonc", BaseURL: "https://new.example.com", ToolType: ToolTypeCodex}
+	if err := vcm.ApplyMirror(mirror); err != nil {
+		t.Fatalf("ApplyMirror() error = %v", err)
+	}
+
+	data, err := os.ReadFile(vcm.settingsPath)
+	if err != nil {
+		t.Fatalf("读取设置文件失败: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{"// 字体设置", "/* 旧的 ChatGPT 地址 */", "// 隐藏 git 目录", `"chatgpt.apiBase": "https://new.example.com"`} {
+		if !strings.Contains(content, want) {
+			t.Errorf("设置文件缺少 %q:\n%s", want, content)
+		}
+	}
+
+	settings, err := vcm.LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings() error = %v", err)
+	}
+	if settings["editor.fontSize"] != float64(14) || settings["chatgpt.config"] == nil {
+		t.Errorf("设置内容不正确: %v", settings)
+	}
+
+	if err := vcm.RemoveChatGPTConfig(); err != nil {
+		t.Fatalf("RemoveChatGPTConfig() error = %v", err)
+	}
+	data, _ = os.ReadFile(vcm.settingsPath)
+	if strings.Contains(string(data), "chatgpt.apiBase") || !strings.Contains(string(data), "// 字体设置") {
+		t.Errorf("移除 ChatGPT 配置后内容不正确:\n%s", data)
+	}
+}